@@ -1,21 +1,308 @@
+// Сервер авторизации: endpoints OAuth 2.0 и административное API поверх
+// хранилища в JSON файле, которое ведет authctl. Пока сервер работает, файл
+// заблокирован: изменяющие команды authctl отказываются запускаться, а
+// изменения вносятся через административное API.
+//
+// Гранты jwt-bearer (RFC 7523) и CIBA не подключаются: первому нужны ключи
+// доверенных издателей, второму — доставка запросов на устройства
+// пользователей, для которой в сервисе нет адаптера, кроме тестового.
 package main
 
 import (
-  "fmt"
+	"AuthAndOauth/internal/adapters/cli"
+	"AuthAndOauth/internal/adapters/http/admin"
+	"AuthAndOauth/internal/adapters/http/clientcert"
+	"AuthAndOauth/internal/adapters/http/oauth"
+	"AuthAndOauth/internal/adapters/repository/file"
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/pkg/yaml"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
 )
 
-//TIP <p>To run your code, right-click the code and select <b>Run</b>.</p> <p>Alternatively, click
-// the <icon src="AllIcons.Actions.Execute"/> icon in the gutter and select the <b>Run</b> menu item from here.</p>
+// RegistrationTokensEnv переменная окружения с начальными токенами динамической
+// регистрации клиентов через запятую; без нее endpoint /register отключен
+const RegistrationTokensEnv = "AUTH_REGISTRATION_TOKENS"
+
+// config параметры запуска сервера
+type config struct {
+	addr          string
+	issuer        string
+	dataFile      string
+	scopesFile    string
+	resourcesFile string
+	loginURL      string
+
+	jwtAccessTokens bool
+
+	certificateHeader       string
+	certificateVerifyHeader string
+}
 
 func main() {
-  //TIP <p>Press <shortcut actionId="ShowIntentionActions"/> when your caret is at the underlined text
-  // to see how GoLand suggests fixing the warning.</p><p>Alternatively, if available, click the lightbulb to view possible fixes.</p>
-  s := "gopher"
-  fmt.Println("Hello and welcome, %s!", s)
-
-  for i := 1; i <= 5; i++ {
-	//TIP <p>To start your debugging session, right-click your code in the editor and select the Debug option.</p> <p>We have set one <icon src="AllIcons.Debugger.Db_set_breakpoint"/> breakpoint
-	// for you, but you can always add more by pressing <shortcut actionId="ToggleLineBreakpoint"/>.</p>
-	fmt.Println("i =", 100/i)
-  }
-}
\ No newline at end of file
+	logger, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "server: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
+	service.SetLogger(logger)
+
+	var cfg config
+	flag.StringVar(&cfg.addr, "addr", ":8080", "listen address")
+	flag.StringVar(&cfg.issuer, "issuer", "", "issuer identifier, the external https URL of the server")
+	flag.StringVar(&cfg.dataFile, "data", os.Getenv(cli.DataFileEnv), "path to the data file maintained by authctl (default $"+cli.DataFileEnv+")")
+	flag.StringVar(&cfg.scopesFile, "scopes", "", "YAML file with the scope registry: hierarchy, templates and descriptions")
+	flag.StringVar(&cfg.resourcesFile, "resources", "", "YAML file with the protected resources clients may request with the resource parameter (default: true marks the audience of grants that name no resource)")
+	flag.BoolVar(&cfg.jwtAccessTokens, "jwt-access-tokens", false, "issue access tokens as signed JWTs (RFC 9068) instead of opaque values")
+	flag.StringVar(&cfg.loginURL, "login-url", "", "login page for users of the consent and confirmation pages")
+	flag.StringVar(&cfg.certificateHeader, "client-cert-header", "", "header with the URL-encoded PEM client certificate set by the TLS proxy")
+	flag.StringVar(&cfg.certificateVerifyHeader, "client-cert-verify-header", "", "header with the proxy's certificate chain verification result")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		logger.Error("server stopped", zap.Error(err))
+		logger.Sync()
+		os.Exit(1)
+	}
+}
+
+// run блокирует хранилище, обслуживает запросы до отмены ctx и сохраняет
+// снимок хранилища после каждого изменяющего запроса
+func run(ctx context.Context, cfg config) error {
+	if cfg.issuer == "" {
+		return fmt.Errorf("-issuer is required")
+	}
+	if cfg.dataFile == "" {
+		return fmt.Errorf("-data or $%s is required", cli.DataFileEnv)
+	}
+
+	lock, err := file.Acquire(cfg.dataFile)
+	if errors.Is(err, file.ErrLocked) {
+		return fmt.Errorf("%s is in use by another process", cfg.dataFile)
+	}
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	store, err := file.Open(cfg.dataFile)
+	if err != nil {
+		return err
+	}
+	handler, err := newHandler(ctx, cfg, store)
+	if err != nil {
+		return err
+	}
+
+	snapshots := newSnapshotter(cfg.dataFile, store)
+	// Снимок с ключом подписи, созданным при запуске, и с доставленными событиями
+	if err := snapshots.save(); err != nil {
+		return err
+	}
+	snapshotCtx, stopSnapshots := context.WithCancel(context.Background())
+	snapshotsDone := make(chan struct{})
+	go func() {
+		snapshots.run(snapshotCtx)
+		close(snapshotsDone)
+	}()
+
+	server := &http.Server{
+		Addr:              cfg.addr,
+		Handler:           snapshots.wrap(handler),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+	zap.L().Info("server started", zap.String("addr", cfg.addr), zap.String("issuer", cfg.issuer))
+
+	var serveErr error
+	select {
+	case err := <-served:
+		serveErr = fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr = fmt.Errorf("shutdown: %w", err)
+		}
+	}
+
+	stopSnapshots()
+	<-snapshotsDone
+	if err := snapshots.save(); err != nil {
+		return errors.Join(serveErr, err)
+	}
+	return serveErr
+}
+
+// newHandler собирает endpoints OAuth 2.0 и административное API (/admin/)
+func newHandler(ctx context.Context, cfg config, store *memory.Store) (http.Handler, error) {
+	secretsConfig := service.DefaultClientSecretManagerConfig()
+	if encoded := os.Getenv(cli.SecretSealingKeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("%s must be a base64 encoded 32-byte key", cli.SecretSealingKeyEnv)
+		}
+		secretsConfig.SealingKey = key
+	}
+	secrets, err := service.NewClientSecretManager(secretsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := loadScopes(cfg.scopesFile)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := loadResources(cfg.resourcesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKeys := service.NewSigningKeyManager(store.SigningKeys, nil)
+	if _, err := signingKeys.ActiveKey(ctx); err != nil {
+		if _, err := signingKeys.Rotate(ctx); err != nil {
+			return nil, fmt.Errorf("create signing key: %w", err)
+		}
+	}
+
+	// События, записанные authctl, доставляются подписчикам до начала работы
+	events := service.NewEventDispatcher()
+	cache := service.NewPermissionCache(nil)
+	cache.Subscribe(events)
+	events.Publish(store.Events.Drain()...)
+
+	validator := service.NewTokenValidator()
+	generator := service.NewTokenGenerator(nil)
+	certificates := clientcert.Source{Header: cfg.certificateHeader, VerifyHeader: cfg.certificateVerifyHeader}
+	dpop := service.NewDPoPService(store.Replays, nil)
+	clientKeys := service.NewClientKeys(nil)
+
+	authorizationConfig := service.DefaultAuthorizationConfig()
+	authorizationConfig.Issuer = cfg.issuer
+	authorizationConfig.ClientKeys = clientKeys
+	authorizationConfig.Scopes = scopes
+	authorizationConfig.Resources = resources
+
+	oauthConfig := oauth.DefaultConfig(cfg.issuer)
+	oauthConfig.RevocationPath = "/revoke"
+	oauthConfig.IntrospectionPath = "/introspect"
+	oauthConfig.LoginURL = cfg.loginURL
+	oauthConfig.ClientCertificateHeader = cfg.certificateHeader
+	oauthConfig.ClientCertificateVerifyHeader = cfg.certificateVerifyHeader
+
+	deps := oauth.Dependencies{
+		Clients:          store.Clients,
+		Tokens:           store.Tokens,
+		ClientSecrets:    secrets,
+		TokenGenerator:   generator,
+		Validator:        validator,
+		Authenticator:    oauth.NewSessionAuthenticator("", store.Sessions, store.Users, validator),
+		SigningKeys:      signingKeys,
+		Authorization:    service.NewAuthorizationService(store.Clients, store.AuthCodes, store.PendingAuthorizations, generator, validator, authorizationConfig),
+		Devices:          service.NewDeviceAuthorizationService(store.DeviceAuthorizations, nil),
+		TokenExchange:    service.NewTokenExchangeService(store.Tokens, store.Clients, validator, nil),
+		DPoP:             dpop,
+		ClientAssertions: service.NewClientAssertionVerifier(clientKeys, secrets, store.Replays, nil),
+		Consents:         service.NewConsentService(store.Consents, store.Tokens, store.AuthCodes, store.AuditLogs),
+		Scopes:           scopes,
+		Resources:        resources,
+	}
+	if cfg.jwtAccessTokens {
+		deps.JWTAccessTokens = service.NewJWTAccessTokenEncoder(signingKeys, cfg.issuer)
+	}
+	if tokens := os.Getenv(RegistrationTokensEnv); tokens != "" {
+		registrationConfig := service.DefaultClientRegistrationConfig()
+		registrationConfig.InitialAccessTokens = strings.Split(tokens, ",")
+		deps.Registration = service.NewClientRegistrationService(store.Clients, secrets, registrationConfig)
+	}
+	// NewHandler передает реестр областей валидатору, поэтому административное
+	// API, использующее тот же валидатор, учитывает иерархию областей
+	oauthHandler := oauth.NewHandler(oauthConfig, deps)
+
+	checkerConfig := service.DefaultPermissionCheckerConfig()
+	checkerConfig.AuditLogRepository = store.AuditLogs
+	checkerConfig.Cache = cache
+
+	authenticator := admin.NewTokenAuthenticator(store.Tokens, store.Users, validator, &admin.DPoPConfig{Proofs: dpop, BaseURL: cfg.issuer})
+	authenticator.SetCertificateSource(certificates)
+	adminHandler := admin.NewHandler(admin.Dependencies{
+		Authenticator:     authenticator,
+		PermissionChecker: service.NewPermissionChecker(checkerConfig),
+		ResourceRegistry:  service.DefaultResourceRegistry(),
+		Users:             store.Users,
+		Roles:             store.Roles,
+		Permissions:       store.Permissions,
+		Clients:           store.Clients,
+		ClientSecrets:     secrets,
+		AuditLogs:         store.AuditLogs,
+		Events:            events,
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", adminHandler)
+	mux.Handle("/", oauthHandler)
+	return mux, nil
+}
+
+// loadScopes загружает реестр областей из YAML файла со списком областей;
+// без файла реестр пуст и области клиентов проверяются точным совпадением
+func loadScopes(path string) (*service.ScopeRegistry, error) {
+	if path == "" {
+		return service.NewScopeRegistry()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read scopes %s: %w", path, err)
+	}
+	var scopes []entity.Scope
+	if err := yaml.Unmarshal(data, &scopes); err != nil {
+		return nil, fmt.Errorf("load scopes %s: %w", path, err)
+	}
+	registry, err := service.NewScopeRegistry(scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("load scopes %s: %w", path, err)
+	}
+	return registry, nil
+}
+
+// loadResources загружает из YAML файла защищенные ресурсы (RFC 8707); без
+// файла параметр resource не поддерживается
+func loadResources(path string) (*service.ResourceIndicators, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read resources %s: %w", path, err)
+	}
+	var servers []entity.ResourceServer
+	if err := yaml.Unmarshal(data, &servers); err != nil {
+		return nil, fmt.Errorf("load resources %s: %w", path, err)
+	}
+	resources, err := service.NewResourceIndicators(servers...)
+	if err != nil {
+		return nil, fmt.Errorf("load resources %s: %w", path, err)
+	}
+	return resources, nil
+}
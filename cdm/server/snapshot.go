@@ -0,0 +1,66 @@
+package main
+
+import (
+	"AuthAndOauth/internal/adapters/repository/file"
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// snapshotter сохраняет хранилище в файл после запросов, которые могут его
+// изменить (выдача токенов, административное API): после аварийного
+// завершения теряются только запросы, выполнявшиеся в этот момент.
+// Запросы, пришедшие во время записи, объединяются в один следующий снимок.
+type snapshotter struct {
+	path  string
+	store *memory.Store
+	dirty chan struct{}
+}
+
+func newSnapshotter(path string, store *memory.Store) *snapshotter {
+	return &snapshotter{
+		path:  path,
+		store: store,
+		dirty: make(chan struct{}, 1),
+	}
+}
+
+// wrap помечает хранилище измененным после каждого запроса, кроме GET, HEAD и OPTIONS
+func (s *snapshotter) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			s.markDirty()
+		}
+	})
+}
+
+func (s *snapshotter) markDirty() {
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// run записывает снимки до отмены ctx
+func (s *snapshotter) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.dirty:
+			if err := s.save(); err != nil {
+				zap.L().Error("failed to save store snapshot", zap.String("path", s.path), zap.Error(err))
+			}
+		}
+	}
+}
+
+// save записывает текущее состояние хранилища
+func (s *snapshotter) save() error {
+	return file.Save(s.path, s.store)
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/valueobject"
+	"AuthAndOauth/internal/core/ports"
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// adminCreate создает роль администратора со всеми зарегистрированными
+// разрешениями и первого пользователя с этой ролью
+func (a *App) adminCreate(args []string) error {
+	fs := a.newFlagSet("admin create")
+	email := fs.String("email", "", "administrator email")
+	password := fs.String("password", "", "administrator password")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of stdin")
+	firstName := fs.String("first-name", "Admin", "first name")
+	lastName := fs.String("last-name", "User", "last name")
+	roleName := fs.String("role", "admin", "name of the administrator role")
+	if err := parseFlags(fs, args, "email"); err != nil {
+		return err
+	}
+
+	if *passwordStdin {
+		line, err := bufio.NewReader(a.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("read password from stdin: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if *password == "" {
+		return fmt.Errorf("password is required: use -password or -password-stdin")
+	}
+
+	role, err := a.ensureAdminRole(*roleName)
+	if err != nil {
+		return err
+	}
+
+	_, holders, err := a.store.Users.List(a.ctx, ports.UserFilter{RoleID: &role.ID}, ports.Pagination{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if holders > 0 {
+		return fmt.Errorf("role %s is already assigned to %d user(s); use 'role assign' to add administrators", role.Name, holders)
+	}
+
+	address, err := valueobject.NewEmail(*email)
+	if err != nil {
+		return err
+	}
+	hash, err := valueobject.NewPassword(*password, nil)
+	if err != nil {
+		return err
+	}
+
+	user := entity.NewUser(address.Address(), *firstName, *lastName, hash.Hash())
+	user.AddRole(*role)
+	if err := a.store.Users.Create(a.ctx, user); err != nil {
+		if errors.Is(err, ports.ErrAlreadyExists) {
+			return fmt.Errorf("user %s already exists; use 'role assign' to grant the %s role", address.Address(), role.Name)
+		}
+		return err
+	}
+	a.publish(user.PullEvents()...)
+
+	if err := a.audit(user.ID.String(), entity.AuditEventRoleChange, "administrator created", map[string]interface{}{
+		"role_id":   role.ID.String(),
+		"role_name": role.Name,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "created administrator %s (id %s) with role %s\n", user.Email, user.ID, role.Name)
+	return nil
+}
+
+// ensureAdminRole возвращает роль администратора, создавая ее и недостающие
+// разрешения для каждой пары ресурс:действие из реестра
+func (a *App) ensureAdminRole(name string) (*entity.Role, error) {
+	role, err := a.store.Roles.GetByName(a.ctx, name)
+	if errors.Is(err, ports.ErrNotFound) {
+		role = entity.NewRole(name, "Full administrative access")
+		if err := a.store.Roles.Create(a.ctx, role); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	existing, err := a.allPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range a.registry.Resources() {
+		for _, action := range a.registry.Actions() {
+			if !resource.SupportsAction(action.Action) {
+				continue
+			}
+			permission := findAllowPermission(existing, resource.Type, action.Action)
+			if permission == nil {
+				permission = entity.NewPermission(
+					string(resource.Type)+":"+string(action.Action),
+					resource.Type,
+					action.Action,
+					"Created by authctl admin create",
+				)
+				if err := a.store.Permissions.Create(a.ctx, permission); err != nil {
+					return nil, err
+				}
+			}
+			role.AddPermission(*permission)
+		}
+	}
+
+	if err := a.store.Roles.Update(a.ctx, role); err != nil {
+		return nil, err
+	}
+	a.publish(role.PullEvents()...)
+	return role, nil
+}
+
+func findAllowPermission(permissions []*entity.Permission, resource entity.ResourceType, action entity.Action) *entity.Permission {
+	for _, permission := range permissions {
+		if !permission.IsDeny() && permission.Matches(resource, action) {
+			return permission
+		}
+	}
+	return nil
+}
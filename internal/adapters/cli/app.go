@@ -0,0 +1,340 @@
+// Package cli реализует утилиту администрирования authctl. Команды работают
+// напрямую с хранилищами, без обращения к HTTP API сервиса.
+package cli
+
+import (
+	"AuthAndOauth/internal/adapters/repository/file"
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/domain/valueobject"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// DataFileEnv переменная окружения с путем к файлу данных
+const DataFileEnv = "AUTHCTL_DATA"
+
+// SecretSealingKeyEnv переменная окружения с ключом AES-256 в base64, которым
+// шифруются секреты клиентов client_secret_jwt; должен совпадать с ключом сервиса
+const SecretSealingKeyEnv = "AUTHCTL_SECRET_SEALING_KEY"
+
+// defaultDataFile путь к файлу данных по умолчанию
+const defaultDataFile = "authctl-data.json"
+
+// auditIP и auditUserAgent записываются в аудит для действий утилиты
+const (
+	auditIP        = "127.0.0.1"
+	auditUserAgent = "authctl"
+)
+
+// errUsage означает, что команда вызвана с неверными аргументами
+var errUsage = errors.New("usage")
+
+// command описывает подкоманду утилиты
+type command struct {
+	name        string
+	description string
+	// mutating команды сохраняют хранилище после успешного выполнения
+	mutating bool
+	run      func(a *App, args []string) error
+}
+
+var commands = []command{
+	{"admin create", "create the first administrator and the admin role", true, (*App).adminCreate},
+	{"client create", "register a client and print its secret once", true, (*App).clientCreate},
+	{"client rotate-secret", "generate a new client secret and print it once", true, (*App).clientRotateSecret},
+	{"client migrate", "import clients from the legacy OAuth client format", true, (*App).clientMigrate},
+	{"client deactivate", "deactivate a client", true, (*App).clientDeactivate},
+	{"role assign", "assign a role to a user", true, (*App).roleAssign},
+	{"role unassign", "remove a role from a user", true, (*App).roleUnassign},
+	{"user revoke", "revoke all tokens and sessions of a user", true, (*App).userRevoke},
+	{"keys rotate", "generate a new signing key and retire the current one", true, (*App).keysRotate},
+	{"keys list", "list signing keys", false, (*App).keysList},
+	{"config export", "export permissions, roles and clients as YAML", false, (*App).configExport},
+	{"config import", "import permissions, roles and clients from YAML", true, (*App).configImport},
+}
+
+// App состояние одного запуска утилиты
+type App struct {
+	ctx      context.Context
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	store    *memory.Store
+	registry *service.ResourceRegistry
+	secrets  *service.ClientSecretManager
+}
+
+// Run выполняет утилиту с аргументами командной строки (без имени программы)
+// и возвращает код завершения
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("authctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	dataFile := global.String("data", "", "path to the data file (default $"+DataFileEnv+" or "+defaultDataFile+")")
+	verbose := global.Bool("verbose", false, "print service debug logs to stderr")
+	global.Usage = func() { printUsage(stderr, global) }
+
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, rest, ok := findCommand(global.Args())
+	if !ok {
+		printUsage(stderr, global)
+		return 2
+	}
+
+	if !*verbose {
+		service.SetLogger(zap.NewNop())
+		valueobject.SetLogger(zap.NewNop())
+	}
+
+	path := *dataFile
+	if path == "" {
+		path = os.Getenv(DataFileEnv)
+	}
+	if path == "" {
+		path = defaultDataFile
+	}
+
+	secretsConfig := service.DefaultClientSecretManagerConfig()
+	if encoded := os.Getenv(SecretSealingKeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			fmt.Fprintf(stderr, "authctl: %s must be a base64 encoded 32-byte key\n", SecretSealingKeyEnv)
+			return 1
+		}
+		secretsConfig.SealingKey = key
+	}
+
+	secrets, err := service.NewClientSecretManager(secretsConfig)
+	if err != nil {
+		fmt.Fprintf(stderr, "authctl: %v\n", err)
+		return 1
+	}
+
+	// Изменяющие команды не запускаются, пока хранилище открыто сервером:
+	// сервер сохранил бы свой снимок поверх их изменений
+	if cmd.mutating {
+		lock, err := file.Acquire(path)
+		if errors.Is(err, file.ErrLocked) {
+			fmt.Fprintf(stderr, "authctl: %s is in use by the server; use the admin API or stop the server\n", path)
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "authctl: %v\n", err)
+			return 1
+		}
+		defer lock.Release()
+	}
+
+	store, err := file.Open(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "authctl: %v\n", err)
+		return 1
+	}
+
+	app := &App{
+		ctx:      ctx,
+		stdin:    stdin,
+		stdout:   stdout,
+		stderr:   stderr,
+		store:    store,
+		registry: service.DefaultResourceRegistry(),
+		secrets:  secrets,
+	}
+
+	if err := cmd.run(app, rest); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "authctl %s: %v\n", cmd.name, err)
+		return 1
+	}
+
+	if cmd.mutating {
+		if err := file.Save(path, store); err != nil {
+			fmt.Fprintf(stderr, "authctl: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// findCommand находит подкоманду по первым двум аргументам
+func findCommand(args []string) (command, []string, bool) {
+	if len(args) < 2 {
+		return command{}, nil, false
+	}
+	name := args[0] + " " + args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, args[2:], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: authctl [global flags] <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-22s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	global.PrintDefaults()
+}
+
+// publish записывает доменные события в хранилище: authctl работает без
+// подписчиков, и события доставляет сервис, который загружает хранилище
+// (например, чтобы сбросить кеш разрешений)
+func (a *App) publish(events ...entity.DomainEvent) {
+	if len(events) > 0 {
+		a.store.Events.Append(events...)
+	}
+}
+
+// newFlagSet создает набор флагов подкоманды
+func (a *App) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("authctl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parseFlags разбирает флаги и проверяет обязательные значения
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return errUsage
+	}
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(fs.Output(), "flag -%s is required\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// stringList флаг, который можно указать несколько раз; значения через запятую также принимаются
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// userByEmail находит пользователя по email
+func (a *App) userByEmail(email string) (*entity.User, error) {
+	user, err := a.store.Users.GetByEmail(a.ctx, email)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, fmt.Errorf("user %s not found", email)
+	}
+	return user, err
+}
+
+// clientByClientID находит клиента по client_id
+func (a *App) clientByClientID(clientID string) (*entity.Client, error) {
+	client, err := a.store.Clients.GetByClientID(a.ctx, clientID)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+	return client, err
+}
+
+// audit сохраняет запись аудита о действии утилиты
+func (a *App) audit(userID string, eventType entity.AuditEventType, description string, metadata map[string]interface{}) error {
+	auditLog := entity.NewAuditLog(userID, eventType, description, auditIP, auditUserAgent, true)
+	auditLog.AddMetadata("actor", auditUserAgent)
+	for key, value := range metadata {
+		auditLog.AddMetadata(key, value)
+	}
+	return a.store.AuditLogs.Save(a.ctx, auditLog)
+}
+
+// allPermissions возвращает все разрешения постранично
+func (a *App) allPermissions() ([]*entity.Permission, error) {
+	var result []*entity.Permission
+	page := ports.Pagination{Limit: ports.MaxPageLimit}
+	for {
+		items, total, err := a.store.Permissions.List(a.ctx, ports.PermissionFilter{}, page)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		page.Offset += len(items)
+		if len(items) == 0 || page.Offset >= total {
+			return result, nil
+		}
+	}
+}
+
+// allRoles возвращает все роли постранично
+func (a *App) allRoles() ([]*entity.Role, error) {
+	var result []*entity.Role
+	page := ports.Pagination{Limit: ports.MaxPageLimit}
+	for {
+		items, total, err := a.store.Roles.List(a.ctx, ports.RoleFilter{}, page)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		page.Offset += len(items)
+		if len(items) == 0 || page.Offset >= total {
+			return result, nil
+		}
+	}
+}
+
+// allClients возвращает всех клиентов постранично
+func (a *App) allClients() ([]*entity.Client, error) {
+	var result []*entity.Client
+	page := ports.Pagination{Limit: ports.MaxPageLimit}
+	for {
+		items, total, err := a.store.Clients.List(a.ctx, ports.ClientFilter{}, page)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		page.Offset += len(items)
+		if len(items) == 0 || page.Offset >= total {
+			return result, nil
+		}
+	}
+}
+
+// sortedGrantTypes возвращает известные типы авторизации для сообщений об ошибках
+func sortedGrantTypes() []string {
+	types := make([]string, 0)
+	for _, grantType := range entity.SupportedGrantTypes() {
+		types = append(types, string(grantType))
+	}
+	sort.Strings(types)
+	return types
+}
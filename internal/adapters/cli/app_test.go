@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"AuthAndOauth/internal/adapters/repository/file"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runCLI выполняет authctl с файлом данных path и возвращает код завершения и вывод
+func runCLI(t *testing.T, path string, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), append([]string{"-data", path}, args...), &bytes.Buffer{}, &stdout, &stderr)
+	return code, stdout.String() + stderr.String()
+}
+
+func TestRoleChangesArePublishedToStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if code, out := runCLI(t, path, "admin", "create", "-email", "admin@example.com", "-password", "Str0ng!Passw0rd"); code != 0 {
+		t.Fatalf("admin create exited with %d: %s", code, out)
+	}
+	if code, out := runCLI(t, path, "role", "unassign", "-email", "admin@example.com", "-role", "admin"); code != 0 {
+		t.Fatalf("role unassign exited with %d: %s", code, out)
+	}
+
+	store, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	counts := make(map[entity.DomainEventType]int)
+	for _, event := range store.Events.Drain() {
+		counts[event.Type]++
+	}
+	if counts[entity.EventRolePermissionsChanged] == 0 {
+		t.Error("admin role changes were not published")
+	}
+	if counts[entity.EventUserRolesChanged] < 2 {
+		t.Errorf("user role changes published %d times, want at least 2", counts[entity.EventUserRolesChanged])
+	}
+}
+
+func TestMutatingCommandsRefuseLockedStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	lock, err := file.Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, out := runCLI(t, path, "admin", "create", "-email", "admin@example.com", "-password", "Str0ng!Passw0rd")
+	if code == 0 || !strings.Contains(out, "in use by the server") {
+		t.Fatalf("admin create on a locked store exited with %d: %s", code, out)
+	}
+	if code, out := runCLI(t, path, "keys", "list"); code != 0 {
+		t.Errorf("keys list on a locked store exited with %d: %s", code, out)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if code, out := runCLI(t, path, "admin", "create", "-email", "admin@example.com", "-password", "Str0ng!Passw0rd"); code != 0 {
+		t.Fatalf("admin create after the lock was released exited with %d: %s", code, out)
+	}
+}
+
+func TestClientMigrateValidatesBeforeSaving(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	legacy := filepath.Join(dir, "legacy.json")
+	writeFile(t, legacy, `[
+		{"clinet_id": "good", "clinet_secret": "legacy-secret", "redirect_uris": "https://good.example.com/cb", "grant_types": "authorization_code", "scopes": ["openid"], "is_active": true},
+		{"clinet_id": "bad", "clinet_secret": "other", "redirect_uris": "", "grant_types": "authorization_code", "scopes": ["openid"], "is_active": true}
+	]`)
+	if code, out := runCLI(t, path, "client", "migrate", "-in", legacy); code == 0 {
+		t.Fatalf("migration with an invalid client succeeded: %s", out)
+	}
+	if store, err := file.Open(path); err == nil {
+		if _, err := store.Clients.GetByClientID(context.Background(), "good"); err == nil {
+			t.Error("valid client was saved although the migration failed")
+		}
+	}
+}
+
+func TestClientMigrateKeepsSecretsOfExistingClients(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	legacy := filepath.Join(dir, "legacy.json")
+	writeFile(t, legacy, `[{"clinet_id": "app", "clinet_secret": "legacy-secret", "redirect_uris": "https://app.example.com/cb", "grant_types": "authorization_code", "scopes": ["openid"], "is_active": true}]`)
+
+	if code, out := runCLI(t, path, "client", "migrate", "-in", legacy); code != 0 {
+		t.Fatalf("first migration exited with %d: %s", code, out)
+	}
+	code, out := runCLI(t, path, "client", "rotate-secret", "-client-id", "app", "-overlap", "1ns")
+	if code != 0 {
+		t.Fatalf("rotate-secret exited with %d: %s", code, out)
+	}
+	_, rotated, _ := strings.Cut(out, "client_secret: ")
+	rotated, _, _ = strings.Cut(rotated, "\n")
+	time.Sleep(time.Millisecond)
+
+	if code, out := runCLI(t, path, "client", "migrate", "-in", legacy); code != 0 {
+		t.Fatalf("second migration exited with %d: %s", code, out)
+	}
+	store, err := file.Open(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	client, err := store.Clients.GetByClientID(context.Background(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := service.DefaultClientSecretManager()
+	if !secrets.Verify(client, rotated) {
+		t.Error("rotated secret stopped working after the second migration")
+	}
+	if secrets.Verify(client, "legacy-secret") {
+		t.Error("second migration restored the legacy secret")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// clientCreate регистрирует клиента и однократно печатает его секрет.
+// Публичные клиенты (-type public) создаются без секрета.
+func (a *App) clientCreate(args []string) error {
+	fs := a.newFlagSet("client create")
+	name := fs.String("name", "", "client name")
+	description := fs.String("description", "", "client description")
+	clientType := fs.String("type", string(entity.ClientTypeConfidential), "client type: confidential or public")
+	authMethod := fs.String("auth-method", "", "token endpoint auth method (default client_secret_basic, none for public clients)")
+	accessTTL := fs.Duration("access-token-ttl", 0, "access token lifetime (0 for the server default)")
+	refreshTTL := fs.Duration("refresh-token-ttl", 0, "refresh token lifetime (0 for the server default)")
+	clientURI := fs.String("client-uri", "", "client home page")
+	logoURI := fs.String("logo-uri", "", "client logo")
+	policyURI := fs.String("policy-uri", "", "privacy policy page")
+	tosURI := fs.String("tos-uri", "", "terms of service page")
+	requirePAR := fs.Bool("require-par", false, "accept authorization requests only through pushed authorization requests")
+	requireDPoP := fs.Bool("require-dpop", false, "issue only DPoP-bound tokens to the client")
+	var redirectURIs, grantTypes, responseTypes, scopes stringList
+	fs.Var(&redirectURIs, "redirect-uri", "allowed redirect URI (repeatable)")
+	fs.Var(&grantTypes, "grant-type", "allowed grant type (repeatable): "+strings.Join(sortedGrantTypes(), ", "))
+	fs.Var(&responseTypes, "response-type", "allowed response type (repeatable; default code for authorization_code clients)")
+	fs.Var(&scopes, "scope", "allowed scope (repeatable)")
+	if err := parseFlags(fs, args, "name", "grant-type"); err != nil {
+		return err
+	}
+
+	types, err := parseGrantTypes(grantTypes)
+	if err != nil {
+		return err
+	}
+
+	var client *entity.Client
+	switch entity.ClientType(*clientType) {
+	case entity.ClientTypeConfidential:
+		client = entity.NewClient(*name, *description, redirectURIs, types, scopes)
+	case entity.ClientTypePublic:
+		client = entity.NewPublicClient(*name, *description, redirectURIs, types, scopes)
+	default:
+		return fmt.Errorf("unknown client type %q (supported: confidential, public)", *clientType)
+	}
+	if *authMethod != "" {
+		client.TokenEndpointAuthMethod = entity.TokenEndpointAuthMethod(*authMethod)
+	}
+	if len(responseTypes) > 0 {
+		client.ResponseTypes = make([]entity.ResponseType, 0, len(responseTypes))
+		for _, responseType := range responseTypes {
+			client.ResponseTypes = append(client.ResponseTypes, entity.ResponseType(responseType))
+		}
+	}
+	client.AccessTokenLifetime = int64(accessTTL.Seconds())
+	client.RefreshTokenLifetime = int64(refreshTTL.Seconds())
+	client.ClientURI = *clientURI
+	client.LogoURI = *logoURI
+	client.PolicyURI = *policyURI
+	client.TosURI = *tosURI
+	client.RequirePushedAuthorizationRequests = *requirePAR
+	client.DPoPBoundAccessTokens = *requireDPoP
+	if err := client.Validate(); err != nil {
+		return err
+	}
+
+	var secret string
+	if client.TokenEndpointAuthMethod.UsesClientSecret() {
+		if secret, err = a.secrets.Issue(client); err != nil {
+			return err
+		}
+	}
+	if err := a.store.Clients.Create(a.ctx, client); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "client_id:     %s\n", client.ClientID)
+	if secret == "" {
+		return nil
+	}
+	fmt.Fprintf(a.stdout, "client_secret: %s\n", secret)
+	fmt.Fprintln(a.stderr, "Store the secret now: it cannot be shown again.")
+	return nil
+}
+
+// clientRotateSecret выдает клиенту новый секрет; прежний действует до конца периода перекрытия
+func (a *App) clientRotateSecret(args []string) error {
+	fs := a.newFlagSet("client rotate-secret")
+	clientID := fs.String("client-id", "", "client_id of the client")
+	overlap := fs.Duration("overlap", service.DefaultClientSecretManagerConfig().RotationOverlap,
+		"how long the previous secret keeps working")
+	if err := parseFlags(fs, args, "client-id"); err != nil {
+		return err
+	}
+	if *overlap <= 0 {
+		return fmt.Errorf("overlap must be positive")
+	}
+
+	client, err := a.clientByClientID(*clientID)
+	if err != nil {
+		return err
+	}
+
+	secret, err := a.secrets.Rotate(client, *overlap)
+	if err != nil {
+		return err
+	}
+	if err := a.store.Clients.Update(a.ctx, client); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "client_id:     %s\n", client.ClientID)
+	fmt.Fprintf(a.stdout, "client_secret: %s\n", secret)
+	fmt.Fprintln(a.stderr, "Store the secret now: it cannot be shown again.")
+	return nil
+}
+
+// clientMigrate переносит клиентов из прежнего формата (JSON массив LegacyOAuthClient)
+// в хранилище. Сначала проверяются все клиенты, и при ошибке хранилище не изменяется.
+// Открытые секреты новых клиентов хешируются, поэтому существующие интеграции
+// продолжают работать. Клиенты с уже существующим client_id обновляются, но
+// сохраняют свои секреты: повторная миграция не возвращает старый секрет после ротации.
+func (a *App) clientMigrate(args []string) error {
+	fs := a.newFlagSet("client migrate")
+	in := fs.String("in", "", "JSON file with legacy clients, - for stdin")
+	if err := parseFlags(fs, args, "in"); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if *in == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", *in, err)
+	}
+
+	var legacy []entity.LegacyOAuthClient
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("decode legacy clients: %w", err)
+	}
+
+	type migration struct {
+		client   *entity.Client
+		secret   string
+		existing *entity.Client
+	}
+	migrations := make([]migration, 0, len(legacy))
+	seen := make(map[string]bool, len(legacy))
+	for i := range legacy {
+		client, secret := legacy[i].ToClient()
+		if client.ClientID == "" {
+			return fmt.Errorf("legacy client %s has no client id", client.ID)
+		}
+		if seen[client.ClientID] {
+			return fmt.Errorf("client %s is listed twice", client.ClientID)
+		}
+		seen[client.ClientID] = true
+		if err := client.Validate(); err != nil {
+			return fmt.Errorf("client %s: %w", client.ClientID, err)
+		}
+
+		existing, err := a.store.Clients.GetByClientID(a.ctx, client.ClientID)
+		switch {
+		case errors.Is(err, ports.ErrNotFound):
+			existing = nil
+		case err != nil:
+			return err
+		}
+		migrations = append(migrations, migration{client: client, secret: secret, existing: existing})
+	}
+
+	for _, m := range migrations {
+		client := m.client
+		if m.existing != nil {
+			client.ID = m.existing.ID
+			client.Name = m.existing.Name
+			client.Description = m.existing.Description
+			client.Secrets = m.existing.Secrets
+			err = a.store.Clients.Update(a.ctx, client)
+		} else {
+			if m.secret != "" {
+				if err := a.secrets.Adopt(client, m.secret); err != nil {
+					return fmt.Errorf("client %s: %w", client.ClientID, err)
+				}
+			}
+			err = a.store.Clients.Create(a.ctx, client)
+		}
+		if err != nil {
+			return fmt.Errorf("save client %s: %w", client.ClientID, err)
+		}
+		fmt.Fprintf(a.stdout, "migrated client %s (%s)\n", client.ClientID, client.Type)
+	}
+
+	fmt.Fprintf(a.stdout, "migrated %d client(s)\n", len(migrations))
+	return nil
+}
+
+// clientDeactivate деактивирует клиента
+func (a *App) clientDeactivate(args []string) error {
+	fs := a.newFlagSet("client deactivate")
+	clientID := fs.String("client-id", "", "client_id of the client")
+	if err := parseFlags(fs, args, "client-id"); err != nil {
+		return err
+	}
+
+	client, err := a.clientByClientID(*clientID)
+	if err != nil {
+		return err
+	}
+
+	client.Deactivate()
+	if err := a.store.Clients.Update(a.ctx, client); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "client %s deactivated\n", client.ClientID)
+	return nil
+}
+
+func parseGrantTypes(values []string) ([]entity.GrantType, error) {
+	types := make([]entity.GrantType, 0, len(values))
+	for _, value := range values {
+		grantType := entity.GrantType(value)
+		if !grantType.IsSupported() {
+			return nil, fmt.Errorf("unsupported grant type %q (supported: %s)", value, strings.Join(sortedGrantTypes(), ", "))
+		}
+		types = append(types, grantType)
+	}
+	return types, nil
+}
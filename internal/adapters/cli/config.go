@@ -0,0 +1,439 @@
+package cli
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"AuthAndOauth/internal/pkg/jose"
+	"AuthAndOauth/internal/pkg/yaml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// configDocument конфигурация сервиса для экспорта и импорта.
+// Роли ссылаются на разрешения по имени, клиенты идентифицируются по client_id.
+// Пользователи, токены и секреты клиентов в документ не входят.
+type configDocument struct {
+	Permissions []permissionConfig `json:"permissions"`
+	Roles       []roleConfig       `json:"roles"`
+	Clients     []clientConfig     `json:"clients"`
+}
+
+type permissionConfig struct {
+	Name        string                  `json:"name"`
+	Resource    entity.ResourceType     `json:"resource"`
+	Action      entity.Action           `json:"action"`
+	Effect      entity.PermissionEffect `json:"effect,omitempty"`
+	Description string                  `json:"description,omitempty"`
+}
+
+type roleConfig struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+type clientConfig struct {
+	ClientID                string                         `json:"client_id"`
+	Name                    string                         `json:"name"`
+	Description             string                         `json:"description,omitempty"`
+	ClientType              entity.ClientType              `json:"client_type,omitempty"`
+	TokenEndpointAuthMethod entity.TokenEndpointAuthMethod `json:"token_endpoint_auth_method,omitempty"`
+	RedirectURIs            []string                       `json:"redirect_uris"`
+	GrantTypes              []string                       `json:"grant_types"`
+	ResponseTypes           []entity.ResponseType          `json:"response_types,omitempty"`
+	Scopes                  []string                       `json:"scopes"`
+	AccessTokenLifetime     int64                          `json:"access_token_lifetime,omitempty"`
+	RefreshTokenLifetime    int64                          `json:"refresh_token_lifetime,omitempty"`
+	ClientURI               string                         `json:"client_uri,omitempty"`
+	LogoURI                 string                         `json:"logo_uri,omitempty"`
+	PolicyURI               string                         `json:"policy_uri,omitempty"`
+	TosURI                  string                         `json:"tos_uri,omitempty"`
+	TokenExchange           *entity.TokenExchangePolicy    `json:"token_exchange,omitempty"`
+	RequirePAR              bool                           `json:"require_pushed_authorization_requests,omitempty"`
+	JWKS                    *jose.JWKSet                   `json:"jwks,omitempty"`
+	JWKSURI                 string                         `json:"jwks_uri,omitempty"`
+	RequestURIs             []string                       `json:"request_uris,omitempty"`
+	RequestObjectSigningAlg string                         `json:"request_object_signing_alg,omitempty"`
+	RequireSignedRequest    bool                           `json:"require_signed_request_object,omitempty"`
+	DPoPBoundAccessTokens   bool                           `json:"dpop_bound_access_tokens,omitempty"`
+	Active                  *bool                          `json:"active,omitempty"`
+
+	entity.TLSClientAuth
+	TLSClientCertificateBoundAccessTokens bool     `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	AuthorizationDetailsTypes             []string `json:"authorization_details_types,omitempty"`
+
+	BackchannelTokenDeliveryMode          entity.BackchannelTokenDeliveryMode `json:"backchannel_token_delivery_mode,omitempty"`
+	BackchannelClientNotificationEndpoint string                              `json:"backchannel_client_notification_endpoint,omitempty"`
+	FirstParty                            bool                                `json:"first_party,omitempty"`
+}
+
+// configExport печатает конфигурацию в формате YAML
+func (a *App) configExport(args []string) error {
+	fs := a.newFlagSet("config export")
+	out := fs.String("out", "-", "output file, - for stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	doc, err := a.buildConfigDocument()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	if *out == "-" {
+		_, err = a.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o644)
+}
+
+// configImport создает или обновляет разрешения, роли и клиентов из YAML документа.
+// Записи, отсутствующие в документе, не удаляются.
+func (a *App) configImport(args []string) error {
+	fs := a.newFlagSet("config import")
+	in := fs.String("in", "", "input file, - for stdin")
+	if err := parseFlags(fs, args, "in"); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if *in == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", *in, err)
+	}
+
+	var doc configDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	permissions, err := a.importPermissions(doc.Permissions)
+	if err != nil {
+		return err
+	}
+	if err := a.importRoles(doc.Roles, permissions); err != nil {
+		return err
+	}
+	if err := a.importClients(doc.Clients); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "imported %d permission(s), %d role(s), %d client(s)\n",
+		len(doc.Permissions), len(doc.Roles), len(doc.Clients))
+	return nil
+}
+
+func (a *App) buildConfigDocument() (*configDocument, error) {
+	doc := &configDocument{
+		Permissions: make([]permissionConfig, 0),
+		Roles:       make([]roleConfig, 0),
+		Clients:     make([]clientConfig, 0),
+	}
+
+	permissions, err := a.allPermissions()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, permission := range permissions {
+		if seen[permission.Name] {
+			return nil, fmt.Errorf("permission name %q is not unique; rename permissions before exporting", permission.Name)
+		}
+		seen[permission.Name] = true
+		doc.Permissions = append(doc.Permissions, permissionConfig{
+			Name:        permission.Name,
+			Resource:    permission.Resource,
+			Action:      permission.Action,
+			Effect:      permission.Effect,
+			Description: permission.Description,
+		})
+	}
+
+	roles, err := a.allRoles()
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		names := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			names = append(names, permission.Name)
+		}
+		doc.Roles = append(doc.Roles, roleConfig{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: names,
+		})
+	}
+
+	clients, err := a.allClients()
+	if err != nil {
+		return nil, err
+	}
+	for _, client := range clients {
+		grantTypes := make([]string, 0, len(client.GrantTypes))
+		for _, grantType := range client.GrantTypes {
+			grantTypes = append(grantTypes, string(grantType))
+		}
+		active := client.Active
+		doc.Clients = append(doc.Clients, clientConfig{
+			ClientID:                client.ClientID,
+			Name:                    client.Name,
+			Description:             client.Description,
+			ClientType:              client.Type,
+			TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+			RedirectURIs:            client.RedirectURIs,
+			GrantTypes:              grantTypes,
+			ResponseTypes:           client.ResponseTypes,
+			Scopes:                  client.Scopes,
+			AccessTokenLifetime:     client.AccessTokenLifetime,
+			RefreshTokenLifetime:    client.RefreshTokenLifetime,
+			ClientURI:               client.ClientURI,
+			LogoURI:                 client.LogoURI,
+			PolicyURI:               client.PolicyURI,
+			TosURI:                  client.TosURI,
+			TokenExchange:           client.TokenExchange,
+			RequirePAR:              client.RequirePushedAuthorizationRequests,
+			JWKS:                    client.JWKS,
+			JWKSURI:                 client.JWKSURI,
+			RequestURIs:             client.RequestURIs,
+			RequestObjectSigningAlg: client.RequestObjectSigningAlg,
+			RequireSignedRequest:    client.RequireSignedRequestObject,
+			DPoPBoundAccessTokens:   client.DPoPBoundAccessTokens,
+			TLSClientAuth:           client.TLSClientAuth,
+			Active:                  &active,
+
+			TLSClientCertificateBoundAccessTokens: client.TLSClientCertificateBoundAccessTokens,
+			AuthorizationDetailsTypes:             client.AuthorizationDetailsTypes,
+			BackchannelTokenDeliveryMode:          client.BackchannelTokenDeliveryMode,
+			BackchannelClientNotificationEndpoint: client.BackchannelClientNotificationEndpoint,
+			FirstParty:                            client.FirstParty,
+		})
+	}
+	return doc, nil
+}
+
+// importPermissions создает или обновляет разрешения по имени и возвращает
+// все разрешения, доступные ролям документа, по имени
+func (a *App) importPermissions(configs []permissionConfig) (map[string]*entity.Permission, error) {
+	existing, err := a.allPermissions()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*entity.Permission, len(existing))
+	for _, permission := range existing {
+		byName[permission.Name] = permission
+	}
+
+	imported := make(map[string]bool, len(configs))
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("permission without name")
+		}
+		if imported[cfg.Name] {
+			return nil, fmt.Errorf("permission %q is defined twice", cfg.Name)
+		}
+		imported[cfg.Name] = true
+
+		effect := cfg.Effect
+		if effect == "" {
+			effect = entity.EffectAllow
+		}
+
+		permission, ok := byName[cfg.Name]
+		if !ok {
+			permission = entity.NewPermissionWithEffect(cfg.Name, cfg.Resource, cfg.Action, effect, cfg.Description)
+		} else {
+			permission.Resource = cfg.Resource
+			permission.Action = cfg.Action
+			permission.Effect = effect
+			permission.Description = cfg.Description
+			permission.UpdatedAt = time.Now()
+		}
+
+		if err := a.registry.ValidatePermission(permission); err != nil {
+			return nil, err
+		}
+
+		if ok {
+			err = a.store.Permissions.Update(a.ctx, permission)
+		} else {
+			err = a.store.Permissions.Create(a.ctx, permission)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("save permission %s: %w", cfg.Name, err)
+		}
+		if ok {
+			a.publish(entity.NewDomainEvent(entity.EventPermissionChanged, permission.ID))
+		}
+		byName[cfg.Name] = permission
+	}
+	return byName, nil
+}
+
+// importRoles создает или обновляет роли по имени; набор разрешений роли
+// заменяется перечисленным в документе
+func (a *App) importRoles(configs []roleConfig, permissions map[string]*entity.Permission) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("role without name")
+		}
+
+		var ordered []*entity.Permission
+		for _, name := range cfg.Permissions {
+			permission, ok := permissions[name]
+			if !ok {
+				return fmt.Errorf("role %s references unknown permission %q", cfg.Name, name)
+			}
+			ordered = append(ordered, permission)
+		}
+
+		role, err := a.store.Roles.GetByName(a.ctx, cfg.Name)
+		created := errors.Is(err, ports.ErrNotFound)
+		if created {
+			role = entity.NewRole(cfg.Name, cfg.Description)
+		} else if err != nil {
+			return err
+		}
+
+		role.Description = cfg.Description
+		for _, current := range append([]entity.Permission(nil), role.Permissions...) {
+			if !containsPermissionID(ordered, current) {
+				role.RemovePermission(current.ID)
+			}
+		}
+		for _, permission := range ordered {
+			role.AddPermission(*permission)
+		}
+		role.UpdatedAt = time.Now()
+
+		if created {
+			err = a.store.Roles.Create(a.ctx, role)
+		} else {
+			err = a.store.Roles.Update(a.ctx, role)
+		}
+		if err != nil {
+			return fmt.Errorf("save role %s: %w", cfg.Name, err)
+		}
+		a.publish(role.PullEvents()...)
+	}
+	return nil
+}
+
+// importClients создает или обновляет клиентов по client_id. Для новых
+// конфиденциальных клиентов генерируется секрет, который печатается один раз.
+// Тип существующего клиента при импорте не меняется.
+func (a *App) importClients(configs []clientConfig) error {
+	for _, cfg := range configs {
+		if cfg.ClientID == "" || cfg.Name == "" {
+			return fmt.Errorf("client requires client_id and name")
+		}
+		grantTypes, err := parseGrantTypes(cfg.GrantTypes)
+		if err != nil {
+			return fmt.Errorf("client %s: %w", cfg.ClientID, err)
+		}
+
+		client, err := a.store.Clients.GetByClientID(a.ctx, cfg.ClientID)
+		created := errors.Is(err, ports.ErrNotFound)
+		if created {
+			if cfg.ClientType == entity.ClientTypePublic {
+				client = entity.NewPublicClient(cfg.Name, cfg.Description, cfg.RedirectURIs, grantTypes, cfg.Scopes)
+			} else {
+				client = entity.NewClient(cfg.Name, cfg.Description, cfg.RedirectURIs, grantTypes, cfg.Scopes)
+			}
+			client.ClientID = cfg.ClientID
+		} else if err != nil {
+			return err
+		} else {
+			if cfg.ClientType != "" && cfg.ClientType != client.Type {
+				return fmt.Errorf("client %s: client_type cannot be changed from %s to %s", cfg.ClientID, client.Type, cfg.ClientType)
+			}
+			client.Name = cfg.Name
+			client.Description = cfg.Description
+			client.RedirectURIs = cfg.RedirectURIs
+			client.GrantTypes = grantTypes
+			client.Scopes = cfg.Scopes
+			client.UpdatedAt = time.Now()
+		}
+
+		if cfg.TokenEndpointAuthMethod != "" {
+			client.TokenEndpointAuthMethod = cfg.TokenEndpointAuthMethod
+		}
+		client.ResponseTypes = cfg.ResponseTypes
+		client.ApplyDefaults()
+		client.AccessTokenLifetime = cfg.AccessTokenLifetime
+		client.RefreshTokenLifetime = cfg.RefreshTokenLifetime
+		client.ClientURI = cfg.ClientURI
+		client.LogoURI = cfg.LogoURI
+		client.PolicyURI = cfg.PolicyURI
+		client.TosURI = cfg.TosURI
+		client.TokenExchange = cfg.TokenExchange
+		client.RequirePushedAuthorizationRequests = cfg.RequirePAR
+		client.JWKS = cfg.JWKS
+		client.JWKSURI = cfg.JWKSURI
+		client.RequestURIs = cfg.RequestURIs
+		client.RequestObjectSigningAlg = cfg.RequestObjectSigningAlg
+		client.RequireSignedRequestObject = cfg.RequireSignedRequest
+		client.DPoPBoundAccessTokens = cfg.DPoPBoundAccessTokens
+		client.TLSClientAuth = cfg.TLSClientAuth
+		client.TLSClientCertificateBoundAccessTokens = cfg.TLSClientCertificateBoundAccessTokens
+		client.AuthorizationDetailsTypes = cfg.AuthorizationDetailsTypes
+		client.BackchannelTokenDeliveryMode = cfg.BackchannelTokenDeliveryMode
+		client.BackchannelClientNotificationEndpoint = cfg.BackchannelClientNotificationEndpoint
+		client.FirstParty = cfg.FirstParty
+		if err := client.Validate(); err != nil {
+			return fmt.Errorf("client %s: %w", cfg.ClientID, err)
+		}
+
+		var secret string
+		if created && client.TokenEndpointAuthMethod.UsesClientSecret() {
+			if secret, err = a.secrets.Issue(client); err != nil {
+				return err
+			}
+		}
+
+		if cfg.Active != nil && *cfg.Active != client.Active {
+			if *cfg.Active {
+				client.Activate()
+			} else {
+				client.Deactivate()
+			}
+		}
+
+		if created {
+			err = a.store.Clients.Create(a.ctx, client)
+		} else {
+			err = a.store.Clients.Update(a.ctx, client)
+		}
+		if err != nil {
+			return fmt.Errorf("save client %s: %w", cfg.ClientID, err)
+		}
+
+		if created && secret != "" {
+			fmt.Fprintf(a.stdout, "created client %s, client_secret: %s\n", client.ClientID, secret)
+		} else if created {
+			fmt.Fprintf(a.stdout, "created public client %s\n", client.ClientID)
+		}
+	}
+	return nil
+}
+
+func containsPermissionID(permissions []*entity.Permission, permission entity.Permission) bool {
+	for _, p := range permissions {
+		if p.ID == permission.ID {
+			return true
+		}
+	}
+	return false
+}
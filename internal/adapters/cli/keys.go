@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"AuthAndOauth/internal/core/domain/service"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// keysRotate создает новый активный ключ подписи
+func (a *App) keysRotate(args []string) error {
+	fs := a.newFlagSet("keys rotate")
+	retiredTTL := fs.Duration("retired-ttl", service.DefaultSigningKeyManagerConfig().RetiredKeyTTL,
+		"how long retired keys stay published for verification")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	manager := service.NewSigningKeyManager(a.store.SigningKeys, &service.SigningKeyManagerConfig{
+		RetiredKeyTTL: *retiredTTL,
+	})
+	key, err := manager.Rotate(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "active signing key: %s (%s)\n", key.ID, key.Algorithm)
+	return nil
+}
+
+// keysList печатает ключи подписи
+func (a *App) keysList(args []string) error {
+	fs := a.newFlagSet("keys list")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	keys, err := a.store.SigningKeys.List(a.ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tSTATUS\tCREATED\tRETIRED")
+	for _, key := range keys {
+		retired := "-"
+		if key.RetiredAt != nil {
+			retired = key.RetiredAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.ID, key.Algorithm, key.Status, key.CreatedAt.Format(time.RFC3339), retired)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"fmt"
+)
+
+// roleAssign назначает роль пользователю
+func (a *App) roleAssign(args []string) error {
+	fs := a.newFlagSet("role assign")
+	email := fs.String("email", "", "user email")
+	roleName := fs.String("role", "", "role name")
+	if err := parseFlags(fs, args, "email", "role"); err != nil {
+		return err
+	}
+
+	user, role, err := a.userAndRole(*email, *roleName)
+	if err != nil {
+		return err
+	}
+	if user.HasRole(role.ID) {
+		fmt.Fprintf(a.stdout, "user %s already has role %s\n", user.Email, role.Name)
+		return nil
+	}
+
+	user.AddRole(*role)
+	if err := a.store.Users.Update(a.ctx, user); err != nil {
+		return err
+	}
+	a.publish(user.PullEvents()...)
+	if err := a.audit(user.ID.String(), entity.AuditEventRoleChange, "role assigned to user", map[string]interface{}{
+		"role_id":   role.ID.String(),
+		"role_name": role.Name,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "role %s assigned to %s\n", role.Name, user.Email)
+	return nil
+}
+
+// roleUnassign отзывает роль у пользователя
+func (a *App) roleUnassign(args []string) error {
+	fs := a.newFlagSet("role unassign")
+	email := fs.String("email", "", "user email")
+	roleName := fs.String("role", "", "role name")
+	if err := parseFlags(fs, args, "email", "role"); err != nil {
+		return err
+	}
+
+	user, role, err := a.userAndRole(*email, *roleName)
+	if err != nil {
+		return err
+	}
+	if !user.HasRole(role.ID) {
+		return fmt.Errorf("user %s does not have role %s", user.Email, role.Name)
+	}
+
+	user.RemoveRole(role.ID)
+	if err := a.store.Users.Update(a.ctx, user); err != nil {
+		return err
+	}
+	a.publish(user.PullEvents()...)
+	if err := a.audit(user.ID.String(), entity.AuditEventRoleChange, "role removed from user", map[string]interface{}{
+		"role_id":   role.ID.String(),
+		"role_name": role.Name,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "role %s removed from %s\n", role.Name, user.Email)
+	return nil
+}
+
+// userRevoke отзывает все действующие токены и сессии пользователя
+func (a *App) userRevoke(args []string) error {
+	fs := a.newFlagSet("user revoke")
+	email := fs.String("email", "", "user email")
+	if err := parseFlags(fs, args, "email"); err != nil {
+		return err
+	}
+
+	user, err := a.userByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := a.store.Tokens.ListByUser(a.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	revokedTokens := 0
+	for _, token := range tokens {
+		if token.IsRevoked {
+			continue
+		}
+		token.Revoke()
+		if err := a.store.Tokens.Update(a.ctx, token); err != nil {
+			return err
+		}
+		revokedTokens++
+	}
+
+	sessions, err := a.store.Sessions.ListByUser(a.ctx, user.ID.String())
+	if err != nil {
+		return err
+	}
+	revokedSessions := 0
+	for _, session := range sessions {
+		if session.Status == entity.SessionStatusRevoked {
+			continue
+		}
+		session.Revoke()
+		if err := a.store.Sessions.Update(a.ctx, session); err != nil {
+			return err
+		}
+		revokedSessions++
+	}
+
+	if err := a.audit(user.ID.String(), entity.AuditEventTokenRevoked, "all tokens and sessions revoked", map[string]interface{}{
+		"revoked_tokens":   revokedTokens,
+		"revoked_sessions": revokedSessions,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "revoked %d token(s) and %d session(s) of %s\n", revokedTokens, revokedSessions, user.Email)
+	return nil
+}
+
+func (a *App) userAndRole(email, roleName string) (*entity.User, *entity.Role, error) {
+	user, err := a.userByEmail(email)
+	if err != nil {
+		return nil, nil, err
+	}
+	role, err := a.store.Roles.GetByName(a.ctx, roleName)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, nil, fmt.Errorf("role %s not found", roleName)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, role, nil
+}
@@ -0,0 +1,206 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/adapters/http/clientcert"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Схемы заголовка Authorization для access token (RFC 6750; RFC 9449, раздел 7.1)
+const (
+	schemeBearer = "Bearer"
+	schemeDPoP   = "DPoP"
+)
+
+// DefaultScope область, без которой access token не принимается административным API:
+// токен, выпущенный пользователю для любого другого клиента, не дает доступа к нему
+const DefaultScope = "admin"
+
+// AuthenticationChallenge ошибка аутентификации, о которой клиенту сообщается
+// в заголовке WWW-Authenticate
+type AuthenticationChallenge struct {
+	Scheme string
+	// Code код ошибки: invalid_token, insufficient_scope, invalid_dpop_proof
+	// или use_dpop_nonce
+	Code string
+	// Nonce новый DPoP nonce для повторного запроса
+	Nonce string
+	// Scope область, необходимая для доступа (RFC 6750, раздел 3)
+	Scope string
+	Err   error
+}
+
+func (e *AuthenticationChallenge) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthenticationChallenge) Unwrap() error {
+	return e.Err
+}
+
+// write записывает заголовки вызова аутентификации
+func (e *AuthenticationChallenge) write(w http.ResponseWriter) {
+	challenge := fmt.Sprintf("%s error=%q", e.Scheme, e.Code)
+	if e.Scope != "" {
+		challenge += fmt.Sprintf(", scope=%q", e.Scope)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	if e.Nonce != "" {
+		w.Header().Set("DPoP-Nonce", e.Nonce)
+	}
+}
+
+// DPoPConfig проверка DPoP proof при предъявлении access token
+type DPoPConfig struct {
+	Proofs *service.DPoPService
+	// BaseURL внешний адрес API (схема и хост), с которым сравнивается htu;
+	// пустой — адрес определяется по запросу
+	BaseURL string
+}
+
+// TokenAuthenticator аутентифицирует администратора по access token
+// из заголовка Authorization: Bearer <token> или, если настроен DPoP,
+// Authorization: DPoP <token> с proof в заголовке DPoP. Токен, привязанный к
+// сертификату, принимается только вместе с этим сертификатом.
+// Токен должен содержать административную область (DefaultScope).
+type TokenAuthenticator struct {
+	tokens    ports.TokenRepository
+	users     ports.UserRepository
+	validator *service.TokenValidator
+	dpop      *DPoPConfig
+	audience  string
+	scope     string
+	certs     clientcert.Source
+}
+
+// NewTokenAuthenticator создает аутентификатор по access токенам; dpop nil —
+// принимаются только bearer токены, привязанные к ключу токены отклоняются
+func NewTokenAuthenticator(tokens ports.TokenRepository, users ports.UserRepository, validator *service.TokenValidator, dpop *DPoPConfig) *TokenAuthenticator {
+	if validator == nil {
+		validator = service.NewTokenValidator()
+	}
+	return &TokenAuthenticator{
+		tokens:    tokens,
+		users:     users,
+		validator: validator,
+		dpop:      dpop,
+		scope:     DefaultScope,
+	}
+}
+
+// SetAudience задает идентификатор API как ресурса (RFC 8707): токены без
+// audience и выпущенные для других ресурсов отклоняются. Без него принимаются
+// только токены без audience.
+func (a *TokenAuthenticator) SetAudience(resource string) {
+	a.audience = resource
+}
+
+// SetScope задает область, обязательную для доступа к API, вместо DefaultScope
+func (a *TokenAuthenticator) SetScope(scope string) {
+	a.scope = scope
+}
+
+// SetCertificateSource задает источник сертификата клиента для токенов,
+// привязанных к сертификату; он должен совпадать с источником token endpoint.
+// По умолчанию сертификат берется из TLS соединения.
+func (a *TokenAuthenticator) SetCertificateSource(source clientcert.Source) {
+	a.certs = source
+}
+
+// Authenticate реализует Authenticator
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*entity.User, error) {
+	scheme, value, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	value = strings.TrimSpace(value)
+	dpop := strings.EqualFold(scheme, schemeDPoP)
+	if !found || value == "" || (!dpop && !strings.EqualFold(scheme, schemeBearer)) {
+		return nil, fmt.Errorf("missing access token")
+	}
+	if dpop && a.dpop == nil {
+		return nil, fmt.Errorf("DPoP is not supported")
+	}
+
+	token, err := a.tokens.GetByValue(r.Context(), value)
+	if err != nil {
+		return nil, a.challenge(dpop, "invalid_token", fmt.Errorf("unknown token: %w", err))
+	}
+	if token.Type != entity.AccessToken {
+		return nil, a.challenge(dpop, "invalid_token", fmt.Errorf("token is not an access token"))
+	}
+	if err := a.validator.ValidateToken(token); err != nil {
+		return nil, a.challenge(dpop, "invalid_token", err)
+	}
+	if err := a.validator.ValidateAudience(token, a.audience); err != nil {
+		return nil, a.challenge(dpop, "invalid_token", err)
+	}
+	if err := a.validator.ValidateScopes(token, []string{a.scope}); err != nil {
+		challenge := a.challenge(dpop, "insufficient_scope", err)
+		challenge.Scope = a.scope
+		return nil, challenge
+	}
+
+	var proof *service.DPoPProof
+	if dpop {
+		if proof, err = a.verifyProof(r, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := service.CheckDPoPBinding(token, proof); err != nil {
+		return nil, a.challenge(token.DPoPThumbprint() != "", "invalid_token", err)
+	}
+	if err := service.CheckCertificateBinding(token, a.certs.Thumbprint(r)); err != nil {
+		return nil, a.challenge(dpop, "invalid_token", err)
+	}
+
+	user, err := a.users.GetByID(r.Context(), token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("token owner not found: %w", err)
+	}
+	return user, nil
+}
+
+// verifyProof проверяет DPoP proof, связанный с предъявленным токеном
+func (a *TokenAuthenticator) verifyProof(r *http.Request, accessToken string) (*service.DPoPProof, error) {
+	proofs := r.Header.Values("DPoP")
+	if len(proofs) != 1 {
+		return nil, a.challenge(true, "invalid_dpop_proof", fmt.Errorf("exactly one DPoP proof is required"))
+	}
+
+	proof, err := a.dpop.Proofs.Verify(r.Context(), proofs[0], r.Method, a.requestURL(r), accessToken)
+	switch {
+	case err == nil:
+		return proof, nil
+	case errors.Is(err, service.ErrUseDPoPNonce):
+		challenge := a.challenge(true, "use_dpop_nonce", err)
+		challenge.Nonce = a.dpop.Proofs.Nonce()
+		return nil, challenge
+	case errors.Is(err, service.ErrInvalidDPoPProof):
+		return nil, a.challenge(true, "invalid_dpop_proof", err)
+	default:
+		return nil, err
+	}
+}
+
+// requestURL восстанавливает адрес запроса для сравнения с htu
+func (a *TokenAuthenticator) requestURL(r *http.Request) string {
+	if a.dpop.BaseURL != "" {
+		return strings.TrimSuffix(a.dpop.BaseURL, "/") + r.URL.Path
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+func (a *TokenAuthenticator) challenge(dpop bool, code string, err error) *AuthenticationChallenge {
+	scheme := schemeBearer
+	if dpop {
+		scheme = schemeDPoP
+	}
+	return &AuthenticationChallenge{Scheme: scheme, Code: code, Err: err}
+}
@@ -0,0 +1,447 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"AuthAndOauth/internal/pkg/jose"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// clientRequest тело запроса на создание или изменение клиента; пустые поля не изменяются
+type clientRequest struct {
+	Name                    *string                         `json:"name,omitempty"`
+	Description             *string                         `json:"description,omitempty"`
+	ClientType              *entity.ClientType              `json:"client_type,omitempty"`
+	TokenEndpointAuthMethod *entity.TokenEndpointAuthMethod `json:"token_endpoint_auth_method,omitempty"`
+	RedirectURIs            []string                        `json:"redirect_uris,omitempty"`
+	GrantTypes              []entity.GrantType              `json:"grant_types,omitempty"`
+	ResponseTypes           []entity.ResponseType           `json:"response_types,omitempty"`
+	Scopes                  []string                        `json:"scopes,omitempty"`
+	AccessTokenLifetime     *int64                          `json:"access_token_lifetime,omitempty"`
+	RefreshTokenLifetime    *int64                          `json:"refresh_token_lifetime,omitempty"`
+	ClientURI               *string                         `json:"client_uri,omitempty"`
+	LogoURI                 *string                         `json:"logo_uri,omitempty"`
+	PolicyURI               *string                         `json:"policy_uri,omitempty"`
+	TosURI                  *string                         `json:"tos_uri,omitempty"`
+	TokenExchange           *entity.TokenExchangePolicy     `json:"token_exchange,omitempty"`
+	RequirePAR              *bool                           `json:"require_pushed_authorization_requests,omitempty"`
+	JWKS                    *jose.JWKSet                    `json:"jwks,omitempty"`
+	JWKSURI                 *string                         `json:"jwks_uri,omitempty"`
+	RequestURIs             []string                        `json:"request_uris,omitempty"`
+	RequestObjectSigningAlg *string                         `json:"request_object_signing_alg,omitempty"`
+	RequireSignedRequest    *bool                           `json:"require_signed_request_object,omitempty"`
+	DPoPBoundAccessTokens   *bool                           `json:"dpop_bound_access_tokens,omitempty"`
+	// TLSClientAuth задается целиком: поля, не переданные вместе с ним, очищаются
+	*entity.TLSClientAuth
+	TLSClientCertificateBoundAccessTokens *bool    `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	AuthorizationDetailsTypes             []string `json:"authorization_details_types,omitempty"`
+
+	BackchannelTokenDeliveryMode          *entity.BackchannelTokenDeliveryMode `json:"backchannel_token_delivery_mode,omitempty"`
+	BackchannelClientNotificationEndpoint *string                              `json:"backchannel_client_notification_endpoint,omitempty"`
+	FirstParty                            *bool                                `json:"first_party,omitempty"`
+}
+
+// apply переносит заданные поля запроса в клиента. Тип клиента задается только при создании.
+func (req clientRequest) apply(client *entity.Client) {
+	if req.Name != nil {
+		client.Name = *req.Name
+	}
+	if req.Description != nil {
+		client.Description = *req.Description
+	}
+	if req.TokenEndpointAuthMethod != nil {
+		client.TokenEndpointAuthMethod = *req.TokenEndpointAuthMethod
+	}
+	if req.RedirectURIs != nil {
+		client.RedirectURIs = req.RedirectURIs
+	}
+	if req.GrantTypes != nil {
+		client.GrantTypes = req.GrantTypes
+	}
+	if req.ResponseTypes != nil {
+		client.ResponseTypes = req.ResponseTypes
+	}
+	if req.Scopes != nil {
+		client.Scopes = req.Scopes
+	}
+	if req.AccessTokenLifetime != nil {
+		client.AccessTokenLifetime = *req.AccessTokenLifetime
+	}
+	if req.RefreshTokenLifetime != nil {
+		client.RefreshTokenLifetime = *req.RefreshTokenLifetime
+	}
+	if req.ClientURI != nil {
+		client.ClientURI = *req.ClientURI
+	}
+	if req.LogoURI != nil {
+		client.LogoURI = *req.LogoURI
+	}
+	if req.PolicyURI != nil {
+		client.PolicyURI = *req.PolicyURI
+	}
+	if req.TosURI != nil {
+		client.TosURI = *req.TosURI
+	}
+	if req.TokenExchange != nil {
+		client.TokenExchange = req.TokenExchange
+	}
+	if req.RequirePAR != nil {
+		client.RequirePushedAuthorizationRequests = *req.RequirePAR
+	}
+	if req.JWKS != nil {
+		client.JWKS = req.JWKS
+	}
+	if req.JWKSURI != nil {
+		client.JWKSURI = *req.JWKSURI
+	}
+	if req.RequestURIs != nil {
+		client.RequestURIs = req.RequestURIs
+	}
+	if req.RequestObjectSigningAlg != nil {
+		client.RequestObjectSigningAlg = *req.RequestObjectSigningAlg
+	}
+	if req.RequireSignedRequest != nil {
+		client.RequireSignedRequestObject = *req.RequireSignedRequest
+	}
+	if req.DPoPBoundAccessTokens != nil {
+		client.DPoPBoundAccessTokens = *req.DPoPBoundAccessTokens
+	}
+	if req.TLSClientAuth != nil {
+		client.TLSClientAuth = *req.TLSClientAuth
+	}
+	if req.TLSClientCertificateBoundAccessTokens != nil {
+		client.TLSClientCertificateBoundAccessTokens = *req.TLSClientCertificateBoundAccessTokens
+	}
+	if req.AuthorizationDetailsTypes != nil {
+		client.AuthorizationDetailsTypes = req.AuthorizationDetailsTypes
+	}
+	if req.BackchannelTokenDeliveryMode != nil {
+		client.BackchannelTokenDeliveryMode = *req.BackchannelTokenDeliveryMode
+	}
+	if req.BackchannelClientNotificationEndpoint != nil {
+		client.BackchannelClientNotificationEndpoint = *req.BackchannelClientNotificationEndpoint
+	}
+	if req.FirstParty != nil {
+		client.FirstParty = *req.FirstParty
+	}
+}
+
+// createdClientResponse ответ на создание клиента или ротацию секрета.
+// Секрет возвращается только здесь: хранится лишь его хеш. У публичных клиентов секрета нет.
+type createdClientResponse struct {
+	*entity.Client
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// rotateSecretRequest тело запроса на ротацию секрета
+type rotateSecretRequest struct {
+	// OverlapSeconds сколько секунд продолжают действовать прежние секреты; 0 — значение по умолчанию
+	OverlapSeconds int64 `json:"overlap_seconds,omitempty"`
+}
+
+// listClients возвращает страницу клиентов.
+// Фильтры: name (подстрока), active, grant_type.
+func (h *Handler) listClients(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionList); !ok {
+		return
+	}
+
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+	active, ok := parseBoolQuery(w, r, "active")
+	if !ok {
+		return
+	}
+
+	filter := ports.ClientFilter{
+		Name:      r.URL.Query().Get("name"),
+		Active:    active,
+		GrantType: entity.GrantType(r.URL.Query().Get("grant_type")),
+	}
+
+	clients, total, err := h.deps.Clients.List(r.Context(), filter, page)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeList(w, clients, total, page)
+}
+
+// createClient регистрирует клиента и однократно возвращает его секрет
+func (h *Handler) createClient(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionCreate)
+	if !ok {
+		return
+	}
+
+	var req clientRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if len(req.GrantTypes) == 0 {
+		writeError(w, http.StatusBadRequest, "grant_types is required")
+		return
+	}
+
+	client := entity.NewClient(*req.Name, "", nil, nil, nil)
+	if req.ClientType != nil && *req.ClientType == entity.ClientTypePublic {
+		client = entity.NewPublicClient(*req.Name, "", nil, nil, nil)
+	} else if req.ClientType != nil && *req.ClientType != entity.ClientTypeConfidential {
+		writeError(w, http.StatusBadRequest, "client_type must be confidential or public")
+		return
+	}
+	client.ResponseTypes = nil
+	req.apply(client)
+	client.ApplyDefaults()
+	if err := client.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var secret string
+	if client.TokenEndpointAuthMethod.UsesClientSecret() {
+		var err error
+		if secret, err = h.deps.ClientSecrets.Issue(client); err != nil {
+			log.Error("failed to issue client secret", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+
+	if err := h.deps.Clients.Create(r.Context(), client); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("client created",
+		zap.String("client_id", client.ClientID),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusCreated, createdClientResponse{
+		Client:       client,
+		ClientSecret: secret,
+	})
+}
+
+// getClient возвращает клиента
+func (h *Handler) getClient(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionRead); !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	client, err := h.deps.Clients.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, client)
+}
+
+// updateClient изменяет метаданные клиента
+func (h *Handler) updateClient(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req clientRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	client, err := h.deps.Clients.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if req.ClientType != nil && *req.ClientType != client.Type {
+		writeError(w, http.StatusBadRequest, "client_type cannot be changed")
+		return
+	}
+	req.apply(client)
+	if err := client.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	client.UpdatedAt = time.Now()
+
+	if err := h.deps.Clients.Update(r.Context(), client); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("client updated",
+		zap.String("client_id", client.ClientID),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, client)
+}
+
+// deleteClient удаляет клиента
+func (h *Handler) deleteClient(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionDelete)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.deps.Clients.Delete(r.Context(), id); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("client deleted",
+		zap.String("id", id.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// activateClient активирует клиента
+func (h *Handler) activateClient(w http.ResponseWriter, r *http.Request) {
+	h.setClientActive(w, r, true)
+}
+
+// deactivateClient деактивирует клиента
+func (h *Handler) deactivateClient(w http.ResponseWriter, r *http.Request) {
+	h.setClientActive(w, r, false)
+}
+
+func (h *Handler) setClientActive(w http.ResponseWriter, r *http.Request, active bool) {
+	actor, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	client, err := h.deps.Clients.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if active {
+		client.Activate()
+	} else {
+		client.Deactivate()
+	}
+
+	if err := h.deps.Clients.Update(r.Context(), client); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("client status changed",
+		zap.String("client_id", client.ClientID),
+		zap.Bool("active", active),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, client)
+}
+
+// rotateClientSecret выдает клиенту новый секрет; прежние действуют до конца периода перекрытия
+func (h *Handler) rotateClientSecret(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req rotateSecretRequest
+	if r.ContentLength != 0 && !decodeBody(w, r, &req) {
+		return
+	}
+	if req.OverlapSeconds < 0 {
+		writeError(w, http.StatusBadRequest, "overlap_seconds must not be negative")
+		return
+	}
+
+	client, err := h.deps.Clients.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	secret, err := h.deps.ClientSecrets.Rotate(client, time.Duration(req.OverlapSeconds)*time.Second)
+	if err != nil {
+		log.Error("failed to rotate client secret", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if err := h.deps.Clients.Update(r.Context(), client); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("client secret rotated",
+		zap.String("client_id", client.ClientID),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, createdClientResponse{
+		Client:       client,
+		ClientSecret: secret,
+	})
+}
+
+// deleteClientSecret немедленно отзывает один из секретов клиента
+func (h *Handler) deleteClientSecret(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceClient, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	secretID := r.PathValue("secret_id")
+
+	client, err := h.deps.Clients.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	if !client.RemoveSecret(secretID) {
+		writeError(w, http.StatusNotFound, "secret not found")
+		return
+	}
+
+	if err := h.deps.Clients.Update(r.Context(), client); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("client secret revoked",
+		zap.String("client_id", client.ClientID),
+		zap.String("secret_id", secretID),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, client)
+}
@@ -0,0 +1,324 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"go.uber.org/zap"
+)
+
+// Authenticator определяет пользователя, выполняющего запрос к административному API
+type Authenticator interface {
+	Authenticate(r *http.Request) (*entity.User, error)
+}
+
+// Dependencies зависимости административного API
+type Dependencies struct {
+	Authenticator     Authenticator
+	PermissionChecker *service.PermissionChecker
+	ResourceRegistry  *service.ResourceRegistry
+
+	Users       ports.UserRepository
+	Roles       ports.RoleRepository
+	Permissions ports.PermissionRepository
+	Clients     ports.ClientRepository
+
+	// ClientSecrets выдает и хеширует секреты клиентов; nil — конфигурация по умолчанию
+	ClientSecrets *service.ClientSecretManager
+
+	// AuditLogs необязательное хранилище аудита для событий role_change
+	AuditLogs ports.AuditLogRepository
+	// Events необязательный диспетчер доменных событий (например, для инвалидации кеша разрешений)
+	Events *service.EventDispatcher
+}
+
+// Handler административное REST API сервиса
+type Handler struct {
+	deps Dependencies
+	mux  *http.ServeMux
+}
+
+// NewHandler создает обработчик административного API
+func NewHandler(deps Dependencies) *Handler {
+	if deps.ClientSecrets == nil {
+		deps.ClientSecrets = service.DefaultClientSecretManager()
+	}
+
+	h := &Handler{
+		deps: deps,
+		mux:  http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("GET /admin/resources", h.listResources)
+	h.mux.HandleFunc("GET /admin/actions", h.listActions)
+
+	h.mux.HandleFunc("GET /admin/users", h.listUsers)
+	h.mux.HandleFunc("POST /admin/users", h.createUser)
+	h.mux.HandleFunc("GET /admin/users/{id}", h.getUser)
+	h.mux.HandleFunc("PATCH /admin/users/{id}", h.updateUser)
+	h.mux.HandleFunc("DELETE /admin/users/{id}", h.deleteUser)
+	h.mux.HandleFunc("POST /admin/users/{id}/roles", h.assignUserRole)
+	h.mux.HandleFunc("DELETE /admin/users/{id}/roles/{role_id}", h.removeUserRole)
+
+	h.mux.HandleFunc("GET /admin/roles", h.listRoles)
+	h.mux.HandleFunc("POST /admin/roles", h.createRole)
+	h.mux.HandleFunc("GET /admin/roles/{id}", h.getRole)
+	h.mux.HandleFunc("PATCH /admin/roles/{id}", h.updateRole)
+	h.mux.HandleFunc("DELETE /admin/roles/{id}", h.deleteRole)
+	h.mux.HandleFunc("POST /admin/roles/{id}/permissions", h.addRolePermission)
+	h.mux.HandleFunc("DELETE /admin/roles/{id}/permissions/{permission_id}", h.removeRolePermission)
+
+	h.mux.HandleFunc("GET /admin/permissions", h.listPermissions)
+	h.mux.HandleFunc("POST /admin/permissions", h.createPermission)
+	h.mux.HandleFunc("GET /admin/permissions/{id}", h.getPermission)
+	h.mux.HandleFunc("PATCH /admin/permissions/{id}", h.updatePermission)
+	h.mux.HandleFunc("DELETE /admin/permissions/{id}", h.deletePermission)
+
+	h.mux.HandleFunc("GET /admin/clients", h.listClients)
+	h.mux.HandleFunc("POST /admin/clients", h.createClient)
+	h.mux.HandleFunc("GET /admin/clients/{id}", h.getClient)
+	h.mux.HandleFunc("PATCH /admin/clients/{id}", h.updateClient)
+	h.mux.HandleFunc("DELETE /admin/clients/{id}", h.deleteClient)
+	h.mux.HandleFunc("POST /admin/clients/{id}/activate", h.activateClient)
+	h.mux.HandleFunc("POST /admin/clients/{id}/deactivate", h.deactivateClient)
+	h.mux.HandleFunc("POST /admin/clients/{id}/secrets", h.rotateClientSecret)
+	h.mux.HandleFunc("DELETE /admin/clients/{id}/secrets/{secret_id}", h.deleteClientSecret)
+
+	return h
+}
+
+// ServeHTTP реализует http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// authorize аутентифицирует пользователя и проверяет его доступ к ресурсу.
+// При отказе ответ уже записан и возвращается false.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, resource entity.ResourceType, action entity.Action) (*entity.User, bool) {
+	user, err := h.deps.Authenticator.Authenticate(r)
+	if err != nil || user == nil {
+		log.Warn("admin request is not authenticated",
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		var challenge *AuthenticationChallenge
+		if errors.As(err, &challenge) {
+			challenge.write(w)
+			if challenge.Code == "insufficient_scope" {
+				writeError(w, http.StatusForbidden, "insufficient scope")
+				return nil, false
+			}
+		}
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return nil, false
+	}
+
+	if !user.Active {
+		log.Warn("admin request from inactive user",
+			zap.String("user_id", user.ID.String()),
+			zap.String("path", r.URL.Path),
+		)
+		writeError(w, http.StatusForbidden, "user is inactive")
+		return nil, false
+	}
+
+	// Объяснение отказа с ролями и разрешениями пользователя уже записано
+	// в журнал, клиент получает только общее сообщение
+	if err := h.deps.PermissionChecker.ValidateUserAccess(user, resource, action); err != nil {
+		writeError(w, http.StatusForbidden, "access denied")
+		return nil, false
+	}
+	return user, true
+}
+
+// canGrant проверяет, что пользователь сам обладает всеми разрешающими
+// разрешениями, которые он выдает другим: иначе обладатель user:update мог бы
+// назначить себе роль администратора. Запреты только сужают доступ и не проверяются.
+func (h *Handler) canGrant(actor *entity.User, permissions ...entity.Permission) bool {
+	for _, permission := range permissions {
+		if !permission.IsDeny() && !h.holds(actor, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// canLift проверяет, что пользователь может снять запреты: после удаления или
+// изменения запрета заблокированное им действие снова доступно, поэтому снятие
+// запрета равносильно выдаче этого действия. Разрешающие разрешения не проверяются.
+func (h *Handler) canLift(actor *entity.User, permissions ...entity.Permission) bool {
+	for _, permission := range permissions {
+		if permission.IsDeny() && !h.holds(actor, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds проверяет, что пользователь сам может выполнить действие разрешения
+func (h *Handler) holds(actor *entity.User, permission entity.Permission) bool {
+	if h.deps.PermissionChecker.HasPermission(actor, permission.Resource, permission.Action) {
+		return true
+	}
+	log.Warn("privilege escalation attempt",
+		zap.String("actor_id", actor.ID.String()),
+		zap.String("permission", permission.String()),
+		zap.String("effect", string(permission.Effect)),
+	)
+	return false
+}
+
+// errorResponse тело ответа с ошибкой
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error("failed to write response", zap.Error(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// listResponse тело ответа со страницей записей
+type listResponse struct {
+	Items  interface{} `json:"items"`
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+}
+
+func writeList(w http.ResponseWriter, items interface{}, total int, page ports.Pagination) {
+	writeJSON(w, http.StatusOK, listResponse{
+		Items:  items,
+		Total:  total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	})
+}
+
+// writeRepositoryError преобразует ошибку хранилища в HTTP ответ
+func writeRepositoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, ports.ErrAlreadyExists):
+		writeError(w, http.StatusConflict, "already exists")
+	default:
+		log.Error("repository error", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// decodeBody разбирает JSON тело запроса; при ошибке ответ уже записан
+func decodeBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// pathID разбирает идентификатор из пути; при ошибке ответ уже записан
+func pathID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s", name))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parsePagination разбирает параметры offset и limit; при ошибке ответ уже записан
+func parsePagination(w http.ResponseWriter, r *http.Request) (ports.Pagination, bool) {
+	var page ports.Pagination
+	query := r.URL.Query()
+
+	for name, dst := range map[string]*int{"offset": &page.Offset, "limit": &page.Limit} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s", name))
+			return page, false
+		}
+		*dst = value
+	}
+	return page.Normalize(), true
+}
+
+// parseBoolQuery разбирает необязательный булев параметр запроса
+func parseBoolQuery(w http.ResponseWriter, r *http.Request, name string) (*bool, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s", name))
+		return nil, false
+	}
+	return &value, true
+}
+
+// publish передает доменные события диспетчеру, если он настроен
+func (h *Handler) publish(events ...entity.DomainEvent) {
+	if h.deps.Events == nil || len(events) == 0 {
+		return
+	}
+	h.deps.Events.Publish(events...)
+}
+
+// auditRoleChange записывает событие role_change от имени администратора
+func (h *Handler) auditRoleChange(r *http.Request, actor *entity.User, description string, metadata map[string]interface{}) {
+	if h.deps.AuditLogs == nil {
+		return
+	}
+
+	auditLog := entity.NewAuditLog(
+		actor.ID.String(),
+		entity.AuditEventRoleChange,
+		description,
+		clientIP(r),
+		r.UserAgent(),
+		true,
+	)
+	for key, value := range metadata {
+		auditLog.AddMetadata(key, value)
+	}
+
+	// Запись аудита не должна зависеть от отмены запроса клиентом
+	if err := h.deps.AuditLogs.Save(context.WithoutCancel(r.Context()), auditLog); err != nil {
+		log.Error("failed to save audit log",
+			zap.String("event_type", string(auditLog.EventType)),
+			zap.Error(err),
+		)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
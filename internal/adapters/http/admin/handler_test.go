@@ -0,0 +1,310 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testAPI административное API поверх хранилища в памяти
+type testAPI struct {
+	t       *testing.T
+	store   *memory.Store
+	handler *Handler
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	store := memory.NewStore()
+	handler := NewHandler(Dependencies{
+		Authenticator:     NewTokenAuthenticator(store.Tokens, store.Users, nil, nil),
+		PermissionChecker: service.NewPermissionChecker(nil),
+		ResourceRegistry:  service.DefaultResourceRegistry(),
+		Users:             store.Users,
+		Roles:             store.Roles,
+		Permissions:       store.Permissions,
+		Clients:           store.Clients,
+	})
+	return &testAPI{t: t, store: store, handler: handler}
+}
+
+// role создает роль с разрешающими разрешениями вида resource:action
+func (a *testAPI) role(name string, permissions ...string) *entity.Role {
+	a.t.Helper()
+	ctx := context.Background()
+	role := entity.NewRole(name, "")
+	for _, value := range permissions {
+		resource, action, _ := strings.Cut(value, ":")
+		permission := entity.NewPermission(value, entity.ResourceType(resource), entity.Action(action), "")
+		if err := a.store.Permissions.Create(ctx, permission); err != nil {
+			a.t.Fatalf("create permission %s: %v", value, err)
+		}
+		role.AddPermission(*permission)
+	}
+	role.PullEvents()
+	if err := a.store.Roles.Create(ctx, role); err != nil {
+		a.t.Fatalf("create role %s: %v", name, err)
+	}
+	return role
+}
+
+// deny добавляет в роль запрет вида resource:action
+func (a *testAPI) deny(role *entity.Role, value string) *entity.Permission {
+	a.t.Helper()
+	ctx := context.Background()
+	resource, action, _ := strings.Cut(value, ":")
+	permission := entity.NewDenyPermission("deny "+value, entity.ResourceType(resource), entity.Action(action), "")
+	if err := a.store.Permissions.Create(ctx, permission); err != nil {
+		a.t.Fatalf("create permission %s: %v", value, err)
+	}
+	role.AddPermission(*permission)
+	role.PullEvents()
+	if err := a.store.Roles.Update(ctx, role); err != nil {
+		a.t.Fatalf("update role %s: %v", role.Name, err)
+	}
+	return permission
+}
+
+// user создает пользователя с ролями
+func (a *testAPI) user(email string, roles ...*entity.Role) *entity.User {
+	a.t.Helper()
+	user := entity.NewUser(email, "Test", "User", "hash")
+	for _, role := range roles {
+		user.AddRole(*role)
+	}
+	user.PullEvents()
+	if err := a.store.Users.Create(context.Background(), user); err != nil {
+		a.t.Fatalf("create user %s: %v", email, err)
+	}
+	return user
+}
+
+// token выпускает пользователю access token с областями
+func (a *testAPI) token(user *entity.User, scopes ...string) string {
+	a.t.Helper()
+	token := entity.NewToken(user.ID, uuid.New(), entity.AccessToken, scopes, time.Hour)
+	if err := a.store.Tokens.Create(context.Background(), token); err != nil {
+		a.t.Fatalf("create token: %v", err)
+	}
+	return token.Value
+}
+
+func (a *testAPI) do(method, path, token, body string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthenticatorRequiresAdminScope(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.user("admin@example.com", api.role("admin", "user:read"))
+	path := "/admin/users/" + admin.ID.String()
+
+	w := api.do(http.MethodGet, path, api.token(admin, "openid", "profile"), "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("token without admin scope: status = %d, want 403", w.Code)
+	}
+	if challenge := w.Header().Get("WWW-Authenticate"); !strings.Contains(challenge, `error="insufficient_scope"`) || !strings.Contains(challenge, `scope="admin"`) {
+		t.Errorf("WWW-Authenticate = %q", challenge)
+	}
+
+	if w := api.do(http.MethodGet, path, api.token(admin, DefaultScope), ""); w.Code != http.StatusOK {
+		t.Errorf("token with admin scope: status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, path, "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: status = %d, want 401", w.Code)
+	}
+}
+
+func TestAssignUserRoleRejectsEscalation(t *testing.T) {
+	api := newTestAPI(t)
+	manager := api.role("user-manager", "user:read", "user:update")
+	superuser := api.role("superuser", "user:update", "role:delete")
+	caller := api.user("manager@example.com", manager)
+	target := api.user("target@example.com")
+	token := api.token(caller, DefaultScope)
+
+	for _, userID := range []uuid.UUID{caller.ID, target.ID} {
+		body := `{"role_id":"` + superuser.ID.String() + `"}`
+		if w := api.do(http.MethodPost, "/admin/users/"+userID.String()+"/roles", token, body); w.Code != http.StatusForbidden {
+			t.Errorf("granting superuser to %s: status = %d, want 403", userID, w.Code)
+		}
+	}
+	stored, err := api.store.Users.GetByID(context.Background(), caller.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.HasRole(superuser.ID) {
+		t.Fatal("caller escalated to superuser")
+	}
+
+	readers := api.role("readers", "user:read")
+	body := `{"role_id":"` + readers.ID.String() + `"}`
+	if w := api.do(http.MethodPost, "/admin/users/"+target.ID.String()+"/roles", token, body); w.Code != http.StatusOK {
+		t.Errorf("granting a subset of own permissions: status = %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func TestAddRolePermissionRejectsEscalation(t *testing.T) {
+	api := newTestAPI(t)
+	editors := api.role("role-editors", "role:update")
+	caller := api.user("editor@example.com", editors)
+	token := api.token(caller, DefaultScope)
+
+	deleteUsers := entity.NewPermission("user:delete", entity.ResourceUser, entity.ActionDelete, "")
+	if err := api.store.Permissions.Create(context.Background(), deleteUsers); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"permission_id":"` + deleteUsers.ID.String() + `"}`
+	if w := api.do(http.MethodPost, "/admin/roles/"+editors.ID.String()+"/permissions", token, body); w.Code != http.StatusForbidden {
+		t.Errorf("adding a permission the caller lacks: status = %d, want 403", w.Code)
+	}
+}
+
+func TestUpdateUserRemovesNullAttributes(t *testing.T) {
+	api := newTestAPI(t)
+	caller := api.user("admin@example.com", api.role("admin", "user:update"))
+	target := api.user("target@example.com")
+	token := api.token(caller, DefaultScope)
+	path := "/admin/users/" + target.ID.String()
+
+	if w := api.do(http.MethodPatch, path, token, `{"attributes":{"department":"sales","level":3}}`); w.Code != http.StatusOK {
+		t.Fatalf("set attributes: status = %d: %s", w.Code, w.Body)
+	}
+	w := api.do(http.MethodPatch, path, token, `{"attributes":{"department":null}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("remove attribute: status = %d: %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body.Attributes["department"]; ok {
+		t.Errorf("department was not removed: %v", body.Attributes)
+	}
+	if body.Attributes["level"] != float64(3) {
+		t.Errorf("level = %v, want 3", body.Attributes["level"])
+	}
+	stored, err := api.store.Users.GetByID(context.Background(), target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored.Attribute("department"); ok {
+		t.Error("department is still stored")
+	}
+}
+
+func TestUpdatePermissionRejectsEscalation(t *testing.T) {
+	api := newTestAPI(t)
+	editors := api.role("permission-editors", "permission:update", "user:read")
+	caller := api.user("editor@example.com", editors)
+	token := api.token(caller, DefaultScope)
+
+	readers := api.role("readers", "role:read")
+	harmless := readers.Permissions[0]
+	path := "/admin/permissions/" + harmless.ID.String()
+	if w := api.do(http.MethodPatch, path, token, `{"resource":"user","action":"delete"}`); w.Code != http.StatusForbidden {
+		t.Errorf("turning an allow into user:delete: status = %d, want 403", w.Code)
+	}
+	if w := api.do(http.MethodPatch, path, token, `{"resource":"user","action":"read"}`); w.Code != http.StatusOK {
+		t.Errorf("changing an allow to a held action: status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	restriction := api.deny(readers, "role:delete")
+	path = "/admin/permissions/" + restriction.ID.String()
+	if w := api.do(http.MethodPatch, path, token, `{"effect":"allow"}`); w.Code != http.StatusForbidden {
+		t.Errorf("turning a deny into an allow: status = %d, want 403", w.Code)
+	}
+	if w := api.do(http.MethodPatch, path, token, `{"resource":"user","action":"read"}`); w.Code != http.StatusForbidden {
+		t.Errorf("moving a deny off an action the caller lacks: status = %d, want 403", w.Code)
+	}
+	stored, err := api.store.Permissions.GetByID(context.Background(), restriction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.IsDeny() || stored.Action != entity.ActionDelete {
+		t.Errorf("deny was changed: %s %s", stored.Effect, stored)
+	}
+}
+
+func TestRemovingDenyRequiresDeniedAction(t *testing.T) {
+	api := newTestAPI(t)
+	admins := api.role("admins", "permission:delete", "role:update", "user:read")
+	caller := api.user("admin@example.com", admins)
+	token := api.token(caller, DefaultScope)
+
+	restricted := api.role("restricted", "user:read")
+	deleteUsers := api.deny(restricted, "user:delete")
+	readUsers := api.deny(restricted, "user:read")
+
+	if w := api.do(http.MethodDelete, "/admin/permissions/"+deleteUsers.ID.String(), token, ""); w.Code != http.StatusForbidden {
+		t.Errorf("deleting a deny of an action the caller lacks: status = %d, want 403", w.Code)
+	}
+	if w := api.do(http.MethodDelete, "/admin/roles/"+restricted.ID.String()+"/permissions/"+deleteUsers.ID.String(), token, ""); w.Code != http.StatusForbidden {
+		t.Errorf("removing a deny from a role: status = %d, want 403", w.Code)
+	}
+	if w := api.do(http.MethodDelete, "/admin/roles/"+restricted.ID.String()+"/permissions/"+readUsers.ID.String(), token, ""); w.Code != http.StatusOK {
+		t.Errorf("removing a deny of a held action: status = %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func TestRemoveUserRoleRejectsLiftingOwnDeny(t *testing.T) {
+	api := newTestAPI(t)
+	managers := api.role("user-managers", "user:update", "user:delete")
+	restricted := api.role("restricted")
+	api.deny(restricted, "user:delete")
+	caller := api.user("manager@example.com", managers, restricted)
+	token := api.token(caller, DefaultScope)
+
+	if w := api.do(http.MethodDelete, "/admin/users/"+caller.ID.String()+"/roles/"+restricted.ID.String(), token, ""); w.Code != http.StatusForbidden {
+		t.Errorf("removing a restricting role from self: status = %d, want 403", w.Code)
+	}
+	stored, err := api.store.Users.GetByID(context.Background(), caller.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.HasRole(restricted.ID) {
+		t.Fatal("caller removed the restricting role")
+	}
+}
+
+func TestUpdateUserCredentialsRequiresTargetPermissions(t *testing.T) {
+	api := newTestAPI(t)
+	caller := api.user("support@example.com", api.role("support", "user:update"))
+	target := api.user("root@example.com", api.role("root", "user:update", "role:delete"))
+	token := api.token(caller, DefaultScope)
+	path := "/admin/users/" + target.ID.String()
+
+	for _, body := range []string{`{"password":"N3w-Passw0rd!x"}`, `{"email":"support+root@example.com"}`} {
+		if w := api.do(http.MethodPatch, path, token, body); w.Code != http.StatusForbidden {
+			t.Errorf("%s for a more privileged user: status = %d, want 403", body, w.Code)
+		}
+	}
+	if w := api.do(http.MethodPatch, path, token, `{"first_name":"Root"}`); w.Code != http.StatusOK {
+		t.Errorf("profile change: status = %d, want 200: %s", w.Code, w.Body)
+	}
+	stored, err := api.store.Users.GetByID(context.Background(), target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Email != target.Email || stored.Password != target.Password {
+		t.Error("credentials of a more privileged user were changed")
+	}
+}
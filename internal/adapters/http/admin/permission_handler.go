@@ -0,0 +1,213 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// permissionRequest тело запроса на создание или изменение разрешения
+type permissionRequest struct {
+	Name        *string                  `json:"name,omitempty"`
+	Resource    *entity.ResourceType     `json:"resource,omitempty"`
+	Action      *entity.Action           `json:"action,omitempty"`
+	Effect      *entity.PermissionEffect `json:"effect,omitempty"`
+	Description *string                  `json:"description,omitempty"`
+}
+
+// apply переносит заданные поля запроса в разрешение
+func (req permissionRequest) apply(permission *entity.Permission) {
+	if req.Name != nil {
+		permission.Name = *req.Name
+	}
+	if req.Resource != nil {
+		permission.Resource = *req.Resource
+	}
+	if req.Action != nil {
+		permission.Action = *req.Action
+	}
+	if req.Effect != nil {
+		permission.Effect = *req.Effect
+	}
+	if req.Description != nil {
+		permission.Description = *req.Description
+	}
+}
+
+// validatePermission проверяет разрешение по реестру ресурсов; при ошибке ответ уже записан
+func (h *Handler) validatePermission(w http.ResponseWriter, permission *entity.Permission) bool {
+	if permission.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return false
+	}
+	if permission.Effect != "" && permission.Effect != entity.EffectAllow && permission.Effect != entity.EffectDeny {
+		writeError(w, http.StatusBadRequest, "effect must be allow or deny")
+		return false
+	}
+	if err := h.deps.ResourceRegistry.ValidatePermission(permission); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// listPermissions возвращает страницу разрешений.
+// Фильтры: resource, action, effect.
+func (h *Handler) listPermissions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionList); !ok {
+		return
+	}
+
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := ports.PermissionFilter{
+		Resource: entity.ResourceType(query.Get("resource")),
+		Action:   entity.Action(query.Get("action")),
+		Effect:   entity.PermissionEffect(query.Get("effect")),
+	}
+
+	permissions, total, err := h.deps.Permissions.List(r.Context(), filter, page)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeList(w, permissions, total, page)
+}
+
+// createPermission создает разрешение
+func (h *Handler) createPermission(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionCreate)
+	if !ok {
+		return
+	}
+
+	var req permissionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	permission := entity.NewPermission("", "", "", "")
+	req.apply(permission)
+	if !h.validatePermission(w, permission) {
+		return
+	}
+
+	if err := h.deps.Permissions.Create(r.Context(), permission); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("permission created",
+		zap.String("permission_id", permission.ID.String()),
+		zap.String("permission", permission.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusCreated, permission)
+}
+
+// getPermission возвращает разрешение
+func (h *Handler) getPermission(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionRead); !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	permission, err := h.deps.Permissions.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, permission)
+}
+
+// updatePermission изменяет разрешение; изменение сразу действует во всех ролях,
+// поэтому результат проверяется как выдача, а измененный запрет — как его снятие
+func (h *Handler) updatePermission(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req permissionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	permission, err := h.deps.Permissions.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	stored := *permission
+	req.apply(permission)
+	permission.UpdatedAt = time.Now()
+	if !h.validatePermission(w, permission) {
+		return
+	}
+	if !h.canGrant(actor, *permission) || !h.canLift(actor, stored) {
+		writeError(w, http.StatusForbidden, "change grants permissions the caller does not have")
+		return
+	}
+
+	if err := h.deps.Permissions.Update(r.Context(), permission); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.publish(entity.NewDomainEvent(entity.EventPermissionChanged, permission.ID))
+
+	log.Info("permission updated",
+		zap.String("permission_id", permission.ID.String()),
+		zap.String("permission", permission.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, permission)
+}
+
+// deletePermission удаляет разрешение; удаление запрета снимает его во всех ролях
+func (h *Handler) deletePermission(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionDelete)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	permission, err := h.deps.Permissions.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	if !h.canLift(actor, *permission) {
+		writeError(w, http.StatusForbidden, "permission denies an action the caller does not have")
+		return
+	}
+
+	if err := h.deps.Permissions.Delete(r.Context(), id); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.publish(entity.NewDomainEvent(entity.EventPermissionDeleted, id))
+
+	log.Info("permission deleted",
+		zap.String("permission_id", id.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
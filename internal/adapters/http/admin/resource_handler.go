@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"net/http"
+)
+
+// listResources возвращает зарегистрированные типы ресурсов
+func (h *Handler) listResources(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionList); !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resources": h.deps.ResourceRegistry.Resources(),
+	})
+}
+
+// listActions возвращает зарегистрированные действия
+func (h *Handler) listActions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourcePermission, entity.ActionList); !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"actions": h.deps.ResourceRegistry.Actions(),
+	})
+}
@@ -0,0 +1,277 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// roleRequest тело запроса на создание или изменение роли
+type roleRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// addPermissionRequest тело запроса на добавление разрешения в роль
+type addPermissionRequest struct {
+	PermissionID uuid.UUID `json:"permission_id"`
+}
+
+// listRoles возвращает страницу ролей.
+// Фильтры: name (подстрока), permission_id.
+func (h *Handler) listRoles(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionList); !ok {
+		return
+	}
+
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	filter := ports.RoleFilter{
+		Name: r.URL.Query().Get("name"),
+	}
+	if raw := r.URL.Query().Get("permission_id"); raw != "" {
+		permissionID, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid permission_id")
+			return
+		}
+		filter.PermissionID = &permissionID
+	}
+
+	roles, total, err := h.deps.Roles.List(r.Context(), filter, page)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeList(w, roles, total, page)
+}
+
+// createRole создает роль без разрешений
+func (h *Handler) createRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionCreate)
+	if !ok {
+		return
+	}
+
+	var req roleRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+	role := entity.NewRole(*req.Name, description)
+
+	if err := h.deps.Roles.Create(r.Context(), role); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("role created",
+		zap.String("role_id", role.ID.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusCreated, role)
+}
+
+// getRole возвращает роль
+func (h *Handler) getRole(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionRead); !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	role, err := h.deps.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, role)
+}
+
+// updateRole изменяет имя и описание роли
+func (h *Handler) updateRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req roleRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	role, err := h.deps.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			writeError(w, http.StatusBadRequest, "name must not be empty")
+			return
+		}
+		role.Name = *req.Name
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+
+	if err := h.deps.Roles.Update(r.Context(), role); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("role updated",
+		zap.String("role_id", role.ID.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, role)
+}
+
+// deleteRole удаляет роль
+func (h *Handler) deleteRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionDelete)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.deps.Roles.Delete(r.Context(), id); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.publish(entity.NewDomainEvent(entity.EventRoleDeleted, id))
+	h.auditRoleChange(r, actor, "role deleted", map[string]interface{}{
+		"role_id": id.String(),
+	})
+
+	log.Info("role deleted",
+		zap.String("role_id", id.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addRolePermission добавляет разрешение в роль
+func (h *Handler) addRolePermission(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req addPermissionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	role, err := h.deps.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	permission, err := h.deps.Permissions.GetByID(r.Context(), req.PermissionID)
+	if err != nil {
+		if errors.Is(err, ports.ErrNotFound) {
+			writeError(w, http.StatusUnprocessableEntity, "permission not found")
+			return
+		}
+		writeRepositoryError(w, err)
+		return
+	}
+	if !h.canGrant(actor, *permission) {
+		writeError(w, http.StatusForbidden, "permission is not held by the caller")
+		return
+	}
+
+	role.AddPermission(*permission)
+	events := role.PullEvents()
+	if len(events) > 0 {
+		if err := h.deps.Roles.Update(r.Context(), role); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+		h.publish(events...)
+		h.auditRoleChange(r, actor, "permission added to role", map[string]interface{}{
+			"role_id":       role.ID.String(),
+			"permission_id": permission.ID.String(),
+			"permission":    permission.String(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, role)
+}
+
+// removeRolePermission удаляет разрешение из роли; удалить запрет может только
+// тот, кто сам обладает запрещенным действием
+func (h *Handler) removeRolePermission(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceRole, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	permissionID, ok := pathID(w, r, "permission_id")
+	if !ok {
+		return
+	}
+
+	role, err := h.deps.Roles.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	if !role.HasPermission(permissionID) {
+		writeError(w, http.StatusNotFound, "permission is not assigned to role")
+		return
+	}
+	for _, permission := range role.Permissions {
+		if permission.ID == permissionID && !h.canLift(actor, permission) {
+			writeError(w, http.StatusForbidden, "permission denies an action the caller does not have")
+			return
+		}
+	}
+
+	role.RemovePermission(permissionID)
+	if err := h.deps.Roles.Update(r.Context(), role); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.publish(role.PullEvents()...)
+	h.auditRoleChange(r, actor, "permission removed from role", map[string]interface{}{
+		"role_id":       role.ID.String(),
+		"permission_id": permissionID.String(),
+	})
+
+	writeJSON(w, http.StatusOK, role)
+}
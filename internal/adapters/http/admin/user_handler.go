@@ -0,0 +1,339 @@
+package admin
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/valueobject"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// createUserRequest тело запроса на создание пользователя
+type createUserRequest struct {
+	Email      string                 `json:"email"`
+	Password   string                 `json:"password"`
+	FirstName  string                 `json:"first_name"`
+	LastName   string                 `json:"last_name"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// updateUserRequest тело запроса на изменение пользователя; пустые поля не изменяются.
+// Атрибут со значением null удаляется (как в JSON Merge Patch, RFC 7396).
+type updateUserRequest struct {
+	Email      *string                `json:"email,omitempty"`
+	Password   *string                `json:"password,omitempty"`
+	FirstName  *string                `json:"first_name,omitempty"`
+	LastName   *string                `json:"last_name,omitempty"`
+	Active     *bool                  `json:"active,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// assignRoleRequest тело запроса на назначение роли
+type assignRoleRequest struct {
+	RoleID uuid.UUID `json:"role_id"`
+}
+
+// listUsers возвращает страницу пользователей.
+// Фильтры: email (подстрока), active, role_id.
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionList); !ok {
+		return
+	}
+
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+	active, ok := parseBoolQuery(w, r, "active")
+	if !ok {
+		return
+	}
+
+	filter := ports.UserFilter{
+		Email:  r.URL.Query().Get("email"),
+		Active: active,
+	}
+	if raw := r.URL.Query().Get("role_id"); raw != "" {
+		roleID, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid role_id")
+			return
+		}
+		filter.RoleID = &roleID
+	}
+
+	users, total, err := h.deps.Users.List(r.Context(), filter, page)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeList(w, users, total, page)
+}
+
+// createUser создает пользователя
+func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionCreate)
+	if !ok {
+		return
+	}
+
+	var req createUserRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.FirstName == "" || req.LastName == "" {
+		writeError(w, http.StatusBadRequest, "first_name and last_name are required")
+		return
+	}
+
+	email, err := valueobject.NewEmail(req.Email)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	password, err := valueobject.NewPassword(req.Password, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user := entity.NewUser(email.Address(), req.FirstName, req.LastName, password.Hash())
+	for key, value := range req.Attributes {
+		user.SetAttribute(key, value)
+	}
+
+	if err := h.deps.Users.Create(r.Context(), user); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("user created",
+		zap.String("user_id", user.ID.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// getUser возвращает пользователя
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionRead); !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.deps.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// updateUser изменяет профиль, пароль, статус и атрибуты пользователя. Сменив
+// пароль или email, можно войти от имени пользователя, поэтому для этого
+// вызывающий должен сам обладать всеми его разрешениями.
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req updateUserRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	user, err := h.deps.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	if (req.Email != nil || req.Password != nil) && user.ID != actor.ID &&
+		!h.canGrant(actor, h.deps.PermissionChecker.GetUserPermissions(user)...) {
+		writeError(w, http.StatusForbidden, "user has permissions the caller does not have")
+		return
+	}
+
+	if req.Email != nil {
+		email, err := valueobject.NewEmail(*req.Email)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		user.Email = email.Address()
+	}
+	if req.Password != nil {
+		password, err := valueobject.NewPassword(*req.Password, nil)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		user.Password = password.Hash()
+	}
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.Active != nil {
+		if *req.Active {
+			user.Activate()
+		} else {
+			user.Deactivate()
+		}
+	}
+	for key, value := range req.Attributes {
+		if value == nil {
+			user.RemoveAttribute(key)
+			continue
+		}
+		user.SetAttribute(key, value)
+	}
+
+	if err := h.deps.Users.Update(r.Context(), user); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+
+	log.Info("user updated",
+		zap.String("user_id", user.ID.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	writeJSON(w, http.StatusOK, user)
+}
+
+// deleteUser удаляет пользователя
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionDelete)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.deps.Users.Delete(r.Context(), id); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.publish(entity.NewDomainEvent(entity.EventUserDeleted, id))
+
+	log.Info("user deleted",
+		zap.String("user_id", id.String()),
+		zap.String("actor_id", actor.ID.String()),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// assignUserRole назначает роль пользователю
+func (h *Handler) assignUserRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req assignRoleRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	user, err := h.deps.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	role, err := h.deps.Roles.GetByID(r.Context(), req.RoleID)
+	if err != nil {
+		if errors.Is(err, ports.ErrNotFound) {
+			writeError(w, http.StatusUnprocessableEntity, "role not found")
+			return
+		}
+		writeRepositoryError(w, err)
+		return
+	}
+	if !h.canGrant(actor, role.Permissions...) {
+		writeError(w, http.StatusForbidden, "role grants permissions the caller does not have")
+		return
+	}
+
+	user.AddRole(*role)
+	events := user.PullEvents()
+	if len(events) > 0 {
+		if err := h.deps.Users.Update(r.Context(), user); err != nil {
+			writeRepositoryError(w, err)
+			return
+		}
+		h.publish(events...)
+		h.auditRoleChange(r, actor, "role assigned to user", map[string]interface{}{
+			"target_user_id": user.ID.String(),
+			"role_id":        role.ID.String(),
+			"role_name":      role.Name,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// removeUserRole отзывает роль у пользователя. Роль с запретами может отозвать
+// только тот, кто сам обладает запрещенными действиями: иначе пользователь снял бы
+// с себя ограничивающую роль.
+func (h *Handler) removeUserRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := h.authorize(w, r, entity.ResourceUser, entity.ActionUpdate)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	roleID, ok := pathID(w, r, "role_id")
+	if !ok {
+		return
+	}
+
+	user, err := h.deps.Users.GetByID(r.Context(), id)
+	if err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	if !user.HasRole(roleID) {
+		writeError(w, http.StatusNotFound, "role is not assigned to user")
+		return
+	}
+	for _, role := range user.Roles {
+		if role.ID == roleID && !h.canLift(actor, role.Permissions...) {
+			writeError(w, http.StatusForbidden, "role denies actions the caller does not have")
+			return
+		}
+	}
+
+	user.RemoveRole(roleID)
+	if err := h.deps.Users.Update(r.Context(), user); err != nil {
+		writeRepositoryError(w, err)
+		return
+	}
+	h.publish(user.PullEvents()...)
+	h.auditRoleChange(r, actor, "role removed from user", map[string]interface{}{
+		"target_user_id": user.ID.String(),
+		"role_id":        roleID.String(),
+	})
+
+	writeJSON(w, http.StatusOK, user)
+}
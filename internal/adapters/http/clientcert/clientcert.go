@@ -0,0 +1,75 @@
+// Package clientcert извлекает сертификат клиента mutual TLS (RFC 8705) из TLS
+// соединения или из заголовков TLS-прокси. Источник сертификата должен быть
+// одинаковым у token endpoint и у API, принимающих привязанные к нему токены.
+package clientcert
+
+import (
+	"AuthAndOauth/internal/pkg/jose"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// VerifiedValue значение заголовка Source.VerifyHeader, означающее, что прокси
+// проверил цепочку сертификата (как $ssl_client_verify в nginx)
+const VerifiedValue = "SUCCESS"
+
+// ErrNoCertificate клиент не предъявил сертификат
+var ErrNoCertificate = errors.New("client certificate is required")
+
+// errMalformedHeader заголовок прокси не содержит сертификат в PEM
+var errMalformedHeader = errors.New("malformed client certificate header")
+
+// Source источник сертификата клиента
+type Source struct {
+	// Header заголовок, в котором TLS-прокси передает сертификат клиента в PEM
+	// с URL-кодированием; пустой — сертификат берется из TLS соединения.
+	// Прокси должен удалять этот заголовок из входящих запросов.
+	Header string
+	// VerifyHeader заголовок, в котором прокси сообщает результат проверки
+	// цепочки сертификата; цепочка считается проверенной при значении VerifiedValue.
+	// Прокси должен удалять и этот заголовок из входящих запросов.
+	VerifyHeader string
+}
+
+// Certificate возвращает сертификат клиента и признак того, что его цепочка
+// проверена TLS-сервером (tls.Config.ClientCAs) или прокси
+func (s Source) Certificate(r *http.Request) (*x509.Certificate, bool, error) {
+	if s.Header == "" {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			return nil, false, ErrNoCertificate
+		}
+		return r.TLS.PeerCertificates[0], len(r.TLS.VerifiedChains) > 0, nil
+	}
+
+	escaped := r.Header.Get(s.Header)
+	if escaped == "" {
+		return nil, false, ErrNoCertificate
+	}
+	raw, err := url.QueryUnescape(escaped)
+	if err != nil {
+		return nil, false, errMalformedHeader
+	}
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, false, errMalformedHeader
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, false, err
+	}
+	verified := s.VerifyHeader != "" && r.Header.Get(s.VerifyHeader) == VerifiedValue
+	return cert, verified, nil
+}
+
+// Thumbprint возвращает x5t#S256 сертификата клиента или пустую строку, если
+// сертификата нет
+func (s Source) Thumbprint(r *http.Request) string {
+	cert, _, err := s.Certificate(r)
+	if err != nil {
+		return ""
+	}
+	return jose.CertificateThumbprint(cert.Raw)
+}
@@ -0,0 +1,79 @@
+package clientcert
+
+import (
+	"AuthAndOauth/internal/pkg/jose"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCertificateFromTLSRequiresVerifiedChain(t *testing.T) {
+	cert := newTestCertificate(t)
+	r := httptest.NewRequest("POST", "/token", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	if _, verified, err := (Source{}).Certificate(r); err != nil || verified {
+		t.Errorf("unverified chain: verified = %v, err = %v", verified, err)
+	}
+	r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	if _, verified, err := (Source{}).Certificate(r); err != nil || !verified {
+		t.Errorf("verified chain: verified = %v, err = %v", verified, err)
+	}
+}
+
+func TestCertificateFromProxyHeader(t *testing.T) {
+	cert := newTestCertificate(t)
+	source := Source{Header: "X-Client-Cert", VerifyHeader: "X-Client-Verify"}
+	r := httptest.NewRequest("POST", "/token", nil)
+	r.Header.Set(source.Header, url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))))
+
+	if _, verified, err := source.Certificate(r); err != nil || verified {
+		t.Errorf("without verify header: verified = %v, err = %v", verified, err)
+	}
+	r.Header.Set(source.VerifyHeader, VerifiedValue)
+	if _, verified, err := source.Certificate(r); err != nil || !verified {
+		t.Errorf("with verify header: verified = %v, err = %v", verified, err)
+	}
+	if got := source.Thumbprint(r); got != jose.CertificateThumbprint(cert.Raw) {
+		t.Errorf("thumbprint = %q", got)
+	}
+
+	// Сертификат TLS соединения не используется, если настроен заголовок прокси
+	r = httptest.NewRequest("POST", "/token", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if got := source.Thumbprint(r); got != "" {
+		t.Errorf("thumbprint without the proxy header = %q, want empty", got)
+	}
+}
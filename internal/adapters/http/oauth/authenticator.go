@@ -0,0 +1,74 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// UserAuthenticator определяет пользователя, вошедшего в систему, на страницах
+// подтверждения (например, при авторизации устройства)
+type UserAuthenticator interface {
+	Authenticate(r *http.Request) (*entity.User, error)
+}
+
+// DefaultSessionCookie имя cookie с идентификатором сессии по умолчанию
+const DefaultSessionCookie = "session_id"
+
+// SessionAuthenticator аутентифицирует пользователя по cookie с идентификатором сессии
+type SessionAuthenticator struct {
+	cookie    string
+	sessions  ports.SessionRepository
+	users     ports.UserRepository
+	validator *service.TokenValidator
+}
+
+// NewSessionAuthenticator создает аутентификатор по сессиям; пустое имя cookie —
+// DefaultSessionCookie
+func NewSessionAuthenticator(cookie string, sessions ports.SessionRepository, users ports.UserRepository, validator *service.TokenValidator) *SessionAuthenticator {
+	if cookie == "" {
+		cookie = DefaultSessionCookie
+	}
+	if validator == nil {
+		validator = service.NewTokenValidator()
+	}
+	return &SessionAuthenticator{
+		cookie:    cookie,
+		sessions:  sessions,
+		users:     users,
+		validator: validator,
+	}
+}
+
+// Authenticate реализует UserAuthenticator
+func (a *SessionAuthenticator) Authenticate(r *http.Request) (*entity.User, error) {
+	cookie, err := r.Cookie(a.cookie)
+	if err != nil || cookie.Value == "" {
+		return nil, fmt.Errorf("missing session cookie")
+	}
+
+	session, err := a.sessions.GetByID(r.Context(), cookie.Value)
+	if err != nil {
+		return nil, fmt.Errorf("unknown session: %w", err)
+	}
+	if err := a.validator.ValidateSession(session); err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session user: %w", err)
+	}
+	user, err := a.users.GetByID(r.Context(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("session owner not found: %w", err)
+	}
+	if !user.Active {
+		return nil, fmt.Errorf("user is inactive")
+	}
+	return user, nil
+}
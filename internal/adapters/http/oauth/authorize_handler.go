@@ -0,0 +1,416 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// pushedAuthorizationResponse ответ endpoint PAR (RFC 9126, раздел 2.2)
+type pushedAuthorizationResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
+
+// authorizationParams читает параметры запроса авторизации. Ошибка означает,
+// что authorization_details не являются JSON массивом объектов с типом.
+func authorizationParams(values url.Values) (entity.AuthorizationRequest, error) {
+	details, err := entity.ParseAuthorizationDetails(values.Get("authorization_details"))
+	if err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+	return entity.AuthorizationRequest{
+		ClientID:            values.Get("client_id"),
+		ResponseType:        entity.ResponseType(values.Get("response_type")),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scopes:              strings.Fields(values.Get("scope")),
+		State:               values.Get("state"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+		Resources:           values["resource"],
+
+		AuthorizationDetails: details,
+	}, nil
+}
+
+// pushAuthorizationRequest принимает параметры авторизации от аутентифицированного
+// клиента и возвращает request_uri для authorization endpoint (RFC 9126, раздел 2)
+func (h *Handler) pushAuthorizationRequest(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	if r.PostForm.Get("request_uri") != "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "request_uri must not be pushed")
+		return
+	}
+
+	params, err := authorizationParams(r.PostForm)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidAuthorizationDetails, err.Error())
+		return
+	}
+	pending, err := h.deps.Authorization.Push(r.Context(), client, params, r.PostForm.Get("request"))
+	var authErr *service.AuthorizationError
+	switch {
+	case err == nil:
+	case errors.As(err, &authErr):
+		writeError(w, http.StatusBadRequest, authErr.Code, authErr.Description)
+		return
+	case errors.Is(err, service.ErrInvalidRequestObject):
+		writeError(w, http.StatusBadRequest, errorInvalidRequestObject, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidAuthorizationRedirectURI), errors.Is(err, service.ErrUnknownAuthorizationClient):
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, err.Error())
+		return
+	default:
+		log.Error("failed to push authorization request", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, pushedAuthorizationResponse{
+		RequestURI: pending.RequestURI(),
+		ExpiresIn:  int64(time.Until(pending.ExpiresAt).Round(time.Second) / time.Second),
+	})
+}
+
+// consentPage данные страницы согласия и ошибок authorization endpoint
+type consentPage struct {
+	ClientName      string
+	ClientURI       string
+	Scopes          []string
+	Resources       []string
+	Details         []consentDetail
+	AuthorizationID string
+	CSRFToken       string
+	Error           string
+}
+
+// consentDetail объект authorization_details на странице согласия: описание
+// типа и поля объекта в виде "имя: значение"
+type consentDetail struct {
+	Description string
+	Fields      []string
+}
+
+var consentPageTemplate = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Authorize application</title></head>
+<body>
+<h1>Authorize application</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>
+{{else}}
+<p><strong>{{.ClientName}}</strong> is requesting access to your account.</p>
+{{if .ClientURI}}<p><a href="{{.ClientURI}}" rel="noopener noreferrer">{{.ClientURI}}</a></p>{{end}}
+{{if .Scopes}}<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Resources}}<p>Access is requested to:</p>
+<ul>{{range .Resources}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{range .Details}}<section>
+<h2>{{.Description}}</h2>
+<ul>{{range .Fields}}<li>{{.}}</li>{{end}}</ul>
+</section>
+{{end}}<form method="post">
+<input type="hidden" name="authorization_id" value="{{.AuthorizationID}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<button type="submit" name="action" value="approve">Allow</button>
+<button type="submit" name="action" value="deny">Deny</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// authorize обрабатывает запрос к authorization endpoint: проверяет его и
+// показывает пользователю страницу согласия (RFC 6749, раздел 4.1.1)
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) {
+	// Пользователь определяется до проверки запроса: request_uri погашается при
+	// проверке и не должен пропасть при перенаправлении на страницу входа
+	user, ok := h.pageUser(w, r, consentPageTemplate, consentPage{Error: "Please sign in to continue."})
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	params, err := authorizationParams(query)
+	if err != nil {
+		// Клиент и redirect_uri еще не проверены, поэтому ошибка не передается перенаправлением
+		log.Info("malformed authorization details", zap.Error(err))
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "The application sent an invalid authorization request."})
+		return
+	}
+	client, req, err := h.deps.Authorization.Resolve(r.Context(), params, query.Get("request"), query.Get("request_uri"))
+	if err != nil {
+		h.authorizationFailed(w, r, req, err)
+		return
+	}
+
+	if h.deps.Consents != nil {
+		covered, err := h.deps.Consents.Covered(r.Context(), client, user.ID, *req)
+		if err != nil {
+			log.Error("failed to check consent", zap.String("client_id", client.ClientID), zap.Error(err))
+			redirectAuthorization(w, r, *req, url.Values{"error": {errorServerError}})
+			return
+		}
+		if covered {
+			log.Info("consent page skipped", zap.String("client_id", client.ClientID), zap.String("user_id", user.ID.String()))
+			h.completeAuthorization(w, r, client, user, *req)
+			return
+		}
+	}
+
+	pending, err := h.deps.Authorization.Hold(r.Context(), client, user.ID, *req)
+	if err != nil {
+		log.Error("failed to store authorization request", zap.Error(err))
+		renderPage(w, http.StatusInternalServerError, consentPageTemplate, consentPage{Error: "Something went wrong. Please try again."})
+		return
+	}
+
+	renderRedirectingPage(w, http.StatusOK, consentPageTemplate, consentPage{
+		ClientName:      client.Name,
+		ClientURI:       client.ClientURI,
+		Scopes:          h.scopeDescriptions(r, req.Scopes),
+		Resources:       h.resourceNames(req.Resources),
+		Details:         h.consentDetails(req.AuthorizationDetails),
+		AuthorizationID: pending.ID,
+		CSRFToken:       h.csrfToken(w, r),
+	}, req.RedirectURI)
+}
+
+// scopeDescriptions возвращает описания областей для страниц подтверждения
+// на языке из заголовка Accept-Language
+func (h *Handler) scopeDescriptions(r *http.Request, scopes []string) []string {
+	languages := acceptedLanguages(r.Header.Get("Accept-Language"))
+	descriptions := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		descriptions = append(descriptions, h.deps.Scopes.Describe(scope, languages))
+	}
+	return descriptions
+}
+
+// acceptedLanguages возвращает языковые теги из Accept-Language в порядке
+// убывания веса q (RFC 9110, раздел 12.5.4); теги с q=0 и "*" пропускаются
+func acceptedLanguages(header string) []string {
+	type weightedLanguage struct {
+		tag    string
+		weight float64
+	}
+	var languages []weightedLanguage
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight > 0 {
+			languages = append(languages, weightedLanguage{tag: tag, weight: weight})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].weight > languages[j].weight
+	})
+
+	tags := make([]string, 0, len(languages))
+	for _, language := range languages {
+		tags = append(tags, language.tag)
+	}
+	return tags
+}
+
+// resourceNames возвращает названия ресурсов для страницы согласия
+func (h *Handler) resourceNames(uris []string) []string {
+	names := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		if resource, ok := h.deps.Resources.Resource(uri); ok && resource.Name != "" {
+			uri = resource.Name
+		}
+		names = append(names, uri)
+	}
+	return names
+}
+
+// consentDetails описывает authorization_details для страницы согласия
+func (h *Handler) consentDetails(details entity.AuthorizationDetails) []consentDetail {
+	types := h.deps.Authorization.Config().AuthorizationDetails
+	result := make([]consentDetail, 0, len(details))
+	for _, detail := range details {
+		description := detail.Type()
+		if registered, ok := types.Type(detail.Type()); ok && registered.Description != "" {
+			description = registered.Description
+		}
+
+		names := make([]string, 0, len(detail))
+		for name := range detail {
+			if name != "type" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		fields := make([]string, 0, len(names))
+		for _, name := range names {
+			value, ok := detail[name].(string)
+			if !ok {
+				raw, _ := json.Marshal(detail[name])
+				value = string(raw)
+			}
+			fields = append(fields, name+": "+value)
+		}
+		result = append(result, consentDetail{Description: description, Fields: fields})
+	}
+	return result
+}
+
+// authorizeDecision фиксирует решение пользователя и возвращает его клиенту
+// перенаправлением на redirect_uri
+func (h *Handler) authorizeDecision(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.pageUser(w, r, consentPageTemplate, consentPage{Error: "Please sign in to continue."})
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil || !checkCSRF(r) {
+		renderPage(w, http.StatusForbidden, consentPageTemplate, consentPage{Error: "The form has expired. Please try again."})
+		return
+	}
+
+	client, pending, err := h.deps.Authorization.Take(r.Context(), r.PostForm.Get("authorization_id"), user.ID)
+	if err != nil {
+		h.authorizationFailed(w, r, nil, err)
+		return
+	}
+	req := pending.Request
+
+	switch r.PostForm.Get("action") {
+	case "approve":
+	case "deny":
+		log.Info("authorization denied by user", zap.String("client_id", client.ClientID), zap.String("user_id", user.ID.String()))
+		redirectAuthorization(w, r, req, url.Values{
+			"error":             {service.AuthorizationErrorAccessDenied},
+			"error_description": {"the user denied the request"},
+		})
+		return
+	default:
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "Unknown action."})
+		return
+	}
+
+	h.completeAuthorization(w, r, client, user, req)
+}
+
+// completeAuthorization запоминает согласие пользователя, выдает код авторизации
+// и возвращает его клиенту перенаправлением
+func (h *Handler) completeAuthorization(w http.ResponseWriter, r *http.Request, client *entity.Client, user *entity.User, req entity.AuthorizationRequest) {
+	if h.deps.Consents != nil {
+		if err := h.deps.Consents.Grant(r.Context(), client, user.ID, req.Scopes, req.Resources); err != nil {
+			log.Error("failed to record consent", zap.String("client_id", client.ClientID), zap.Error(err))
+			redirectAuthorization(w, r, req, url.Values{"error": {errorServerError}})
+			return
+		}
+	}
+
+	code, err := h.deps.Authorization.IssueCode(r.Context(), client, user.ID, req)
+	if err != nil {
+		log.Error("failed to issue authorization code", zap.String("client_id", client.ClientID), zap.Error(err))
+		redirectAuthorization(w, r, req, url.Values{"error": {errorServerError}})
+		return
+	}
+	redirectAuthorization(w, r, req, url.Values{"code": {code.Code}})
+}
+
+// authorizationFailed сообщает об ошибке запроса авторизации. Ошибки клиента,
+// redirect_uri, request_uri и объекта запроса показываются пользователю, остальные передаются
+// клиенту перенаправлением.
+func (h *Handler) authorizationFailed(w http.ResponseWriter, r *http.Request, req *entity.AuthorizationRequest, err error) {
+	var authErr *service.AuthorizationError
+	switch {
+	case errors.As(err, &authErr) && req != nil:
+		redirectAuthorization(w, r, *req, url.Values{
+			"error":             {authErr.Code},
+			"error_description": {authErr.Description},
+		})
+	case errors.Is(err, service.ErrUnknownAuthorizationClient):
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "The application is unknown or disabled."})
+	case errors.Is(err, service.ErrInvalidAuthorizationRedirectURI):
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "The application sent an invalid redirect address."})
+	case errors.Is(err, service.ErrInvalidRequestObject):
+		log.Info("invalid authorization request object", zap.Error(err))
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "The application sent an invalid authorization request."})
+	case errors.Is(err, service.ErrInvalidRequestURI), errors.Is(err, service.ErrInvalidPendingAuthorization):
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "The authorization request has expired or was already used. Please start again."})
+	default:
+		log.Error("failed to process authorization request", zap.Error(err))
+		renderPage(w, http.StatusInternalServerError, consentPageTemplate, consentPage{Error: "Something went wrong. Please try again."})
+	}
+}
+
+// redirectAuthorization перенаправляет пользователя на redirect_uri клиента с
+// параметрами ответа и state (RFC 6749, раздел 4.1.2)
+func redirectAuthorization(w http.ResponseWriter, r *http.Request, req entity.AuthorizationRequest, params url.Values) {
+	target, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		renderPage(w, http.StatusBadRequest, consentPageTemplate, consentPage{Error: "The application sent an invalid redirect address."})
+		return
+	}
+	query := target.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	target.RawQuery = query.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	status := http.StatusFound
+	if r.Method == http.MethodPost {
+		status = http.StatusSeeOther
+	}
+	http.Redirect(w, r, target.String(), status)
+}
+
+// authorizationCodeGrant обменивает код авторизации на токены (RFC 6749, раздел 4.1.3)
+func (h *Handler) authorizationCodeGrant(w http.ResponseWriter, r *http.Request, client *entity.Client) {
+	value := r.PostForm.Get("code")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "code is required")
+		return
+	}
+
+	code, err := h.deps.Authorization.RedeemCode(r.Context(), client, value, r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+	if errors.Is(err, service.ErrInvalidAuthorizationCode) {
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error("failed to redeem authorization code", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	h.writeTokens(w, r, client, tokenGrant{
+		userID:    code.UserID,
+		scopes:    code.Scopes,
+		resources: code.Resources,
+		details:   code.AuthorizationDetails,
+	})
+}
@@ -0,0 +1,322 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxClientNotificationTokenLength ограничение длины client_notification_token
+// (CIBA Core, раздел 7.1)
+const maxClientNotificationTokenLength = 1024
+
+// backchannelAuthenticationResponse ответ backchannel authentication endpoint
+// (CIBA Core, раздел 7.3)
+type backchannelAuthenticationResponse struct {
+	AuthReqID string `json:"auth_req_id"`
+	ExpiresIn int64  `json:"expires_in"`
+	Interval  int64  `json:"interval,omitempty"`
+}
+
+// backchannelPing уведомление клиента в режиме ping (CIBA Core, раздел 10.2)
+type backchannelPing struct {
+	AuthReqID string `json:"auth_req_id"`
+}
+
+// backchannelPushResult результат, отправляемый клиенту в режиме push
+// (CIBA Core, раздел 10.3): токены или ошибка
+type backchannelPushResult struct {
+	AuthReqID string `json:"auth_req_id"`
+	tokenResponse
+}
+
+// backchannelPushError отказ пользователя в режиме push (CIBA Core, раздел 12)
+type backchannelPushError struct {
+	AuthReqID string `json:"auth_req_id"`
+	errorResponse
+}
+
+// backchannelAuthentication принимает запрос аутентификации пользователя от
+// клиента (CIBA Core, раздел 7.1). Пользователь определяется по login_hint;
+// login_hint_token, id_token_hint и подписанные запросы не поддерживаются.
+func (h *Handler) backchannelAuthentication(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	if err := h.deps.Validator.ValidateClient(client, entity.GrantTypeCIBA); err != nil {
+		writeError(w, http.StatusBadRequest, errorUnauthorizedClient, err.Error())
+		return
+	}
+
+	if r.PostForm.Get("request") != "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "signed authentication requests are not supported")
+		return
+	}
+	if r.PostForm.Get("login_hint_token") != "" || r.PostForm.Get("id_token_hint") != "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "only login_hint is supported")
+		return
+	}
+	loginHint := r.PostForm.Get("login_hint")
+	if loginHint == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "login_hint is required")
+		return
+	}
+	scopes, ok := h.requestedScopes(w, r, client)
+	if !ok {
+		return
+	}
+
+	req := service.BackchannelAuthenticationRequest{
+		LoginHint:               loginHint,
+		Scopes:                  scopes,
+		BindingMessage:          r.PostForm.Get("binding_message"),
+		ClientNotificationToken: r.PostForm.Get("client_notification_token"),
+	}
+	if raw := r.PostForm.Get("requested_expiry"); raw != "" {
+		seconds, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || seconds <= 0 {
+			writeError(w, http.StatusBadRequest, errorInvalidRequest, "requested_expiry must be a positive integer")
+			return
+		}
+		req.RequestedExpiry = time.Duration(seconds) * time.Second
+	}
+	if client.BackchannelDeliveryMode().NotifiesClient() && req.ClientNotificationToken == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "client_notification_token is required")
+		return
+	}
+	if len(req.ClientNotificationToken) > maxClientNotificationTokenLength {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "client_notification_token is too long")
+		return
+	}
+
+	request, err := h.deps.Backchannel.Start(r.Context(), client, req)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrUnknownUserID):
+		writeError(w, http.StatusBadRequest, errorUnknownUserID, "the user could not be identified")
+		return
+	case errors.Is(err, service.ErrInvalidBindingMessage):
+		writeError(w, http.StatusBadRequest, errorInvalidBindingMessage, err.Error())
+		return
+	default:
+		log.Error("failed to start backchannel authentication", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	response := backchannelAuthenticationResponse{
+		AuthReqID: request.AuthReqID,
+		ExpiresIn: int64(time.Until(request.ExpiresAt).Round(time.Second) / time.Second),
+	}
+	if request.DeliveryMode != entity.BackchannelDeliveryPush {
+		response.Interval = int64((request.Interval + time.Second - 1) / time.Second)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// cibaGrant обрабатывает опрос token endpoint по auth_req_id в режимах poll
+// и ping (CIBA Core, раздел 10.1)
+func (h *Handler) cibaGrant(w http.ResponseWriter, r *http.Request, client *entity.Client) {
+	authReqID := r.PostForm.Get("auth_req_id")
+	if authReqID == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "auth_req_id is required")
+		return
+	}
+
+	request, err := h.deps.Backchannel.Poll(r.Context(), client, authReqID)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrAuthorizationPending):
+		writeError(w, http.StatusBadRequest, errorAuthorizationPending, "the user has not yet been authenticated")
+		return
+	case errors.Is(err, service.ErrSlowDown):
+		writeError(w, http.StatusBadRequest, errorSlowDown, "polling too frequently")
+		return
+	case errors.Is(err, service.ErrAuthReqIDExpired):
+		writeError(w, http.StatusBadRequest, errorExpiredToken, "the auth_req_id has expired")
+		return
+	case errors.Is(err, service.ErrBackchannelAccessDenied):
+		writeError(w, http.StatusBadRequest, errorAccessDenied, "the user denied the request")
+		return
+	case errors.Is(err, service.ErrInvalidAuthReqID):
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, "invalid auth_req_id")
+		return
+	default:
+		log.Error("failed to poll backchannel authentication", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	h.writeTokens(w, r, client, tokenGrant{userID: request.UserID, scopes: request.Scopes})
+}
+
+// backchannelPage данные страницы подтверждения запроса аутентификации
+type backchannelPage struct {
+	RequestID      string
+	ClientName     string
+	Scopes         []string
+	BindingMessage string
+	CSRFToken      string
+	Message        string
+	Error          string
+}
+
+var backchannelPageTemplate = template.Must(template.New("backchannel").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Confirm sign-in</title></head>
+<body>
+<h1>Confirm sign-in</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+{{if .Message}}<p>{{.Message}}</p>
+{{else if .ClientName}}
+<p><strong>{{.ClientName}}</strong> is asking you to confirm a sign-in.</p>
+{{if .BindingMessage}}<p>Make sure this message matches the one you were given: <strong>{{.BindingMessage}}</strong></p>{{end}}
+{{if .Scopes}}<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<form method="post">
+<input type="hidden" name="request_id" value="{{.RequestID}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<button type="submit" name="action" value="approve">Confirm</button>
+<button type="submit" name="action" value="deny">Deny</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// backchannelConfirmation показывает пользователю запрос аутентификации,
+// доставленный на его устройство
+func (h *Handler) backchannelConfirmation(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.pageUser(w, r, backchannelPageTemplate, backchannelPage{Error: "Please sign in to continue."})
+	if !ok {
+		return
+	}
+
+	page, status := h.backchannelRequestPage(r, user, r.URL.Query().Get("request_id"))
+	page.CSRFToken = h.csrfToken(w, r)
+	renderPage(w, status, backchannelPageTemplate, page)
+}
+
+// backchannelDecision фиксирует решение пользователя и доставляет результат
+// клиенту в режимах ping и push
+func (h *Handler) backchannelDecision(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.pageUser(w, r, backchannelPageTemplate, backchannelPage{Error: "Please sign in to continue."})
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil || !checkCSRF(r) {
+		renderPage(w, http.StatusForbidden, backchannelPageTemplate, backchannelPage{Error: "The form has expired. Please try again."})
+		return
+	}
+
+	id, err := uuid.Parse(r.PostForm.Get("request_id"))
+	if err != nil {
+		renderPage(w, http.StatusBadRequest, backchannelPageTemplate, backchannelPage{Error: "The request is invalid or has expired."})
+		return
+	}
+	var request *entity.BackchannelAuthentication
+	var message string
+	switch r.PostForm.Get("action") {
+	case "approve":
+		request, err = h.deps.Backchannel.Approve(r.Context(), id, user)
+		message = "Sign-in confirmed. You can return to the conversation."
+	case "deny":
+		request, err = h.deps.Backchannel.Deny(r.Context(), id, user)
+		message = "Sign-in denied."
+	default:
+		renderPage(w, http.StatusBadRequest, backchannelPageTemplate, backchannelPage{Error: "Unknown action."})
+		return
+	}
+
+	if errors.Is(err, service.ErrInvalidBackchannelRequest) {
+		renderPage(w, http.StatusBadRequest, backchannelPageTemplate, backchannelPage{Error: "The request is invalid or has expired."})
+		return
+	}
+	if err != nil {
+		log.Error("failed to record backchannel decision", zap.Error(err))
+		renderPage(w, http.StatusInternalServerError, backchannelPageTemplate, backchannelPage{Error: "Something went wrong. Please try again."})
+		return
+	}
+
+	h.deliverBackchannelResult(r.Context(), request)
+	renderPage(w, http.StatusOK, backchannelPageTemplate, backchannelPage{Message: message})
+}
+
+// backchannelRequestPage готовит страницу подтверждения для запроса
+func (h *Handler) backchannelRequestPage(r *http.Request, user *entity.User, rawID string) (backchannelPage, int) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return backchannelPage{Error: "The request is invalid or has expired."}, http.StatusBadRequest
+	}
+	request, err := h.deps.Backchannel.Pending(r.Context(), id, user)
+	if errors.Is(err, service.ErrInvalidBackchannelRequest) {
+		return backchannelPage{Error: "The request is invalid or has expired."}, http.StatusBadRequest
+	}
+	if err != nil {
+		log.Error("failed to load backchannel authentication", zap.Error(err))
+		return backchannelPage{Error: "Something went wrong. Please try again."}, http.StatusInternalServerError
+	}
+
+	client, err := h.deps.Clients.GetByID(r.Context(), request.ClientID)
+	if err != nil {
+		log.Error("failed to load backchannel client", zap.Error(err))
+		return backchannelPage{Error: "Something went wrong. Please try again."}, http.StatusInternalServerError
+	}
+
+	return backchannelPage{
+		RequestID:      request.ID.String(),
+		ClientName:     client.Name,
+		Scopes:         h.scopeDescriptions(r, request.Scopes),
+		BindingMessage: request.BindingMessage,
+	}, http.StatusOK
+}
+
+// deliverBackchannelResult сообщает клиенту о решении пользователя: в режиме
+// ping — уведомлением, после которого клиент забирает токены, в режиме push —
+// самими токенами. Ошибки доставки только журналируются: решение пользователя
+// уже сохранено, а клиент в режиме ping может продолжить опрос.
+func (h *Handler) deliverBackchannelResult(ctx context.Context, request *entity.BackchannelAuthentication) {
+	if !request.DeliveryMode.NotifiesClient() {
+		return
+	}
+	client, err := h.deps.Clients.GetByID(ctx, request.ClientID)
+	if err != nil {
+		log.Error("failed to load backchannel client", zap.String("request_id", request.ID.String()), zap.Error(err))
+		return
+	}
+
+	if request.DeliveryMode == entity.BackchannelDeliveryPing {
+		_ = h.deps.Backchannel.NotifyClient(ctx, client, request, backchannelPing{AuthReqID: request.AuthReqID})
+		return
+	}
+
+	if err := h.deps.Backchannel.Consume(ctx, request); err != nil {
+		log.Error("failed to consume backchannel authentication", zap.String("request_id", request.ID.String()), zap.Error(err))
+		return
+	}
+	var payload interface{} = backchannelPushError{
+		AuthReqID:     request.AuthReqID,
+		errorResponse: errorResponse{Error: errorAccessDenied, ErrorDescription: "the user denied the request"},
+	}
+	if request.Status == entity.BackchannelAuthenticationApproved {
+		grant := tokenGrant{userID: request.UserID, scopes: request.Scopes}
+		response, err := h.issueTokens(ctx, client, grant, grant)
+		if err != nil {
+			log.Error("failed to issue tokens", zap.String("client_id", client.ClientID), zap.Error(err))
+			return
+		}
+		payload = backchannelPushResult{AuthReqID: request.AuthReqID, tokenResponse: *response}
+	}
+	_ = h.deps.Backchannel.NotifyClient(ctx, client, request, payload)
+}
@@ -0,0 +1,288 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/adapters/notification"
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testNotificationEndpoint = "https://client.example.com/ciba"
+
+// staticUser аутентифицирует на страницах подтверждения одного пользователя
+type staticUser struct {
+	user *entity.User
+}
+
+func (a staticUser) Authenticate(r *http.Request) (*entity.User, error) {
+	return a.user, nil
+}
+
+// clientNotification уведомление, отправленное клиенту в режимах ping и push
+type clientNotification struct {
+	endpoint string
+	token    string
+	payload  map[string]interface{}
+}
+
+// cibaTest сервер CIBA с одним пользователем и клиентом в заданном режиме доставки
+type cibaTest struct {
+	t        *testing.T
+	store    *memory.Store
+	handler  *Handler
+	config   Config
+	devices  *notification.FakeDeviceNotifier
+	client   *entity.Client
+	secret   string
+	user     *entity.User
+	interval time.Duration
+
+	mu            sync.Mutex
+	notifications []clientNotification
+}
+
+func newCIBATest(t *testing.T, mode entity.BackchannelTokenDeliveryMode) *cibaTest {
+	t.Helper()
+	ctx := context.Background()
+	test := &cibaTest{t: t, store: memory.NewStore(), devices: notification.NewFakeDeviceNotifier(), interval: 5 * time.Second}
+
+	test.user = entity.NewUser("user@example.com", "Test", "User", "hash")
+	if err := test.store.Users.Create(ctx, test.user); err != nil {
+		t.Fatal(err)
+	}
+	test.client = entity.NewClient("ciba client", "", nil, []entity.GrantType{entity.GrantTypeCIBA}, []string{"openid"})
+	test.client.BackchannelTokenDeliveryMode = mode
+	if mode.NotifiesClient() {
+		test.client.BackchannelClientNotificationEndpoint = testNotificationEndpoint
+	}
+	secrets := service.DefaultClientSecretManager()
+	secret, err := secrets.Issue(test.client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.secret = secret
+	if err := test.store.Clients.Create(ctx, test.client); err != nil {
+		t.Fatal(err)
+	}
+
+	config := service.DefaultBackchannelAuthenticationConfig()
+	config.PollInterval = test.interval
+	config.NotifyClient = test.notify
+	test.config = DefaultConfig("https://auth.example.com")
+	test.handler = NewHandler(test.config, Dependencies{
+		Clients:       test.store.Clients,
+		Tokens:        test.store.Tokens,
+		ClientSecrets: secrets,
+		Authenticator: staticUser{user: test.user},
+		Backchannel:   service.NewBackchannelAuthenticationService(test.store.BackchannelAuthentications, test.store.Users, test.devices, config),
+	})
+	return test
+}
+
+func (c *cibaTest) notify(ctx context.Context, endpoint, token string, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications = append(c.notifications, clientNotification{endpoint: endpoint, token: token, payload: decoded})
+	return nil
+}
+
+// lastNotification возвращает последнее уведомление клиента
+func (c *cibaTest) lastNotification() (clientNotification, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.notifications) == 0 {
+		return clientNotification{}, false
+	}
+	return c.notifications[len(c.notifications)-1], true
+}
+
+// post отправляет форму от имени клиента
+func (c *cibaTest) post(path string, form url.Values) (int, map[string]interface{}) {
+	c.t.Helper()
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth(c.client.ClientID, c.secret)
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, r)
+
+	var body map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&body)
+	return w.Code, body
+}
+
+// start запрашивает аутентификацию пользователя и возвращает auth_req_id
+func (c *cibaTest) start() string {
+	c.t.Helper()
+	form := url.Values{"login_hint": {c.user.Email}, "scope": {"openid"}}
+	if c.client.BackchannelDeliveryMode().NotifiesClient() {
+		form.Set("client_notification_token", "notification-token")
+	}
+	status, body := c.post(c.config.BackchannelAuthenticationPath, form)
+	authReqID, _ := body["auth_req_id"].(string)
+	if status != http.StatusOK || authReqID == "" {
+		c.t.Fatalf("backchannel authentication: status = %d, body = %v", status, body)
+	}
+	return authReqID
+}
+
+// decide принимает решение пользователя на странице подтверждения
+func (c *cibaTest) decide(action string) {
+	c.t.Helper()
+	sent, ok := c.devices.Last()
+	if !ok {
+		c.t.Fatal("authentication request was not delivered to the device")
+	}
+	form := url.Values{"request_id": {sent.Request.ID.String()}, "action": {action}, "csrf_token": {strings.Repeat("c", 43)}}
+	r := httptest.NewRequest(http.MethodPost, c.config.BackchannelConfirmationPath, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(&http.Cookie{Name: csrfCookie, Value: form.Get("csrf_token")})
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		c.t.Fatalf("%s: status = %d: %s", action, w.Code, w.Body)
+	}
+}
+
+// poll опрашивает token endpoint по auth_req_id
+func (c *cibaTest) poll(authReqID string) (int, map[string]interface{}) {
+	c.t.Helper()
+	return c.post(c.config.TokenPath, url.Values{"grant_type": {string(entity.GrantTypeCIBA)}, "auth_req_id": {authReqID}})
+}
+
+// wait сдвигает время последнего опроса, как если бы клиент выждал interval
+func (c *cibaTest) wait(authReqID string) {
+	c.t.Helper()
+	c.updateRequest(authReqID, func(request *entity.BackchannelAuthentication) {
+		if request.LastPolledAt != nil {
+			earlier := request.LastPolledAt.Add(-request.Interval)
+			request.LastPolledAt = &earlier
+		}
+	})
+}
+
+func (c *cibaTest) updateRequest(authReqID string, update func(*entity.BackchannelAuthentication)) {
+	c.t.Helper()
+	ctx := context.Background()
+	request, err := c.store.BackchannelAuthentications.GetByAuthReqID(ctx, authReqID)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	update(request)
+	if err := c.store.BackchannelAuthentications.Update(ctx, request); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func expectError(t *testing.T, name string, status int, body map[string]interface{}, code string) {
+	t.Helper()
+	if status != http.StatusBadRequest || body["error"] != code {
+		t.Errorf("%s: status = %d, error = %v, want 400 %s", name, status, body["error"], code)
+	}
+}
+
+func TestCIBAPollFlow(t *testing.T) {
+	test := newCIBATest(t, entity.BackchannelDeliveryPoll)
+	authReqID := test.start()
+
+	status, body := test.poll(authReqID)
+	expectError(t, "first poll", status, body, errorAuthorizationPending)
+	status, body = test.poll(authReqID)
+	expectError(t, "poll within the interval", status, body, errorSlowDown)
+
+	request, err := test.store.BackchannelAuthentications.GetByAuthReqID(context.Background(), authReqID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := test.interval + service.DefaultBackchannelAuthenticationConfig().SlowDownIncrement; request.Interval != want {
+		t.Errorf("interval after slow_down = %s, want %s", request.Interval, want)
+	}
+
+	test.decide("approve")
+	test.wait(authReqID)
+	status, body = test.poll(authReqID)
+	if status != http.StatusOK || body["access_token"] == nil {
+		t.Fatalf("poll after approval: status = %d, body = %v", status, body)
+	}
+	status, body = test.poll(authReqID)
+	expectError(t, "poll after the tokens were issued", status, body, errorInvalidGrant)
+}
+
+func TestCIBAPollDeniedAndExpired(t *testing.T) {
+	test := newCIBATest(t, entity.BackchannelDeliveryPoll)
+
+	denied := test.start()
+	test.decide("deny")
+	status, body := test.poll(denied)
+	expectError(t, "denied request", status, body, errorAccessDenied)
+
+	expired := test.start()
+	test.updateRequest(expired, func(request *entity.BackchannelAuthentication) {
+		request.ExpiresAt = time.Now().Add(-time.Second)
+	})
+	status, body = test.poll(expired)
+	expectError(t, "expired request", status, body, errorExpiredToken)
+	status, body = test.poll(expired)
+	expectError(t, "expired request polled again", status, body, errorInvalidGrant)
+}
+
+func TestCIBAPingFlow(t *testing.T) {
+	test := newCIBATest(t, entity.BackchannelDeliveryPing)
+	authReqID := test.start()
+
+	test.decide("approve")
+	sent, ok := test.lastNotification()
+	if !ok {
+		t.Fatal("client was not notified")
+	}
+	if sent.endpoint != testNotificationEndpoint || sent.token != "notification-token" || sent.payload["auth_req_id"] != authReqID {
+		t.Errorf("ping notification = %+v", sent)
+	}
+	if _, ok := sent.payload["access_token"]; ok {
+		t.Error("ping notification carries tokens")
+	}
+
+	status, body := test.poll(authReqID)
+	if status != http.StatusOK || body["access_token"] == nil {
+		t.Fatalf("token request after ping: status = %d, body = %v", status, body)
+	}
+}
+
+func TestCIBAPushFlow(t *testing.T) {
+	test := newCIBATest(t, entity.BackchannelDeliveryPush)
+
+	approved := test.start()
+	test.decide("approve")
+	sent, ok := test.lastNotification()
+	if !ok {
+		t.Fatal("tokens were not pushed")
+	}
+	if sent.payload["auth_req_id"] != approved || sent.payload["access_token"] == nil {
+		t.Errorf("push result = %v", sent.payload)
+	}
+	status, body := test.poll(approved)
+	expectError(t, "polling in push mode", status, body, errorInvalidGrant)
+
+	denied := test.start()
+	test.decide("deny")
+	sent, _ = test.lastNotification()
+	if sent.payload["auth_req_id"] != denied || sent.payload["error"] != errorAccessDenied {
+		t.Errorf("push error = %v", sent.payload)
+	}
+}
@@ -0,0 +1,163 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/zap"
+)
+
+// clientCredentials учетные данные клиента, предъявленные в запросе
+type clientCredentials struct {
+	clientID string
+	secret   string
+	// assertion утверждение JWT (RFC 7523); способ проверки определяется регистрацией клиента
+	assertion string
+	method    entity.TokenEndpointAuthMethod
+}
+
+// readClientCredentials извлекает учетные данные клиента из заголовка Authorization
+// или из тела запроса (RFC 6749, раздел 2.3.1; RFC 7521, раздел 4.2).
+// Использование двух способов сразу запрещено.
+func readClientCredentials(r *http.Request) (clientCredentials, error) {
+	formID := r.PostForm.Get("client_id")
+	formSecret := r.PostForm.Get("client_secret")
+
+	assertionType := r.PostForm.Get("client_assertion_type")
+	assertion := r.PostForm.Get("client_assertion")
+	if assertionType != "" || assertion != "" {
+		if _, _, ok := r.BasicAuth(); ok || formSecret != "" {
+			return clientCredentials{}, errors.New("multiple client authentication methods")
+		}
+		if assertionType != service.ClientAssertionType {
+			return clientCredentials{}, errors.New("unsupported client_assertion_type")
+		}
+		clientID, err := service.AssertionSubject(assertion)
+		if err != nil {
+			return clientCredentials{}, errors.New("malformed client assertion")
+		}
+		if formID != "" && formID != clientID {
+			return clientCredentials{}, errors.New("client id mismatch")
+		}
+		return clientCredentials{clientID: clientID, assertion: assertion}, nil
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		if formSecret != "" {
+			return clientCredentials{}, errors.New("multiple client authentication methods")
+		}
+		clientID, err := url.QueryUnescape(username)
+		if err != nil {
+			return clientCredentials{}, errors.New("malformed client id")
+		}
+		secret, err := url.QueryUnescape(password)
+		if err != nil {
+			return clientCredentials{}, errors.New("malformed client secret")
+		}
+		if formID != "" && formID != clientID {
+			return clientCredentials{}, errors.New("client id mismatch")
+		}
+		return clientCredentials{clientID: clientID, secret: secret, method: entity.AuthMethodClientSecretBasic}, nil
+	}
+
+	if formID == "" {
+		return clientCredentials{}, errors.New("client authentication required")
+	}
+	if formSecret != "" {
+		return clientCredentials{clientID: formID, secret: formSecret, method: entity.AuthMethodClientSecretPost}, nil
+	}
+	return clientCredentials{clientID: formID, method: entity.AuthMethodNone}, nil
+}
+
+// authenticateClient аутентифицирует клиента способом, указанным при его регистрации.
+// Форма запроса должна быть уже разобрана. При ошибке ответ уже записан.
+func (h *Handler) authenticateClient(w http.ResponseWriter, r *http.Request) (*entity.Client, bool) {
+	credentials, err := readClientCredentials(r)
+	if err != nil {
+		writeClientAuthError(w, r, err.Error())
+		return nil, false
+	}
+
+	client, err := h.deps.Clients.GetByClientID(r.Context(), credentials.clientID)
+	if errors.Is(err, ports.ErrNotFound) {
+		log.Warn("unknown client", zap.String("client_id", credentials.clientID))
+		writeClientAuthError(w, r, "client authentication failed")
+		return nil, false
+	}
+	if err != nil {
+		log.Error("failed to load client", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return nil, false
+	}
+
+	method := credentials.method
+	if method == entity.AuthMethodNone && isCertificateAuthMethod(client.TokenEndpointAuthMethod) {
+		cert, verified, err := h.certificates().Certificate(r)
+		if err == nil {
+			err = service.VerifyClientCertificate(client, cert, verified)
+		}
+		if err != nil {
+			log.Warn("client certificate rejected", zap.String("client_id", client.ClientID), zap.Error(err))
+			writeClientAuthError(w, r, "client authentication failed")
+			return nil, false
+		}
+		method = client.TokenEndpointAuthMethod
+	}
+	if credentials.assertion != "" {
+		if err := h.verifyClientAssertion(r, client, credentials.assertion); err != nil {
+			log.Warn("client assertion rejected", zap.String("client_id", client.ClientID), zap.Error(err))
+			writeClientAuthError(w, r, "client authentication failed")
+			return nil, false
+		}
+		method = client.TokenEndpointAuthMethod
+	}
+
+	if method != client.TokenEndpointAuthMethod {
+		log.Warn("client used an unexpected authentication method",
+			zap.String("client_id", client.ClientID),
+			zap.String("expected", string(client.TokenEndpointAuthMethod)),
+			zap.String("actual", string(method)),
+		)
+		writeClientAuthError(w, r, "client authentication failed")
+		return nil, false
+	}
+	if method.UsesClientSecret() && !method.UsesClientAssertion() && !h.deps.ClientSecrets.Verify(client, credentials.secret) {
+		writeClientAuthError(w, r, "client authentication failed")
+		return nil, false
+	}
+	if !client.Active {
+		log.Warn("inactive client", zap.String("client_id", client.ClientID))
+		writeClientAuthError(w, r, "client is inactive")
+		return nil, false
+	}
+	return client, true
+}
+
+// verifyClientAssertion проверяет утверждение клиента private_key_jwt или
+// client_secret_jwt. В aud допускаются только идентификатор сервера и адрес
+// token endpoint, а на endpoint PAR — и его адрес (RFC 9126, раздел 2): адрес
+// произвольного endpoint, к которому обращается клиент, не принимается.
+func (h *Handler) verifyClientAssertion(r *http.Request, client *entity.Client, assertion string) error {
+	if h.deps.ClientAssertions == nil || !client.TokenEndpointAuthMethod.UsesClientAssertion() {
+		return fmt.Errorf("client does not authenticate with assertions")
+	}
+	audiences := []string{h.config.Issuer, h.endpointURL(h.config.TokenPath)}
+	if r.URL.Path == h.config.PushedAuthorizationRequestPath {
+		audiences = append(audiences, h.endpointURL(h.config.PushedAuthorizationRequestPath))
+	}
+	return h.deps.ClientAssertions.Verify(r.Context(), client, assertion, audiences)
+}
+
+// writeClientAuthError отвечает invalid_client; если клиент пытался использовать
+// HTTP Basic, ответ содержит WWW-Authenticate (RFC 6749, раздел 5.2)
+func writeClientAuthError(w http.ResponseWriter, r *http.Request, description string) {
+	if _, _, ok := r.BasicAuth(); ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	writeError(w, http.StatusUnauthorized, errorInvalidClient, description)
+}
@@ -0,0 +1,120 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// assertionTest token endpoint с клиентом private_key_jwt
+type assertionTest struct {
+	t       *testing.T
+	handler *Handler
+	client  *entity.Client
+	key     *ecdsa.PrivateKey
+}
+
+func newAssertionTest(t *testing.T) *assertionTest {
+	t.Helper()
+	store := memory.NewStore()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := jose.NewJWK(&key.PublicKey, "key-1", jose.ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := entity.NewClient("assertion client", "", nil, []entity.GrantType{entity.GrantTypeRefreshToken}, []string{"openid"})
+	client.TokenEndpointAuthMethod = entity.AuthMethodPrivateKeyJWT
+	client.JWKS = &jose.JWKSet{Keys: []jose.JWK{*jwk}}
+	if err := store.Clients.Create(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewHandler(DefaultConfig("https://auth.example.com"), Dependencies{
+		Clients:          store.Clients,
+		Tokens:           store.Tokens,
+		ClientAssertions: service.NewClientAssertionVerifier(nil, nil, memory.NewReplayCache(), nil),
+	})
+	return &assertionTest{t: t, handler: handler, client: client, key: key}
+}
+
+// assertion подписывает утверждение клиента с audience и jti
+func (a *assertionTest) assertion(audience, jti string) string {
+	a.t.Helper()
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": a.client.ClientID,
+		"sub": a.client.ClientID,
+		"aud": audience,
+		"jti": jti,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}
+	assertion, err := jose.Sign(jose.Header{Algorithm: jose.ES256, KeyID: "key-1"}, claims, a.key)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return assertion
+}
+
+// authenticated проверяет, прошел ли клиент аутентификацию на token endpoint:
+// неизвестный refresh token дает invalid_grant, а не invalid_client
+func (a *assertionTest) authenticated(assertion string) bool {
+	a.t.Helper()
+	form := url.Values{
+		"grant_type":            {string(entity.GrantTypeRefreshToken)},
+		"refresh_token":         {"unknown"},
+		"client_assertion_type": {service.ClientAssertionType},
+		"client_assertion":      {assertion},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	a.handler.ServeHTTP(w, r)
+	return w.Code != http.StatusUnauthorized
+}
+
+func TestClientAssertionRejectsReplay(t *testing.T) {
+	test := newAssertionTest(t)
+	assertion := test.assertion("https://auth.example.com/token", uuid.NewString())
+
+	if !test.authenticated(assertion) {
+		t.Fatal("valid assertion was rejected")
+	}
+	if test.authenticated(assertion) {
+		t.Error("replayed assertion was accepted")
+	}
+}
+
+func TestClientAssertionAudience(t *testing.T) {
+	test := newAssertionTest(t)
+	tests := []struct {
+		audience string
+		want     bool
+	}{
+		{"https://auth.example.com", true},
+		{"https://auth.example.com/token", true},
+		{"https://auth.example.com/par", false},
+		{"https://other.example.com/token", false},
+	}
+	for _, tt := range tests {
+		if got := test.authenticated(test.assertion(tt.audience, uuid.NewString())); got != tt.want {
+			t.Errorf("aud %s: authenticated = %v, want %v", tt.audience, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// connectedApp приложение, которому пользователь разрешил доступ к аккаунту
+type connectedApp struct {
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	ClientURI  string    `json:"client_uri,omitempty"`
+	LogoURI    string    `json:"logo_uri,omitempty"`
+	Scopes     []string  `json:"scopes"`
+	Resources  []string  `json:"resources,omitempty"`
+	GrantedAt  time.Time `json:"granted_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// connectedAppsResponse список подключенных приложений пользователя
+type connectedAppsResponse struct {
+	Apps []connectedApp `json:"apps"`
+}
+
+// revokedAppResponse результат отзыва согласия
+type revokedAppResponse struct {
+	ClientID      string `json:"client_id"`
+	RevokedTokens int    `json:"revoked_tokens"`
+}
+
+// listConnectedApps возвращает приложения, которым пользователь дал согласие
+func (h *Handler) listConnectedApps(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.accountUser(w, r)
+	if !ok {
+		return
+	}
+
+	consents, err := h.deps.Consents.List(r.Context(), user.ID)
+	if err != nil {
+		log.Error("failed to list consents", zap.String("user_id", user.ID.String()), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	response := connectedAppsResponse{Apps: make([]connectedApp, 0, len(consents))}
+	for _, consent := range consents {
+		client, err := h.deps.Clients.GetByID(r.Context(), consent.ClientID)
+		if errors.Is(err, ports.ErrNotFound) {
+			// Клиент удален, его токены больше не принимаются
+			continue
+		}
+		if err != nil {
+			log.Error("failed to load consent client", zap.String("client_id", consent.ClientID.String()), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+			return
+		}
+		response.Apps = append(response.Apps, connectedApp{
+			ClientID:   client.ClientID,
+			ClientName: client.Name,
+			ClientURI:  client.ClientURI,
+			LogoURI:    client.LogoURI,
+			Scopes:     consent.Scopes,
+			Resources:  consent.Resources,
+			GrantedAt:  consent.CreatedAt,
+			UpdatedAt:  consent.UpdatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// revokeConnectedApp отзывает согласие пользователя и все токены, выданные
+// приложению от его имени
+func (h *Handler) revokeConnectedApp(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.accountUser(w, r)
+	if !ok {
+		return
+	}
+
+	client, err := h.deps.Clients.GetByClientID(r.Context(), r.PathValue("client_id"))
+	if errors.Is(err, ports.ErrNotFound) {
+		writeError(w, http.StatusNotFound, errorInvalidRequest, "application not found")
+		return
+	}
+	if err != nil {
+		log.Error("failed to load client", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	revoked, err := h.deps.Consents.Revoke(r.Context(), user.ID, client)
+	if errors.Is(err, service.ErrConsentNotFound) {
+		writeError(w, http.StatusNotFound, errorInvalidRequest, "application not found")
+		return
+	}
+	if err != nil {
+		log.Error("failed to revoke consent", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, revokedAppResponse{ClientID: client.ClientID, RevokedTokens: revoked})
+}
+
+// accountUser определяет пользователя, вошедшего в систему, для API аккаунта.
+// При отказе ответ уже записан.
+func (h *Handler) accountUser(w http.ResponseWriter, r *http.Request) (*entity.User, bool) {
+	if h.deps.Authenticator != nil {
+		user, err := h.deps.Authenticator.Authenticate(r)
+		if err == nil && user != nil && user.Active {
+			return user, true
+		}
+		log.Debug("account request is not authenticated", zap.Error(err))
+	}
+	writeError(w, http.StatusUnauthorized, errorAccessDenied, "authentication required")
+	return nil, false
+}
@@ -0,0 +1,47 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// csrfCookie имя cookie с токеном защиты форм от CSRF
+const csrfCookie = "oauth_csrf"
+
+// csrfField имя поля формы с токеном защиты от CSRF
+const csrfField = "csrf_token"
+
+// csrfToken возвращает токен для формы страницы и при необходимости выставляет cookie
+// (схема double submit cookie). Пустая строка означает ошибку генерации.
+func (h *Handler) csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && len(cookie.Value) >= 32 {
+		return cookie.Value
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return ""
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.config.Issuer, "https://"),
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// checkCSRF сравнивает токен из формы с cookie
+func checkCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostForm.Get(csrfField))) == 1
+}
@@ -0,0 +1,292 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// deviceAuthorizationResponse ответ device authorization endpoint (RFC 8628, раздел 3.2)
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// deviceAuthorization начинает авторизацию устройства (RFC 8628, раздел 3.1)
+func (h *Handler) deviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	if err := h.deps.Validator.ValidateClient(client, entity.GrantTypeDeviceCode); err != nil {
+		writeError(w, http.StatusBadRequest, errorUnauthorizedClient, err.Error())
+		return
+	}
+	scopes, ok := h.requestedScopes(w, r, client)
+	if !ok {
+		return
+	}
+
+	authorization, err := h.deps.Devices.Start(r.Context(), client, scopes)
+	if err != nil {
+		log.Error("failed to start device authorization", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	verificationURI := h.endpointURL(h.config.DeviceVerificationPath)
+	writeJSON(w, http.StatusOK, deviceAuthorizationResponse{
+		DeviceCode:              authorization.DeviceCode,
+		UserCode:                service.FormatUserCode(authorization.UserCode),
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(authorization.UserCode),
+		ExpiresIn:               int64(time.Until(authorization.ExpiresAt).Round(time.Second) / time.Second),
+		Interval:                int64((authorization.Interval + time.Second - 1) / time.Second),
+	})
+}
+
+// deviceCodeGrant обрабатывает опрос token endpoint устройством (RFC 8628, раздел 3.4)
+func (h *Handler) deviceCodeGrant(w http.ResponseWriter, r *http.Request, client *entity.Client) {
+	deviceCode := r.PostForm.Get("device_code")
+	if deviceCode == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "device_code is required")
+		return
+	}
+
+	authorization, err := h.deps.Devices.Poll(r.Context(), client, deviceCode)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrAuthorizationPending):
+		writeError(w, http.StatusBadRequest, errorAuthorizationPending, "the user has not yet completed authorization")
+		return
+	case errors.Is(err, service.ErrSlowDown):
+		writeError(w, http.StatusBadRequest, errorSlowDown, "polling too frequently")
+		return
+	case errors.Is(err, service.ErrDeviceCodeExpired):
+		writeError(w, http.StatusBadRequest, errorExpiredToken, "the device code has expired")
+		return
+	case errors.Is(err, service.ErrDeviceAccessDenied):
+		writeError(w, http.StatusBadRequest, errorAccessDenied, "the user denied the request")
+		return
+	case errors.Is(err, service.ErrInvalidDeviceCode):
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, "invalid device code")
+		return
+	default:
+		log.Error("failed to poll device authorization", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	h.writeTokens(w, r, client, tokenGrant{userID: authorization.UserID, scopes: authorization.Scopes})
+}
+
+// devicePage данные страницы подтверждения устройства
+type devicePage struct {
+	UserCode   string
+	ClientName string
+	Scopes     []string
+	CSRFToken  string
+	Message    string
+	Error      string
+}
+
+// tooManyAttemptsMessage сообщение после исчерпания попыток ввода кода
+const tooManyAttemptsMessage = "Too many incorrect codes. Please try again later."
+
+var devicePageTemplate = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Connect a device</title></head>
+<body>
+<h1>Connect a device</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+{{if .Message}}<p>{{.Message}}</p>
+{{else if .ClientName}}
+<p><strong>{{.ClientName}}</strong> is requesting access to your account.</p>
+{{if .Scopes}}<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<p>Make sure the code <strong>{{.UserCode}}</strong> matches the one shown on your device.</p>
+<form method="post">
+<input type="hidden" name="user_code" value="{{.UserCode}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<button type="submit" name="action" value="approve">Allow</button>
+<button type="submit" name="action" value="deny">Deny</button>
+</form>
+{{else}}
+<form method="get">
+<label>Enter the code shown on your device: <input name="user_code" autocomplete="off" autofocus></label>
+<button type="submit">Continue</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// deviceVerification показывает форму ввода кода или запрос на подтверждение
+func (h *Handler) deviceVerification(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.pageUser(w, r, devicePageTemplate, devicePage{Error: "Please sign in to continue."})
+	if !ok {
+		return
+	}
+
+	userCode := r.URL.Query().Get("user_code")
+	if userCode == "" {
+		renderPage(w, http.StatusOK, devicePageTemplate, devicePage{})
+		return
+	}
+
+	page, status := h.deviceConfirmation(r, userCode, user)
+	page.CSRFToken = h.csrfToken(w, r)
+	log.Debug("device verification page shown", zap.String("user_id", user.ID.String()))
+	renderPage(w, status, devicePageTemplate, page)
+}
+
+// deviceDecision фиксирует решение пользователя по запросу устройства
+func (h *Handler) deviceDecision(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.pageUser(w, r, devicePageTemplate, devicePage{Error: "Please sign in to continue."})
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil || !checkCSRF(r) {
+		renderPage(w, http.StatusForbidden, devicePageTemplate, devicePage{Error: "The form has expired. Please try again."})
+		return
+	}
+
+	userCode := r.PostForm.Get("user_code")
+	var err error
+	var message string
+	switch r.PostForm.Get("action") {
+	case "approve":
+		err = h.deps.Devices.Approve(r.Context(), userCode, user)
+		message = "Device connected. You can return to your device."
+	case "deny":
+		err = h.deps.Devices.Deny(r.Context(), userCode, user)
+		message = "Access denied. The device was not connected."
+	default:
+		renderPage(w, http.StatusBadRequest, devicePageTemplate, devicePage{Error: "Unknown action."})
+		return
+	}
+
+	if errors.Is(err, service.ErrInvalidUserCode) {
+		renderPage(w, http.StatusBadRequest, devicePageTemplate, devicePage{Error: "The code is invalid or has expired."})
+		return
+	}
+	if errors.Is(err, service.ErrTooManyUserCodeAttempts) {
+		renderPage(w, http.StatusTooManyRequests, devicePageTemplate, devicePage{Error: tooManyAttemptsMessage})
+		return
+	}
+	if err != nil {
+		log.Error("failed to record device decision", zap.Error(err))
+		renderPage(w, http.StatusInternalServerError, devicePageTemplate, devicePage{Error: "Something went wrong. Please try again."})
+		return
+	}
+	renderPage(w, http.StatusOK, devicePageTemplate, devicePage{Message: message})
+}
+
+// deviceConfirmation готовит страницу подтверждения для введенного кода
+func (h *Handler) deviceConfirmation(r *http.Request, userCode string, user *entity.User) (devicePage, int) {
+	authorization, err := h.deps.Devices.Pending(r.Context(), userCode, user)
+	if errors.Is(err, service.ErrInvalidUserCode) {
+		return devicePage{Error: "The code is invalid or has expired."}, http.StatusBadRequest
+	}
+	if errors.Is(err, service.ErrTooManyUserCodeAttempts) {
+		return devicePage{Error: tooManyAttemptsMessage}, http.StatusTooManyRequests
+	}
+	if err != nil {
+		log.Error("failed to load device authorization", zap.Error(err))
+		return devicePage{Error: "Something went wrong. Please try again."}, http.StatusInternalServerError
+	}
+
+	client, err := h.deps.Clients.GetByID(r.Context(), authorization.ClientID)
+	if err != nil {
+		log.Error("failed to load device client", zap.Error(err))
+		return devicePage{Error: "Something went wrong. Please try again."}, http.StatusInternalServerError
+	}
+
+	return devicePage{
+		UserCode:   service.FormatUserCode(authorization.UserCode),
+		ClientName: client.Name,
+		Scopes:     h.scopeDescriptions(r, authorization.Scopes),
+	}, http.StatusOK
+}
+
+// pageUser определяет пользователя страницы подтверждения. Если пользователь
+// не вошел, он перенаправляется на LoginURL или получает 401 со страницей page.
+// При отказе ответ уже записан.
+func (h *Handler) pageUser(w http.ResponseWriter, r *http.Request, tmpl *template.Template, page interface{}) (*entity.User, bool) {
+	if h.deps.Authenticator != nil {
+		user, err := h.deps.Authenticator.Authenticate(r)
+		if err == nil && user != nil && user.Active {
+			return user, true
+		}
+		log.Debug("confirmation page requires sign in", zap.Error(err))
+	}
+
+	if h.config.LoginURL != "" && r.Method == http.MethodGet {
+		target, err := url.Parse(h.config.LoginURL)
+		if err == nil {
+			query := target.Query()
+			query.Set("return_to", h.endpointURL(r.URL.RequestURI()))
+			target.RawQuery = query.Encode()
+			http.Redirect(w, r, target.String(), http.StatusFound)
+			return nil, false
+		}
+	}
+	renderPage(w, http.StatusUnauthorized, tmpl, page)
+	return nil, false
+}
+
+// renderPage выводит HTML страницу, которую нельзя встраивать во фреймы и кешировать
+func renderPage(w http.ResponseWriter, status int, tmpl *template.Template, data interface{}) {
+	writePage(w, status, tmpl, data, "'self'")
+}
+
+// renderRedirectingPage выводит страницу с формой, ответ на которую
+// перенаправляет браузер на redirectURI клиента. Директива form-action
+// распространяется и на перенаправления после отправки формы, поэтому в нее
+// добавляется источник redirectURI.
+func renderRedirectingPage(w http.ResponseWriter, status int, tmpl *template.Template, data interface{}, redirectURI string) {
+	formAction := "'self'"
+	if source := formActionSource(redirectURI); source != "" {
+		formAction += " " + source
+	}
+	writePage(w, status, tmpl, data, formAction)
+}
+
+// formActionSource возвращает источник CSP для адреса перенаправления:
+// origin для http и https и схему для адресов нативных приложений
+func formActionSource(redirectURI string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		if u.Host == "" {
+			return ""
+		}
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + ":"
+}
+
+func writePage(w http.ResponseWriter, status int, tmpl *template.Template, data interface{}, formAction string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; form-action "+formAction+"; frame-ancestors 'none'")
+	w.WriteHeader(status)
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Error("failed to render page", zap.Error(err))
+	}
+}
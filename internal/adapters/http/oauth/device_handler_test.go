@@ -0,0 +1,29 @@
+package oauth
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderRedirectingPageAllowsRedirectOrigin(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse("<form></form>"))
+	tests := []struct {
+		redirectURI string
+		want        string
+	}{
+		{"https://client.example.com/cb?x=1", "form-action 'self' https://client.example.com;"},
+		{"http://127.0.0.1:8400/cb", "form-action 'self' http://127.0.0.1:8400;"},
+		{"com.example.app:/oauth", "form-action 'self' com.example.app:;"},
+		{"", "form-action 'self';"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		renderRedirectingPage(w, http.StatusOK, tmpl, nil, tt.redirectURI)
+		if policy := w.Header().Get("Content-Security-Policy"); !strings.Contains(policy, tt.want) {
+			t.Errorf("%q: Content-Security-Policy = %q, want %q", tt.redirectURI, policy, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// Заголовки DPoP (RFC 9449, разделы 4.1 и 8)
+const (
+	DPoPHeader      = "DPoP"
+	DPoPNonceHeader = "DPoP-Nonce"
+)
+
+// Коды ошибок DPoP (RFC 9449, разделы 5 и 8)
+const (
+	errorInvalidDPoPProof = "invalid_dpop_proof"
+	errorUseDPoPNonce     = "use_dpop_nonce"
+)
+
+// verifyDPoP проверяет DPoP proof запроса к token endpoint и сохраняет отпечаток
+// ключа в контексте запроса: выданные токены привязываются к нему.
+// При ошибке ответ уже записан.
+func (h *Handler) verifyDPoP(w http.ResponseWriter, r *http.Request, client *entity.Client) (*http.Request, bool) {
+	proofs := r.Header.Values(DPoPHeader)
+	if len(proofs) == 0 {
+		if client.DPoPBoundAccessTokens {
+			writeError(w, http.StatusBadRequest, errorInvalidDPoPProof, "DPoP proof is required for this client")
+			return nil, false
+		}
+		return r, true
+	}
+	if h.deps.DPoP == nil {
+		writeError(w, http.StatusBadRequest, errorInvalidDPoPProof, "DPoP is not supported")
+		return nil, false
+	}
+	if len(proofs) > 1 {
+		writeError(w, http.StatusBadRequest, errorInvalidDPoPProof, "exactly one DPoP proof is required")
+		return nil, false
+	}
+
+	proof, err := h.deps.DPoP.Verify(r.Context(), proofs[0], r.Method, h.endpointURL(h.config.TokenPath), "")
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrUseDPoPNonce):
+		w.Header().Set(DPoPNonceHeader, h.deps.DPoP.Nonce())
+		writeError(w, http.StatusBadRequest, errorUseDPoPNonce, "authorization server requires nonce in DPoP proof")
+		return nil, false
+	case errors.Is(err, service.ErrInvalidDPoPProof):
+		writeError(w, http.StatusBadRequest, errorInvalidDPoPProof, err.Error())
+		return nil, false
+	default:
+		log.Error("failed to verify DPoP proof", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return nil, false
+	}
+
+	if h.deps.DPoP.RequiresNonce() {
+		w.Header().Set(DPoPNonceHeader, h.deps.DPoP.Nonce())
+	}
+	return withConfirmation(r, func(confirmation *entity.TokenConfirmation) {
+		confirmation.JKT = proof.JKT
+	}), true
+}
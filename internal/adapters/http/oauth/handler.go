@@ -0,0 +1,354 @@
+// Package oauth реализует HTTP endpoints сервера авторизации OAuth 2.0
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Config настройки endpoints сервера авторизации
+type Config struct {
+	// Issuer идентификатор сервера авторизации — базовый https URL без завершающего слеша;
+	// от него строятся абсолютные адреса endpoints
+	Issuer string
+
+	// AuthorizationPath и TokenPath пути authorization и token endpoints
+	AuthorizationPath string
+	TokenPath         string
+	// PushedAuthorizationRequestPath путь endpoint PAR (RFC 9126)
+	PushedAuthorizationRequestPath string
+	// DeviceAuthorizationPath и DeviceVerificationPath пути endpoint авторизации
+	// устройств и страницы ввода кода пользователя (RFC 8628)
+	DeviceAuthorizationPath string
+	DeviceVerificationPath  string
+	// BackchannelAuthenticationPath и BackchannelConfirmationPath пути endpoint
+	// аутентификации по обратному каналу и страницы подтверждения на устройстве
+	// пользователя (CIBA)
+	BackchannelAuthenticationPath string
+	BackchannelConfirmationPath   string
+	// ConnectedAppsPath путь API, в котором пользователь видит приложения, получившие
+	// доступ к его аккаунту, и отзывает их согласия
+	ConnectedAppsPath string
+	// RevocationPath и IntrospectionPath пути необязательных endpoints; пустые не публикуются
+	RevocationPath    string
+	IntrospectionPath string
+
+	// GrantTypes включенные типы авторизации; nil — все, что поддерживает entity.GrantType
+	GrantTypes []entity.GrantType
+	// Scopes области действия, публикуемые в метаданных сервера; пусто —
+	// публикуются области реестра Dependencies.Scopes
+	Scopes []string
+	// ServiceDocumentation адрес документации для разработчиков клиентов
+	ServiceDocumentation string
+	// LoginURL страница входа, на которую перенаправляются пользователи страниц
+	// подтверждения; адрес возврата передается в параметре return_to
+	LoginURL string
+	// ClientCertificateHeader заголовок, в котором TLS-прокси передает сертификат
+	// клиента в PEM с URL-кодированием; пустой — сертификат берется из TLS соединения.
+	// Прокси должен удалять этот заголовок из входящих запросов.
+	ClientCertificateHeader string
+	// ClientCertificateVerifyHeader заголовок, в котором прокси сообщает результат
+	// проверки цепочки сертификата (clientcert.VerifiedValue). Без него клиенты
+	// tls_client_auth за прокси не аутентифицируются: их сертификат доверен только
+	// после проверки цепочки. Без прокси цепочку проверяет TLS-сервер (ClientCAs).
+	ClientCertificateVerifyHeader string
+}
+
+// DefaultConfig возвращает настройки по умолчанию для указанного issuer
+func DefaultConfig(issuer string) Config {
+	return Config{
+		Issuer:                         issuer,
+		AuthorizationPath:              "/authorize",
+		TokenPath:                      "/token",
+		PushedAuthorizationRequestPath: "/par",
+		DeviceAuthorizationPath:        "/device_authorization",
+		DeviceVerificationPath:         "/device",
+		BackchannelAuthenticationPath:  "/bc-authorize",
+		BackchannelConfirmationPath:    "/bc-authorize/confirm",
+		ConnectedAppsPath:              "/account/connected-apps",
+	}
+}
+
+// Dependencies зависимости endpoints сервера авторизации
+type Dependencies struct {
+	// Clients и Tokens включают token endpoint
+	Clients ports.ClientRepository
+	Tokens  ports.TokenRepository
+	// ClientSecrets проверяет секреты клиентов; nil — конфигурация по умолчанию
+	ClientSecrets *service.ClientSecretManager
+	// TokenGenerator выдает токены; nil — конфигурация по умолчанию
+	TokenGenerator *service.TokenGenerator
+	// Validator проверяет клиентов и токены; nil — значение по умолчанию
+	Validator *service.TokenValidator
+	// Authenticator определяет пользователя на страницах подтверждения
+	Authenticator UserAuthenticator
+
+	// Registration динамическая регистрация клиентов; nil — endpoint /register отключен
+	Registration *service.ClientRegistrationService
+	// SigningKeys ключи подписи токенов; nil — JWKS не публикуется
+	SigningKeys *service.SigningKeyManager
+	// Authorization authorization endpoint, PAR и authorization_code; требует token endpoint
+	Authorization *service.AuthorizationService
+	// Devices авторизация устройств (RFC 8628); требует token endpoint
+	Devices *service.DeviceAuthorizationService
+	// TokenExchange обмен токенов (RFC 8693); требует token endpoint
+	TokenExchange *service.TokenExchangeService
+	// DPoP проверка DPoP proof (RFC 9449); nil — выдаются только bearer токены
+	DPoP *service.DPoPService
+	// JWTBearer обмен утверждений доверенных издателей (RFC 7523); требует token endpoint
+	JWTBearer *service.JWTBearerService
+	// ClientAssertions аутентификация клиентов private_key_jwt и client_secret_jwt
+	// (RFC 7523); nil — эти способы не поддерживаются
+	ClientAssertions *service.ClientAssertionVerifier
+	// Resources ресурсы для параметра resource на token endpoint (RFC 8707);
+	// nil — параметр не поддерживается. Должен совпадать с реестром AuthorizationService;
+	// Consents и TokenExchange получают его в NewHandler.
+	Resources *service.ResourceIndicators
+	// JWTAccessTokens выпускает access токены в формате JWT (RFC 9068); nil —
+	// выдаются непрозрачные токены
+	JWTAccessTokens *service.JWTAccessTokenEncoder
+	// Backchannel аутентификация по обратному каналу (CIBA); требует token endpoint
+	Backchannel *service.BackchannelAuthenticationService
+	// Consents согласия пользователей; nil — страница согласия показывается при
+	// каждом запросе авторизации, API подключенных приложений отключен
+	Consents *service.ConsentService
+	// Scopes реестр областей: описания для страниц подтверждения, иерархия
+	// и области по умолчанию; nil — области клиента проверяются точным
+	// совпадением. Должен совпадать с реестром AuthorizationService; Validator,
+	// Consents, Resources и TokenExchange получают его в NewHandler.
+	Scopes *service.ScopeRegistry
+}
+
+// Handler endpoints сервера авторизации
+type Handler struct {
+	config Config
+	deps   Dependencies
+	mux    *http.ServeMux
+	// grants обработчики типов авторизации token endpoint
+	grants map[entity.GrantType]grantHandler
+}
+
+// NewHandler создает обработчик endpoints сервера авторизации
+func NewHandler(config Config, deps Dependencies) *Handler {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.GrantTypes == nil {
+		config.GrantTypes = entity.SupportedGrantTypes()
+	}
+
+	if deps.ClientSecrets == nil {
+		deps.ClientSecrets = service.DefaultClientSecretManager()
+	}
+	if deps.TokenGenerator == nil {
+		deps.TokenGenerator = service.NewTokenGenerator(nil)
+	}
+	if deps.Validator == nil {
+		deps.Validator = service.NewTokenValidator()
+	}
+	if deps.Scopes != nil {
+		// Проверки областей токенов и согласий учитывают ту же иерархию,
+		// что и выдача областей
+		deps.Validator.SetScopes(deps.Scopes)
+		if deps.Consents != nil {
+			deps.Consents.SetScopes(deps.Scopes)
+		}
+		if deps.Resources != nil {
+			deps.Resources.SetScopes(deps.Scopes)
+		}
+		if deps.TokenExchange != nil {
+			deps.TokenExchange.SetScopes(deps.Scopes)
+		}
+	}
+	if deps.Resources != nil {
+		if deps.Consents != nil {
+			deps.Consents.SetResources(deps.Resources)
+		}
+		if deps.TokenExchange != nil {
+			deps.TokenExchange.SetResources(deps.Resources)
+		}
+	}
+
+	h := &Handler{
+		config: config,
+		deps:   deps,
+		mux:    http.NewServeMux(),
+		grants: make(map[entity.GrantType]grantHandler),
+	}
+
+	h.mux.HandleFunc("GET "+MetadataPath, h.serverMetadata)
+	if h.publishesJWKS() {
+		h.mux.HandleFunc("GET "+JWKSPath, h.jwks)
+	}
+	if deps.Registration != nil {
+		h.mux.HandleFunc("POST "+RegistrationPath, h.registerClient)
+		h.mux.HandleFunc("GET "+RegistrationPath+"/{client_id}", h.readClientConfiguration)
+		h.mux.HandleFunc("PUT "+RegistrationPath+"/{client_id}", h.updateClientConfiguration)
+		h.mux.HandleFunc("DELETE "+RegistrationPath+"/{client_id}", h.deleteClientConfiguration)
+	}
+
+	if h.tokenEndpointEnabled() {
+		h.mux.HandleFunc("POST "+config.TokenPath, h.token)
+		h.grants[entity.GrantTypeRefreshToken] = h.refreshTokenGrant
+
+		if deps.Authorization != nil {
+			h.grants[entity.GrantTypeAuthCode] = h.authorizationCodeGrant
+			h.mux.HandleFunc("GET "+config.AuthorizationPath, h.authorize)
+			h.mux.HandleFunc("POST "+config.AuthorizationPath, h.authorizeDecision)
+			if config.PushedAuthorizationRequestPath != "" {
+				h.mux.HandleFunc("POST "+config.PushedAuthorizationRequestPath, h.pushAuthorizationRequest)
+			}
+		}
+
+		if deps.Devices != nil {
+			h.grants[entity.GrantTypeDeviceCode] = h.deviceCodeGrant
+			h.mux.HandleFunc("POST "+config.DeviceAuthorizationPath, h.deviceAuthorization)
+			h.mux.HandleFunc("GET "+config.DeviceVerificationPath, h.deviceVerification)
+			h.mux.HandleFunc("POST "+config.DeviceVerificationPath, h.deviceDecision)
+		}
+		if deps.Backchannel != nil {
+			h.grants[entity.GrantTypeCIBA] = h.cibaGrant
+			h.mux.HandleFunc("POST "+config.BackchannelAuthenticationPath, h.backchannelAuthentication)
+			h.mux.HandleFunc("GET "+config.BackchannelConfirmationPath, h.backchannelConfirmation)
+			h.mux.HandleFunc("POST "+config.BackchannelConfirmationPath, h.backchannelDecision)
+		}
+		if deps.TokenExchange != nil {
+			h.grants[entity.GrantTypeTokenExchange] = h.tokenExchangeGrant
+		}
+		if deps.JWTBearer != nil {
+			h.grants[entity.GrantTypeJWTBearer] = h.jwtBearerGrant
+		}
+		if deps.Consents != nil && config.ConnectedAppsPath != "" {
+			h.mux.HandleFunc("GET "+config.ConnectedAppsPath, h.listConnectedApps)
+			h.mux.HandleFunc("DELETE "+config.ConnectedAppsPath+"/{client_id}", h.revokeConnectedApp)
+		}
+		if config.IntrospectionPath != "" {
+			h.mux.HandleFunc("POST "+config.IntrospectionPath, h.introspect)
+		}
+		if config.RevocationPath != "" {
+			h.mux.HandleFunc("POST "+config.RevocationPath, h.revoke)
+		}
+	}
+
+	// Клиенты не регистрируются с типами авторизации, которые не обслуживает token endpoint
+	if deps.Registration != nil {
+		deps.Registration.SetSupportedGrantTypes(h.enabledGrantTypes())
+	}
+	return h
+}
+
+// ServeHTTP реализует http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// tokenEndpointEnabled проверяет, настроены ли зависимости token endpoint
+func (h *Handler) tokenEndpointEnabled() bool {
+	return h.deps.Clients != nil && h.deps.Tokens != nil && h.config.TokenPath != ""
+}
+
+// endpointURL возвращает абсолютный адрес endpoint; для пустого пути — пустую строку
+func (h *Handler) endpointURL(path string) string {
+	if path == "" {
+		return ""
+	}
+	return h.config.Issuer + path
+}
+
+// Коды ошибок OAuth 2.0 (RFC 6749, раздел 5.2; RFC 6750, раздел 3.1)
+const (
+	errorInvalidRequest       = "invalid_request"
+	errorInvalidClient        = "invalid_client"
+	errorInvalidGrant         = "invalid_grant"
+	errorInvalidScope         = "invalid_scope"
+	errorInvalidToken         = "invalid_token"
+	errorUnauthorizedClient   = "unauthorized_client"
+	errorUnsupportedGrantType = "unsupported_grant_type"
+	errorAccessDenied         = "access_denied"
+	errorServerError          = "server_error"
+)
+
+// Коды ошибок опроса при авторизации устройств (RFC 8628, раздел 3.5)
+const (
+	errorAuthorizationPending = "authorization_pending"
+	errorSlowDown             = "slow_down"
+	errorExpiredToken         = "expired_token"
+)
+
+// errorInvalidRequestObject объект запроса недействителен (RFC 9101, раздел 6.3)
+const errorInvalidRequestObject = "invalid_request_object"
+
+// errorUnsupportedTokenType сервер не отзывает токены этого типа (RFC 7009, раздел 2.2.1)
+const errorUnsupportedTokenType = "unsupported_token_type"
+
+// errorInvalidTarget запрошенный audience или resource недопустим (RFC 8693, раздел 2.2.2)
+const errorInvalidTarget = "invalid_target"
+
+// errorInvalidAuthorizationDetails authorization_details недопустимы (RFC 9396, раздел 5)
+const errorInvalidAuthorizationDetails = "invalid_authorization_details"
+
+// Коды ошибок backchannel authentication endpoint (CIBA Core, раздел 13)
+const (
+	errorUnknownUserID         = "unknown_user_id"
+	errorInvalidBindingMessage = "invalid_binding_message"
+)
+
+// errorResponse тело ответа с ошибкой OAuth
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	// Ответы с учетными данными не должны кешироваться (RFC 6749, раздел 5.1)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error("failed to write response", zap.Error(err))
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, errorResponse{Error: code, ErrorDescription: description})
+}
+
+// writeBearerError отвечает 401 с заголовком WWW-Authenticate (RFC 6750, раздел 3)
+func writeBearerError(w http.ResponseWriter, code, description string) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer error=%q, error_description=%q", code, description))
+	writeError(w, http.StatusUnauthorized, code, description)
+}
+
+// bearerToken возвращает токен из заголовка Authorization: Bearer
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// maxJSONBodySize максимальный размер JSON тела запроса, например метаданных
+// регистрации клиента с зарегистрированным JWKS
+const maxJSONBodySize = 64 << 10
+
+// decodeJSON разбирает JSON тело запроса. Неизвестные поля допускаются:
+// сервер должен игнорировать метаданные, которые не поддерживает.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if mediaType := r.Header.Get("Content-Type"); !strings.HasPrefix(mediaType, "application/json") {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "content type must be application/json")
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodySize)).Decode(dst); err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// introspectionResponse ответ endpoint интроспекции (RFC 7662, раздел 2.2)
+type introspectionResponse struct {
+	Active    bool                      `json:"active"`
+	Scope     string                    `json:"scope,omitempty"`
+	ClientID  string                    `json:"client_id,omitempty"`
+	TokenType string                    `json:"token_type,omitempty"`
+	Exp       int64                     `json:"exp,omitempty"`
+	Iat       int64                     `json:"iat,omitempty"`
+	Sub       string                    `json:"sub,omitempty"`
+	Aud       []string                  `json:"aud,omitempty"`
+	Iss       string                    `json:"iss,omitempty"`
+	Act       *entity.TokenActor        `json:"act,omitempty"`
+	Cnf       *entity.TokenConfirmation `json:"cnf,omitempty"`
+	// AuthorizationDetails детальные права токена (RFC 9396, раздел 9.2)
+	AuthorizationDetails entity.AuthorizationDetails `json:"authorization_details,omitempty"`
+}
+
+// introspect сообщает аутентифицированному клиенту состояние токена (RFC 7662).
+// Клиент видит только свои токены и токены, в audience которых он указан;
+// о прочих, как и о недействительных, сообщается active=false.
+func (h *Handler) introspect(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	value := r.PostForm.Get("token")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "token is required")
+		return
+	}
+
+	token, err := h.deps.Tokens.GetByValue(r.Context(), value)
+	if err != nil || h.deps.Validator.ValidateToken(token) != nil {
+		writeJSON(w, http.StatusOK, introspectionResponse{Active: false})
+		return
+	}
+	if token.ClientID != client.ID && !containsString(token.Audience, client.ClientID) {
+		log.Warn("client introspected a foreign token", zap.String("client_id", client.ClientID))
+		writeJSON(w, http.StatusOK, introspectionResponse{Active: false})
+		return
+	}
+
+	owner := client
+	if token.ClientID != client.ID {
+		if owner, err = h.deps.Clients.GetByID(r.Context(), token.ClientID); err != nil {
+			writeJSON(w, http.StatusOK, introspectionResponse{Active: false})
+			return
+		}
+	}
+
+	response := introspectionResponse{
+		Active:   true,
+		Scope:    strings.Join(token.Scopes, " "),
+		ClientID: owner.ClientID,
+		Exp:      token.ExpiresAt.Unix(),
+		Iat:      token.CreatedAt.Unix(),
+		Aud:      token.Audience,
+		Iss:      h.config.Issuer,
+		Act:      token.Actor,
+		Cnf:      token.Confirmation,
+
+		AuthorizationDetails: token.AuthorizationDetails,
+	}
+	if token.Type == entity.AccessToken {
+		response.TokenType = accessTokenType(token)
+	}
+	if token.UserID != uuid.Nil {
+		response.Sub = token.UserID.String()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// accessTokenType возвращает тип access token: DPoP для привязанных к ключу DPoP
+func accessTokenType(token *entity.Token) string {
+	if token.DPoPThumbprint() != "" {
+		return tokenTypeDPoP
+	}
+	return "Bearer"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
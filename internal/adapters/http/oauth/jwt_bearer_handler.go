@@ -0,0 +1,43 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// jwtBearerGrant выдает токены пользователю, о котором доверенный издатель
+// сделал утверждение (RFC 7523, раздел 2.1)
+func (h *Handler) jwtBearerGrant(w http.ResponseWriter, r *http.Request, client *entity.Client) {
+	assertion := r.PostForm.Get("assertion")
+	if assertion == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "assertion is required")
+		return
+	}
+
+	result, err := h.deps.JWTBearer.Exchange(r.Context(), client, service.JWTBearerRequest{
+		Assertion: assertion,
+		Scopes:    strings.Fields(r.PostForm.Get("scope")),
+		Audiences: []string{h.config.Issuer, h.endpointURL(h.config.TokenPath)},
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidBearerAssertion):
+		log.Warn("jwt bearer assertion rejected", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidBearerScope):
+		writeError(w, http.StatusBadRequest, errorInvalidScope, err.Error())
+		return
+	default:
+		log.Error("failed to accept jwt bearer assertion", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	h.writeTokens(w, r, client, tokenGrant{userID: result.User.ID, scopes: result.Scopes})
+}
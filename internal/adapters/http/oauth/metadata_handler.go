@@ -0,0 +1,245 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// MetadataPath путь документа метаданных сервера авторизации (RFC 8414, раздел 3)
+const MetadataPath = "/.well-known/oauth-authorization-server"
+
+// JWKSPath путь набора открытых ключей подписи
+const JWKSPath = "/.well-known/jwks.json"
+
+// metadataMaxAge срок кеширования метаданных и JWKS клиентами, в секундах
+const metadataMaxAge = 3600
+
+// ServerMetadata метаданные сервера авторизации (RFC 8414, раздел 2)
+type ServerMetadata struct {
+	Issuer                            string                           `json:"issuer"`
+	AuthorizationEndpoint             string                           `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                     string                           `json:"token_endpoint,omitempty"`
+	JWKSURI                           string                           `json:"jwks_uri,omitempty"`
+	RegistrationEndpoint              string                           `json:"registration_endpoint,omitempty"`
+	ScopesSupported                   []string                         `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []entity.ResponseType            `json:"response_types_supported"`
+	GrantTypesSupported               []entity.GrantType               `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported []entity.TokenEndpointAuthMethod `json:"token_endpoint_auth_methods_supported"`
+	ServiceDocumentation              string                           `json:"service_documentation,omitempty"`
+	RevocationEndpoint                string                           `json:"revocation_endpoint,omitempty"`
+	IntrospectionEndpoint             string                           `json:"introspection_endpoint,omitempty"`
+	CodeChallengeMethodsSupported     []string                         `json:"code_challenge_methods_supported,omitempty"`
+	DeviceAuthorizationEndpoint       string                           `json:"device_authorization_endpoint,omitempty"`
+	// Способы аутентификации клиентов на остальных endpoints совпадают с token endpoint
+	TokenEndpointAuthSigningAlgValuesSupported []string                         `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	RevocationEndpointAuthMethodsSupported     []entity.TokenEndpointAuthMethod `json:"revocation_endpoint_auth_methods_supported,omitempty"`
+	IntrospectionEndpointAuthMethodsSupported  []entity.TokenEndpointAuthMethod `json:"introspection_endpoint_auth_methods_supported,omitempty"`
+	// Метаданные PAR (RFC 9126, раздел 5)
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint,omitempty"`
+	RequirePushedAuthorizationRequests bool   `json:"require_pushed_authorization_requests,omitempty"`
+	// Метаданные объектов запроса (RFC 9101, раздел 10.1)
+	RequestParameterSupported                 bool     `json:"request_parameter_supported,omitempty"`
+	RequestURIParameterSupported              bool     `json:"request_uri_parameter_supported,omitempty"`
+	RequireRequestURIRegistration             bool     `json:"require_request_uri_registration,omitempty"`
+	RequestObjectSigningAlgValuesSupported    []string `json:"request_object_signing_alg_values_supported,omitempty"`
+	RequestObjectEncryptionAlgValuesSupported []string `json:"request_object_encryption_alg_values_supported,omitempty"`
+	RequestObjectEncryptionEncValuesSupported []string `json:"request_object_encryption_enc_values_supported,omitempty"`
+	RequireSignedRequestObject                bool     `json:"require_signed_request_object,omitempty"`
+	// DPoPSigningAlgValuesSupported алгоритмы DPoP proof (RFC 9449, раздел 5.1)
+	DPoPSigningAlgValuesSupported []string `json:"dpop_signing_alg_values_supported,omitempty"`
+	// TLSClientCertificateBoundAccessTokens поддержка токенов, привязанных к сертификату (RFC 8705, раздел 3.3)
+	TLSClientCertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	// AuthorizationDetailsTypesSupported типы authorization_details (RFC 9396, раздел 10)
+	AuthorizationDetailsTypesSupported []string `json:"authorization_details_types_supported,omitempty"`
+	// Метаданные CIBA (CIBA Core, раздел 4)
+	BackchannelAuthenticationEndpoint      string                                `json:"backchannel_authentication_endpoint,omitempty"`
+	BackchannelTokenDeliveryModesSupported []entity.BackchannelTokenDeliveryMode `json:"backchannel_token_delivery_modes_supported,omitempty"`
+}
+
+// scopesSupported возвращает области для метаданных: заданные в конфигурации
+// или, если их нет, зарегистрированные в реестре
+func (h *Handler) scopesSupported() []string {
+	if len(h.config.Scopes) > 0 {
+		return h.config.Scopes
+	}
+	return h.deps.Scopes.Supported()
+}
+
+// Metadata строит метаданные из текущей конфигурации и подключенных зависимостей,
+// поэтому документ меняется вместе с набором включенных возможностей
+func (h *Handler) Metadata() ServerMetadata {
+	metadata := ServerMetadata{
+		Issuer:                            h.config.Issuer,
+		TokenEndpoint:                     h.endpointURL(h.config.TokenPath),
+		ScopesSupported:                   h.scopesSupported(),
+		ResponseTypesSupported:            make([]entity.ResponseType, 0),
+		GrantTypesSupported:               make([]entity.GrantType, 0, len(h.config.GrantTypes)),
+		TokenEndpointAuthMethodsSupported: h.clientAuthMethods(),
+		ServiceDocumentation:              h.config.ServiceDocumentation,
+	}
+
+	if h.tokenEndpointEnabled() {
+		if h.config.RevocationPath != "" {
+			metadata.RevocationEndpoint = h.endpointURL(h.config.RevocationPath)
+			metadata.RevocationEndpointAuthMethodsSupported = metadata.TokenEndpointAuthMethodsSupported
+		}
+		if h.config.IntrospectionPath != "" {
+			metadata.IntrospectionEndpoint = h.endpointURL(h.config.IntrospectionPath)
+			metadata.IntrospectionEndpointAuthMethodsSupported = metadata.TokenEndpointAuthMethodsSupported
+		}
+		if h.deps.ClientAssertions != nil {
+			metadata.TokenEndpointAuthSigningAlgValuesSupported = h.deps.ClientAssertions.Algorithms()
+		}
+	}
+
+	metadata.GrantTypesSupported = append(metadata.GrantTypesSupported, h.enabledGrantTypes()...)
+
+	if h.deps.Authorization != nil && h.grantTypeEnabled(entity.GrantTypeAuthCode) {
+		metadata.AuthorizationEndpoint = h.endpointURL(h.config.AuthorizationPath)
+		metadata.ResponseTypesSupported = entity.SupportedResponseTypes()
+		metadata.CodeChallengeMethodsSupported = h.deps.Authorization.CodeChallengeMethods()
+		metadata.PushedAuthorizationRequestEndpoint = h.endpointURL(h.config.PushedAuthorizationRequestPath)
+		metadata.RequirePushedAuthorizationRequests = h.deps.Authorization.Config().RequirePushedRequests
+
+		metadata.RequestParameterSupported = true
+		metadata.RequestURIParameterSupported = true
+		metadata.RequireRequestURIRegistration = true
+		metadata.RequestObjectSigningAlgValuesSupported = h.deps.Authorization.RequestObjectSigningAlgorithms()
+		if algorithms := h.deps.Authorization.RequestObjectEncryptionAlgorithms(); len(algorithms) > 0 {
+			metadata.RequestObjectEncryptionAlgValuesSupported = algorithms
+			metadata.RequestObjectEncryptionEncValuesSupported = jose.ContentEncryptionAlgorithms
+		}
+		metadata.RequireSignedRequestObject = h.deps.Authorization.Config().RequireSignedRequestObject
+		metadata.AuthorizationDetailsTypesSupported = h.deps.Authorization.Config().AuthorizationDetails.Types()
+	}
+	if h.deps.DPoP != nil && h.tokenEndpointEnabled() {
+		metadata.DPoPSigningAlgValuesSupported = h.deps.DPoP.Algorithms()
+	}
+	metadata.TLSClientCertificateBoundAccessTokens = h.tokenEndpointEnabled()
+	if h.publishesJWKS() {
+		metadata.JWKSURI = h.endpointURL(JWKSPath)
+	}
+	if h.deps.Registration != nil {
+		metadata.RegistrationEndpoint = h.endpointURL(RegistrationPath)
+	}
+	if h.grantTypeEnabled(entity.GrantTypeDeviceCode) {
+		metadata.DeviceAuthorizationEndpoint = h.endpointURL(h.config.DeviceAuthorizationPath)
+	}
+	if h.grantTypeEnabled(entity.GrantTypeCIBA) {
+		metadata.BackchannelAuthenticationEndpoint = h.endpointURL(h.config.BackchannelAuthenticationPath)
+		metadata.BackchannelTokenDeliveryModesSupported = entity.SupportedBackchannelTokenDeliveryModes()
+	}
+	return metadata
+}
+
+// grantTypeEnabled проверяет, включен ли тип авторизации в конфигурации и
+// подключен ли его обработчик к token endpoint
+func (h *Handler) grantTypeEnabled(grantType entity.GrantType) bool {
+	if _, handled := h.grants[grantType]; !handled {
+		return false
+	}
+	for _, enabled := range h.config.GrantTypes {
+		if enabled == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledGrantTypes возвращает включенные типы авторизации в порядке конфигурации
+func (h *Handler) enabledGrantTypes() []entity.GrantType {
+	grantTypes := make([]entity.GrantType, 0, len(h.grants))
+	for _, grantType := range h.config.GrantTypes {
+		if h.grantTypeEnabled(grantType) {
+			grantTypes = append(grantTypes, grantType)
+		}
+	}
+	return grantTypes
+}
+
+// clientAuthMethods возвращает способы аутентификации клиентов; способы по
+// утверждениям JWT доступны, только если подключена их проверка
+func (h *Handler) clientAuthMethods() []entity.TokenEndpointAuthMethod {
+	var assertionMethods []entity.TokenEndpointAuthMethod
+	if h.deps.ClientAssertions != nil {
+		assertionMethods = h.deps.ClientAssertions.AuthMethods()
+	}
+	methods := make([]entity.TokenEndpointAuthMethod, 0)
+	for _, method := range entity.SupportedTokenEndpointAuthMethods() {
+		if !method.UsesClientAssertion() || containsAuthMethod(assertionMethods, method) {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func containsAuthMethod(values []entity.TokenEndpointAuthMethod, value entity.TokenEndpointAuthMethod) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// serverMetadata возвращает документ метаданных сервера авторизации
+func (h *Handler) serverMetadata(w http.ResponseWriter, r *http.Request) {
+	writePublicJSON(w, h.Metadata())
+}
+
+// publishesJWKS сообщает, есть ли у сервера ключи подписи или шифрования для публикации
+func (h *Handler) publishesJWKS() bool {
+	return h.deps.SigningKeys != nil ||
+		(h.deps.Authorization != nil && len(h.deps.Authorization.RequestObjectEncryptionKeys()) > 0)
+}
+
+// jwks возвращает открытые ключи, которыми можно проверить подписи сервера,
+// и ключи шифрования объектов запроса (use=enc)
+func (h *Handler) jwks(w http.ResponseWriter, r *http.Request) {
+	set := jose.JWKSet{Keys: make([]jose.JWK, 0)}
+	if h.deps.SigningKeys != nil {
+		keys, err := h.deps.SigningKeys.VerificationKeys(r.Context())
+		if err != nil {
+			log.Error("failed to load signing keys", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+			return
+		}
+		for _, key := range keys {
+			jwk, err := signingKeyJWK(key)
+			if err != nil {
+				log.Error("failed to encode signing key", zap.String("kid", key.ID), zap.Error(err))
+				continue
+			}
+			set.Keys = append(set.Keys, *jwk)
+		}
+	}
+	if h.deps.Authorization != nil {
+		set.Keys = append(set.Keys, h.deps.Authorization.RequestObjectEncryptionKeys()...)
+	}
+	writePublicJSON(w, set)
+}
+
+// signingKeyJWK преобразует открытую часть ключа подписи в JWK
+func signingKeyJWK(key *entity.SigningKey) (*jose.JWK, error) {
+	publicKey, err := x509.ParsePKIXPublicKey(key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	return jose.NewJWK(publicKey, key.ID, key.Algorithm)
+}
+
+// writePublicJSON записывает общедоступный документ, который клиенты могут кешировать
+func writePublicJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", metadataMaxAge))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error("failed to write response", zap.Error(err))
+	}
+}
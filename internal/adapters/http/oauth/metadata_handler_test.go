@@ -0,0 +1,58 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetadataListsOnlyHandledGrantTypes(t *testing.T) {
+	store := memory.NewStore()
+	h := NewHandler(DefaultConfig("https://auth.example.com"), Dependencies{
+		Clients: store.Clients,
+		Tokens:  store.Tokens,
+	})
+
+	grantTypes := h.Metadata().GrantTypesSupported
+	if len(grantTypes) != 1 || grantTypes[0] != entity.GrantTypeRefreshToken {
+		t.Errorf("grant_types_supported = %v, want only refresh_token", grantTypes)
+	}
+	if h.Metadata().AuthorizationEndpoint != "" {
+		t.Error("authorization_endpoint is published without an authorization service")
+	}
+}
+
+func TestRegistrationRejectsUnhandledGrantTypes(t *testing.T) {
+	store := memory.NewStore()
+	config := service.DefaultClientRegistrationConfig()
+	config.InitialAccessTokens = []string{"initial-token"}
+	h := NewHandler(DefaultConfig("https://auth.example.com"), Dependencies{
+		Clients:      store.Clients,
+		Tokens:       store.Tokens,
+		Registration: service.NewClientRegistrationService(store.Clients, nil, config),
+	})
+
+	for _, body := range []string{
+		`{"redirect_uris":["https://app.example.com/cb"]}`,
+		`{"grant_types":["client_credentials"]}`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, RegistrationPath, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Authorization", "Bearer initial-token")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		var response struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(w.Body).Decode(&response)
+		if w.Code != http.StatusBadRequest || response.Error != service.RegistrationErrorInvalidClientMetadata {
+			t.Errorf("%s: status = %d, error = %q, want 400 invalid_client_metadata", body, w.Code, response.Error)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/adapters/http/clientcert"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"net/http"
+)
+
+// isCertificateAuthMethod проверяет, аутентифицируется ли клиент сертификатом (RFC 8705, раздел 2)
+func isCertificateAuthMethod(method entity.TokenEndpointAuthMethod) bool {
+	return method == entity.AuthMethodTLSClientAuth || method == entity.AuthMethodSelfSignedTLSClientAuth
+}
+
+// certificates возвращает источник сертификата клиента из настроек
+func (h *Handler) certificates() clientcert.Source {
+	return clientcert.Source{
+		Header:       h.config.ClientCertificateHeader,
+		VerifyHeader: h.config.ClientCertificateVerifyHeader,
+	}
+}
+
+// bindCertificate привязывает выдаваемые токены к сертификату клиента, если
+// клиент зарегистрирован с tls_client_certificate_bound_access_tokens
+// (RFC 8705, раздел 3). При ошибке ответ уже записан.
+func (h *Handler) bindCertificate(w http.ResponseWriter, r *http.Request, client *entity.Client) (*http.Request, bool) {
+	if !client.TLSClientCertificateBoundAccessTokens {
+		return r, true
+	}
+	cert, _, err := h.certificates().Certificate(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "certificate-bound tokens require a client certificate")
+		return nil, false
+	}
+	return withConfirmation(r, func(confirmation *entity.TokenConfirmation) {
+		confirmation.X5tS256 = jose.CertificateThumbprint(cert.Raw)
+	}), true
+}
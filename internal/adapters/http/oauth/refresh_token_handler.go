@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"AuthAndOauth/internal/core/ports"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// refreshTokenGrant выдает новые токены по refresh token (RFC 6749, раздел 6).
+// Параметрами scope, resource и authorization_details access token сужается до
+// части гранта, например до другого ресурса (RFC 8707, раздел 2.2). Refresh
+// token ротируется: предъявленный отзывается, новый покрывает тот же грант и
+// входит в то же семейство. Повторное предъявление отозванного refresh token
+// означает, что он мог быть украден, и отзывает все семейство
+// (OAuth 2.0 Security BCP, раздел 4.14.2).
+func (h *Handler) refreshTokenGrant(w http.ResponseWriter, r *http.Request, client *entity.Client) {
+	value := r.PostForm.Get("refresh_token")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "refresh_token is required")
+		return
+	}
+
+	token, err := h.deps.Tokens.GetByValue(r.Context(), value)
+	if err != nil || token.Type != entity.RefreshToken || token.ClientID != client.ID {
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, "invalid refresh token")
+		return
+	}
+	if token.IsRevoked {
+		h.refreshTokenReused(w, r, token)
+		return
+	}
+	if err := h.deps.Validator.ValidateToken(token); err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, err.Error())
+		return
+	}
+	// Привязанный refresh token предъявляется с тем же ключом DPoP и сертификатом
+	if err := service.CheckTokenConfirmation(token, presentedConfirmation(r.Context())); err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidGrant, err.Error())
+		return
+	}
+
+	grant := tokenGrant{userID: token.UserID, scopes: token.Scopes, resources: token.Audience, details: token.AuthorizationDetails, family: token.Family()}
+	access, ok := h.accessGrant(w, r, grant)
+	if !ok {
+		return
+	}
+
+	// Отзыв в хранилище атомарен: из одновременных запросов ротацию проводит один
+	err = h.deps.Tokens.Revoke(r.Context(), token.ID)
+	if errors.Is(err, ports.ErrNotFound) {
+		h.refreshTokenReused(w, r, token)
+		return
+	}
+	if err != nil {
+		log.Error("failed to rotate refresh token", zap.String("token_id", token.ID.String()), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+	response, err := h.issueTokens(r.Context(), client, grant, access)
+	if err != nil {
+		log.Error("failed to issue tokens", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// refreshTokenReused отзывает семейство повторно предъявленного refresh token
+func (h *Handler) refreshTokenReused(w http.ResponseWriter, r *http.Request, token *entity.Token) {
+	revoked, err := h.deps.Tokens.RevokeFamily(r.Context(), token.Family())
+	if err != nil {
+		log.Error("failed to revoke token family", zap.String("token_id", token.ID.String()), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+	log.Warn("revoked refresh token reused",
+		zap.String("token_id", token.ID.String()),
+		zap.String("user_id", token.UserID.String()),
+		zap.Int("revoked_tokens", revoked),
+	)
+	writeError(w, http.StatusBadRequest, errorInvalidGrant, "invalid refresh token")
+}
@@ -0,0 +1,116 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// refreshTest token endpoint с публичным клиентом и выданным ему refresh token
+type refreshTest struct {
+	t       *testing.T
+	store   *memory.Store
+	handler *Handler
+	client  *entity.Client
+	token   *entity.Token
+}
+
+func newRefreshTest(t *testing.T) *refreshTest {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	client := entity.NewClient("public client", "", []string{"https://client.example.com/cb"}, []entity.GrantType{entity.GrantTypeRefreshToken}, []string{"openid"})
+	client.TokenEndpointAuthMethod = entity.AuthMethodNone
+	if err := store.Clients.Create(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	token := entity.NewToken(uuid.New(), client.ID, entity.RefreshToken, []string{"openid"}, time.Hour)
+	if err := store.Tokens.Create(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewHandler(DefaultConfig("https://auth.example.com"), Dependencies{
+		Clients: store.Clients,
+		Tokens:  store.Tokens,
+	})
+	return &refreshTest{t: t, store: store, handler: handler, client: client, token: token}
+}
+
+// refresh обменивает refresh token и возвращает ответ token endpoint
+func (rt *refreshTest) refresh(value string) (int, tokenResponse) {
+	rt.t.Helper()
+	form := url.Values{
+		"grant_type":    {string(entity.GrantTypeRefreshToken)},
+		"refresh_token": {value},
+		"client_id":     {rt.client.ClientID},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	rt.handler.ServeHTTP(w, r)
+
+	var response tokenResponse
+	json.NewDecoder(w.Body).Decode(&response)
+	return w.Code, response
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	rt := newRefreshTest(t)
+	ctx := context.Background()
+
+	status, rotated := rt.refresh(rt.token.Value)
+	if status != http.StatusOK || rotated.RefreshToken == "" {
+		t.Fatalf("rotation: status = %d, refresh_token = %q", status, rotated.RefreshToken)
+	}
+	if status, _ := rt.refresh(rt.token.Value); status != http.StatusBadRequest {
+		t.Fatalf("reused refresh token: status = %d, want 400", status)
+	}
+
+	for _, value := range []string{rotated.AccessToken, rotated.RefreshToken} {
+		token, err := rt.store.Tokens.GetByValue(ctx, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !token.IsRevoked {
+			t.Errorf("%s of the rotated family is still valid", token.Type)
+		}
+	}
+	if status, _ := rt.refresh(rotated.RefreshToken); status != http.StatusBadRequest {
+		t.Errorf("refresh token of a revoked family: status = %d, want 400", status)
+	}
+}
+
+func TestRefreshTokenRotatesOnce(t *testing.T) {
+	rt := newRefreshTest(t)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		rotated int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, _ := rt.refresh(rt.token.Value); status == http.StatusOK {
+				mu.Lock()
+				rotated++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if rotated != 1 {
+		t.Fatalf("refresh token rotated %d times, want 1", rotated)
+	}
+}
@@ -0,0 +1,131 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// RegistrationPath путь endpoint динамической регистрации клиентов
+const RegistrationPath = "/register"
+
+// clientInformationResponse ответ с информацией о клиенте (RFC 7591, раздел 3.2.1).
+// Секрет и registration access token возвращаются только при регистрации:
+// сервер хранит лишь их хеши.
+type clientInformationResponse struct {
+	ClientID                string `json:"client_id"`
+	ClientSecret            string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt        int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   *int64 `json:"client_secret_expires_at,omitempty"`
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+	service.ClientMetadata
+}
+
+// clientUpdateRequest тело запроса на изменение конфигурации клиента (RFC 7592, раздел 2.2)
+type clientUpdateRequest struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	service.ClientMetadata
+}
+
+func (h *Handler) clientInformation(client *entity.Client) clientInformationResponse {
+	response := clientInformationResponse{
+		ClientID:              client.ClientID,
+		ClientIDIssuedAt:      client.CreatedAt.Unix(),
+		RegistrationClientURI: h.endpointURL(RegistrationPath + "/" + client.ClientID),
+		ClientMetadata:        service.MetadataFromClient(client),
+	}
+	if client.TokenEndpointAuthMethod.UsesClientSecret() {
+		// Секреты не истекают сами по себе: их срок задается только ротацией
+		var never int64
+		response.ClientSecretExpiresAt = &never
+	}
+	return response
+}
+
+// registerClient регистрирует клиента (RFC 7591, раздел 3.1)
+func (h *Handler) registerClient(w http.ResponseWriter, r *http.Request) {
+	var metadata service.ClientMetadata
+	if !decodeJSON(w, r, &metadata) {
+		return
+	}
+
+	registered, err := h.deps.Registration.Register(r.Context(), metadata, bearerToken(r))
+	if err != nil {
+		writeRegistrationError(w, err)
+		return
+	}
+
+	response := h.clientInformation(registered.Client)
+	response.ClientSecret = registered.ClientSecret
+	response.RegistrationAccessToken = registered.RegistrationAccessToken
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// readClientConfiguration возвращает конфигурацию клиента (RFC 7592, раздел 2.1)
+func (h *Handler) readClientConfiguration(w http.ResponseWriter, r *http.Request) {
+	client, err := h.deps.Registration.Read(r.Context(), r.PathValue("client_id"), bearerToken(r))
+	if err != nil {
+		writeRegistrationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.clientInformation(client))
+}
+
+// updateClientConfiguration заменяет метаданные клиента (RFC 7592, раздел 2.2)
+func (h *Handler) updateClientConfiguration(w http.ResponseWriter, r *http.Request) {
+	clientID := r.PathValue("client_id")
+	token := bearerToken(r)
+
+	// Токен проверяется до разбора тела, чтобы не раскрывать детали валидации посторонним
+	if _, err := h.deps.Registration.Read(r.Context(), clientID, token); err != nil {
+		writeRegistrationError(w, err)
+		return
+	}
+
+	var req clientUpdateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.ClientID != clientID {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "client_id does not match the configuration endpoint")
+		return
+	}
+
+	client, err := h.deps.Registration.Update(r.Context(), clientID, token, req.ClientMetadata)
+	if err != nil {
+		writeRegistrationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.clientInformation(client))
+}
+
+// deleteClientConfiguration удаляет клиента (RFC 7592, раздел 2.3)
+func (h *Handler) deleteClientConfiguration(w http.ResponseWriter, r *http.Request) {
+	if err := h.deps.Registration.Delete(r.Context(), r.PathValue("client_id"), bearerToken(r)); err != nil {
+		writeRegistrationError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRegistrationError преобразует ошибку регистрации в ответ
+func writeRegistrationError(w http.ResponseWriter, err error) {
+	var registrationErr *service.RegistrationError
+	switch {
+	case errors.As(err, &registrationErr):
+		writeError(w, http.StatusBadRequest, registrationErr.Code, registrationErr.Description)
+	case errors.Is(err, service.ErrInvalidInitialAccessToken), errors.Is(err, service.ErrInvalidRegistrationToken):
+		writeBearerError(w, errorInvalidToken, err.Error())
+	case errors.Is(err, service.ErrRegistrationClosed):
+		writeError(w, http.StatusForbidden, errorAccessDenied, "client registration is not available")
+	default:
+		log.Error("client registration failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+	}
+}
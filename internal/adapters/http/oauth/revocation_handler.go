@@ -0,0 +1,90 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// revocationTokenTypes значения token_type_hint, которые понимает сервер (RFC 7009, раздел 2.1)
+var revocationTokenTypes = map[string]bool{
+	"":              true,
+	"access_token":  true,
+	"refresh_token": true,
+}
+
+// revoke отзывает токен аутентифицированного клиента (RFC 7009). Неизвестные,
+// уже недействительные и чужие токены не раскрываются: ответ всегда 200.
+func (h *Handler) revoke(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	value := r.PostForm.Get("token")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "token is required")
+		return
+	}
+	if hint := r.PostForm.Get("token_type_hint"); !revocationTokenTypes[hint] {
+		writeError(w, http.StatusBadRequest, errorUnsupportedTokenType, fmt.Sprintf("token type %s is not supported", hint))
+		return
+	}
+
+	token, err := h.deps.Tokens.GetByValue(r.Context(), value)
+	if err != nil || token.IsRevoked {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if token.ClientID != client.ID {
+		log.Warn("client tried to revoke a foreign token", zap.String("client_id", client.ClientID))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := h.revokeToken(r.Context(), token); err != nil {
+		log.Error("failed to revoke token", zap.String("token_id", token.ID.String()), zap.Error(err))
+		writeError(w, http.StatusServiceUnavailable, errorServerError, "token was not revoked, retry later")
+		return
+	}
+	log.Info("token revoked",
+		zap.String("client_id", client.ClientID),
+		zap.String("token_id", token.ID.String()),
+		zap.String("token_type", string(token.Type)),
+	)
+	w.WriteHeader(http.StatusOK)
+}
+
+// revokeToken отзывает токен. Вместе с refresh token отзываются access токены,
+// выданные тому же пользователю для того же клиента (RFC 7009, раздел 2.1):
+// связь токенов одного гранта не хранится.
+func (h *Handler) revokeToken(ctx context.Context, token *entity.Token) error {
+	token.Revoke()
+	if err := h.deps.Tokens.Update(ctx, token); err != nil {
+		return err
+	}
+	if token.Type != entity.RefreshToken || token.UserID == uuid.Nil {
+		return nil
+	}
+
+	related, err := h.deps.Tokens.ListByUser(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	for _, access := range related {
+		if access.Type != entity.AccessToken || access.ClientID != token.ClientID || access.IsRevoked {
+			continue
+		}
+		access.Revoke()
+		if err := h.deps.Tokens.Update(ctx, access); err != nil {
+			return err
+		}
+	}
+	return nil
+}
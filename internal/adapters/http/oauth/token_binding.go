@@ -0,0 +1,49 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"net/http"
+)
+
+// tokenTypeDPoP тип токена, привязанного к ключу DPoP (RFC 9449, раздел 5).
+// Токены, привязанные к сертификату, остаются bearer (RFC 8705, раздел 3.1).
+const tokenTypeDPoP = "DPoP"
+
+// confirmationContextKey ключ контекста запроса к token endpoint с ключами,
+// к которым привязываются выданные токены
+type confirmationContextKey struct{}
+
+// withConfirmation добавляет в контекст запроса ключ привязки токенов
+func withConfirmation(r *http.Request, update func(*entity.TokenConfirmation)) *http.Request {
+	confirmation, _ := r.Context().Value(confirmationContextKey{}).(*entity.TokenConfirmation)
+	confirmation = confirmation.Clone()
+	if confirmation == nil {
+		confirmation = &entity.TokenConfirmation{}
+	}
+	update(confirmation)
+	return r.WithContext(context.WithValue(r.Context(), confirmationContextKey{}, confirmation))
+}
+
+// bindToken привязывает токены к ключам из контекста запроса и возвращает
+// тип выданного токена
+func bindToken(ctx context.Context, tokens ...*entity.Token) string {
+	confirmation := presentedConfirmation(ctx)
+	if confirmation == nil {
+		return "Bearer"
+	}
+	for _, token := range tokens {
+		token.Confirmation = confirmation.Clone()
+	}
+	if confirmation.JKT != "" {
+		return tokenTypeDPoP
+	}
+	return "Bearer"
+}
+
+// presentedConfirmation возвращает ключи, подтвержденные в запросе к token endpoint,
+// или nil
+func presentedConfirmation(ctx context.Context) *entity.TokenConfirmation {
+	confirmation, _ := ctx.Value(confirmationContextKey{}).(*entity.TokenConfirmation)
+	return confirmation
+}
@@ -0,0 +1,91 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// tokenExchangeGrant обменивает токен субъекта на токен для другого сервиса (RFC 8693, раздел 2)
+func (h *Handler) tokenExchangeGrant(w http.ResponseWriter, r *http.Request, client *entity.Client) {
+	result, err := h.deps.TokenExchange.Exchange(r.Context(), client, service.TokenExchangeRequest{
+		SubjectToken:       r.PostForm.Get("subject_token"),
+		SubjectTokenType:   r.PostForm.Get("subject_token_type"),
+		ActorToken:         r.PostForm.Get("actor_token"),
+		ActorTokenType:     r.PostForm.Get("actor_token_type"),
+		RequestedTokenType: r.PostForm.Get("requested_token_type"),
+		Audiences:          r.PostForm["audience"],
+		Resources:          r.PostForm["resource"],
+		Scopes:             strings.Fields(r.PostForm.Get("scope")),
+		Confirmation:       presentedConfirmation(r.Context()),
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrInvalidSubjectToken),
+		errors.Is(err, service.ErrInvalidActorToken),
+		errors.Is(err, service.ErrUnsupportedTokenType):
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidTarget):
+		writeError(w, http.StatusBadRequest, errorInvalidTarget, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidExchangeScope):
+		writeError(w, http.StatusBadRequest, errorInvalidScope, err.Error())
+		return
+	case errors.Is(err, service.ErrTokenExchangeNotAllowed):
+		writeError(w, http.StatusBadRequest, errorUnauthorizedClient, err.Error())
+		return
+	default:
+		log.Error("failed to exchange token", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+
+	response, err := h.issueExchangedToken(r, client, result)
+	if err != nil {
+		log.Error("failed to issue exchanged token", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// issueExchangedToken выдает access token по результату обмена. Refresh token не
+// выдается: делегированный доступ не должен переживать исходный токен.
+func (h *Handler) issueExchangedToken(r *http.Request, client *entity.Client, result *service.TokenExchangeResult) (*tokenResponse, error) {
+	accessToken, _, err := h.deps.TokenGenerator.GenerateTokenPair(result.UserID, client.ID, result.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	accessToken.ExpiresAt = accessToken.CreatedAt.Add(client.AccessTokenTTL(accessToken.ExpiresAt.Sub(accessToken.CreatedAt)))
+	if accessToken.ExpiresAt.After(result.NotAfter) {
+		accessToken.ExpiresAt = result.NotAfter
+	}
+	accessToken.Audience = result.Audience
+	accessToken.Actor = result.Actor
+	tokenType := bindToken(r.Context(), accessToken)
+
+	if err := h.deps.Tokens.Create(r.Context(), accessToken); err != nil {
+		return nil, fmt.Errorf("failed to store access token: %w", err)
+	}
+
+	log.Info("token exchanged",
+		zap.String("client_id", client.ClientID),
+		zap.String("user_id", result.UserID.String()),
+		zap.Strings("audience", result.Audience),
+		zap.Strings("scopes", result.Scopes),
+	)
+	return &tokenResponse{
+		AccessToken:     accessToken.Value,
+		TokenType:       tokenType,
+		ExpiresIn:       int64(time.Until(accessToken.ExpiresAt).Round(time.Second) / time.Second),
+		Scope:           strings.Join(result.Scopes, " "),
+		IssuedTokenType: result.IssuedTokenType,
+	}, nil
+}
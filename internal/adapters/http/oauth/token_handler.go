@@ -0,0 +1,232 @@
+package oauth
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/domain/service"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// grantHandler обрабатывает запрос к token endpoint для одного типа авторизации.
+// Клиент уже аутентифицирован, и ему разрешен этот тип авторизации.
+type grantHandler func(w http.ResponseWriter, r *http.Request, client *entity.Client)
+
+// tokenResponse успешный ответ token endpoint (RFC 6749, раздел 5.1)
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// IssuedTokenType тип выпущенного токена при обмене (RFC 8693, раздел 2.2.1)
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	// AuthorizationDetails детальные права выданного токена (RFC 9396, раздел 7)
+	AuthorizationDetails entity.AuthorizationDetails `json:"authorization_details,omitempty"`
+}
+
+// tokenGrant права, на которые выдаются токены: одобренные пользователем или
+// подтвержденные утверждением. Refresh token получает весь грант, access token
+// может быть сужен параметрами resource, scope и authorization_details.
+// family — семейство ротируемого refresh token; нулевое начинает новое.
+type tokenGrant struct {
+	userID    uuid.UUID
+	scopes    []string
+	resources []string
+	details   entity.AuthorizationDetails
+	family    uuid.UUID
+}
+
+// repeatableParams параметры token endpoint, которые могут передаваться несколько раз
+var repeatableParams = map[string]bool{
+	"audience": true,
+	"resource": true,
+}
+
+// token обрабатывает запрос к token endpoint и передает его обработчику типа авторизации
+func (h *Handler) token(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+
+	grantType := entity.GrantType(r.PostForm.Get("grant_type"))
+	if grantType == "" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "grant_type is required")
+		return
+	}
+	handle, ok := h.grants[grantType]
+	if !ok || !h.grantTypeEnabled(grantType) {
+		writeError(w, http.StatusBadRequest, errorUnsupportedGrantType, fmt.Sprintf("grant type %s is not supported", grantType))
+		return
+	}
+
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	if err := h.deps.Validator.ValidateClient(client, grantType); err != nil {
+		writeError(w, http.StatusBadRequest, errorUnauthorizedClient, err.Error())
+		return
+	}
+	if r, ok = h.verifyDPoP(w, r, client); !ok {
+		return
+	}
+	if r, ok = h.bindCertificate(w, r, client); !ok {
+		return
+	}
+
+	handle(w, r, client)
+}
+
+// issueTokens выдает access token на права access и, если клиенту разрешен
+// refresh_token, refresh token на весь грант.
+// Время жизни берется из настроек клиента, а при их отсутствии — из TokenGenerator.
+func (h *Handler) issueTokens(ctx context.Context, client *entity.Client, grant, access tokenGrant) (*tokenResponse, error) {
+	userID := grant.userID
+	accessToken, refreshToken, err := h.deps.TokenGenerator.GenerateTokenPair(userID, client.ID, access.scopes)
+	if err != nil {
+		return nil, err
+	}
+	accessToken.Audience = access.resources
+	accessToken.AuthorizationDetails = access.details
+	refreshToken.Scopes = append([]string(nil), grant.scopes...)
+	refreshToken.Audience = append([]string(nil), grant.resources...)
+	refreshToken.AuthorizationDetails = grant.details.Clone()
+	family := grant.family
+	if family == uuid.Nil {
+		family = refreshToken.ID
+	}
+	accessToken.FamilyID = family
+	refreshToken.FamilyID = family
+	accessToken.ExpiresAt = accessToken.CreatedAt.Add(client.AccessTokenTTL(accessToken.ExpiresAt.Sub(accessToken.CreatedAt)))
+	refreshToken.ExpiresAt = refreshToken.CreatedAt.Add(client.RefreshTokenTTL(refreshToken.ExpiresAt.Sub(refreshToken.CreatedAt)))
+	tokenType := bindToken(ctx, accessToken, refreshToken)
+
+	if h.deps.JWTAccessTokens != nil {
+		if accessToken.Value, err = h.deps.JWTAccessTokens.Encode(ctx, client, accessToken); err != nil {
+			return nil, fmt.Errorf("failed to encode access token: %w", err)
+		}
+	}
+	if err := h.deps.Tokens.Create(ctx, accessToken); err != nil {
+		return nil, fmt.Errorf("failed to store access token: %w", err)
+	}
+
+	response := &tokenResponse{
+		AccessToken:          accessToken.Value,
+		TokenType:            tokenType,
+		ExpiresIn:            int64(time.Until(accessToken.ExpiresAt).Round(time.Second) / time.Second),
+		Scope:                strings.Join(access.scopes, " "),
+		AuthorizationDetails: access.details,
+	}
+
+	if client.IsGrantTypeAllowed(entity.GrantTypeRefreshToken) && userID != uuid.Nil {
+		if err := h.deps.Tokens.Create(ctx, refreshToken); err != nil {
+			return nil, fmt.Errorf("failed to store refresh token: %w", err)
+		}
+		response.RefreshToken = refreshToken.Value
+	}
+
+	log.Info("tokens issued",
+		zap.String("client_id", client.ClientID),
+		zap.String("user_id", userID.String()),
+		zap.Strings("scopes", access.scopes),
+		zap.Strings("audience", access.resources),
+		zap.Strings("authorization_details_types", access.details.Types()),
+		zap.Bool("refresh_token", response.RefreshToken != ""),
+		zap.String("token_type", tokenType),
+	)
+	return response, nil
+}
+
+// writeTokens выдает токены по гранту и записывает ответ token endpoint
+func (h *Handler) writeTokens(w http.ResponseWriter, r *http.Request, client *entity.Client, grant tokenGrant) {
+	access, ok := h.accessGrant(w, r, grant)
+	if !ok {
+		return
+	}
+	response, err := h.issueTokens(r.Context(), client, grant, access)
+	if err != nil {
+		log.Error("failed to issue tokens", zap.String("client_id", client.ClientID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// accessGrant определяет права access token по параметрам scope, resource
+// (RFC 8707, раздел 2.2) и authorization_details (RFC 9396, раздел 6.1) запроса;
+// без них токен получает весь грант. resources результата — audience токена.
+// При ошибке ответ уже записан.
+func (h *Handler) accessGrant(w http.ResponseWriter, r *http.Request, grant tokenGrant) (tokenGrant, bool) {
+	requested, err := entity.ParseAuthorizationDetails(r.PostForm.Get("authorization_details"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidAuthorizationDetails, err.Error())
+		return tokenGrant{}, false
+	}
+	details, err := service.NarrowAuthorizationDetails(grant.details, requested)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidAuthorizationDetails, err.Error())
+		return tokenGrant{}, false
+	}
+
+	scopes, audience, err := h.deps.Resources.Narrow(grant.scopes, grant.resources, r.PostForm["resource"], strings.Fields(r.PostForm.Get("scope")))
+	switch {
+	case err == nil:
+		return tokenGrant{userID: grant.userID, scopes: scopes, resources: audience, details: details}, true
+	case errors.Is(err, service.ErrInvalidTarget):
+		writeError(w, http.StatusBadRequest, errorInvalidTarget, err.Error())
+	case errors.Is(err, service.ErrInvalidResourceScope):
+		writeError(w, http.StatusBadRequest, errorInvalidScope, err.Error())
+	default:
+		log.Error("failed to resolve token audience", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, errorServerError, "internal error")
+	}
+	return tokenGrant{}, false
+}
+
+// requestedScopes разбирает параметр scope и проверяет, что клиенту разрешены
+// все запрошенные области. Без параметра выдаются области по умолчанию из
+// реестра, а без реестра — все области клиента. При ошибке ответ уже записан.
+func (h *Handler) requestedScopes(w http.ResponseWriter, r *http.Request, client *entity.Client) ([]string, bool) {
+	scopes := strings.Fields(r.PostForm.Get("scope"))
+	if len(scopes) == 0 {
+		return h.deps.Scopes.Defaults(client), true
+	}
+	for _, scope := range scopes {
+		if !h.deps.Scopes.IsAllowed(client, scope) {
+			writeError(w, http.StatusBadRequest, errorInvalidScope, fmt.Sprintf("scope %s is not allowed", scope))
+			return nil, false
+		}
+	}
+	return scopes, true
+}
+
+// parseForm разбирает тело запроса application/x-www-form-urlencoded.
+// При ошибке ответ уже записан.
+func parseForm(w http.ResponseWriter, r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/x-www-form-urlencoded" {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "content type must be application/x-www-form-urlencoded")
+		return false
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, errorInvalidRequest, "invalid form body")
+		return false
+	}
+	// Параметры не должны повторяться (RFC 6749, раздел 3.2), кроме определенных
+	// расширениями как многозначные
+	for name, values := range r.PostForm {
+		if len(values) > 1 && !repeatableParams[name] {
+			writeError(w, http.StatusBadRequest, errorInvalidRequest, fmt.Sprintf("parameter %s is repeated", name))
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// Package notification реализует доставку запросов аутентификации на устройства пользователей
+package notification
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DeviceNotification запрос аутентификации, принятый FakeDeviceNotifier
+type DeviceNotification struct {
+	UserID     uuid.UUID
+	ClientID   string
+	ClientName string
+	Request    entity.BackchannelAuthentication
+}
+
+// FakeDeviceNotifier запоминает уведомления вместо отправки на устройство.
+// Предназначен для тестов и локальной разработки: запрос подтверждается на
+// странице подтверждения по идентификатору Request.ID.
+type FakeDeviceNotifier struct {
+	mu            sync.Mutex
+	notifications []DeviceNotification
+	err           error
+}
+
+var _ ports.AuthenticationDeviceNotifier = (*FakeDeviceNotifier)(nil)
+
+// NewFakeDeviceNotifier создает новый FakeDeviceNotifier
+func NewFakeDeviceNotifier() *FakeDeviceNotifier {
+	return &FakeDeviceNotifier{}
+}
+
+// Notify реализует ports.AuthenticationDeviceNotifier
+func (n *FakeDeviceNotifier) Notify(ctx context.Context, user *entity.User, client *entity.Client, request *entity.BackchannelAuthentication) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+	notification := DeviceNotification{
+		UserID:     user.ID,
+		ClientID:   client.ClientID,
+		ClientName: client.Name,
+		Request:    *request,
+	}
+	notification.Request.Scopes = append([]string(nil), request.Scopes...)
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// Notifications возвращает принятые уведомления в порядке поступления
+func (n *FakeDeviceNotifier) Notifications() []DeviceNotification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]DeviceNotification(nil), n.notifications...)
+}
+
+// Last возвращает последнее принятое уведомление
+func (n *FakeDeviceNotifier) Last() (DeviceNotification, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.notifications) == 0 {
+		return DeviceNotification{}, false
+	}
+	return n.notifications[len(n.notifications)-1], true
+}
+
+// FailWith задает ошибку, которую вернут следующие вызовы Notify; nil — доставка
+// снова успешна
+func (n *FakeDeviceNotifier) FailWith(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.err = err
+}
+
+// Reset забывает принятые уведомления
+func (n *FakeDeviceNotifier) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = nil
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLocked хранилище открыто другим процессом на запись
+var ErrLocked = errors.New("store is locked by another process")
+
+// Lock исключительная блокировка хранилища. Сервер держит ее все время работы,
+// authctl — на время изменяющей команды, поэтому изменения одного процесса не
+// перезаписываются снимком другого. Блокируется отдельный файл path.lock: Save
+// заменяет файл хранилища новым.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire захватывает блокировку хранилища path; если она занята, возвращает ErrLocked
+func Acquire(path string) (*Lock, error) {
+	lockPath := path + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open lock %s: %w", lockPath, err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock %s: %w", lockPath, err)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release освобождает блокировку
+func (l *Lock) Release() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("unlock %s: %w", l.file.Name(), err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package file
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockFile на платформах без flock блокировки не поддерживаются
+func lockFile(f *os.File) error {
+	return fmt.Errorf("file locks are not supported on this platform")
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package file сохраняет состояние хранилищ в памяти в JSON файл.
+// Используется утилитой authctl и для небольших развертываний без БД.
+package file
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Open загружает хранилище из файла. Если файл не существует,
+// возвращается пустое хранилище.
+func Open(path string) (*memory.Store, error) {
+	store := memory.NewStore()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read store %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("load store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Save атомарно записывает состояние хранилища в файл. Файл содержит
+// хеши паролей, секреты клиентов и закрытые ключи, поэтому доступен только владельцу.
+func Save(path string, store *memory.Store) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace store %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+)
+
+// AuditLogRepository хранилище записей аудита в памяти
+type AuditLogRepository struct {
+	mu   sync.RWMutex
+	logs []*entity.AuditLog
+}
+
+var _ ports.AuditLogRepository = (*AuditLogRepository)(nil)
+
+// NewAuditLogRepository создает новое хранилище записей аудита в памяти
+func NewAuditLogRepository() *AuditLogRepository {
+	return &AuditLogRepository{}
+}
+
+// Save сохраняет запись аудита
+func (r *AuditLogRepository) Save(ctx context.Context, auditLog *entity.AuditLog) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, auditLog)
+	return nil
+}
+
+// List возвращает записи аудита, упорядоченные по времени создания
+func (r *AuditLogRepository) List(ctx context.Context, filter ports.AuditLogFilter) ([]*entity.AuditLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.AuditLog
+	for _, auditLog := range r.logs {
+		if filter.UserID != "" && auditLog.UserID != filter.UserID {
+			continue
+		}
+		if filter.EventType != "" && auditLog.EventType != filter.EventType {
+			continue
+		}
+		if !filter.From.IsZero() && auditLog.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && auditLog.CreatedAt.After(filter.To) {
+			continue
+		}
+		result = append(result, auditLog)
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// AuthCodeRepository хранилище кодов авторизации в памяти
+type AuthCodeRepository struct {
+	mu     sync.RWMutex
+	codes  map[uuid.UUID]*entity.AuthCode
+	byCode map[string]uuid.UUID
+}
+
+var _ ports.AuthCodeRepository = (*AuthCodeRepository)(nil)
+
+// NewAuthCodeRepository создает новое хранилище кодов авторизации в памяти
+func NewAuthCodeRepository() *AuthCodeRepository {
+	return &AuthCodeRepository{
+		codes:  make(map[uuid.UUID]*entity.AuthCode),
+		byCode: make(map[string]uuid.UUID),
+	}
+}
+
+// Create сохраняет новый код авторизации
+func (r *AuthCodeRepository) Create(ctx context.Context, code *entity.AuthCode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.codes[code.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if _, exists := r.byCode[code.Code]; exists {
+		return ports.ErrAlreadyExists
+	}
+	r.codes[code.ID] = cloneAuthCode(code)
+	r.byCode[code.Code] = code.ID
+	return nil
+}
+
+// GetByCode возвращает код авторизации по его значению
+func (r *AuthCodeRepository) GetByCode(ctx context.Context, code string) (*entity.AuthCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byCode[code]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return cloneAuthCode(r.codes[id]), nil
+}
+
+// Update сохраняет изменения кода авторизации; значение кода не изменяется
+func (r *AuthCodeRepository) Update(ctx context.Context, code *entity.AuthCode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.codes[code.ID]
+	if !exists {
+		return ports.ErrNotFound
+	}
+	clone := cloneAuthCode(code)
+	clone.Code = existing.Code
+	r.codes[code.ID] = clone
+	return nil
+}
+
+// Delete удаляет код авторизации по его значению
+func (r *AuthCodeRepository) Delete(ctx context.Context, code string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.byCode[code]
+	if !ok {
+		return ports.ErrNotFound
+	}
+	delete(r.codes, id)
+	delete(r.byCode, code)
+	return nil
+}
+
+// DeleteByUserAndClient удаляет коды, выданные клиенту от имени пользователя
+func (r *AuthCodeRepository) DeleteByUserAndClient(ctx context.Context, userID, clientID uuid.UUID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for id, code := range r.codes {
+		if code.UserID == userID && code.ClientID == clientID {
+			delete(r.byCode, code.Code)
+			delete(r.codes, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func cloneAuthCode(code *entity.AuthCode) *entity.AuthCode {
+	clone := *code
+	clone.Scopes = append([]string(nil), code.Scopes...)
+	clone.Resources = append([]string(nil), code.Resources...)
+	clone.AuthorizationDetails = code.AuthorizationDetails.Clone()
+	return &clone
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BackchannelAuthenticationRepository хранилище запросов аутентификации по
+// обратному каналу в памяти
+type BackchannelAuthenticationRepository struct {
+	mu          sync.RWMutex
+	requests    map[uuid.UUID]*entity.BackchannelAuthentication
+	byAuthReqID map[string]uuid.UUID
+}
+
+var _ ports.BackchannelAuthenticationRepository = (*BackchannelAuthenticationRepository)(nil)
+
+// NewBackchannelAuthenticationRepository создает новое хранилище запросов аутентификации
+func NewBackchannelAuthenticationRepository() *BackchannelAuthenticationRepository {
+	return &BackchannelAuthenticationRepository{
+		requests:    make(map[uuid.UUID]*entity.BackchannelAuthentication),
+		byAuthReqID: make(map[string]uuid.UUID),
+	}
+}
+
+// Create сохраняет новый запрос; auth_req_id должен быть уникален
+func (r *BackchannelAuthenticationRepository) Create(ctx context.Context, request *entity.BackchannelAuthentication) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.requests[request.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if _, exists := r.byAuthReqID[request.AuthReqID]; exists {
+		return ports.ErrAlreadyExists
+	}
+
+	r.requests[request.ID] = cloneBackchannelAuthentication(request)
+	r.byAuthReqID[request.AuthReqID] = request.ID
+	return nil
+}
+
+// GetByID возвращает запрос по идентификатору
+func (r *BackchannelAuthenticationRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.BackchannelAuthentication, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return cloneBackchannelAuthentication(request), nil
+}
+
+// GetByAuthReqID возвращает запрос по auth_req_id
+func (r *BackchannelAuthenticationRepository) GetByAuthReqID(ctx context.Context, authReqID string) (*entity.BackchannelAuthentication, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byAuthReqID[authReqID]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return cloneBackchannelAuthentication(r.requests[id]), nil
+}
+
+// Update сохраняет изменения запроса; auth_req_id не изменяется
+func (r *BackchannelAuthenticationRepository) Update(ctx context.Context, request *entity.BackchannelAuthentication) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.requests[request.ID]
+	if !exists {
+		return ports.ErrNotFound
+	}
+	clone := cloneBackchannelAuthentication(request)
+	clone.AuthReqID = existing.AuthReqID
+	r.requests[request.ID] = clone
+	return nil
+}
+
+// Delete удаляет запрос
+func (r *BackchannelAuthenticationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.requests[id]
+	if !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.byAuthReqID, existing.AuthReqID)
+	delete(r.requests, id)
+	return nil
+}
+
+func cloneBackchannelAuthentication(request *entity.BackchannelAuthentication) *entity.BackchannelAuthentication {
+	clone := *request
+	clone.Scopes = append([]string(nil), request.Scopes...)
+	if request.LastPolledAt != nil {
+		lastPolledAt := *request.LastPolledAt
+		clone.LastPolledAt = &lastPolledAt
+	}
+	return &clone
+}
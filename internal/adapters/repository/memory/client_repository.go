@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ClientRepository хранилище OAuth клиентов в памяти
+type ClientRepository struct {
+	mu      sync.RWMutex
+	clients map[uuid.UUID]entity.Client
+}
+
+var _ ports.ClientRepository = (*ClientRepository)(nil)
+
+// NewClientRepository создает новое хранилище клиентов в памяти
+func NewClientRepository() *ClientRepository {
+	return &ClientRepository{
+		clients: make(map[uuid.UUID]entity.Client),
+	}
+}
+
+// Create сохраняет нового клиента
+func (r *ClientRepository) Create(ctx context.Context, client *entity.Client) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[client.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if r.clientIDTaken(client.ClientID, client.ID) {
+		return ports.ErrAlreadyExists
+	}
+	r.clients[client.ID] = *client.Clone()
+	return nil
+}
+
+// GetByID возвращает клиента по идентификатору
+func (r *ClientRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return client.Clone(), nil
+}
+
+// GetByClientID возвращает клиента по публичному идентификатору client_id
+func (r *ClientRepository) GetByClientID(ctx context.Context, clientID string) (*entity.Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, client := range r.clients {
+		if client.ClientID == clientID {
+			return client.Clone(), nil
+		}
+	}
+	return nil, ports.ErrNotFound
+}
+
+// Update сохраняет изменения клиента
+func (r *ClientRepository) Update(ctx context.Context, client *entity.Client) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[client.ID]; !exists {
+		return ports.ErrNotFound
+	}
+	if r.clientIDTaken(client.ClientID, client.ID) {
+		return ports.ErrAlreadyExists
+	}
+	r.clients[client.ID] = *client.Clone()
+	return nil
+}
+
+// Delete удаляет клиента
+func (r *ClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.clients[id]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.clients, id)
+	return nil
+}
+
+// List возвращает страницу клиентов по фильтру
+func (r *ClientRepository) List(ctx context.Context, filter ports.ClientFilter, page ports.Pagination) ([]*entity.Client, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*entity.Client, 0)
+	for _, client := range r.clients {
+		if filter.Name != "" && !containsFold(client.Name, filter.Name) {
+			continue
+		}
+		if filter.Active != nil && client.Active != *filter.Active {
+			continue
+		}
+		if filter.GrantType != "" && !client.IsGrantTypeAllowed(filter.GrantType) {
+			continue
+		}
+		matched = append(matched, client.Clone())
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return lessByCreation(matched[i].CreatedAt, matched[j].CreatedAt, matched[i].ID, matched[j].ID)
+	})
+
+	start, end := page.Bounds(len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+// clientIDTaken проверяет, занят ли client_id другим клиентом; вызывается под блокировкой
+func (r *ClientRepository) clientIDTaken(clientID string, id uuid.UUID) bool {
+	for _, client := range r.clients {
+		if client.ID != id && client.ClientID == clientID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// consentKey пара пользователь — клиент, которой принадлежит согласие
+type consentKey struct {
+	userID   uuid.UUID
+	clientID uuid.UUID
+}
+
+// ConsentRepository хранилище согласий пользователей в памяти
+type ConsentRepository struct {
+	mu       sync.RWMutex
+	consents map[consentKey]*entity.Consent
+}
+
+var _ ports.ConsentRepository = (*ConsentRepository)(nil)
+
+// NewConsentRepository создает новое хранилище согласий
+func NewConsentRepository() *ConsentRepository {
+	return &ConsentRepository{
+		consents: make(map[consentKey]*entity.Consent),
+	}
+}
+
+// Save создает согласие или заменяет согласие той же пары пользователь — клиент
+func (r *ConsentRepository) Save(ctx context.Context, consent *entity.Consent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.consents[consentKey{userID: consent.UserID, clientID: consent.ClientID}] = consent.Clone()
+	return nil
+}
+
+// Get возвращает согласие пользователя на доступ клиента
+func (r *ConsentRepository) Get(ctx context.Context, userID, clientID uuid.UUID) (*entity.Consent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	consent, ok := r.consents[consentKey{userID: userID, clientID: clientID}]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return consent.Clone(), nil
+}
+
+// ListByUser возвращает согласия пользователя, упорядоченные по времени создания
+func (r *ConsentRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Consent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	consents := make([]*entity.Consent, 0)
+	for key, consent := range r.consents {
+		if key.userID == userID {
+			consents = append(consents, consent.Clone())
+		}
+	}
+
+	sort.Slice(consents, func(i, j int) bool {
+		return lessByCreation(consents[i].CreatedAt, consents[j].CreatedAt, consents[i].ID, consents[j].ID)
+	})
+	return consents, nil
+}
+
+// Delete удаляет согласие пользователя на доступ клиента
+func (r *ConsentRepository) Delete(ctx context.Context, userID, clientID uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := consentKey{userID: userID, clientID: clientID}
+	if _, exists := r.consents[key]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.consents, key)
+	return nil
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DeviceAuthorizationRepository хранилище запросов авторизации устройств в памяти
+type DeviceAuthorizationRepository struct {
+	mu             sync.RWMutex
+	authorizations map[uuid.UUID]*entity.DeviceAuthorization
+	byDeviceCode   map[string]uuid.UUID
+	byUserCode     map[string]uuid.UUID
+}
+
+var _ ports.DeviceAuthorizationRepository = (*DeviceAuthorizationRepository)(nil)
+
+// NewDeviceAuthorizationRepository создает новое хранилище запросов авторизации устройств
+func NewDeviceAuthorizationRepository() *DeviceAuthorizationRepository {
+	return &DeviceAuthorizationRepository{
+		authorizations: make(map[uuid.UUID]*entity.DeviceAuthorization),
+		byDeviceCode:   make(map[string]uuid.UUID),
+		byUserCode:     make(map[string]uuid.UUID),
+	}
+}
+
+// Create сохраняет новый запрос; коды устройства и пользователя должны быть уникальны
+func (r *DeviceAuthorizationRepository) Create(ctx context.Context, authorization *entity.DeviceAuthorization) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.authorizations[authorization.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if _, exists := r.byDeviceCode[authorization.DeviceCode]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if _, exists := r.byUserCode[authorization.UserCode]; exists {
+		return ports.ErrAlreadyExists
+	}
+
+	r.authorizations[authorization.ID] = cloneDeviceAuthorization(authorization)
+	r.byDeviceCode[authorization.DeviceCode] = authorization.ID
+	r.byUserCode[authorization.UserCode] = authorization.ID
+	return nil
+}
+
+// GetByDeviceCode возвращает запрос по коду устройства
+func (r *DeviceAuthorizationRepository) GetByDeviceCode(ctx context.Context, deviceCode string) (*entity.DeviceAuthorization, error) {
+	return r.getByIndex(ctx, r.byDeviceCode, deviceCode)
+}
+
+// GetByUserCode возвращает запрос по коду пользователя
+func (r *DeviceAuthorizationRepository) GetByUserCode(ctx context.Context, userCode string) (*entity.DeviceAuthorization, error) {
+	return r.getByIndex(ctx, r.byUserCode, userCode)
+}
+
+func (r *DeviceAuthorizationRepository) getByIndex(ctx context.Context, index map[string]uuid.UUID, key string) (*entity.DeviceAuthorization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := index[key]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return cloneDeviceAuthorization(r.authorizations[id]), nil
+}
+
+// Update сохраняет изменения запроса; коды и решение пользователя не изменяются,
+// поэтому опрос устройства не затирает одновременно принятое решение
+func (r *DeviceAuthorizationRepository) Update(ctx context.Context, authorization *entity.DeviceAuthorization) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.authorizations[authorization.ID]
+	if !exists {
+		return ports.ErrNotFound
+	}
+	clone := cloneDeviceAuthorization(authorization)
+	clone.DeviceCode = existing.DeviceCode
+	clone.UserCode = existing.UserCode
+	clone.Status = existing.Status
+	clone.UserID = existing.UserID
+	r.authorizations[authorization.ID] = clone
+	return nil
+}
+
+// Decide сохраняет решение пользователя, если запрос все еще ожидает решения
+func (r *DeviceAuthorizationRepository) Decide(ctx context.Context, authorization *entity.DeviceAuthorization) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.authorizations[authorization.ID]
+	if !exists || !existing.IsPending() {
+		return ports.ErrNotFound
+	}
+	existing.Status = authorization.Status
+	existing.UserID = authorization.UserID
+	return nil
+}
+
+// Delete удаляет запрос
+func (r *DeviceAuthorizationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.authorizations[id]
+	if !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.byDeviceCode, existing.DeviceCode)
+	delete(r.byUserCode, existing.UserCode)
+	delete(r.authorizations, id)
+	return nil
+}
+
+func cloneDeviceAuthorization(authorization *entity.DeviceAuthorization) *entity.DeviceAuthorization {
+	clone := *authorization
+	clone.Scopes = append([]string(nil), authorization.Scopes...)
+	if authorization.LastPolledAt != nil {
+		lastPolledAt := *authorization.LastPolledAt
+		clone.LastPolledAt = &lastPolledAt
+	}
+	return &clone
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"sync"
+)
+
+// EventOutbox доменные события, записанные процессом без подписчиков
+// (например, authctl). Очередь сохраняется в снимок Store, и сервис,
+// загрузивший то же хранилище, доставляет события своим подписчикам.
+type EventOutbox struct {
+	mu     sync.Mutex
+	events []entity.DomainEvent
+}
+
+// NewEventOutbox создает пустую очередь событий
+func NewEventOutbox() *EventOutbox {
+	return &EventOutbox{}
+}
+
+// Append добавляет события в очередь
+func (o *EventOutbox) Append(events ...entity.DomainEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, events...)
+}
+
+// Drain возвращает накопленные события и очищает очередь
+func (o *EventOutbox) Drain() []entity.DomainEvent {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	events := o.events
+	o.events = nil
+	return events
+}
@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// lessByCreation задает стабильный порядок записей для постраничной выборки
+func lessByCreation(a, b time.Time, idA, idB uuid.UUID) bool {
+	if !a.Equal(b) {
+		return a.Before(b)
+	}
+	return bytes.Compare(idA[:], idB[:]) < 0
+}
+
+// containsFold проверяет вхождение подстроки без учета регистра
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+)
+
+// PendingAuthorizationRepository хранилище ожидающих запросов авторизации в памяти
+type PendingAuthorizationRepository struct {
+	mu      sync.RWMutex
+	pending map[string]*entity.PendingAuthorization
+}
+
+var _ ports.PendingAuthorizationRepository = (*PendingAuthorizationRepository)(nil)
+
+// NewPendingAuthorizationRepository создает новое хранилище ожидающих запросов авторизации
+func NewPendingAuthorizationRepository() *PendingAuthorizationRepository {
+	return &PendingAuthorizationRepository{
+		pending: make(map[string]*entity.PendingAuthorization),
+	}
+}
+
+// Create сохраняет новый запрос
+func (r *PendingAuthorizationRepository) Create(ctx context.Context, pending *entity.PendingAuthorization) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.pending[pending.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	r.pending[pending.ID] = clonePendingAuthorization(pending)
+	return nil
+}
+
+// GetByID возвращает запрос по идентификатору
+func (r *PendingAuthorizationRepository) GetByID(ctx context.Context, id string) (*entity.PendingAuthorization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pending, ok := r.pending[id]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return clonePendingAuthorization(pending), nil
+}
+
+// Delete удаляет запрос
+func (r *PendingAuthorizationRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.pending[id]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.pending, id)
+	return nil
+}
+
+func clonePendingAuthorization(pending *entity.PendingAuthorization) *entity.PendingAuthorization {
+	clone := *pending
+	clone.Request = pending.Request.Clone()
+	return &clone
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// PermissionRepository хранилище разрешений в памяти
+type PermissionRepository struct {
+	mu          sync.RWMutex
+	permissions map[uuid.UUID]entity.Permission
+}
+
+var _ ports.PermissionRepository = (*PermissionRepository)(nil)
+
+// NewPermissionRepository создает новое хранилище разрешений в памяти
+func NewPermissionRepository() *PermissionRepository {
+	return &PermissionRepository{
+		permissions: make(map[uuid.UUID]entity.Permission),
+	}
+}
+
+// Create сохраняет новое разрешение
+func (r *PermissionRepository) Create(ctx context.Context, permission *entity.Permission) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.permissions[permission.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	r.permissions[permission.ID] = *permission
+	return nil
+}
+
+// GetByID возвращает разрешение по идентификатору
+func (r *PermissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Permission, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	permission, ok := r.permissions[id]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return &permission, nil
+}
+
+// Update сохраняет изменения разрешения
+func (r *PermissionRepository) Update(ctx context.Context, permission *entity.Permission) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.permissions[permission.ID]; !exists {
+		return ports.ErrNotFound
+	}
+	r.permissions[permission.ID] = *permission
+	return nil
+}
+
+// Delete удаляет разрешение
+func (r *PermissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.permissions[id]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.permissions, id)
+	return nil
+}
+
+// List возвращает страницу разрешений по фильтру
+func (r *PermissionRepository) List(ctx context.Context, filter ports.PermissionFilter, page ports.Pagination) ([]*entity.Permission, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*entity.Permission, 0)
+	for _, permission := range r.permissions {
+		if filter.Resource != "" && permission.Resource != filter.Resource {
+			continue
+		}
+		if filter.Action != "" && permission.Action != filter.Action {
+			continue
+		}
+		if filter.Effect == entity.EffectDeny && !permission.IsDeny() {
+			continue
+		}
+		if filter.Effect == entity.EffectAllow && permission.IsDeny() {
+			continue
+		}
+		permission := permission
+		matched = append(matched, &permission)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return lessByCreation(matched[i].CreatedAt, matched[j].CreatedAt, matched[i].ID, matched[j].ID)
+	})
+
+	start, end := page.Bounds(len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+// get возвращает разрешение без проверки контекста; используется другими хранилищами
+func (r *PermissionRepository) get(id uuid.UUID) (entity.Permission, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	permission, ok := r.permissions[id]
+	return permission, ok
+}
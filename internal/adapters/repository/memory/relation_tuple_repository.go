@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// relationTupleRecord хранит отношение вместе с ревизиями его создания и удаления
+type relationTupleRecord struct {
+	tuple           entity.RelationTuple
+	createdRevision uint64
+	deletedRevision uint64
+}
+
+func (r *relationTupleRecord) visibleAt(revision uint64) bool {
+	return r.createdRevision <= revision && (r.deletedRevision == 0 || r.deletedRevision > revision)
+}
+
+// RelationTupleRepository хранилище отношений в памяти с поддержкой снимков по ревизиям
+type RelationTupleRepository struct {
+	mu       sync.RWMutex
+	revision uint64
+	records  []*relationTupleRecord
+}
+
+var _ ports.RelationTupleRepository = (*RelationTupleRepository)(nil)
+
+// NewRelationTupleRepository создает новое хранилище отношений в памяти
+func NewRelationTupleRepository() *RelationTupleRepository {
+	return &RelationTupleRepository{}
+}
+
+// Write атомарно добавляет и удаляет отношения
+func (r *RelationTupleRepository) Write(ctx context.Context, inserts []entity.RelationTuple, deletes []entity.RelationTuple) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revision := r.revision + 1
+
+	live := make(map[string]*relationTupleRecord)
+	for _, record := range r.records {
+		if record.deletedRevision == 0 {
+			live[record.tuple.String()] = record
+		}
+	}
+
+	for _, tuple := range deletes {
+		if _, ok := live[tuple.String()]; !ok {
+			return 0, fmt.Errorf("relation tuple not found: %s", tuple)
+		}
+	}
+
+	for _, tuple := range deletes {
+		key := tuple.String()
+		live[key].deletedRevision = revision
+		delete(live, key)
+	}
+
+	for _, tuple := range inserts {
+		key := tuple.String()
+		if _, ok := live[key]; ok {
+			continue
+		}
+		record := &relationTupleRecord{tuple: tuple, createdRevision: revision}
+		r.records = append(r.records, record)
+		live[key] = record
+	}
+
+	r.revision = revision
+	return revision, nil
+}
+
+// Read возвращает отношения, существовавшие на указанной ревизии
+func (r *RelationTupleRepository) Read(ctx context.Context, filter ports.RelationTupleFilter, revision uint64) ([]entity.RelationTuple, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if revision > r.revision {
+		return nil, fmt.Errorf("revision %d is not available yet", revision)
+	}
+
+	var tuples []entity.RelationTuple
+	for _, record := range r.records {
+		if !record.visibleAt(revision) || !matchesRelationTupleFilter(record.tuple, filter) {
+			continue
+		}
+		tuples = append(tuples, record.tuple)
+	}
+	return tuples, nil
+}
+
+// CurrentRevision возвращает последнюю зафиксированную ревизию
+func (r *RelationTupleRepository) CurrentRevision(ctx context.Context) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.revision, nil
+}
+
+func matchesRelationTupleFilter(tuple entity.RelationTuple, filter ports.RelationTupleFilter) bool {
+	if filter.Namespace != "" && tuple.Object.Namespace != filter.Namespace {
+		return false
+	}
+	if filter.ObjectID != "" && tuple.Object.ID != filter.ObjectID {
+		return false
+	}
+	if filter.Relation != "" && tuple.Relation != filter.Relation {
+		return false
+	}
+	if filter.Subject != nil && tuple.Subject != *filter.Subject {
+		return false
+	}
+	return true
+}
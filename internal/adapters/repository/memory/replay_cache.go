@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sync"
+	"time"
+)
+
+// replayCachePurgeInterval как часто удаляются истекшие идентификаторы
+const replayCachePurgeInterval = time.Minute
+
+// ReplayCache кеш одноразовых идентификаторов в памяти. Идентификаторы нужны
+// только до истечения срока, поэтому в снимок Store кеш не попадает.
+type ReplayCache struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	nextPurge time.Time
+}
+
+var _ ports.ReplayCache = (*ReplayCache)(nil)
+
+// NewReplayCache создает новый кеш одноразовых идентификаторов в памяти
+func NewReplayCache() *ReplayCache {
+	return &ReplayCache{
+		keys: make(map[string]time.Time),
+	}
+}
+
+// Use отмечает идентификатор использованным
+func (c *ReplayCache) Use(ctx context.Context, key string, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.After(c.nextPurge) {
+		for k, exp := range c.keys {
+			if now.After(exp) {
+				delete(c.keys, k)
+			}
+		}
+		c.nextPurge = now.Add(replayCachePurgeInterval)
+	}
+
+	if exp, exists := c.keys[key]; exists && !now.After(exp) {
+		return ports.ErrAlreadyExists
+	}
+	c.keys[key] = expiresAt
+	return nil
+}
@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// roleRecord хранит роль со ссылками на разрешения, как это сделала бы реляционная БД
+type roleRecord struct {
+	role          entity.Role
+	permissionIDs []uuid.UUID
+}
+
+// RoleRepository хранилище ролей в памяти. Разрешения ролей разрешаются
+// через PermissionRepository при чтении, поэтому изменения и удаления
+// разрешений сразу видны во всех ролях.
+type RoleRepository struct {
+	mu          sync.RWMutex
+	roles       map[uuid.UUID]*roleRecord
+	permissions *PermissionRepository
+}
+
+var _ ports.RoleRepository = (*RoleRepository)(nil)
+
+// NewRoleRepository создает новое хранилище ролей в памяти
+func NewRoleRepository(permissions *PermissionRepository) *RoleRepository {
+	return &RoleRepository{
+		roles:       make(map[uuid.UUID]*roleRecord),
+		permissions: permissions,
+	}
+}
+
+// Create сохраняет новую роль
+func (r *RoleRepository) Create(ctx context.Context, role *entity.Role) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if r.nameTaken(role.Name, role.ID) {
+		return ports.ErrAlreadyExists
+	}
+	r.roles[role.ID] = newRoleRecord(role)
+	return nil
+}
+
+// GetByID возвращает роль по идентификатору
+func (r *RoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	role, ok := r.get(id)
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return &role, nil
+}
+
+// GetByName возвращает роль по имени
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.roles {
+		if strings.EqualFold(record.role.Name, name) {
+			role := r.hydrate(record)
+			return &role, nil
+		}
+	}
+	return nil, ports.ErrNotFound
+}
+
+// Update сохраняет изменения роли
+func (r *RoleRepository) Update(ctx context.Context, role *entity.Role) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.ID]; !exists {
+		return ports.ErrNotFound
+	}
+	if r.nameTaken(role.Name, role.ID) {
+		return ports.ErrAlreadyExists
+	}
+	r.roles[role.ID] = newRoleRecord(role)
+	return nil
+}
+
+// Delete удаляет роль
+func (r *RoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[id]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.roles, id)
+	return nil
+}
+
+// List возвращает страницу ролей по фильтру
+func (r *RoleRepository) List(ctx context.Context, filter ports.RoleFilter, page ports.Pagination) ([]*entity.Role, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*entity.Role, 0)
+	for _, record := range r.roles {
+		if filter.Name != "" && !containsFold(record.role.Name, filter.Name) {
+			continue
+		}
+		role := r.hydrate(record)
+		if filter.PermissionID != nil && !role.HasPermission(*filter.PermissionID) {
+			continue
+		}
+		matched = append(matched, &role)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return lessByCreation(matched[i].CreatedAt, matched[j].CreatedAt, matched[i].ID, matched[j].ID)
+	})
+
+	start, end := page.Bounds(len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+// get возвращает роль без проверки контекста; используется другими хранилищами
+func (r *RoleRepository) get(id uuid.UUID) (entity.Role, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.roles[id]
+	if !ok {
+		return entity.Role{}, false
+	}
+	return r.hydrate(record), true
+}
+
+// nameTaken проверяет, занято ли имя другой ролью; вызывается под блокировкой
+func (r *RoleRepository) nameTaken(name string, id uuid.UUID) bool {
+	for _, record := range r.roles {
+		if record.role.ID != id && strings.EqualFold(record.role.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hydrate восстанавливает роль с актуальными разрешениями
+func (r *RoleRepository) hydrate(record *roleRecord) entity.Role {
+	role := *record.role.Clone()
+	if r.permissions == nil {
+		return role
+	}
+
+	role.Permissions = make([]entity.Permission, 0, len(record.permissionIDs))
+	for _, id := range record.permissionIDs {
+		if permission, ok := r.permissions.get(id); ok {
+			role.Permissions = append(role.Permissions, permission)
+		}
+	}
+	return role
+}
+
+func newRoleRecord(role *entity.Role) *roleRecord {
+	record := &roleRecord{
+		role:          *role.Clone(),
+		permissionIDs: make([]uuid.UUID, 0, len(role.Permissions)),
+	}
+	for _, permission := range role.Permissions {
+		record.permissionIDs = append(record.permissionIDs, permission.ID)
+	}
+	return record
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"sync"
+)
+
+// SessionRepository хранилище сессий в памяти
+type SessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]entity.Session
+}
+
+var _ ports.SessionRepository = (*SessionRepository)(nil)
+
+// NewSessionRepository создает новое хранилище сессий в памяти
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		sessions: make(map[string]entity.Session),
+	}
+}
+
+// Create сохраняет новую сессию
+func (r *SessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// GetByID возвращает сессию по идентификатору
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*entity.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return &session, nil
+}
+
+// Update сохраняет изменения сессии
+func (r *SessionRepository) Update(ctx context.Context, session *entity.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; !exists {
+		return ports.ErrNotFound
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// ListByUser возвращает все сессии пользователя
+func (r *SessionRepository) ListByUser(ctx context.Context, userID string) ([]*entity.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]*entity.Session, 0)
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"sync"
+)
+
+// SigningKeyRepository хранилище ключей подписи в памяти
+type SigningKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]*entity.SigningKey
+}
+
+var _ ports.SigningKeyRepository = (*SigningKeyRepository)(nil)
+
+// NewSigningKeyRepository создает новое хранилище ключей подписи в памяти
+func NewSigningKeyRepository() *SigningKeyRepository {
+	return &SigningKeyRepository{
+		keys: make(map[string]*entity.SigningKey),
+	}
+}
+
+// Create сохраняет новый ключ
+func (r *SigningKeyRepository) Create(ctx context.Context, key *entity.SigningKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	r.keys[key.ID] = cloneSigningKey(key)
+	return nil
+}
+
+// GetByID возвращает ключ по идентификатору kid
+func (r *SigningKeyRepository) GetByID(ctx context.Context, kid string) (*entity.SigningKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return cloneSigningKey(key), nil
+}
+
+// Update сохраняет изменения ключа
+func (r *SigningKeyRepository) Update(ctx context.Context, key *entity.SigningKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; !exists {
+		return ports.ErrNotFound
+	}
+	r.keys[key.ID] = cloneSigningKey(key)
+	return nil
+}
+
+// Delete удаляет ключ
+func (r *SigningKeyRepository) Delete(ctx context.Context, kid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[kid]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.keys, kid)
+	return nil
+}
+
+// List возвращает все ключи, упорядоченные по времени создания
+func (r *SigningKeyRepository) List(ctx context.Context) ([]*entity.SigningKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*entity.SigningKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, cloneSigningKey(key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+func cloneSigningKey(key *entity.SigningKey) *entity.SigningKey {
+	clone := *key
+	clone.PrivateKey = append([]byte(nil), key.PrivateKey...)
+	clone.PublicKey = append([]byte(nil), key.PublicKey...)
+	if key.RetiredAt != nil {
+		retiredAt := *key.RetiredAt
+		clone.RetiredAt = &retiredAt
+	}
+	return &clone
+}
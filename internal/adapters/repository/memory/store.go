@@ -0,0 +1,356 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store объединяет связанные хранилища в памяти. Состояние Store можно
+// сериализовать в JSON и восстановить, что позволяет сохранять его на диск.
+type Store struct {
+	Permissions *PermissionRepository
+	Roles       *RoleRepository
+	Users       *UserRepository
+	Clients     *ClientRepository
+	Tokens      *TokenRepository
+	Sessions    *SessionRepository
+	SigningKeys *SigningKeyRepository
+	AuditLogs   *AuditLogRepository
+	Consents    *ConsentRepository
+
+	DeviceAuthorizations  *DeviceAuthorizationRepository
+	AuthCodes             *AuthCodeRepository
+	PendingAuthorizations *PendingAuthorizationRepository
+
+	BackchannelAuthentications *BackchannelAuthenticationRepository
+
+	// Events доменные события для процесса сервиса, например изменения ролей,
+	// сделанные authctl
+	Events *EventOutbox
+
+	// Replays одноразовые идентификаторы; не сохраняются в снимок
+	Replays *ReplayCache
+}
+
+// NewStore создает пустой набор связанных хранилищ
+func NewStore() *Store {
+	permissions := NewPermissionRepository()
+	roles := NewRoleRepository(permissions)
+	return &Store{
+		Permissions: permissions,
+		Roles:       roles,
+		Users:       NewUserRepository(roles),
+		Clients:     NewClientRepository(),
+		Tokens:      NewTokenRepository(),
+		Sessions:    NewSessionRepository(),
+		SigningKeys: NewSigningKeyRepository(),
+		AuditLogs:   NewAuditLogRepository(),
+		Consents:    NewConsentRepository(),
+
+		DeviceAuthorizations:  NewDeviceAuthorizationRepository(),
+		AuthCodes:             NewAuthCodeRepository(),
+		PendingAuthorizations: NewPendingAuthorizationRepository(),
+
+		BackchannelAuthentications: NewBackchannelAuthenticationRepository(),
+
+		Events:  NewEventOutbox(),
+		Replays: NewReplayCache(),
+	}
+}
+
+// snapshot сериализуемое состояние Store. Поля, скрытые в JSON представлении
+// сущностей (хеш пароля, хеши секретов клиента, закрытый ключ), сохраняются явно.
+type snapshot struct {
+	Permissions []entity.Permission  `json:"permissions"`
+	Roles       []roleSnapshot       `json:"roles"`
+	Users       []userSnapshot       `json:"users"`
+	Clients     []clientSnapshot     `json:"clients"`
+	Tokens      []entity.Token       `json:"tokens"`
+	Sessions    []entity.Session     `json:"sessions"`
+	SigningKeys []signingKeySnapshot `json:"signing_keys"`
+	AuditLogs   []entity.AuditLog    `json:"audit_logs"`
+	Consents    []entity.Consent     `json:"consents,omitempty"`
+
+	DeviceAuthorizations  []entity.DeviceAuthorization  `json:"device_authorizations,omitempty"`
+	AuthCodes             []entity.AuthCode             `json:"auth_codes,omitempty"`
+	PendingAuthorizations []entity.PendingAuthorization `json:"pending_authorizations,omitempty"`
+
+	BackchannelAuthentications []entity.BackchannelAuthentication `json:"backchannel_authentications,omitempty"`
+
+	Events []entity.DomainEvent `json:"events,omitempty"`
+}
+
+type roleSnapshot struct {
+	ID            uuid.UUID   `json:"id"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description,omitempty"`
+	PermissionIDs []uuid.UUID `json:"permission_ids"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+type userSnapshot struct {
+	ID           uuid.UUID              `json:"id"`
+	Email        string                 `json:"email"`
+	PasswordHash string                 `json:"password_hash"`
+	FirstName    string                 `json:"first_name"`
+	LastName     string                 `json:"last_name"`
+	Active       bool                   `json:"active"`
+	RoleIDs      []uuid.UUID            `json:"role_ids"`
+	Permissions  []string               `json:"permissions,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	LastLoginAt  *time.Time             `json:"last_login_at,omitempty"`
+}
+
+type clientSnapshot struct {
+	entity.Client
+	SecretHashes          []string `json:"secret_hashes"`
+	SealedSecrets         []string `json:"sealed_secrets,omitempty"`
+	RegistrationTokenHash string   `json:"registration_token_hash,omitempty"`
+}
+
+type signingKeySnapshot struct {
+	entity.SigningKey
+	Private []byte `json:"private_key"`
+}
+
+// MarshalJSON сериализует состояние всех хранилищ
+func (s *Store) MarshalJSON() ([]byte, error) {
+	var snap snapshot
+
+	s.Permissions.mu.RLock()
+	for _, permission := range s.Permissions.permissions {
+		snap.Permissions = append(snap.Permissions, permission)
+	}
+	s.Permissions.mu.RUnlock()
+
+	s.Roles.mu.RLock()
+	for _, record := range s.Roles.roles {
+		snap.Roles = append(snap.Roles, roleSnapshot{
+			ID:            record.role.ID,
+			Name:          record.role.Name,
+			Description:   record.role.Description,
+			PermissionIDs: record.permissionIDs,
+			CreatedAt:     record.role.CreatedAt,
+			UpdatedAt:     record.role.UpdatedAt,
+		})
+	}
+	s.Roles.mu.RUnlock()
+
+	s.Users.mu.RLock()
+	for _, record := range s.Users.users {
+		user := record.user
+		snap.Users = append(snap.Users, userSnapshot{
+			ID:           user.ID,
+			Email:        user.Email,
+			PasswordHash: user.Password,
+			FirstName:    user.FirstName,
+			LastName:     user.LastName,
+			Active:       user.Active,
+			RoleIDs:      record.roleIDs,
+			Permissions:  user.Permissions,
+			Attributes:   user.Attributes,
+			CreatedAt:    user.CreatedAt,
+			UpdatedAt:    user.UpdatedAt,
+			LastLoginAt:  user.LastLoginAt,
+		})
+	}
+	s.Users.mu.RUnlock()
+
+	s.Clients.mu.RLock()
+	for _, client := range s.Clients.clients {
+		hashes := make([]string, 0, len(client.Secrets))
+		var sealed []string
+		for i, secret := range client.Secrets {
+			hashes = append(hashes, secret.Hash)
+			if secret.Sealed != "" {
+				if sealed == nil {
+					sealed = make([]string, len(client.Secrets))
+				}
+				sealed[i] = secret.Sealed
+			}
+		}
+		snap.Clients = append(snap.Clients, clientSnapshot{
+			Client:                client,
+			SecretHashes:          hashes,
+			SealedSecrets:         sealed,
+			RegistrationTokenHash: client.RegistrationAccessTokenHash,
+		})
+	}
+	s.Clients.mu.RUnlock()
+
+	s.Tokens.mu.RLock()
+	for _, token := range s.Tokens.tokens {
+		snap.Tokens = append(snap.Tokens, *token)
+	}
+	s.Tokens.mu.RUnlock()
+
+	s.Sessions.mu.RLock()
+	for _, session := range s.Sessions.sessions {
+		snap.Sessions = append(snap.Sessions, session)
+	}
+	s.Sessions.mu.RUnlock()
+
+	s.SigningKeys.mu.RLock()
+	for _, key := range s.SigningKeys.keys {
+		snap.SigningKeys = append(snap.SigningKeys, signingKeySnapshot{SigningKey: *key, Private: key.PrivateKey})
+	}
+	s.SigningKeys.mu.RUnlock()
+
+	s.AuditLogs.mu.RLock()
+	for _, auditLog := range s.AuditLogs.logs {
+		snap.AuditLogs = append(snap.AuditLogs, *auditLog)
+	}
+	s.AuditLogs.mu.RUnlock()
+
+	s.Consents.mu.RLock()
+	for _, consent := range s.Consents.consents {
+		snap.Consents = append(snap.Consents, *consent)
+	}
+	s.Consents.mu.RUnlock()
+
+	s.DeviceAuthorizations.mu.RLock()
+	for _, authorization := range s.DeviceAuthorizations.authorizations {
+		snap.DeviceAuthorizations = append(snap.DeviceAuthorizations, *authorization)
+	}
+	s.DeviceAuthorizations.mu.RUnlock()
+
+	s.AuthCodes.mu.RLock()
+	for _, code := range s.AuthCodes.codes {
+		snap.AuthCodes = append(snap.AuthCodes, *code)
+	}
+	s.AuthCodes.mu.RUnlock()
+
+	s.PendingAuthorizations.mu.RLock()
+	for _, pending := range s.PendingAuthorizations.pending {
+		snap.PendingAuthorizations = append(snap.PendingAuthorizations, *pending)
+	}
+	s.PendingAuthorizations.mu.RUnlock()
+
+	s.BackchannelAuthentications.mu.RLock()
+	for _, request := range s.BackchannelAuthentications.requests {
+		snap.BackchannelAuthentications = append(snap.BackchannelAuthentications, *request)
+	}
+	s.BackchannelAuthentications.mu.RUnlock()
+
+	s.Events.mu.Lock()
+	snap.Events = append(snap.Events, s.Events.events...)
+	s.Events.mu.Unlock()
+
+	return json.Marshal(snap)
+}
+
+// UnmarshalJSON заменяет состояние всех хранилищ сохраненным снимком
+func (s *Store) UnmarshalJSON(data []byte) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decode store snapshot: %w", err)
+	}
+
+	restored := NewStore()
+
+	for _, permission := range snap.Permissions {
+		restored.Permissions.permissions[permission.ID] = permission
+	}
+	for _, role := range snap.Roles {
+		restored.Roles.roles[role.ID] = &roleRecord{
+			role: entity.Role{
+				ID:          role.ID,
+				Name:        role.Name,
+				Description: role.Description,
+				Permissions: make([]entity.Permission, 0),
+				CreatedAt:   role.CreatedAt,
+				UpdatedAt:   role.UpdatedAt,
+			},
+			permissionIDs: role.PermissionIDs,
+		}
+	}
+	for _, user := range snap.Users {
+		restored.Users.users[user.ID] = &userRecord{
+			user: entity.User{
+				ID:          user.ID,
+				Email:       user.Email,
+				Password:    user.PasswordHash,
+				FirstName:   user.FirstName,
+				LastName:    user.LastName,
+				Active:      user.Active,
+				Roles:       make([]entity.Role, 0),
+				Permissions: user.Permissions,
+				Attributes:  user.Attributes,
+				CreatedAt:   user.CreatedAt,
+				UpdatedAt:   user.UpdatedAt,
+				LastLoginAt: user.LastLoginAt,
+			},
+			roleIDs: user.RoleIDs,
+		}
+	}
+	for _, record := range snap.Clients {
+		client := record.Client
+		if len(record.SecretHashes) != len(client.Secrets) {
+			return fmt.Errorf("client %s: secret hashes do not match secrets", client.ClientID)
+		}
+		if record.SealedSecrets != nil && len(record.SealedSecrets) != len(client.Secrets) {
+			return fmt.Errorf("client %s: sealed secrets do not match secrets", client.ClientID)
+		}
+		for i := range client.Secrets {
+			client.Secrets[i].Hash = record.SecretHashes[i]
+			if record.SealedSecrets != nil {
+				client.Secrets[i].Sealed = record.SealedSecrets[i]
+			}
+		}
+		client.RegistrationAccessTokenHash = record.RegistrationTokenHash
+		client.ApplyDefaults()
+		restored.Clients.clients[client.ID] = client
+	}
+	for i := range snap.Tokens {
+		token := snap.Tokens[i]
+		restored.Tokens.tokens[token.ID] = &token
+		restored.Tokens.byValue[token.Value] = token.ID
+	}
+	for _, session := range snap.Sessions {
+		restored.Sessions.sessions[session.ID] = session
+	}
+	for _, record := range snap.SigningKeys {
+		key := record.SigningKey
+		key.PrivateKey = record.Private
+		restored.SigningKeys.keys[key.ID] = &key
+	}
+	for i := range snap.AuditLogs {
+		auditLog := snap.AuditLogs[i]
+		restored.AuditLogs.logs = append(restored.AuditLogs.logs, &auditLog)
+	}
+	for i := range snap.Consents {
+		consent := snap.Consents[i]
+		restored.Consents.consents[consentKey{userID: consent.UserID, clientID: consent.ClientID}] = &consent
+	}
+	for i := range snap.DeviceAuthorizations {
+		authorization := snap.DeviceAuthorizations[i]
+		restored.DeviceAuthorizations.authorizations[authorization.ID] = &authorization
+		restored.DeviceAuthorizations.byDeviceCode[authorization.DeviceCode] = authorization.ID
+		restored.DeviceAuthorizations.byUserCode[authorization.UserCode] = authorization.ID
+	}
+	for i := range snap.AuthCodes {
+		code := snap.AuthCodes[i]
+		restored.AuthCodes.codes[code.ID] = &code
+		restored.AuthCodes.byCode[code.Code] = code.ID
+	}
+	for i := range snap.PendingAuthorizations {
+		pending := snap.PendingAuthorizations[i]
+		restored.PendingAuthorizations.pending[pending.ID] = &pending
+	}
+	for i := range snap.BackchannelAuthentications {
+		request := snap.BackchannelAuthentications[i]
+		restored.BackchannelAuthentications.requests[request.ID] = &request
+		restored.BackchannelAuthentications.byAuthReqID[request.AuthReqID] = request.ID
+	}
+	restored.Events.events = snap.Events
+
+	*s = *restored
+	return nil
+}
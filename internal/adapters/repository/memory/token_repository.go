@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// TokenRepository хранилище токенов в памяти
+type TokenRepository struct {
+	mu      sync.RWMutex
+	tokens  map[uuid.UUID]*entity.Token
+	byValue map[string]uuid.UUID
+}
+
+var _ ports.TokenRepository = (*TokenRepository)(nil)
+
+// NewTokenRepository создает новое хранилище токенов в памяти
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{
+		tokens:  make(map[uuid.UUID]*entity.Token),
+		byValue: make(map[string]uuid.UUID),
+	}
+}
+
+// Create сохраняет новый токен
+func (r *TokenRepository) Create(ctx context.Context, token *entity.Token) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if _, exists := r.byValue[token.Value]; exists {
+		return ports.ErrAlreadyExists
+	}
+	r.tokens[token.ID] = cloneToken(token)
+	r.byValue[token.Value] = token.ID
+	return nil
+}
+
+// GetByValue возвращает токен по его значению
+func (r *TokenRepository) GetByValue(ctx context.Context, value string) (*entity.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byValue[value]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return cloneToken(r.tokens[id]), nil
+}
+
+// Update сохраняет изменения токена
+func (r *TokenRepository) Update(ctx context.Context, token *entity.Token) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.tokens[token.ID]
+	if !ok {
+		return ports.ErrNotFound
+	}
+	delete(r.byValue, existing.Value)
+	r.tokens[token.ID] = cloneToken(token)
+	r.byValue[token.Value] = token.ID
+	return nil
+}
+
+// Revoke отзывает действующий токен
+func (r *TokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.IsRevoked {
+		return ports.ErrNotFound
+	}
+	token.Revoke()
+	return nil
+}
+
+// RevokeFamily отзывает все действующие токены семейства
+func (r *TokenRepository) RevokeFamily(ctx context.Context, family uuid.UUID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revoked := 0
+	for _, token := range r.tokens {
+		if token.Family() == family && !token.IsRevoked {
+			token.Revoke()
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// RevokeByUserAndClient отзывает действующие токены клиента и пользователя
+func (r *TokenRepository) RevokeByUserAndClient(ctx context.Context, userID, clientID uuid.UUID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revoked := 0
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.ClientID == clientID && !token.IsRevoked {
+			token.Revoke()
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// ListByUser возвращает все токены пользователя
+func (r *TokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]*entity.Token, 0)
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, cloneToken(token))
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return lessByCreation(tokens[i].CreatedAt, tokens[j].CreatedAt, tokens[i].ID, tokens[j].ID)
+	})
+	return tokens, nil
+}
+
+func cloneToken(token *entity.Token) *entity.Token {
+	clone := *token
+	clone.Scopes = append([]string(nil), token.Scopes...)
+	clone.Audience = append([]string(nil), token.Audience...)
+	clone.Actor = token.Actor.Clone()
+	clone.Confirmation = token.Confirmation.Clone()
+	clone.AuthorizationDetails = token.AuthorizationDetails.Clone()
+	if token.RevokedAt != nil {
+		revokedAt := *token.RevokedAt
+		clone.RevokedAt = &revokedAt
+	}
+	return &clone
+}
@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// userRecord хранит пользователя со ссылками на роли, как это сделала бы реляционная БД
+type userRecord struct {
+	user    entity.User
+	roleIDs []uuid.UUID
+}
+
+// UserRepository хранилище пользователей в памяти. Роли пользователей
+// разрешаются через RoleRepository при чтении, поэтому изменения ролей
+// сразу видны у всех пользователей, которым они назначены.
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]*userRecord
+	roles *RoleRepository
+}
+
+var _ ports.UserRepository = (*UserRepository)(nil)
+
+// NewUserRepository создает новое хранилище пользователей в памяти
+func NewUserRepository(roles *RoleRepository) *UserRepository {
+	return &UserRepository{
+		users: make(map[uuid.UUID]*userRecord),
+		roles: roles,
+	}
+}
+
+// Create сохраняет нового пользователя
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.ID]; exists {
+		return ports.ErrAlreadyExists
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return ports.ErrAlreadyExists
+	}
+	r.users[user.ID] = newUserRecord(user)
+	return nil
+}
+
+// GetByID возвращает пользователя по идентификатору
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.users[id]
+	if !ok {
+		return nil, ports.ErrNotFound
+	}
+	return r.hydrate(record), nil
+}
+
+// GetByEmail возвращает пользователя по email без учета регистра
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.users {
+		if strings.EqualFold(record.user.Email, email) {
+			return r.hydrate(record), nil
+		}
+	}
+	return nil, ports.ErrNotFound
+}
+
+// Update сохраняет изменения пользователя
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.ID]; !exists {
+		return ports.ErrNotFound
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return ports.ErrAlreadyExists
+	}
+	r.users[user.ID] = newUserRecord(user)
+	return nil
+}
+
+// Delete удаляет пользователя
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[id]; !exists {
+		return ports.ErrNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// List возвращает страницу пользователей по фильтру
+func (r *UserRepository) List(ctx context.Context, filter ports.UserFilter, page ports.Pagination) ([]*entity.User, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*entity.User, 0)
+	for _, record := range r.users {
+		if filter.Email != "" && !containsFold(record.user.Email, filter.Email) {
+			continue
+		}
+		if filter.Active != nil && record.user.Active != *filter.Active {
+			continue
+		}
+		user := r.hydrate(record)
+		if filter.RoleID != nil && !user.HasRole(*filter.RoleID) {
+			continue
+		}
+		matched = append(matched, user)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return lessByCreation(matched[i].CreatedAt, matched[j].CreatedAt, matched[i].ID, matched[j].ID)
+	})
+
+	start, end := page.Bounds(len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+// emailTaken проверяет, занят ли email другим пользователем; вызывается под блокировкой
+func (r *UserRepository) emailTaken(email string, id uuid.UUID) bool {
+	for _, record := range r.users {
+		if record.user.ID != id && strings.EqualFold(record.user.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// hydrate восстанавливает пользователя с актуальными ролями
+func (r *UserRepository) hydrate(record *userRecord) *entity.User {
+	user := record.user.Clone()
+	if r.roles == nil {
+		return user
+	}
+
+	user.Roles = make([]entity.Role, 0, len(record.roleIDs))
+	for _, id := range record.roleIDs {
+		if role, ok := r.roles.get(id); ok {
+			user.Roles = append(user.Roles, role)
+		}
+	}
+	return user
+}
+
+func newUserRecord(user *entity.User) *userRecord {
+	record := &userRecord{
+		user:    *user.Clone(),
+		roleIDs: make([]uuid.UUID, 0, len(user.Roles)),
+	}
+	for _, role := range user.Roles {
+		record.roleIDs = append(record.roleIDs, role.ID)
+	}
+	return record
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"github.com/google/uuid"
 	"time"
 )
 
@@ -14,6 +15,9 @@ const (
 	AuditEventTokenRevoked   AuditEventType = "token_revoked"
 	AuditEventPasswordChange AuditEventType = "password_change"
 	AuditEventRoleChange     AuditEventType = "role_change"
+	AuditEventAccessDecision AuditEventType = "access_decision"
+	AuditEventConsentGranted AuditEventType = "consent_granted"
+	AuditEventConsentRevoked AuditEventType = "consent_revoked"
 )
 
 // AuditLog представляет запись аудита безопасности
@@ -21,7 +25,7 @@ type AuditLog struct {
 	ID          string                 `json:"id" validate:"required,uuid"`
 	UserID      string                 `json:"user_id" validate:"required,uuid"`
 	ClientID    *string                `json:"client_id,omitempty" validate:"omitempty,uuid"`
-	EventType   AuditEventType         `json:"event_type" validate:"required,oneof=login logout token_issued token_revoked password_change role_change"`
+	EventType   AuditEventType         `json:"event_type" validate:"required,oneof=login logout token_issued token_revoked password_change role_change access_decision consent_granted consent_revoked"`
 	Description string                 `json:"description" validate:"required"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 	IP          string                 `json:"ip" validate:"required,ip"`
@@ -40,6 +44,7 @@ func NewAuditLog(
 	success bool,
 ) *AuditLog {
 	return &AuditLog{
+		ID:          uuid.New().String(),
 		UserID:      userID,
 		EventType:   eventType,
 		Description: description,
@@ -5,6 +5,17 @@ import (
 	"time"
 )
 
+// Методы PKCE (RFC 7636, раздел 4.2)
+const (
+	CodeChallengeMethodPlain = "plain"
+	CodeChallengeMethodS256  = "S256"
+)
+
+// SupportedCodeChallengeMethods возвращает методы PKCE, допустимые в AuthCode.CodeMethod
+func SupportedCodeChallengeMethods() []string {
+	return []string{CodeChallengeMethodS256, CodeChallengeMethodPlain}
+}
+
 // AuthCode представляет код авторизации OAuth
 type AuthCode struct {
 	ID            uuid.UUID `json:"id" validate:"required,uuid"`
@@ -18,6 +29,13 @@ type AuthCode struct {
 	ExpiresAt     time.Time `json:"expires_at" validate:"required,gt=now"`
 	CreatedAt     time.Time `json:"created_at" validate:"required"`
 	Used          bool      `json:"used"`
+	// Resources ресурсы, одобренные вместе с кодом (RFC 8707)
+	Resources []string `json:"resources,omitempty"`
+	// AuthorizationDetails детальные права, одобренные вместе с кодом (RFC 9396)
+	AuthorizationDetails AuthorizationDetails `json:"authorization_details,omitempty"`
+	// RedirectURIRequired redirect_uri был передан в запросе авторизации и
+	// обязателен при обмене кода (RFC 6749, раздел 4.1.3)
+	RedirectURIRequired bool `json:"redirect_uri_required,omitempty"`
 }
 
 // IsExpired проверяет, истек ли срок действия кода авторизации
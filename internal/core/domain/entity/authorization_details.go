@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// AuthorizationDetail объект authorization_details (RFC 9396, раздел 2): поле
+// type и поля, которые определяет этот тип, например сумма и счет перевода
+type AuthorizationDetail map[string]interface{}
+
+// Type возвращает тип объекта
+func (d AuthorizationDetail) Type() string {
+	value, _ := d["type"].(string)
+	return value
+}
+
+// AuthorizationDetails детальные права, запрошенные клиентом вместо или вместе
+// с областями действия
+type AuthorizationDetails []AuthorizationDetail
+
+// ParseAuthorizationDetails разбирает значение параметра authorization_details:
+// JSON массив объектов с непустым строковым полем type (RFC 9396, раздел 2)
+func ParseAuthorizationDetails(raw string) (AuthorizationDetails, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var details AuthorizationDetails
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		return nil, fmt.Errorf("authorization_details must be a JSON array of objects")
+	}
+	for i, detail := range details {
+		if detail == nil || detail.Type() == "" {
+			return nil, fmt.Errorf("authorization_details[%d]: type is required", i)
+		}
+	}
+	return details, nil
+}
+
+// AuthorizationDetailsFromClaim преобразует claim authorization_details объекта
+// запроса или утверждения в детальные права
+func AuthorizationDetailsFromClaim(claim interface{}) (AuthorizationDetails, error) {
+	if claim == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(claim)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization_details: %w", err)
+	}
+	return ParseAuthorizationDetails(string(raw))
+}
+
+// Types возвращает типы объектов без повторов
+func (d AuthorizationDetails) Types() []string {
+	types := make([]string, 0, len(d))
+	seen := make(map[string]bool, len(d))
+	for _, detail := range d {
+		if !seen[detail.Type()] {
+			seen[detail.Type()] = true
+			types = append(types, detail.Type())
+		}
+	}
+	return types
+}
+
+// Contains проверяет, есть ли среди прав объект, совпадающий с detail
+func (d AuthorizationDetails) Contains(detail AuthorizationDetail) bool {
+	for _, granted := range d {
+		if reflect.DeepEqual(granted, detail) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone возвращает глубокую копию прав
+func (d AuthorizationDetails) Clone() AuthorizationDetails {
+	if d == nil {
+		return nil
+	}
+	clone := make(AuthorizationDetails, len(d))
+	for i, detail := range d {
+		clone[i] = cloneJSONValue(map[string]interface{}(detail)).(map[string]interface{})
+	}
+	return clone
+}
+
+// cloneJSONValue копирует значение, полученное из encoding/json
+func cloneJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			clone[key] = cloneJSONValue(item)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneJSONValue(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
+
+// AuthorizationDetailType тип authorization_details, который принимает сервер
+type AuthorizationDetailType struct {
+	// Type значение поля type
+	Type string `json:"type"`
+	// Description описание для страницы согласия
+	Description string `json:"description,omitempty"`
+	// Schema JSON Schema объекта; пусто — проверяется только поле type
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
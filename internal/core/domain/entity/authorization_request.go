@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestURIPrefix префикс request_uri, выдаваемых endpoint PAR (RFC 9126, раздел 2.2)
+const RequestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+// AuthorizationRequest параметры запроса к authorization endpoint
+// (RFC 6749, раздел 4.1.1; RFC 7636, раздел 4.3)
+type AuthorizationRequest struct {
+	ClientID            string       `json:"client_id"`
+	ResponseType        ResponseType `json:"response_type"`
+	RedirectURI         string       `json:"redirect_uri,omitempty"`
+	Scopes              []string     `json:"scopes,omitempty"`
+	State               string       `json:"state,omitempty"`
+	CodeChallenge       string       `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string       `json:"code_challenge_method,omitempty"`
+	// Resources ресурсы, для которых запрашиваются токены (RFC 8707, раздел 2.1)
+	Resources []string `json:"resources,omitempty"`
+	// AuthorizationDetails детальные права (RFC 9396, раздел 2)
+	AuthorizationDetails AuthorizationDetails `json:"authorization_details,omitempty"`
+	// RedirectURIDefaulted redirect_uri не был передан клиентом и подставлен
+	// единственным зарегистрированным адресом
+	RedirectURIDefaulted bool `json:"redirect_uri_defaulted,omitempty"`
+}
+
+// Clone возвращает глубокую копию запроса
+func (r AuthorizationRequest) Clone() AuthorizationRequest {
+	r.Scopes = append([]string(nil), r.Scopes...)
+	r.Resources = append([]string(nil), r.Resources...)
+	r.AuthorizationDetails = r.AuthorizationDetails.Clone()
+	return r
+}
+
+// PendingAuthorization проверенный запрос авторизации, сохраненный на сервере:
+// переданный клиентом через PAR или ожидающий решения пользователя на странице
+// согласия. Каждый запрос используется один раз.
+type PendingAuthorization struct {
+	ID       string               `json:"id"`
+	ClientID uuid.UUID            `json:"client_id"`
+	Request  AuthorizationRequest `json:"request"`
+	// Pushed запрос передан клиентом через PAR, а не получен из адресной строки
+	Pushed bool `json:"pushed,omitempty"`
+	// UserID пользователь, которому показана страница согласия; uuid.Nil у запросов PAR
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RequestURI возвращает request_uri запроса, переданного через PAR
+func (p *PendingAuthorization) RequestURI() string {
+	return RequestURIPrefix + p.ID
+}
+
+// IsExpired проверяет, истек ли срок действия запроса
+func (p *PendingAuthorization) IsExpired(now time.Time) bool {
+	return now.After(p.ExpiresAt)
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackchannelTokenDeliveryMode способ, которым клиент получает результат
+// аутентификации по обратному каналу (CIBA Core, раздел 5)
+type BackchannelTokenDeliveryMode string
+
+const (
+	// BackchannelDeliveryPoll клиент опрашивает token endpoint
+	BackchannelDeliveryPoll BackchannelTokenDeliveryMode = "poll"
+	// BackchannelDeliveryPing сервер сообщает клиенту о решении пользователя,
+	// после чего клиент забирает токены на token endpoint
+	BackchannelDeliveryPing BackchannelTokenDeliveryMode = "ping"
+	// BackchannelDeliveryPush сервер отправляет токены на адрес уведомлений клиента
+	BackchannelDeliveryPush BackchannelTokenDeliveryMode = "push"
+)
+
+// SupportedBackchannelTokenDeliveryModes возвращает все поддерживаемые способы доставки
+func SupportedBackchannelTokenDeliveryModes() []BackchannelTokenDeliveryMode {
+	return []BackchannelTokenDeliveryMode{BackchannelDeliveryPoll, BackchannelDeliveryPing, BackchannelDeliveryPush}
+}
+
+// IsSupported проверяет, поддерживает ли сервер способ доставки
+func (m BackchannelTokenDeliveryMode) IsSupported() bool {
+	for _, supported := range SupportedBackchannelTokenDeliveryModes() {
+		if m == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifiesClient проверяет, отправляет ли сервер уведомления клиенту в этом режиме
+func (m BackchannelTokenDeliveryMode) NotifiesClient() bool {
+	return m == BackchannelDeliveryPing || m == BackchannelDeliveryPush
+}
+
+// BackchannelAuthenticationStatus определяет состояние запроса аутентификации
+type BackchannelAuthenticationStatus string
+
+const (
+	// BackchannelAuthenticationPending пользователь еще не ответил на устройстве
+	BackchannelAuthenticationPending BackchannelAuthenticationStatus = "pending"
+	// BackchannelAuthenticationApproved пользователь подтвердил запрос
+	BackchannelAuthenticationApproved BackchannelAuthenticationStatus = "approved"
+	// BackchannelAuthenticationDenied пользователь отклонил запрос
+	BackchannelAuthenticationDenied BackchannelAuthenticationStatus = "denied"
+)
+
+// BackchannelAuthentication запрос аутентификации по обратному каналу (CIBA).
+// Клиент получает AuthReqID и ждет результата, а пользователь подтверждает
+// запрос на своем устройстве аутентификации без перенаправления браузера.
+type BackchannelAuthentication struct {
+	ID        uuid.UUID `json:"id" validate:"required"`
+	AuthReqID string    `json:"auth_req_id" validate:"required"`
+	ClientID  uuid.UUID `json:"client_id" validate:"required"`
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	Scopes    []string  `json:"scopes"`
+	// BindingMessage короткий текст, который пользователь видит и на устройстве
+	// клиента, и на устройстве аутентификации, чтобы сопоставить запросы
+	BindingMessage string                       `json:"binding_message,omitempty"`
+	DeliveryMode   BackchannelTokenDeliveryMode `json:"delivery_mode" validate:"required,oneof=poll ping push"`
+	// ClientNotificationToken bearer токен, с которым сервер обращается к адресу
+	// уведомлений клиента в режимах ping и push
+	ClientNotificationToken string                          `json:"client_notification_token,omitempty"`
+	Status                  BackchannelAuthenticationStatus `json:"status" validate:"required,oneof=pending approved denied"`
+	Interval                time.Duration                   `json:"interval" validate:"required"`
+	LastPolledAt            *time.Time                      `json:"last_polled_at,omitempty"`
+	ExpiresAt               time.Time                       `json:"expires_at" validate:"required"`
+	CreatedAt               time.Time                       `json:"created_at" validate:"required"`
+}
+
+// IsExpired проверяет, истек ли срок действия запроса
+func (b *BackchannelAuthentication) IsExpired(now time.Time) bool {
+	return !now.Before(b.ExpiresAt)
+}
+
+// IsPending проверяет, ожидает ли запрос решения пользователя
+func (b *BackchannelAuthentication) IsPending() bool {
+	return b.Status == BackchannelAuthenticationPending
+}
+
+// Approve фиксирует подтверждение пользователя
+func (b *BackchannelAuthentication) Approve() {
+	b.Status = BackchannelAuthenticationApproved
+}
+
+// Deny фиксирует отказ пользователя
+func (b *BackchannelAuthentication) Deny() {
+	b.Status = BackchannelAuthenticationDenied
+}
+
+// Poll отмечает опрос token endpoint и возвращает false, если клиент
+// опрашивает чаще установленного интервала
+func (b *BackchannelAuthentication) Poll(now time.Time) bool {
+	tooFast := b.LastPolledAt != nil && now.Sub(*b.LastPolledAt) < b.Interval
+	b.LastPolledAt = &now
+	return !tooFast
+}
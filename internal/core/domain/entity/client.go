@@ -1,8 +1,12 @@
 package entity
 
 import (
-	"time"
+	"AuthAndOauth/internal/pkg/jose"
+	"fmt"
 	"github.com/google/uuid"
+	"net/url"
+	"strings"
+	"time"
 )
 
 // GrantType определяет тип авторизации OAuth
@@ -13,30 +17,193 @@ const (
 	GrantTypeClientCreds  GrantType = "client_credentials"
 	GrantTypeRefreshToken GrantType = "refresh_token"
 	GrantTypePassword     GrantType = "password"
+	// GrantTypeDeviceCode авторизация устройств без браузера (RFC 8628)
+	GrantTypeDeviceCode GrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// GrantTypeTokenExchange обмен токенов между сервисами (RFC 8693)
+	GrantTypeTokenExchange GrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// GrantTypeJWTBearer обмен утверждения доверенного издателя на токен (RFC 7523)
+	GrantTypeJWTBearer GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	// GrantTypeCIBA получение токенов по запросу аутентификации через обратный канал (CIBA)
+	GrantTypeCIBA GrantType = "urn:openid:params:grant-type:ciba"
+)
+
+// SupportedGrantTypes возвращает все типы авторизации, которые поддерживает сервер
+func SupportedGrantTypes() []GrantType {
+	return []GrantType{
+		GrantTypeAuthCode,
+		GrantTypeClientCreds,
+		GrantTypeRefreshToken,
+		GrantTypePassword,
+		GrantTypeDeviceCode,
+		GrantTypeTokenExchange,
+		GrantTypeJWTBearer,
+		GrantTypeCIBA,
+	}
+}
+
+// IsExtension проверяет, является ли тип авторизации расширением, заданным
+// абсолютным URI (RFC 6749, раздел 4.5)
+func (g GrantType) IsExtension() bool {
+	return strings.Contains(string(g), ":")
+}
+
+// IsSupported проверяет, поддерживает ли сервер тип авторизации
+func (g GrantType) IsSupported() bool {
+	for _, supported := range SupportedGrantTypes() {
+		if g == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientType определяет тип клиента (RFC 6749, раздел 2.1)
+type ClientType string
+
+const (
+	// ClientTypeConfidential клиент, способный хранить секрет (серверное приложение)
+	ClientTypeConfidential ClientType = "confidential"
+	// ClientTypePublic клиент, не способный хранить секрет (SPA, мобильное приложение)
+	ClientTypePublic ClientType = "public"
+)
+
+// TokenEndpointAuthMethod определяет способ аутентификации клиента на token endpoint
+type TokenEndpointAuthMethod string
+
+const (
+	AuthMethodClientSecretBasic TokenEndpointAuthMethod = "client_secret_basic"
+	AuthMethodClientSecretPost  TokenEndpointAuthMethod = "client_secret_post"
+	AuthMethodNone              TokenEndpointAuthMethod = "none"
+	// AuthMethodTLSClientAuth сертификат клиента, выданный доверенным УЦ (RFC 8705, раздел 2.1)
+	AuthMethodTLSClientAuth TokenEndpointAuthMethod = "tls_client_auth"
+	// AuthMethodSelfSignedTLSClientAuth самоподписанный сертификат с ключом из JWKS клиента (RFC 8705, раздел 2.2)
+	AuthMethodSelfSignedTLSClientAuth TokenEndpointAuthMethod = "self_signed_tls_client_auth"
+	// AuthMethodPrivateKeyJWT JWT, подписанный ключом из JWKS клиента (RFC 7523, раздел 2.2)
+	AuthMethodPrivateKeyJWT TokenEndpointAuthMethod = "private_key_jwt"
+	// AuthMethodClientSecretJWT JWT, подписанный HMAC на секрете клиента (RFC 7523, раздел 2.2)
+	AuthMethodClientSecretJWT TokenEndpointAuthMethod = "client_secret_jwt"
+)
+
+// SupportedTokenEndpointAuthMethods возвращает все поддерживаемые способы аутентификации клиентов
+func SupportedTokenEndpointAuthMethods() []TokenEndpointAuthMethod {
+	return []TokenEndpointAuthMethod{
+		AuthMethodClientSecretBasic,
+		AuthMethodClientSecretPost,
+		AuthMethodNone,
+		AuthMethodTLSClientAuth,
+		AuthMethodSelfSignedTLSClientAuth,
+		AuthMethodPrivateKeyJWT,
+		AuthMethodClientSecretJWT,
+	}
+}
+
+// IsSupported проверяет, поддерживает ли сервер способ аутентификации
+func (m TokenEndpointAuthMethod) IsSupported() bool {
+	for _, supported := range SupportedTokenEndpointAuthMethods() {
+		if m == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesClientSecret сообщает, аутентифицируется ли клиент этим способом по секрету
+func (m TokenEndpointAuthMethod) UsesClientSecret() bool {
+	return m == AuthMethodClientSecretBasic || m == AuthMethodClientSecretPost || m == AuthMethodClientSecretJWT
+}
+
+// UsesClientAssertion сообщает, аутентифицируется ли клиент этим способом по JWT (RFC 7523)
+func (m TokenEndpointAuthMethod) UsesClientAssertion() bool {
+	return m == AuthMethodPrivateKeyJWT || m == AuthMethodClientSecretJWT
+}
+
+// ResponseType определяет тип ответа authorization endpoint
+type ResponseType string
+
+const (
+	ResponseTypeCode ResponseType = "code"
 )
 
-// Client представляет OAuth клиента
+// SupportedResponseTypes возвращает все поддерживаемые типы ответа
+func SupportedResponseTypes() []ResponseType {
+	return []ResponseType{ResponseTypeCode}
+}
+
+// Client представляет OAuth клиента.
+// Время жизни токенов задается в секундах; 0 означает значение сервера по умолчанию.
+// Допустимые типы авторизации определяются SupportedGrantTypes и проверяются в Validate.
 type Client struct {
-	ID           uuid.UUID   `json:"id" validate:"required"`
-	ClientID     string      `json:"client_id" validate:"required"`
-	ClientSecret string      `json:"-" validate:"required"`
-	Name         string      `json:"name" validate:"required"`
-	Description  string      `json:"description,omitempty"`
-	RedirectURIs []string    `json:"redirect_uris" validate:"required,dive,url"`
-	GrantTypes   []GrantType `json:"grant_types" validate:"required,dive,oneof=authorization_code client_credentials refresh_token password"`
-	Scopes       []string    `json:"scopes" validate:"required,dive,required"`
-	Active       bool        `json:"active"`
-	CreatedAt    time.Time   `json:"created_at" validate:"required"`
-	UpdatedAt    time.Time   `json:"updated_at" validate:"required"`
-}
-
-// NewClient создает нового OAuth клиента
+	ID                      uuid.UUID               `json:"id" validate:"required"`
+	ClientID                string                  `json:"client_id" validate:"required"`
+	Type                    ClientType              `json:"client_type" validate:"required,oneof=confidential public"`
+	TokenEndpointAuthMethod TokenEndpointAuthMethod `json:"token_endpoint_auth_method" validate:"required"`
+	Secrets                 []ClientSecret          `json:"secrets,omitempty"`
+	Name                    string                  `json:"name" validate:"required"`
+	Description             string                  `json:"description,omitempty"`
+	RedirectURIs            []string                `json:"redirect_uris" validate:"required,dive,url"`
+	GrantTypes              []GrantType             `json:"grant_types" validate:"required,dive,required"`
+	ResponseTypes           []ResponseType          `json:"response_types"`
+	Scopes                  []string                `json:"scopes" validate:"required,dive,required"`
+	AccessTokenLifetime     int64                   `json:"access_token_lifetime,omitempty" validate:"gte=0"`
+	RefreshTokenLifetime    int64                   `json:"refresh_token_lifetime,omitempty" validate:"gte=0"`
+	ClientURI               string                  `json:"client_uri,omitempty" validate:"omitempty,url"`
+	LogoURI                 string                  `json:"logo_uri,omitempty" validate:"omitempty,url"`
+	PolicyURI               string                  `json:"policy_uri,omitempty" validate:"omitempty,url"`
+	TosURI                  string                  `json:"tos_uri,omitempty" validate:"omitempty,url"`
+	SoftwareID              string                  `json:"software_id,omitempty"`
+	SoftwareVersion         string                  `json:"software_version,omitempty"`
+	// JWKS открытые ключи клиента, которыми подписаны его объекты запроса и
+	// утверждения private_key_jwt
+	JWKS *jose.JWKSet `json:"jwks,omitempty"`
+	// JWKSURI https адрес, по которому публикуются ключи клиента; задается вместо JWKS
+	JWKSURI string `json:"jwks_uri,omitempty"`
+	// RequestURIs заранее зарегистрированные https адреса объектов запроса (RFC 9101, раздел 5.2)
+	RequestURIs []string `json:"request_uris,omitempty"`
+	// RequestObjectSigningAlg единственный допустимый алгоритм подписи объектов запроса;
+	// пустой — любой асимметричный
+	RequestObjectSigningAlg string `json:"request_object_signing_alg,omitempty"`
+	// RequireSignedRequestObject клиент передает параметры авторизации только
+	// в подписанном объекте запроса (RFC 9101, раздел 10.5)
+	RequireSignedRequestObject bool `json:"require_signed_request_object,omitempty"`
+	// RequirePushedAuthorizationRequests клиент может начинать авторизацию только
+	// через PAR (RFC 9126, раздел 6)
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests,omitempty"`
+	// DPoPBoundAccessTokens клиент всегда получает токены, привязанные к ключу DPoP
+	// (RFC 9449, раздел 5.2)
+	DPoPBoundAccessTokens bool `json:"dpop_bound_access_tokens,omitempty"`
+	// TLSClientAuth ожидаемый субъект сертификата клиента для tls_client_auth
+	TLSClientAuth
+	// TLSClientCertificateBoundAccessTokens токены клиента привязываются к его
+	// сертификату (RFC 8705, раздел 3.4)
+	TLSClientCertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	// TokenExchange политика обмена токенов (RFC 8693); nil — только собственные токены
+	// клиента, без делегирования и без указания целевых audience
+	TokenExchange *TokenExchangePolicy `json:"token_exchange,omitempty"`
+	// AuthorizationDetailsTypes типы authorization_details, которые может запрашивать
+	// клиент (RFC 9396, раздел 10); пусто — любые типы, поддерживаемые сервером
+	AuthorizationDetailsTypes []string `json:"authorization_details_types,omitempty"`
+	// BackchannelTokenDeliveryMode способ доставки результата CIBA; пусто — poll
+	BackchannelTokenDeliveryMode BackchannelTokenDeliveryMode `json:"backchannel_token_delivery_mode,omitempty"`
+	// BackchannelClientNotificationEndpoint https адрес уведомлений клиента в режимах ping и push
+	BackchannelClientNotificationEndpoint string `json:"backchannel_client_notification_endpoint,omitempty"`
+	// FirstParty собственное приложение сервиса: пользователи не подтверждают
+	// его доступ на странице согласия. Задается только оператором.
+	FirstParty bool `json:"first_party,omitempty"`
+	// RegistrationAccessTokenHash хеш токена доступа к конфигурации клиента (RFC 7592);
+	// пуст у клиентов, созданных оператором
+	RegistrationAccessTokenHash string    `json:"-"`
+	Active                      bool      `json:"active"`
+	CreatedAt                   time.Time `json:"created_at" validate:"required"`
+	UpdatedAt                   time.Time `json:"updated_at" validate:"required"`
+}
+
+// NewClient создает нового конфиденциального OAuth клиента
 func NewClient(name, description string, redirectURIs []string, grantTypes []GrantType, scopes []string) *Client {
 	now := time.Now()
-	return &Client{
+	client := &Client{
 		ID:           uuid.New(),
 		ClientID:     uuid.New().String(),
-		ClientSecret: uuid.New().String(),
+		Type:         ClientTypeConfidential,
 		Name:         name,
 		Description:  description,
 		RedirectURIs: redirectURIs,
@@ -46,6 +213,247 @@ func NewClient(name, description string, redirectURIs []string, grantTypes []Gra
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
+	client.ApplyDefaults()
+	return client
+}
+
+// NewPublicClient создает публичного OAuth клиента без секрета
+func NewPublicClient(name, description string, redirectURIs []string, grantTypes []GrantType, scopes []string) *Client {
+	client := NewClient(name, description, redirectURIs, grantTypes, scopes)
+	client.Type = ClientTypePublic
+	client.TokenEndpointAuthMethod = AuthMethodNone
+	return client
+}
+
+// ApplyDefaults заполняет поля, отсутствующие у клиентов, сохраненных
+// до появления типа клиента, способа аутентификации и типов ответа
+func (c *Client) ApplyDefaults() {
+	if c.Type == "" {
+		c.Type = ClientTypeConfidential
+		if c.TokenEndpointAuthMethod == AuthMethodNone {
+			c.Type = ClientTypePublic
+		}
+	}
+	if c.TokenEndpointAuthMethod == "" {
+		c.TokenEndpointAuthMethod = AuthMethodClientSecretBasic
+		if c.Type == ClientTypePublic {
+			c.TokenEndpointAuthMethod = AuthMethodNone
+		}
+	}
+	if c.ResponseTypes == nil {
+		c.ResponseTypes = make([]ResponseType, 0, 1)
+		if c.IsGrantTypeAllowed(GrantTypeAuthCode) {
+			c.ResponseTypes = append(c.ResponseTypes, ResponseTypeCode)
+		}
+	}
+}
+
+// IsPublic проверяет, является ли клиент публичным
+func (c *Client) IsPublic() bool {
+	return c.Type == ClientTypePublic
+}
+
+// IsResponseTypeAllowed проверяет, разрешен ли тип ответа
+func (c *Client) IsResponseTypeAllowed(responseType ResponseType) bool {
+	for _, rt := range c.ResponseTypes {
+		if rt == responseType {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenTTL возвращает время жизни access token клиента или значение по умолчанию
+func (c *Client) AccessTokenTTL(defaultTTL time.Duration) time.Duration {
+	if c.AccessTokenLifetime > 0 {
+		return time.Duration(c.AccessTokenLifetime) * time.Second
+	}
+	return defaultTTL
+}
+
+// RefreshTokenTTL возвращает время жизни refresh token клиента или значение по умолчанию
+func (c *Client) RefreshTokenTTL(defaultTTL time.Duration) time.Duration {
+	if c.RefreshTokenLifetime > 0 {
+		return time.Duration(c.RefreshTokenLifetime) * time.Second
+	}
+	return defaultTTL
+}
+
+// Validate проверяет согласованность метаданных клиента
+func (c *Client) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("client name is required")
+	}
+
+	switch c.Type {
+	case ClientTypeConfidential:
+		if c.TokenEndpointAuthMethod == AuthMethodNone {
+			return fmt.Errorf("confidential client must authenticate at the token endpoint")
+		}
+	case ClientTypePublic:
+		if c.TokenEndpointAuthMethod != AuthMethodNone {
+			return fmt.Errorf("public client must use token endpoint auth method none")
+		}
+		if c.IsGrantTypeAllowed(GrantTypeClientCreds) {
+			return fmt.Errorf("public client cannot use the client_credentials grant")
+		}
+	default:
+		return fmt.Errorf("unknown client type: %s", c.Type)
+	}
+
+	if !c.TokenEndpointAuthMethod.IsSupported() {
+		return fmt.Errorf("unsupported token endpoint auth method: %s", c.TokenEndpointAuthMethod)
+	}
+	for _, grantType := range c.GrantTypes {
+		if !grantType.IsSupported() {
+			return fmt.Errorf("unsupported grant type: %s", grantType)
+		}
+	}
+
+	for _, responseType := range c.ResponseTypes {
+		if responseType != ResponseTypeCode {
+			return fmt.Errorf("unsupported response type: %s", responseType)
+		}
+		if !c.IsGrantTypeAllowed(GrantTypeAuthCode) {
+			return fmt.Errorf("response type %s requires the authorization_code grant", responseType)
+		}
+	}
+	if c.IsGrantTypeAllowed(GrantTypeAuthCode) && len(c.RedirectURIs) == 0 {
+		return fmt.Errorf("authorization_code grant requires at least one redirect uri")
+	}
+
+	for _, raw := range c.RedirectURIs {
+		uri, err := url.Parse(raw)
+		if err != nil || uri.Scheme == "" || uri.Host == "" {
+			return fmt.Errorf("invalid redirect uri: %s", raw)
+		}
+		if uri.Fragment != "" {
+			return fmt.Errorf("redirect uri must not contain a fragment: %s", raw)
+		}
+	}
+	for name, raw := range map[string]string{
+		"client_uri": c.ClientURI,
+		"logo_uri":   c.LogoURI,
+		"policy_uri": c.PolicyURI,
+		"tos_uri":    c.TosURI,
+	} {
+		if raw == "" {
+			continue
+		}
+		uri, err := url.Parse(raw)
+		if err != nil || (uri.Scheme != "https" && uri.Scheme != "http") || uri.Host == "" {
+			return fmt.Errorf("invalid %s: %s", name, raw)
+		}
+	}
+
+	if c.AccessTokenLifetime < 0 || c.RefreshTokenLifetime < 0 {
+		return fmt.Errorf("token lifetimes must not be negative")
+	}
+	if err := c.validateRequestObjectMetadata(); err != nil {
+		return err
+	}
+	if err := c.validateTLSClientAuth(); err != nil {
+		return err
+	}
+	if c.TokenEndpointAuthMethod == AuthMethodPrivateKeyJWT && !c.HasKeys() {
+		return fmt.Errorf("private_key_jwt requires client jwks or jwks_uri")
+	}
+	if c.TokenExchange != nil {
+		if err := c.TokenExchange.Validate(); err != nil {
+			return fmt.Errorf("invalid token exchange policy: %w", err)
+		}
+	}
+	return c.validateBackchannelMetadata()
+}
+
+// validateBackchannelMetadata проверяет настройки доставки результата CIBA
+func (c *Client) validateBackchannelMetadata() error {
+	if c.BackchannelTokenDeliveryMode != "" && !c.BackchannelTokenDeliveryMode.IsSupported() {
+		return fmt.Errorf("unsupported backchannel token delivery mode: %s", c.BackchannelTokenDeliveryMode)
+	}
+	if c.IsGrantTypeAllowed(GrantTypeCIBA) && c.IsPublic() {
+		return fmt.Errorf("public client cannot use the ciba grant")
+	}
+	if c.BackchannelDeliveryMode().NotifiesClient() && c.BackchannelClientNotificationEndpoint == "" {
+		return fmt.Errorf("backchannel token delivery mode %s requires a client notification endpoint", c.BackchannelTokenDeliveryMode)
+	}
+	if raw := c.BackchannelClientNotificationEndpoint; raw != "" {
+		uri, err := url.Parse(raw)
+		if err != nil || uri.Scheme != "https" || uri.Host == "" || uri.Fragment != "" {
+			return fmt.Errorf("invalid backchannel client notification endpoint: %s", raw)
+		}
+	}
+	return nil
+}
+
+// BackchannelDeliveryMode возвращает способ доставки результата CIBA; по
+// умолчанию клиент опрашивает token endpoint
+func (c *Client) BackchannelDeliveryMode() BackchannelTokenDeliveryMode {
+	if c.BackchannelTokenDeliveryMode == "" {
+		return BackchannelDeliveryPoll
+	}
+	return c.BackchannelTokenDeliveryMode
+}
+
+// validateRequestObjectMetadata проверяет ключи клиента и настройки объектов запроса
+func (c *Client) validateRequestObjectMetadata() error {
+	if c.JWKS != nil {
+		for _, key := range c.JWKS.Keys {
+			if key.IsPrivate() {
+				return fmt.Errorf("jwks must contain only public keys")
+			}
+			if _, err := key.PublicKey(); err != nil {
+				return fmt.Errorf("invalid key %q in jwks: %w", key.KeyID, err)
+			}
+		}
+	}
+	if c.JWKSURI != "" {
+		// Ключи задаются одним способом (RFC 7591, раздел 2)
+		if c.JWKS != nil {
+			return fmt.Errorf("jwks and jwks_uri must not both be set")
+		}
+		uri, err := url.Parse(c.JWKSURI)
+		if err != nil || uri.Scheme != "https" || uri.Host == "" {
+			return fmt.Errorf("jwks uri must be an absolute https uri: %s", c.JWKSURI)
+		}
+	}
+	for _, raw := range c.RequestURIs {
+		uri, err := url.Parse(raw)
+		if err != nil || uri.Scheme != "https" || uri.Host == "" {
+			return fmt.Errorf("request uri must be an absolute https uri: %s", raw)
+		}
+	}
+	if c.RequestObjectSigningAlg != "" {
+		supported := false
+		for _, alg := range jose.AsymmetricAlgorithms {
+			supported = supported || alg == c.RequestObjectSigningAlg
+		}
+		if !supported {
+			return fmt.Errorf("unsupported request object signing alg: %s", c.RequestObjectSigningAlg)
+		}
+	}
+	if c.RequireSignedRequestObject && !c.HasKeys() {
+		return fmt.Errorf("signed request objects require client jwks or jwks_uri")
+	}
+	return nil
+}
+
+// HasKeys проверяет, зарегистрированы ли открытые ключи клиента
+func (c *Client) HasKeys() bool {
+	return c.JWKSURI != "" || (c.JWKS != nil && len(c.JWKS.Keys) > 0)
+}
+
+// IsRequestURIRegistered проверяет, зарегистрирован ли адрес объекта запроса.
+// Фрагмент адреса не учитывается (RFC 9101, раздел 5.2.1).
+func (c *Client) IsRequestURIRegistered(uri string) bool {
+	uri, _, _ = strings.Cut(uri, "#")
+	for _, registered := range c.RequestURIs {
+		registered, _, _ = strings.Cut(registered, "#")
+		if registered == uri {
+			return true
+		}
+	}
+	return false
 }
 
 // IsGrantTypeAllowed проверяет, разрешен ли тип авторизации
@@ -68,6 +476,20 @@ func (c *Client) IsScopeAllowed(scope string) bool {
 	return false
 }
 
+// IsAuthorizationDetailTypeAllowed проверяет, может ли клиент запрашивать
+// authorization_details этого типа
+func (c *Client) IsAuthorizationDetailTypeAllowed(detailType string) bool {
+	if len(c.AuthorizationDetailsTypes) == 0 {
+		return true
+	}
+	for _, allowed := range c.AuthorizationDetailsTypes {
+		if allowed == detailType {
+			return true
+		}
+	}
+	return false
+}
+
 // IsRedirectURIAllowed проверяет, разрешен ли URI перенаправления
 func (c *Client) IsRedirectURIAllowed(uri string) bool {
 	for _, redirectURI := range c.RedirectURIs {
@@ -78,6 +500,11 @@ func (c *Client) IsRedirectURIAllowed(uri string) bool {
 	return false
 }
 
+// IsDynamicallyRegistered проверяет, зарегистрирован ли клиент через /register
+func (c *Client) IsDynamicallyRegistered() bool {
+	return c.RegistrationAccessTokenHash != ""
+}
+
 // Deactivate деактивирует клиента
 func (c *Client) Deactivate() {
 	c.Active = false
@@ -88,4 +515,68 @@ func (c *Client) Deactivate() {
 func (c *Client) Activate() {
 	c.Active = true
 	c.UpdatedAt = time.Now()
-} 
\ No newline at end of file
+}
+
+// Clone возвращает глубокую копию клиента
+func (c *Client) Clone() *Client {
+	clone := *c
+	clone.RedirectURIs = append([]string(nil), c.RedirectURIs...)
+	clone.GrantTypes = append([]GrantType(nil), c.GrantTypes...)
+	clone.Scopes = append([]string(nil), c.Scopes...)
+	clone.ResponseTypes = append(make([]ResponseType, 0, len(c.ResponseTypes)), c.ResponseTypes...)
+	if c.TokenExchange != nil {
+		clone.TokenExchange = c.TokenExchange.Clone()
+	}
+	if c.JWKS != nil {
+		clone.JWKS = &jose.JWKSet{Keys: append([]jose.JWK(nil), c.JWKS.Keys...)}
+	}
+	clone.RequestURIs = append([]string(nil), c.RequestURIs...)
+	clone.AuthorizationDetailsTypes = append([]string(nil), c.AuthorizationDetailsTypes...)
+	clone.Secrets = make([]ClientSecret, 0, len(c.Secrets))
+	for _, secret := range c.Secrets {
+		if secret.ExpiresAt != nil {
+			expiresAt := *secret.ExpiresAt
+			secret.ExpiresAt = &expiresAt
+		}
+		clone.Secrets = append(clone.Secrets, secret)
+	}
+	return &clone
+}
+
+// AddSecret добавляет хешированный секрет клиента
+func (c *Client) AddSecret(secret ClientSecret) {
+	c.Secrets = append(c.Secrets, secret)
+	c.UpdatedAt = time.Now()
+}
+
+// ActiveSecrets возвращает секреты, срок действия которых не истек
+func (c *Client) ActiveSecrets(now time.Time) []ClientSecret {
+	active := make([]ClientSecret, 0, len(c.Secrets))
+	for _, secret := range c.Secrets {
+		if !secret.IsExpired(now) {
+			active = append(active, secret)
+		}
+	}
+	return active
+}
+
+// RemoveExpiredSecrets удаляет секреты с истекшим сроком действия
+func (c *Client) RemoveExpiredSecrets(now time.Time) {
+	active := c.ActiveSecrets(now)
+	if len(active) != len(c.Secrets) {
+		c.Secrets = active
+		c.UpdatedAt = now
+	}
+}
+
+// RemoveSecret удаляет секрет по идентификатору
+func (c *Client) RemoveSecret(secretID string) bool {
+	for i, secret := range c.Secrets {
+		if secret.ID == secretID {
+			c.Secrets = append(c.Secrets[:i], c.Secrets[i+1:]...)
+			c.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+)
+
+// ClientSecret хешированный секрет клиента. Значение секрета не хранится:
+// оно показывается один раз при выдаче, затем проверяется по хешу.
+type ClientSecret struct {
+	ID   string `json:"id" validate:"required"`
+	Hash string `json:"-" validate:"required"`
+	// Sealed значение секрета, зашифрованное ключом сервера. Задается только у
+	// клиентов client_secret_jwt: подпись HMAC нельзя проверить по хешу.
+	Sealed    string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// IsExpired проверяет, истек ли срок действия секрета
+func (s ClientSecret) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Consent согласие пользователя на доступ клиента: области действия и ресурсы,
+// которые пользователь уже разрешил. Для пары пользователь — клиент хранится
+// одна запись, новые разрешения добавляются к ней.
+//
+// Пустой набор ресурсов — отдельное значение: токены без audience, которые
+// принимает любой ресурс. Согласие на конкретные ресурсы его не покрывает,
+// поэтому он отмечается флагом NoAudience.
+type Consent struct {
+	ID         uuid.UUID `json:"id" validate:"required"`
+	UserID     uuid.UUID `json:"user_id" validate:"required"`
+	ClientID   uuid.UUID `json:"client_id" validate:"required"`
+	Scopes     []string  `json:"scopes"`
+	Resources  []string  `json:"resources,omitempty"`
+	NoAudience bool      `json:"no_audience,omitempty"`
+	CreatedAt  time.Time `json:"created_at" validate:"required"`
+	UpdatedAt  time.Time `json:"updated_at" validate:"required"`
+}
+
+// NewConsent создает согласие пользователя на доступ клиента
+func NewConsent(userID, clientID uuid.UUID, scopes, resources []string) *Consent {
+	now := time.Now()
+	return &Consent{
+		ID:         uuid.New(),
+		UserID:     userID,
+		ClientID:   clientID,
+		Scopes:     append([]string(nil), scopes...),
+		Resources:  append([]string(nil), resources...),
+		NoAudience: len(resources) == 0,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Covers проверяет, разрешены ли уже все запрошенные области и ресурсы;
+// пустой набор ресурсов покрывается только согласием на токены без audience
+func (c *Consent) Covers(scopes, resources []string) bool {
+	if !containsAll(c.Scopes, scopes) {
+		return false
+	}
+	if len(resources) == 0 {
+		return c.NoAudience
+	}
+	return containsAll(c.Resources, resources)
+}
+
+// Grant добавляет к согласию новые области и ресурсы и возвращает false,
+// если все они уже были разрешены
+func (c *Consent) Grant(scopes, resources []string) bool {
+	if c.Covers(scopes, resources) {
+		return false
+	}
+	c.Scopes = appendMissing(c.Scopes, scopes)
+	c.Resources = appendMissing(c.Resources, resources)
+	if len(resources) == 0 {
+		c.NoAudience = true
+	}
+	c.UpdatedAt = time.Now()
+	return true
+}
+
+// Clone возвращает копию согласия
+func (c *Consent) Clone() *Consent {
+	clone := *c
+	clone.Scopes = append([]string(nil), c.Scopes...)
+	clone.Resources = append([]string(nil), c.Resources...)
+	return &clone
+}
+
+func containsAll(values, required []string) bool {
+	for _, value := range required {
+		if !containsValue(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func appendMissing(values, added []string) []string {
+	for _, value := range added {
+		if !containsValue(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceAuthorizationStatus определяет состояние запроса авторизации устройства
+type DeviceAuthorizationStatus string
+
+const (
+	// DeviceAuthorizationPending пользователь еще не подтвердил запрос
+	DeviceAuthorizationPending DeviceAuthorizationStatus = "pending"
+	// DeviceAuthorizationApproved пользователь разрешил доступ
+	DeviceAuthorizationApproved DeviceAuthorizationStatus = "approved"
+	// DeviceAuthorizationDenied пользователь отказал в доступе
+	DeviceAuthorizationDenied DeviceAuthorizationStatus = "denied"
+)
+
+// DeviceAuthorization запрос авторизации устройства (RFC 8628). Устройство
+// опрашивает token endpoint по DeviceCode, пока пользователь вводит UserCode
+// на странице подтверждения.
+type DeviceAuthorization struct {
+	ID           uuid.UUID                 `json:"id" validate:"required"`
+	DeviceCode   string                    `json:"device_code" validate:"required"`
+	UserCode     string                    `json:"user_code" validate:"required"`
+	ClientID     uuid.UUID                 `json:"client_id" validate:"required"`
+	Scopes       []string                  `json:"scopes"`
+	Status       DeviceAuthorizationStatus `json:"status" validate:"required,oneof=pending approved denied"`
+	UserID       uuid.UUID                 `json:"user_id,omitempty"`
+	Interval     time.Duration             `json:"interval" validate:"required"`
+	LastPolledAt *time.Time                `json:"last_polled_at,omitempty"`
+	ExpiresAt    time.Time                 `json:"expires_at" validate:"required"`
+	CreatedAt    time.Time                 `json:"created_at" validate:"required"`
+}
+
+// IsExpired проверяет, истек ли срок действия запроса
+func (d *DeviceAuthorization) IsExpired(now time.Time) bool {
+	return !now.Before(d.ExpiresAt)
+}
+
+// IsPending проверяет, ожидает ли запрос решения пользователя
+func (d *DeviceAuthorization) IsPending() bool {
+	return d.Status == DeviceAuthorizationPending
+}
+
+// Approve фиксирует согласие пользователя
+func (d *DeviceAuthorization) Approve(userID uuid.UUID) {
+	d.Status = DeviceAuthorizationApproved
+	d.UserID = userID
+}
+
+// Deny фиксирует отказ пользователя
+func (d *DeviceAuthorization) Deny() {
+	d.Status = DeviceAuthorizationDenied
+}
+
+// Poll отмечает опрос устройства и возвращает false, если устройство
+// опрашивает чаще установленного интервала
+func (d *DeviceAuthorization) Poll(now time.Time) bool {
+	tooFast := d.LastPolledAt != nil && now.Sub(*d.LastPolledAt) < d.Interval
+	d.LastPolledAt = &now
+	return !tooFast
+}
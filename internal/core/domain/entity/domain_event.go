@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// DomainEventType определяет тип доменного события
+type DomainEventType string
+
+const (
+	EventUserRolesChanged       DomainEventType = "user.roles_changed"
+	EventUserDeleted            DomainEventType = "user.deleted"
+	EventRolePermissionsChanged DomainEventType = "role.permissions_changed"
+	EventRoleDeleted            DomainEventType = "role.deleted"
+	EventPermissionChanged      DomainEventType = "permission.changed"
+	EventPermissionDeleted      DomainEventType = "permission.deleted"
+)
+
+// DomainEvent представляет изменение в доменной модели, о котором
+// должны узнать другие части системы (например, кеш разрешений)
+type DomainEvent struct {
+	Type        DomainEventType `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// NewDomainEvent создает новое доменное событие
+func NewDomainEvent(eventType DomainEventType, aggregateID uuid.UUID) DomainEvent {
+	return DomainEvent{
+		Type:        eventType,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now(),
+	}
+}
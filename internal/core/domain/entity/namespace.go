@@ -0,0 +1,172 @@
+package entity
+
+import (
+	"fmt"
+)
+
+// UsersetRewrite определяет, как вычисляется множество пользователей отношения.
+// Ровно одно из полей должно быть задано.
+type UsersetRewrite struct {
+	// This - пользователи из отношений, записанных непосредственно для объекта
+	This bool `json:"this,omitempty"`
+	// ComputedUserset - пользователи другого отношения того же объекта
+	ComputedUserset string `json:"computed_userset,omitempty"`
+	// TupleToUserset - пользователи отношения у объектов, связанных через отношение
+	TupleToUserset *TupleToUserset `json:"tuple_to_userset,omitempty"`
+}
+
+// TupleToUserset находит объекты по отношению Tupleset и берет у них
+// отношение ComputedUserset, например parent -> viewer у папки документа
+type TupleToUserset struct {
+	Tupleset        string `json:"tupleset" validate:"required"`
+	ComputedUserset string `json:"computed_userset" validate:"required"`
+}
+
+// RelationConfig описывает отношение в пространстве имен.
+// Итоговое множество пользователей - объединение всех правил Union;
+// пустой Union эквивалентен правилу This.
+type RelationConfig struct {
+	Name  string           `json:"name" validate:"required"`
+	Union []UsersetRewrite `json:"union,omitempty"`
+}
+
+// Rewrites возвращает правила вычисления отношения с учетом значения по умолчанию
+func (r *RelationConfig) Rewrites() []UsersetRewrite {
+	if len(r.Union) == 0 {
+		return []UsersetRewrite{{This: true}}
+	}
+	return r.Union
+}
+
+// AllowsDirect проверяет, можно ли записывать отношения непосредственно
+func (r *RelationConfig) AllowsDirect() bool {
+	for _, rewrite := range r.Rewrites() {
+		if rewrite.This {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceConfig описывает пространство имен объектов и его отношения
+type NamespaceConfig struct {
+	Name      string           `json:"name" validate:"required"`
+	Relations []RelationConfig `json:"relations,omitempty" validate:"dive"`
+}
+
+// Relation возвращает конфигурацию отношения по имени
+func (n *NamespaceConfig) Relation(name string) (*RelationConfig, bool) {
+	for i := range n.Relations {
+		if n.Relations[i].Name == name {
+			return &n.Relations[i], true
+		}
+	}
+	return nil, false
+}
+
+// NamespaceSchema набор пространств имен для авторизации на основе отношений
+type NamespaceSchema struct {
+	namespaces map[string]*NamespaceConfig
+}
+
+// NewNamespaceSchema создает схему и проверяет ссылки между отношениями
+func NewNamespaceSchema(namespaces ...NamespaceConfig) (*NamespaceSchema, error) {
+	schema := &NamespaceSchema{namespaces: make(map[string]*NamespaceConfig, len(namespaces))}
+	for i := range namespaces {
+		ns := namespaces[i]
+		if ns.Name == "" {
+			return nil, fmt.Errorf("namespace name is required")
+		}
+		if _, exists := schema.namespaces[ns.Name]; exists {
+			return nil, fmt.Errorf("duplicate namespace: %s", ns.Name)
+		}
+		schema.namespaces[ns.Name] = &ns
+	}
+
+	for _, ns := range schema.namespaces {
+		if err := schema.validateNamespace(ns); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// Namespace возвращает конфигурацию пространства имен
+func (s *NamespaceSchema) Namespace(name string) (*NamespaceConfig, bool) {
+	ns, ok := s.namespaces[name]
+	return ns, ok
+}
+
+// Relation возвращает конфигурацию отношения пространства имен
+func (s *NamespaceSchema) Relation(namespace, relation string) (*RelationConfig, bool) {
+	ns, ok := s.namespaces[namespace]
+	if !ok {
+		return nil, false
+	}
+	return ns.Relation(relation)
+}
+
+// ValidateTuple проверяет, что отношение соответствует схеме
+func (s *NamespaceSchema) ValidateTuple(tuple RelationTuple) error {
+	relation, ok := s.Relation(tuple.Object.Namespace, tuple.Relation)
+	if !ok {
+		return fmt.Errorf("unknown relation %s#%s", tuple.Object.Namespace, tuple.Relation)
+	}
+	if !relation.AllowsDirect() {
+		return fmt.Errorf("relation %s#%s is computed and cannot be written directly", tuple.Object.Namespace, tuple.Relation)
+	}
+	if tuple.Subject.IsUserset() {
+		if _, ok := s.Relation(tuple.Subject.Object.Namespace, tuple.Subject.Relation); !ok {
+			return fmt.Errorf("unknown subject relation %s", tuple.Subject)
+		}
+	}
+	return nil
+}
+
+func (s *NamespaceSchema) validateNamespace(ns *NamespaceConfig) error {
+	seen := make(map[string]bool, len(ns.Relations))
+	for _, relation := range ns.Relations {
+		if relation.Name == "" {
+			return fmt.Errorf("namespace %s: relation name is required", ns.Name)
+		}
+		if seen[relation.Name] {
+			return fmt.Errorf("namespace %s: duplicate relation %s", ns.Name, relation.Name)
+		}
+		seen[relation.Name] = true
+	}
+
+	for _, relation := range ns.Relations {
+		for _, rewrite := range relation.Union {
+			if err := s.validateRewrite(ns, &relation, rewrite); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *NamespaceSchema) validateRewrite(ns *NamespaceConfig, relation *RelationConfig, rewrite UsersetRewrite) error {
+	set := 0
+	if rewrite.This {
+		set++
+	}
+	if rewrite.ComputedUserset != "" {
+		set++
+		if _, ok := ns.Relation(rewrite.ComputedUserset); !ok {
+			return fmt.Errorf("namespace %s: relation %s: unknown computed userset %s", ns.Name, relation.Name, rewrite.ComputedUserset)
+		}
+	}
+	if rewrite.TupleToUserset != nil {
+		set++
+		if _, ok := ns.Relation(rewrite.TupleToUserset.Tupleset); !ok {
+			return fmt.Errorf("namespace %s: relation %s: unknown tupleset %s", ns.Name, relation.Name, rewrite.TupleToUserset.Tupleset)
+		}
+		if rewrite.TupleToUserset.ComputedUserset == "" {
+			return fmt.Errorf("namespace %s: relation %s: tuple_to_userset requires computed_userset", ns.Name, relation.Name)
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("namespace %s: relation %s: rewrite must define exactly one rule", ns.Name, relation.Name)
+	}
+	return nil
+}
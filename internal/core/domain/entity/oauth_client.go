@@ -2,11 +2,15 @@ package entity
 
 import (
 	"github.com/google/uuid"
+	"strings"
 	"time"
 )
 
-// OAuthClient представляет клиента OAuth 2.0.
-type OAuthClient struct {
+// LegacyOAuthClient прежнее представление клиента OAuth 2.0 (бывший OAuthClient).
+// Сохранено только для миграции данных: поля с опечатками в JSON тегах,
+// списки URI и типов авторизации хранятся строками через запятую,
+// а секрет — открытым текстом. Новый код должен использовать Client.
+type LegacyOAuthClient struct {
 	ID           uuid.UUID `json:"id"`
 	ClinetID     string    `json:"clinet_id"`
 	ClinetSecret string    `json:"clinet_secret"`
@@ -17,3 +21,54 @@ type OAuthClient struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+// ToClient преобразует клиента в новую модель. Открытый секрет возвращается
+// отдельно: вызывающий код должен захешировать его (ClientSecretManager.Adopt),
+// чтобы существующие интеграции продолжили работать.
+func (l *LegacyOAuthClient) ToClient() (*Client, string) {
+	grantTypes := make([]GrantType, 0)
+	for _, grantType := range splitLegacyList(l.GrantTypes) {
+		grantTypes = append(grantTypes, GrantType(grantType))
+	}
+
+	client := &Client{
+		ID:           l.ID,
+		ClientID:     l.ClinetID,
+		Name:         l.ClinetID,
+		RedirectURIs: splitLegacyList(l.RedirectURIs),
+		GrantTypes:   grantTypes,
+		Scopes:       append([]string(nil), l.Scopes...),
+		Active:       l.IsActive,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+	}
+	if client.ID == uuid.Nil {
+		client.ID = uuid.New()
+	}
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = time.Now()
+	}
+	if client.UpdatedAt.IsZero() {
+		client.UpdatedAt = client.CreatedAt
+	}
+	if l.ClinetSecret == "" {
+		client.Type = ClientTypePublic
+	}
+	client.ApplyDefaults()
+
+	return client, l.ClinetSecret
+}
+
+// splitLegacyList разбирает список, сохраненный строкой через запятую или пробел
+func splitLegacyList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
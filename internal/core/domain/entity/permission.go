@@ -5,7 +5,8 @@ import (
 	"github.com/google/uuid"
 )
 
-// ResourceType определяет тип ресурса
+// ResourceType определяет тип ресурса. Встроенные типы перечислены ниже,
+// приложения могут регистрировать собственные в реестре ресурсов.
 type ResourceType string
 
 const (
@@ -15,7 +16,8 @@ const (
 	ResourceClient    ResourceType = "client"
 )
 
-// Action определяет действие над ресурсом
+// Action определяет действие над ресурсом. Встроенные действия перечислены ниже,
+// приложения могут регистрировать собственные в реестре ресурсов.
 type Action string
 
 const (
@@ -26,31 +28,63 @@ const (
 	ActionList   Action = "list"
 )
 
-// Permission представляет разрешение в системе
+// PermissionEffect определяет эффект разрешения: разрешить или запретить действие
+type PermissionEffect string
+
+const (
+	EffectAllow PermissionEffect = "allow"
+	EffectDeny  PermissionEffect = "deny"
+)
+
+// Permission представляет разрешение в системе.
+// Допустимые ресурсы и действия определяются реестром ресурсов во время
+// выполнения (ResourceRegistry.ValidatePermission), а не фиксированными списками.
 type Permission struct {
-	ID          uuid.UUID    `json:"id" validate:"required"`
-	Name        string       `json:"name" validate:"required"`
-	Resource    ResourceType `json:"resource" validate:"required,oneof=user role permission client"`
-	Action      Action      `json:"action" validate:"required,oneof=create read update delete list"`
-	Description string       `json:"description,omitempty"`
-	CreatedAt   time.Time    `json:"created_at" validate:"required"`
-	UpdatedAt   time.Time    `json:"updated_at" validate:"required"`
+	ID          uuid.UUID        `json:"id" validate:"required"`
+	Name        string           `json:"name" validate:"required"`
+	Resource    ResourceType     `json:"resource" validate:"required"`
+	Action      Action           `json:"action" validate:"required"`
+	Effect      PermissionEffect `json:"effect,omitempty" validate:"omitempty,oneof=allow deny"`
+	Description string           `json:"description,omitempty"`
+	CreatedAt   time.Time        `json:"created_at" validate:"required"`
+	UpdatedAt   time.Time        `json:"updated_at" validate:"required"`
 }
 
-// NewPermission создает новое разрешение
+// NewPermission создает новое разрешающее разрешение
 func NewPermission(name string, resource ResourceType, action Action, description string) *Permission {
+	return NewPermissionWithEffect(name, resource, action, EffectAllow, description)
+}
+
+// NewDenyPermission создает явный запрет действия над ресурсом
+func NewDenyPermission(name string, resource ResourceType, action Action, description string) *Permission {
+	return NewPermissionWithEffect(name, resource, action, EffectDeny, description)
+}
+
+// NewPermissionWithEffect создает новое разрешение с указанным эффектом
+func NewPermissionWithEffect(name string, resource ResourceType, action Action, effect PermissionEffect, description string) *Permission {
 	now := time.Now()
 	return &Permission{
 		ID:          uuid.New(),
 		Name:        name,
 		Resource:    resource,
 		Action:      action,
+		Effect:      effect,
 		Description: description,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
 
+// IsDeny проверяет, является ли разрешение явным запретом
+func (p *Permission) IsDeny() bool {
+	return p.Effect == EffectDeny
+}
+
+// Matches проверяет, относится ли разрешение к указанному ресурсу и действию
+func (p *Permission) Matches(resource ResourceType, action Action) bool {
+	return p.Resource == resource && p.Action == action
+}
+
 // String возвращает строковое представление разрешения
 func (p *Permission) String() string {
 	return string(p.Resource) + ":" + string(p.Action)
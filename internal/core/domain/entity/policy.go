@@ -0,0 +1,181 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConditionOperator определяет оператор сравнения в условии политики
+type ConditionOperator string
+
+const (
+	OperatorEquals         ConditionOperator = "eq"
+	OperatorNotEquals      ConditionOperator = "ne"
+	OperatorIn             ConditionOperator = "in"
+	OperatorNotIn          ConditionOperator = "not_in"
+	OperatorContains       ConditionOperator = "contains"
+	OperatorGreaterThan    ConditionOperator = "gt"
+	OperatorGreaterOrEqual ConditionOperator = "gte"
+	OperatorLessThan       ConditionOperator = "lt"
+	OperatorLessOrEqual    ConditionOperator = "lte"
+	OperatorExists         ConditionOperator = "exists"
+	OperatorTimeBetween    ConditionOperator = "time_between"
+	OperatorWeekdayIn      ConditionOperator = "weekday_in"
+	OperatorIPInCIDR       ConditionOperator = "ip_in_cidr"
+)
+
+// Префиксы атрибутов, доступных в условиях политик
+const (
+	AttributeSubject     = "subject"
+	AttributeResource    = "resource"
+	AttributeAction      = "action"
+	AttributeEnvironment = "environment"
+)
+
+// Condition представляет условие политики над атрибутами запроса.
+// Attribute и ValueFrom задаются в виде "subject.department", "resource.owner_id",
+// "environment.time" и т.п. Если указан ValueFrom, сравнение выполняется
+// со значением другого атрибута вместо константы Value. Значения сравниваются
+// строго по типу. Условие с отсутствующим атрибутом не выполняется ни с каким
+// оператором, кроме условий запрещающих политик: они срабатывают, чтобы
+// нехватка атрибута не снимала запрет.
+type Condition struct {
+	Attribute string            `json:"attribute" validate:"required"`
+	Operator  ConditionOperator `json:"operator" validate:"required"`
+	Value     interface{}       `json:"value,omitempty"`
+	ValueFrom string            `json:"value_from,omitempty"`
+}
+
+// Policy представляет политику доступа на основе атрибутов (ABAC).
+// Политика применяется, если ресурс и действие запроса входят в ее списки
+// (пустой список означает любой ресурс или действие) и выполнены все условия.
+type Policy struct {
+	ID          string           `json:"id" validate:"required"`
+	Description string           `json:"description,omitempty"`
+	Effect      PermissionEffect `json:"effect" validate:"required,oneof=allow deny"`
+	Resources   []ResourceType   `json:"resources,omitempty"`
+	Actions     []Action         `json:"actions,omitempty"`
+	Conditions  []Condition      `json:"conditions,omitempty" validate:"dive"`
+}
+
+// AppliesTo проверяет, относится ли политика к ресурсу и действию
+func (p *Policy) AppliesTo(resource ResourceType, action Action) bool {
+	return p.coversResource(resource) && p.coversAction(action)
+}
+
+func (p *Policy) coversResource(resource ResourceType) bool {
+	if len(p.Resources) == 0 {
+		return true
+	}
+	for _, r := range p.Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) coversAction(action Action) bool {
+	if len(p.Actions) == 0 {
+		return true
+	}
+	for _, a := range p.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет корректность политики
+func (p *Policy) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("policy id is required")
+	}
+	if p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return fmt.Errorf("policy %s: invalid effect: %q", p.ID, p.Effect)
+	}
+	for i, condition := range p.Conditions {
+		if err := condition.Validate(); err != nil {
+			return fmt.Errorf("policy %s: condition %d: %w", p.ID, i, err)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет корректность условия
+func (c *Condition) Validate() error {
+	if !isAttributePath(c.Attribute) {
+		return fmt.Errorf("invalid attribute: %q", c.Attribute)
+	}
+	if c.ValueFrom != "" && !isAttributePath(c.ValueFrom) {
+		return fmt.Errorf("invalid value_from attribute: %q", c.ValueFrom)
+	}
+
+	switch c.Operator {
+	case OperatorEquals, OperatorNotEquals, OperatorContains,
+		OperatorGreaterThan, OperatorGreaterOrEqual, OperatorLessThan, OperatorLessOrEqual:
+		return nil
+	case OperatorExists:
+		return nil
+	case OperatorIn, OperatorNotIn, OperatorWeekdayIn, OperatorIPInCIDR:
+		if c.ValueFrom == "" && c.Value == nil {
+			return fmt.Errorf("operator %s requires a value", c.Operator)
+		}
+		return nil
+	case OperatorTimeBetween:
+		window, ok := c.Value.(string)
+		if !ok {
+			return fmt.Errorf("operator %s requires a value like \"09:00-18:00\"", c.Operator)
+		}
+		if _, _, err := ParseTimeWindow(window); err != nil {
+			return fmt.Errorf("operator %s: %w", c.Operator, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown operator: %q", c.Operator)
+	}
+}
+
+// ParseTimeWindow разбирает интервал времени суток "HH:MM-HH:MM" в минуты от
+// полуночи. Начало больше конца означает интервал через полночь.
+func ParseTimeWindow(window string) (from, to int, err error) {
+	fromStr, toStr, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time window %q, expected \"HH:MM-HH:MM\"", window)
+	}
+	if from, err = parseClock(fromStr); err != nil {
+		return 0, 0, fmt.Errorf("invalid time window %q: %w", window, err)
+	}
+	if to, err = parseClock(toStr); err != nil {
+		return 0, 0, fmt.Errorf("invalid time window %q: %w", window, err)
+	}
+	return from, to, nil
+}
+
+// parseClock разбирает время суток "HH:MM" в минуты от полуночи
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// isAttributePath проверяет, что путь начинается с известного префикса атрибута
+func isAttributePath(path string) bool {
+	if path == AttributeAction {
+		return true
+	}
+	prefix, name, ok := strings.Cut(path, ".")
+	if !ok || name == "" {
+		return false
+	}
+	switch prefix {
+	case AttributeSubject, AttributeResource, AttributeEnvironment:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObjectRef ссылка на объект в пространстве имен, например "doc:42"
+type ObjectRef struct {
+	Namespace string `json:"namespace" validate:"required"`
+	ID        string `json:"id" validate:"required"`
+}
+
+// String возвращает строковое представление объекта
+func (o ObjectRef) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// ParseObjectRef разбирает ссылку на объект вида "namespace:id"
+func ParseObjectRef(s string) (ObjectRef, error) {
+	namespace, id, ok := strings.Cut(s, ":")
+	if !ok || namespace == "" || id == "" {
+		return ObjectRef{}, fmt.Errorf("invalid object reference: %q", s)
+	}
+	return ObjectRef{Namespace: namespace, ID: id}, nil
+}
+
+// SubjectRef субъект отношения: конкретный объект ("user:alice")
+// или множество пользователей другого отношения ("group:eng#member")
+type SubjectRef struct {
+	Object   ObjectRef `json:"object" validate:"required"`
+	Relation string    `json:"relation,omitempty"`
+}
+
+// IsUserset проверяет, является ли субъект множеством пользователей
+func (s SubjectRef) IsUserset() bool {
+	return s.Relation != ""
+}
+
+// String возвращает строковое представление субъекта
+func (s SubjectRef) String() string {
+	if s.IsUserset() {
+		return s.Object.String() + "#" + s.Relation
+	}
+	return s.Object.String()
+}
+
+// ParseSubjectRef разбирает субъект вида "namespace:id" или "namespace:id#relation"
+func ParseSubjectRef(s string) (SubjectRef, error) {
+	objectStr, relation, hasRelation := strings.Cut(s, "#")
+	if hasRelation && relation == "" {
+		return SubjectRef{}, fmt.Errorf("invalid subject reference: %q", s)
+	}
+	object, err := ParseObjectRef(objectStr)
+	if err != nil {
+		return SubjectRef{}, err
+	}
+	return SubjectRef{Object: object, Relation: relation}, nil
+}
+
+// RelationTuple представляет отношение между объектом и субъектом,
+// например "doc:42#editor@user:alice" или "doc:42#viewer@group:eng#member"
+type RelationTuple struct {
+	Object   ObjectRef  `json:"object" validate:"required"`
+	Relation string     `json:"relation" validate:"required"`
+	Subject  SubjectRef `json:"subject" validate:"required"`
+}
+
+// String возвращает строковое представление отношения
+func (t RelationTuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseRelationTuple разбирает отношение вида "namespace:id#relation@subject"
+func ParseRelationTuple(s string) (RelationTuple, error) {
+	objectPart, subjectPart, ok := strings.Cut(s, "@")
+	if !ok {
+		return RelationTuple{}, fmt.Errorf("invalid relation tuple: %q", s)
+	}
+
+	objectStr, relation, ok := strings.Cut(objectPart, "#")
+	if !ok || relation == "" {
+		return RelationTuple{}, fmt.Errorf("invalid relation tuple: %q", s)
+	}
+
+	object, err := ParseObjectRef(objectStr)
+	if err != nil {
+		return RelationTuple{}, fmt.Errorf("invalid relation tuple %q: %w", s, err)
+	}
+
+	subject, err := ParseSubjectRef(subjectPart)
+	if err != nil {
+		return RelationTuple{}, fmt.Errorf("invalid relation tuple %q: %w", s, err)
+	}
+
+	return RelationTuple{Object: object, Relation: relation, Subject: subject}, nil
+}
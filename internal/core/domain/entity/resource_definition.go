@@ -0,0 +1,28 @@
+package entity
+
+// ResourceDefinition описывает тип ресурса, зарегистрированный в системе.
+// Пустой список Actions означает, что к ресурсу применимо любое зарегистрированное действие.
+type ResourceDefinition struct {
+	Type        ResourceType `json:"type" validate:"required"`
+	Description string       `json:"description"`
+	Actions     []Action     `json:"actions,omitempty"`
+}
+
+// SupportsAction проверяет, применимо ли действие к ресурсу
+func (d *ResourceDefinition) SupportsAction(action Action) bool {
+	if len(d.Actions) == 0 {
+		return true
+	}
+	for _, a := range d.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionDefinition описывает действие, зарегистрированное в системе
+type ActionDefinition struct {
+	Action      Action `json:"action" validate:"required"`
+	Description string `json:"description"`
+}
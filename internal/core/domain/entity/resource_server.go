@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ResourceServer защищенный ресурс, для которого сервер выпускает токены (RFC 8707)
+type ResourceServer struct {
+	// URI идентификатор ресурса: значение параметра resource и aud выданных токенов
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+	// Scopes области действия, которые принимает ресурс; пусто — любые
+	Scopes []string `json:"scopes,omitempty"`
+	// Default ресурс по умолчанию: audience токенов, грант которых не называет
+	// ресурсов. Ресурс по умолчанию может быть только один.
+	Default bool `json:"default,omitempty"`
+}
+
+// AcceptsScope проверяет, имеет ли область действия смысл для ресурса
+func (r *ResourceServer) AcceptsScope(scope string) bool {
+	if len(r.Scopes) == 0 {
+		return true
+	}
+	for _, accepted := range r.Scopes {
+		if accepted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет идентификатор ресурса
+func (r *ResourceServer) Validate() error {
+	if !IsResourceIndicator(r.URI) {
+		return fmt.Errorf("resource must be an absolute URI without a fragment: %s", r.URI)
+	}
+	for _, scope := range r.Scopes {
+		if scope == "" {
+			return fmt.Errorf("resource %s has an empty scope", r.URI)
+		}
+	}
+	return nil
+}
+
+// IsResourceIndicator проверяет формат значения параметра resource: абсолютный
+// URI без фрагмента (RFC 8707, раздел 2)
+func IsResourceIndicator(value string) bool {
+	uri, err := url.Parse(value)
+	return err == nil && uri.IsAbs() && uri.Fragment == ""
+}
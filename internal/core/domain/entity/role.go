@@ -13,6 +13,8 @@ type Role struct {
 	Permissions []Permission `json:"permissions" validate:"required,dive,required"`
 	CreatedAt   time.Time   `json:"created_at" validate:"required"`
 	UpdatedAt   time.Time   `json:"updated_at" validate:"required"`
+
+	events []DomainEvent
 }
 
 // NewRole создает новую роль
@@ -37,6 +39,7 @@ func (r *Role) AddPermission(permission Permission) {
 	}
 	r.Permissions = append(r.Permissions, permission)
 	r.UpdatedAt = time.Now()
+	r.events = append(r.events, NewDomainEvent(EventRolePermissionsChanged, r.ID))
 }
 
 // RemovePermission удаляет разрешение из роли
@@ -45,6 +48,7 @@ func (r *Role) RemovePermission(permissionID uuid.UUID) {
 		if perm.ID == permissionID {
 			r.Permissions = append(r.Permissions[:i], r.Permissions[i+1:]...)
 			r.UpdatedAt = time.Now()
+			r.events = append(r.events, NewDomainEvent(EventRolePermissionsChanged, r.ID))
 			return
 		}
 	}
@@ -59,3 +63,18 @@ func (r *Role) HasPermission(permissionID uuid.UUID) bool {
 	}
 	return false
 }
+
+// PullEvents возвращает накопленные доменные события и очищает их список
+func (r *Role) PullEvents() []DomainEvent {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// Clone возвращает копию роли без накопленных доменных событий
+func (r *Role) Clone() *Role {
+	clone := *r
+	clone.Permissions = append([]Permission(nil), r.Permissions...)
+	clone.events = nil
+	return &clone
+}
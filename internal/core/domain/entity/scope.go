@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"strings"
+)
+
+// scopeSeparator разделяет сегменты имени области действия: orders:read, tenant:{id}
+const scopeSeparator = ":"
+
+// Scope описание области действия в реестре сервера
+type Scope struct {
+	// Name имя области. Сегмент вида {id} — параметр: шаблон tenant:{id}
+	// описывает области tenant:42, tenant:acme и т.д.
+	Name string `json:"name"`
+	// Description описание для страницы согласия; параметры шаблона
+	// подставляются, например "Access to tenant {id}"
+	Description string `json:"description,omitempty"`
+	// Descriptions переводы описания по языковым тегам (ru, en-US)
+	Descriptions map[string]string `json:"descriptions,omitempty"`
+	// Implies дочерние области, которые включает эта: orders включает orders:read.
+	// В дочерних областях шаблона можно использовать его параметры.
+	Implies []string `json:"implies,omitempty"`
+	// Default область выдается, если клиент не запросил ни одной
+	Default bool `json:"default,omitempty"`
+}
+
+// IsParameterized проверяет, является ли область шаблоном с параметрами
+func (s Scope) IsParameterized() bool {
+	return len(s.Parameters()) > 0
+}
+
+// Parameters возвращает имена параметров шаблона в порядке следования
+func (s Scope) Parameters() []string {
+	var params []string
+	for _, segment := range strings.Split(s.Name, scopeSeparator) {
+		if name, ok := scopeParameter(segment); ok {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// Match сопоставляет значение с именем области и возвращает значения параметров.
+// Параметр соответствует одному непустому сегменту.
+func (s Scope) Match(value string) (map[string]string, bool) {
+	pattern := strings.Split(s.Name, scopeSeparator)
+	segments := strings.Split(value, scopeSeparator)
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range pattern {
+		name, ok := scopeParameter(segment)
+		switch {
+		case ok:
+			if segments[i] == "" || strings.ContainsAny(segments[i], "{}") {
+				return nil, false
+			}
+			params[name] = segments[i]
+		case segment != segments[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Describe возвращает описание на первом подходящем языке из languages
+// (язык "ru" подходит для запроса "ru-RU") с подставленными параметрами;
+// без перевода — Description, без описания — само значение области
+func (s Scope) Describe(value string, params map[string]string, languages []string) string {
+	description := s.Description
+	for _, language := range languages {
+		if translated, ok := s.translation(language); ok {
+			description = translated
+			break
+		}
+	}
+	if description == "" {
+		return value
+	}
+	return ExpandScopeParameters(description, params)
+}
+
+func (s Scope) translation(language string) (string, bool) {
+	for tag, translated := range s.Descriptions {
+		if strings.EqualFold(tag, language) {
+			return translated, true
+		}
+	}
+	primary, _, _ := strings.Cut(language, "-")
+	for tag, translated := range s.Descriptions {
+		if strings.EqualFold(tag, primary) {
+			return translated, true
+		}
+	}
+	return "", false
+}
+
+// ExpandScopeParameters подставляет значения параметров {name} в текст
+func ExpandScopeParameters(text string, params map[string]string) string {
+	for name, value := range params {
+		text = strings.ReplaceAll(text, "{"+name+"}", value)
+	}
+	return text
+}
+
+// scopeParameter возвращает имя параметра, если сегмент имеет вид {name}
+func scopeParameter(segment string) (string, bool) {
+	if len(segment) < 3 || segment[0] != '{' || segment[len(segment)-1] != '}' {
+		return "", false
+	}
+	return segment[1 : len(segment)-1], true
+}
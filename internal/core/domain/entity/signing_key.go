@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+)
+
+// SigningKeyStatus определяет состояние ключа подписи
+type SigningKeyStatus string
+
+const (
+	// SigningKeyActive ключ используется для подписи новых токенов
+	SigningKeyActive SigningKeyStatus = "active"
+	// SigningKeyRetired ключ больше не подписывает, но публикуется для проверки выданных токенов
+	SigningKeyRetired SigningKeyStatus = "retired"
+)
+
+// SigningKey представляет ключ подписи токенов. Закрытый ключ хранится
+// в формате PKCS#8 DER, открытый — в формате PKIX DER.
+type SigningKey struct {
+	ID         string           `json:"kid" validate:"required"`
+	Algorithm  string           `json:"alg" validate:"required"`
+	PrivateKey []byte           `json:"-" validate:"required"`
+	PublicKey  []byte           `json:"public_key" validate:"required"`
+	Status     SigningKeyStatus `json:"status" validate:"required,oneof=active retired"`
+	CreatedAt  time.Time        `json:"created_at" validate:"required"`
+	RetiredAt  *time.Time       `json:"retired_at,omitempty"`
+}
+
+// IsActive проверяет, используется ли ключ для подписи
+func (k *SigningKey) IsActive() bool {
+	return k.Status == SigningKeyActive
+}
+
+// Retire выводит ключ из использования для подписи
+func (k *SigningKey) Retire() {
+	if k.Status == SigningKeyRetired {
+		return
+	}
+	now := time.Now()
+	k.Status = SigningKeyRetired
+	k.RetiredAt = &now
+}
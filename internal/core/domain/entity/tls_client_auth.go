@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// TLSClientAuth ожидаемый субъект сертификата клиента при tls_client_auth.
+// Задается ровно одно поле (RFC 8705, раздел 2.1.2).
+type TLSClientAuth struct {
+	// SubjectDN отличительное имя субъекта в формате RFC 4514
+	SubjectDN string `json:"tls_client_auth_subject_dn,omitempty"`
+	// SANDNS, SANURI, SANIP и SANEmail значение альтернативного имени субъекта
+	SANDNS   string `json:"tls_client_auth_san_dns,omitempty"`
+	SANURI   string `json:"tls_client_auth_san_uri,omitempty"`
+	SANIP    string `json:"tls_client_auth_san_ip,omitempty"`
+	SANEmail string `json:"tls_client_auth_san_email,omitempty"`
+}
+
+// IsZero проверяет, что ожидаемый субъект не задан
+func (t TLSClientAuth) IsZero() bool {
+	return t == TLSClientAuth{}
+}
+
+// validate проверяет, что задано ровно одно корректное значение
+func (t TLSClientAuth) validate() error {
+	set := 0
+	for _, value := range []string{t.SubjectDN, t.SANDNS, t.SANURI, t.SANIP, t.SANEmail} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("tls_client_auth requires exactly one of subject dn, san dns, san uri, san ip or san email")
+	}
+	if t.SANIP != "" && net.ParseIP(t.SANIP) == nil {
+		return fmt.Errorf("invalid tls_client_auth_san_ip: %s", t.SANIP)
+	}
+	if t.SANURI != "" {
+		if uri, err := url.Parse(t.SANURI); err != nil || !uri.IsAbs() {
+			return fmt.Errorf("invalid tls_client_auth_san_uri: %s", t.SANURI)
+		}
+	}
+	return nil
+}
+
+// validateTLSClientAuth проверяет метаданные аутентификации по сертификату
+func (c *Client) validateTLSClientAuth() error {
+	switch c.TokenEndpointAuthMethod {
+	case AuthMethodTLSClientAuth:
+		return c.TLSClientAuth.validate()
+	case AuthMethodSelfSignedTLSClientAuth:
+		if c.JWKS == nil || len(c.JWKS.Keys) == 0 {
+			return fmt.Errorf("self_signed_tls_client_auth requires client jwks")
+		}
+	}
+	if !c.TLSClientAuth.IsZero() {
+		return fmt.Errorf("tls client auth subject is only used with tls_client_auth")
+	}
+	return nil
+}
@@ -21,12 +21,41 @@ type Token struct {
 	Type      TokenType `json:"type" validate:"required,oneof=access_token refresh_token"`
 	Value     string    `json:"value" validate:"required"`
 	Scopes    []string  `json:"scopes" validate:"required,dive,required"`
+	// Audience ресурсы, для которых выпущен токен (RFC 8707), или audience
+	// обмена (RFC 8693); пусто — токен не ограничен ресурсами
+	Audience  []string  `json:"audience,omitempty"`
+	// Actor цепочка делегирования: кто действует от имени пользователя
+	Actor     *TokenActor `json:"act,omitempty"`
+	// Confirmation ключ, которым клиент должен подтверждать владение токеном
+	Confirmation *TokenConfirmation `json:"cnf,omitempty"`
+	// AuthorizationDetails детальные права токена (RFC 9396)
+	AuthorizationDetails AuthorizationDetails `json:"authorization_details,omitempty"`
+	// FamilyID семейство токенов одного гранта: токены, выданные по ротируемому
+	// refresh token, наследуют его семейство. Нулевой — семейство из одного токена.
+	FamilyID uuid.UUID `json:"family_id"`
 	ExpiresAt time.Time `json:"expires_at" validate:"required,gt=now"`
 	CreatedAt time.Time `json:"created_at" validate:"required"`
 	RevokedAt *time.Time `json:"revoked_at,omitempty"`
 	IsRevoked bool      `json:"is_revoked"`
 }
 
+// TokenConfirmation ключ, к которому привязан токен (RFC 7800, раздел 3.1)
+type TokenConfirmation struct {
+	// JKT отпечаток открытого ключа DPoP по RFC 7638 (RFC 9449, раздел 6.1)
+	JKT string `json:"jkt,omitempty"`
+	// X5tS256 отпечаток SHA-256 сертификата клиента mTLS (RFC 8705, раздел 3.1)
+	X5tS256 string `json:"x5t#S256,omitempty"`
+}
+
+// Clone возвращает копию подтверждения
+func (c *TokenConfirmation) Clone() *TokenConfirmation {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	return &clone
+}
+
 // NewToken создает новый токен
 func NewToken(userID, clientID uuid.UUID, tokenType TokenType, scopes []string, expiresIn time.Duration) *Token {
 	now := time.Now()
@@ -53,6 +82,32 @@ func (t *Token) IsValid() bool {
 	return !t.IsExpired() && !t.IsRevoked
 }
 
+// DPoPThumbprint возвращает отпечаток ключа DPoP, к которому привязан токен;
+// пусто для bearer токена
+func (t *Token) DPoPThumbprint() string {
+	if t.Confirmation == nil {
+		return ""
+	}
+	return t.Confirmation.JKT
+}
+
+// CertificateThumbprint возвращает отпечаток сертификата, к которому привязан
+// токен; пусто, если токен не привязан к сертификату
+func (t *Token) CertificateThumbprint() string {
+	if t.Confirmation == nil {
+		return ""
+	}
+	return t.Confirmation.X5tS256
+}
+
+// Family возвращает семейство токена
+func (t *Token) Family() uuid.UUID {
+	if t.FamilyID == uuid.Nil {
+		return t.ID
+	}
+	return t.FamilyID
+}
+
 // Revoke отзывает токен
 func (t *Token) Revoke() {
 	now := time.Now()
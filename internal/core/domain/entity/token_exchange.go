@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// TokenExchangePolicy определяет, какие обмены токенов разрешены клиенту (RFC 8693)
+type TokenExchangePolicy struct {
+	// SubjectClients client_id клиентов, чьи токены клиент может обменивать;
+	// собственные токены клиента разрешены всегда, "*" — токены любых клиентов
+	SubjectClients []string `json:"subject_clients,omitempty"`
+	// Audiences допустимые значения параметров audience и resource
+	Audiences []string `json:"audiences,omitempty"`
+	// AllowDelegation разрешает передавать actor_token и получать токены с claim act
+	AllowDelegation bool `json:"allow_delegation,omitempty"`
+	// AllowImpersonation разрешает обмен без actor_token, при котором новый
+	// токен полностью представляет субъекта
+	AllowImpersonation bool `json:"allow_impersonation,omitempty"`
+}
+
+// AnySubjectClient значение SubjectClients, разрешающее токены любых клиентов
+const AnySubjectClient = "*"
+
+// DefaultTokenExchangePolicy политика клиента без явных настроек: обмен
+// собственных токенов без делегирования и без целевых audience
+func DefaultTokenExchangePolicy() *TokenExchangePolicy {
+	return &TokenExchangePolicy{AllowImpersonation: true}
+}
+
+// IsSubjectClientAllowed проверяет, можно ли обменивать токены клиента subjectClientID
+func (p *TokenExchangePolicy) IsSubjectClientAllowed(clientID, subjectClientID string) bool {
+	if clientID == subjectClientID {
+		return true
+	}
+	for _, allowed := range p.SubjectClients {
+		if allowed == AnySubjectClient || allowed == subjectClientID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAudienceAllowed проверяет, может ли клиент запросить токен для audience
+func (p *TokenExchangePolicy) IsAudienceAllowed(audience string) bool {
+	for _, allowed := range p.Audiences {
+		if allowed == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет согласованность политики
+func (p *TokenExchangePolicy) Validate() error {
+	if !p.AllowDelegation && !p.AllowImpersonation {
+		return fmt.Errorf("either delegation or impersonation must be allowed")
+	}
+	for _, clientID := range p.SubjectClients {
+		if clientID == "" {
+			return fmt.Errorf("subject client id must not be empty")
+		}
+	}
+	for _, audience := range p.Audiences {
+		if audience == "" {
+			return fmt.Errorf("audience must not be empty")
+		}
+		if uri, err := url.Parse(audience); err == nil && uri.Fragment != "" {
+			return fmt.Errorf("audience must not contain a fragment: %s", audience)
+		}
+	}
+	return nil
+}
+
+// Clone возвращает глубокую копию политики
+func (p *TokenExchangePolicy) Clone() *TokenExchangePolicy {
+	clone := *p
+	clone.SubjectClients = append([]string(nil), p.SubjectClients...)
+	clone.Audiences = append([]string(nil), p.Audiences...)
+	return &clone
+}
+
+// TokenActor участник цепочки делегирования (claim act, RFC 8693, раздел 4.1).
+// Вложенный Actor — предыдущий участник цепочки.
+type TokenActor struct {
+	// Subject идентификатор пользователя или client_id, если действует сам клиент
+	Subject  string      `json:"sub"`
+	ClientID string      `json:"client_id,omitempty"`
+	Actor    *TokenActor `json:"act,omitempty"`
+}
+
+// Depth возвращает длину цепочки делегирования
+func (a *TokenActor) Depth() int {
+	depth := 0
+	for actor := a; actor != nil; actor = actor.Actor {
+		depth++
+	}
+	return depth
+}
+
+// Clone возвращает глубокую копию цепочки
+func (a *TokenActor) Clone() *TokenActor {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	clone.Actor = a.Actor.Clone()
+	return &clone
+}
@@ -7,17 +7,20 @@ import (
 
 // User представляет пользователя системы
 type User struct {
-	ID          uuid.UUID  `json:"id" validate:"required"`
-	Email       string     `json:"email" validate:"required,email"`
-	Password    string     `json:"-" validate:"required,min=8"`
-	FirstName   string     `json:"first_name" validate:"required"`
-	LastName    string     `json:"last_name" validate:"required"`
-	Active      bool       `json:"active"`
-	Roles       []Role     `json:"roles" validate:"required,dive,required"`
-	Permissions []string   `json:"permissions,omitempty"`
-	CreatedAt   time.Time  `json:"created_at" validate:"required"`
-	UpdatedAt   time.Time  `json:"updated_at" validate:"required"`
-	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	ID          uuid.UUID              `json:"id" validate:"required"`
+	Email       string                 `json:"email" validate:"required,email"`
+	Password    string                 `json:"-" validate:"required,min=8"`
+	FirstName   string                 `json:"first_name" validate:"required"`
+	LastName    string                 `json:"last_name" validate:"required"`
+	Active      bool                   `json:"active"`
+	Roles       []Role                 `json:"roles" validate:"required,dive,required"`
+	Permissions []string               `json:"permissions,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	CreatedAt   time.Time              `json:"created_at" validate:"required"`
+	UpdatedAt   time.Time              `json:"updated_at" validate:"required"`
+	LastLoginAt *time.Time             `json:"last_login_at,omitempty"`
+
+	events []DomainEvent
 }
 
 // NewUser создает нового пользователя
@@ -43,8 +46,11 @@ func (u *User) AddRole(role Role) {
 			return
 		}
 	}
+	// События роли принадлежат самой роли, а не ее копии у пользователя
+	role.events = nil
 	u.Roles = append(u.Roles, role)
 	u.UpdatedAt = time.Now()
+	u.events = append(u.events, NewDomainEvent(EventUserRolesChanged, u.ID))
 }
 
 // RemoveRole удаляет роль у пользователя
@@ -53,6 +59,7 @@ func (u *User) RemoveRole(roleID uuid.UUID) {
 		if role.ID == roleID {
 			u.Roles = append(u.Roles[:i], u.Roles[i+1:]...)
 			u.UpdatedAt = time.Now()
+			u.events = append(u.events, NewDomainEvent(EventUserRolesChanged, u.ID))
 			return
 		}
 	}
@@ -87,6 +94,59 @@ func (u *User) Activate() {
 	u.UpdatedAt = time.Now()
 }
 
+// SetAttribute устанавливает пользовательский атрибут, используемый в политиках доступа
+func (u *User) SetAttribute(key string, value interface{}) {
+	if u.Attributes == nil {
+		u.Attributes = make(map[string]interface{})
+	}
+	u.Attributes[key] = value
+	u.UpdatedAt = time.Now()
+}
+
+// RemoveAttribute удаляет пользовательский атрибут
+func (u *User) RemoveAttribute(key string) {
+	if _, ok := u.Attributes[key]; !ok {
+		return
+	}
+	delete(u.Attributes, key)
+	u.UpdatedAt = time.Now()
+}
+
+// Attribute возвращает пользовательский атрибут
+func (u *User) Attribute(key string) (interface{}, bool) {
+	value, ok := u.Attributes[key]
+	return value, ok
+}
+
+// PullEvents возвращает накопленные доменные события и очищает их список
+func (u *User) PullEvents() []DomainEvent {
+	events := u.events
+	u.events = nil
+	return events
+}
+
+// Clone возвращает копию пользователя без накопленных доменных событий
+func (u *User) Clone() *User {
+	clone := *u
+	clone.Roles = make([]Role, 0, len(u.Roles))
+	for i := range u.Roles {
+		clone.Roles = append(clone.Roles, *u.Roles[i].Clone())
+	}
+	clone.Permissions = append([]string(nil), u.Permissions...)
+	if u.Attributes != nil {
+		clone.Attributes = make(map[string]interface{}, len(u.Attributes))
+		for key, value := range u.Attributes {
+			clone.Attributes[key] = value
+		}
+	}
+	if u.LastLoginAt != nil {
+		lastLoginAt := *u.LastLoginAt
+		clone.LastLoginAt = &lastLoginAt
+	}
+	clone.events = nil
+	return &clone
+}
+
 // FullName возвращает полное имя пользователя
 func (u *User) FullName() string {
 	return u.FirstName + " " + u.LastName
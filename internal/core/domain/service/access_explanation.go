@@ -0,0 +1,187 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DecisionLogMode определяет, какие решения о доступе записываются в журнал аудита
+type DecisionLogMode string
+
+const (
+	DecisionLogNone   DecisionLogMode = "none"
+	DecisionLogDenied DecisionLogMode = "denied"
+	DecisionLogAll    DecisionLogMode = "all"
+)
+
+// PermissionExplanation описывает разрешение роли, рассмотренное при проверке
+type PermissionExplanation struct {
+	PermissionID string                  `json:"permission_id"`
+	Name         string                  `json:"name"`
+	Permission   string                  `json:"permission"`
+	Effect       entity.PermissionEffect `json:"effect"`
+	Matched      bool                    `json:"matched"`
+}
+
+// RoleExplanation описывает роль пользователя, рассмотренную при проверке
+type RoleExplanation struct {
+	RoleID      string                  `json:"role_id"`
+	RoleName    string                  `json:"role_name"`
+	Permissions []PermissionExplanation `json:"permissions"`
+}
+
+// AccessExplanation объясняет решение о доступе: какие роли и разрешения
+// были рассмотрены, какие из них совпали и почему доступ разрешен или запрещен
+type AccessExplanation struct {
+	UserID             string              `json:"user_id"`
+	Resource           entity.ResourceType `json:"resource"`
+	Action             entity.Action       `json:"action"`
+	CombiningAlgorithm CombiningAlgorithm  `json:"combining_algorithm"`
+	Granted            bool                `json:"granted"`
+	Reason             string              `json:"reason"`
+	Roles              []RoleExplanation   `json:"roles"`
+	MatchedAllows      []string            `json:"matched_allows,omitempty"`
+	MatchedDenies      []string            `json:"matched_denies,omitempty"`
+	EvaluatedAt        time.Time           `json:"evaluated_at"`
+}
+
+// AccessDeniedError ошибка отказа в доступе с объяснением решения
+type AccessDeniedError struct {
+	Explanation *AccessExplanation
+}
+
+// Error реализует интерфейс error. Сообщение не содержит ролей и разрешений
+// пользователя: их раскрывает только Explanation.
+func (e *AccessDeniedError) Error() string {
+	ex := e.Explanation
+	if len(ex.MatchedDenies) > 0 {
+		return fmt.Sprintf("user is explicitly denied permission: %s:%s", ex.Resource, ex.Action)
+	}
+	return fmt.Sprintf("user does not have permission: %s:%s", ex.Resource, ex.Action)
+}
+
+// Explain вычисляет решение о доступе и возвращает его подробное объяснение
+func (pc *PermissionChecker) Explain(user *entity.User, resource entity.ResourceType, action entity.Action) *AccessExplanation {
+	explanation := &AccessExplanation{
+		Resource:           resource,
+		Action:             action,
+		CombiningAlgorithm: pc.algorithm(),
+		Roles:              make([]RoleExplanation, 0),
+		EvaluatedAt:        time.Now(),
+	}
+
+	if user == nil {
+		explanation.Reason = "user is nil"
+		return explanation
+	}
+	explanation.UserID = user.ID.String()
+
+	for _, role := range user.Roles {
+		roleExplanation := RoleExplanation{
+			RoleID:      role.ID.String(),
+			RoleName:    role.Name,
+			Permissions: make([]PermissionExplanation, 0, len(role.Permissions)),
+		}
+
+		for _, permission := range role.Permissions {
+			effect := entity.EffectAllow
+			if permission.IsDeny() {
+				effect = entity.EffectDeny
+			}
+
+			matched := permission.Matches(resource, action)
+			roleExplanation.Permissions = append(roleExplanation.Permissions, PermissionExplanation{
+				PermissionID: permission.ID.String(),
+				Name:         permission.Name,
+				Permission:   permission.String(),
+				Effect:       effect,
+				Matched:      matched,
+			})
+			if !matched {
+				continue
+			}
+
+			source := fmt.Sprintf("%s (%s) in role %s", permission.Name, permission.ID, role.Name)
+			if effect == entity.EffectDeny {
+				explanation.MatchedDenies = append(explanation.MatchedDenies, source)
+			} else {
+				explanation.MatchedAllows = append(explanation.MatchedAllows, source)
+			}
+		}
+
+		explanation.Roles = append(explanation.Roles, roleExplanation)
+	}
+
+	// Решение принимается так же, как в HasPermission (через кеш разрешений),
+	// роли выше лишь объясняют его
+	allowed, denied := pc.matchPermissions(user, resource, action)
+	explanation.Granted = pc.combine(allowed, denied)
+	explanation.Reason = explainReason(explanation, allowed, denied)
+	return explanation
+}
+
+// explainReason формирует человекочитаемую причину решения
+func explainReason(ex *AccessExplanation, allowed, denied bool) string {
+	switch {
+	case ex.Granted && denied:
+		return fmt.Sprintf("allowed by %s; deny from %s overridden by %s",
+			strings.Join(ex.MatchedAllows, ", "), strings.Join(ex.MatchedDenies, ", "), ex.CombiningAlgorithm)
+	case ex.Granted:
+		return "allowed by " + strings.Join(ex.MatchedAllows, ", ")
+	case denied && allowed:
+		return fmt.Sprintf("denied by %s; allow from %s overridden by %s",
+			strings.Join(ex.MatchedDenies, ", "), strings.Join(ex.MatchedAllows, ", "), ex.CombiningAlgorithm)
+	case denied:
+		return "denied by " + strings.Join(ex.MatchedDenies, ", ")
+	case len(ex.Roles) == 0:
+		return "user has no roles"
+	default:
+		return fmt.Sprintf("none of %d roles grants %s:%s", len(ex.Roles), ex.Resource, ex.Action)
+	}
+}
+
+// logsAllDecisions проверяет, записываются ли в журнал и разрешенные запросы
+func (pc *PermissionChecker) logsAllDecisions() bool {
+	return pc.config.AuditLogRepository != nil && pc.config.DecisionLogMode == DecisionLogAll
+}
+
+// logDecision записывает решение о доступе в журнал аудита согласно настройкам
+func (pc *PermissionChecker) logDecision(explanation *AccessExplanation) {
+	if pc.config.AuditLogRepository == nil {
+		return
+	}
+	switch pc.config.DecisionLogMode {
+	case DecisionLogAll:
+	case DecisionLogDenied:
+		if explanation.Granted {
+			return
+		}
+	default:
+		return
+	}
+
+	auditLog := entity.NewAuditLog(
+		explanation.UserID,
+		entity.AuditEventAccessDecision,
+		fmt.Sprintf("access to %s:%s: %s", explanation.Resource, explanation.Action, explanation.Reason),
+		"",
+		"",
+		explanation.Granted,
+	)
+	auditLog.CreatedAt = explanation.EvaluatedAt
+	auditLog.AddMetadata("resource", string(explanation.Resource))
+	auditLog.AddMetadata("action", string(explanation.Action))
+	auditLog.AddMetadata("explanation", explanation)
+
+	if err := pc.config.AuditLogRepository.Save(context.Background(), auditLog); err != nil {
+		log.Error("failed to save access decision audit log",
+			zap.String("user_id", explanation.UserID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,545 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Коды ошибок authorization endpoint (RFC 6749, раздел 4.1.2.1)
+const (
+	AuthorizationErrorInvalidRequest          = "invalid_request"
+	AuthorizationErrorUnauthorizedClient      = "unauthorized_client"
+	AuthorizationErrorAccessDenied            = "access_denied"
+	AuthorizationErrorUnsupportedResponseType = "unsupported_response_type"
+	AuthorizationErrorInvalidScope            = "invalid_scope"
+	// AuthorizationErrorInvalidTarget недопустимый параметр resource (RFC 8707, раздел 2)
+	AuthorizationErrorInvalidTarget = "invalid_target"
+	// AuthorizationErrorInvalidAuthorizationDetails недопустимые authorization_details (RFC 9396, раздел 5)
+	AuthorizationErrorInvalidAuthorizationDetails = "invalid_authorization_details"
+)
+
+// AuthorizationError ошибка запроса авторизации, о которой клиенту сообщают
+// перенаправлением на его redirect_uri
+type AuthorizationError struct {
+	Code        string
+	Description string
+}
+
+func (e *AuthorizationError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func authorizationError(code, format string, args ...interface{}) error {
+	return &AuthorizationError{Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+// Ошибки, при которых перенаправлять пользователя нельзя: адрес клиента не
+// подтвержден (RFC 6749, раздел 4.1.2.1)
+var (
+	ErrUnknownAuthorizationClient      = errors.New("unknown or inactive client")
+	ErrInvalidAuthorizationRedirectURI = errors.New("invalid redirect uri")
+	ErrInvalidRequestURI               = errors.New("invalid, expired or already used request_uri")
+	ErrInvalidPendingAuthorization     = errors.New("authorization request expired or already used")
+)
+
+// ErrInvalidAuthorizationCode код авторизации не найден, истек, уже использован
+// или не прошел проверку PKCE
+var ErrInvalidAuthorizationCode = errors.New("invalid authorization code")
+
+// AuthorizationConfig конфигурация authorization endpoint
+type AuthorizationConfig struct {
+	// RequirePushedRequests все клиенты должны передавать запросы через PAR
+	RequirePushedRequests bool
+	// RequirePKCE PKCE обязателен для всех клиентов; публичным клиентам он нужен всегда
+	RequirePKCE bool
+	// AllowPlainPKCE разрешает метод plain вместо S256
+	AllowPlainPKCE bool
+	// PushedRequestLifetime срок действия request_uri (RFC 9126, раздел 2.2)
+	PushedRequestLifetime time.Duration
+	// ConsentLifetime сколько запрос ждет решения пользователя на странице согласия
+	ConsentLifetime time.Duration
+	// RequestIDBytes число случайных байт в идентификаторах сохраненных запросов
+	RequestIDBytes int
+
+	// Issuer идентификатор сервера, который объект запроса должен содержать в aud (RFC 9101)
+	Issuer string
+	// RequireSignedRequestObject все клиенты должны передавать параметры в объекте запроса
+	RequireSignedRequestObject bool
+	// RequestObjectLifetime насколько далеко в будущем может быть exp объекта запроса
+	RequestObjectLifetime time.Duration
+	// DecryptionKeys ключи сервера для зашифрованных объектов запроса; пусто — шифрование не поддерживается
+	DecryptionKeys []RequestObjectDecryptionKey
+	// FetchRequestObject загружает объект запроса по request_uri; nil — загрузка по https
+	FetchRequestObject RequestObjectFetcher
+	// ClientKeys источник ключей клиентов для проверки подписи; nil — конфигурация по умолчанию
+	ClientKeys *ClientKeys
+	// Resources ресурсы, которые можно запрашивать параметром resource (RFC 8707);
+	// nil — параметр не поддерживается
+	Resources *ResourceIndicators
+	// AuthorizationDetails типы authorization_details (RFC 9396); nil — параметр не поддерживается
+	AuthorizationDetails *AuthorizationDetailTypes
+	// Scopes реестр областей: иерархия, шаблоны и области по умолчанию;
+	// nil — области клиента проверяются точным совпадением
+	Scopes *ScopeRegistry
+}
+
+// DefaultAuthorizationConfig возвращает конфигурацию по умолчанию
+func DefaultAuthorizationConfig() *AuthorizationConfig {
+	return &AuthorizationConfig{
+		PushedRequestLifetime: time.Minute,
+		ConsentLifetime:       10 * time.Minute,
+		RequestIDBytes:        32,
+		RequestObjectLifetime: time.Hour,
+	}
+}
+
+// AuthorizationService проверяет запросы авторизации, принимает их через PAR
+// (RFC 9126) и выдает и погашает коды авторизации
+type AuthorizationService struct {
+	clients   ports.ClientRepository
+	codes     ports.AuthCodeRepository
+	pending   ports.PendingAuthorizationRepository
+	generator *TokenGenerator
+	validator *TokenValidator
+	config    *AuthorizationConfig
+	keys      *ClientKeys
+}
+
+// NewAuthorizationService создает новый экземпляр AuthorizationService
+func NewAuthorizationService(clients ports.ClientRepository, codes ports.AuthCodeRepository, pending ports.PendingAuthorizationRepository, generator *TokenGenerator, validator *TokenValidator, config *AuthorizationConfig) *AuthorizationService {
+	if generator == nil {
+		generator = NewTokenGenerator(nil)
+	}
+	if validator == nil {
+		validator = NewTokenValidator()
+	}
+	if config == nil {
+		config = DefaultAuthorizationConfig()
+	}
+	keys := config.ClientKeys
+	if keys == nil {
+		keys = NewClientKeys(nil)
+	}
+	return &AuthorizationService{
+		clients:   clients,
+		codes:     codes,
+		pending:   pending,
+		generator: generator,
+		validator: validator,
+		config:    config,
+		keys:      keys,
+	}
+}
+
+// Config возвращает конфигурацию сервиса
+func (s *AuthorizationService) Config() AuthorizationConfig {
+	return *s.config
+}
+
+// CodeChallengeMethods возвращает методы PKCE, которые принимает сервер
+func (s *AuthorizationService) CodeChallengeMethods() []string {
+	if s.config.AllowPlainPKCE {
+		return entity.SupportedCodeChallengeMethods()
+	}
+	return []string{entity.CodeChallengeMethodS256}
+}
+
+// Validate проверяет запрос авторизации и дополняет его значениями по умолчанию:
+// единственным redirect_uri клиента и, если не запрошены ни области, ни
+// authorization_details, его областями действия.
+// Ошибки клиента и redirect_uri не оборачиваются в AuthorizationError: о них
+// нельзя сообщать перенаправлением.
+func (s *AuthorizationService) Validate(ctx context.Context, req *entity.AuthorizationRequest) (*entity.Client, error) {
+	client, err := s.activeClient(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RedirectURI == "" {
+		if len(client.RedirectURIs) != 1 {
+			return nil, fmt.Errorf("%w: redirect_uri is required", ErrInvalidAuthorizationRedirectURI)
+		}
+		req.RedirectURI = client.RedirectURIs[0]
+		req.RedirectURIDefaulted = true
+	} else if !client.IsRedirectURIAllowed(req.RedirectURI) {
+		return nil, fmt.Errorf("%w: %s is not registered", ErrInvalidAuthorizationRedirectURI, req.RedirectURI)
+	}
+
+	if err := s.validator.ValidateClient(client, entity.GrantTypeAuthCode); err != nil {
+		return client, authorizationError(AuthorizationErrorUnauthorizedClient, "%v", err)
+	}
+
+	if req.ResponseType == "" {
+		return client, authorizationError(AuthorizationErrorInvalidRequest, "response_type is required")
+	}
+	if req.ResponseType != entity.ResponseTypeCode || !client.IsResponseTypeAllowed(req.ResponseType) {
+		return client, authorizationError(AuthorizationErrorUnsupportedResponseType, "response type %s is not allowed", req.ResponseType)
+	}
+
+	if len(req.Scopes) == 0 && len(req.AuthorizationDetails) == 0 {
+		req.Scopes = s.config.Scopes.Defaults(client)
+	}
+	for _, scope := range req.Scopes {
+		if !s.config.Scopes.IsAllowed(client, scope) {
+			return client, authorizationError(AuthorizationErrorInvalidScope, "scope %s is not allowed", scope)
+		}
+	}
+
+	if err := s.config.AuthorizationDetails.Validate(client, req.AuthorizationDetails); err != nil {
+		return client, authorizationError(AuthorizationErrorInvalidAuthorizationDetails, "%v", err)
+	}
+	if err := s.validateResources(req); err != nil {
+		return client, err
+	}
+	if err := s.validatePKCE(client, req); err != nil {
+		return client, err
+	}
+	return client, nil
+}
+
+// validateResources проверяет запрошенные ресурсы: каждый должен быть
+// зарегистрирован и принимать хотя бы одну из запрошенных областей (RFC 8707, раздел 2)
+func (s *AuthorizationService) validateResources(req *entity.AuthorizationRequest) error {
+	if len(req.Resources) == 0 {
+		return nil
+	}
+	// Запрос сам определяет грант, поэтому проверяется только регистрация
+	// ресурсов и их области
+	_, _, err := s.config.Resources.Narrow(req.Scopes, req.Resources, req.Resources, nil)
+	switch {
+	case errors.Is(err, ErrInvalidTarget):
+		return authorizationError(AuthorizationErrorInvalidTarget, "%v", err)
+	case errors.Is(err, ErrInvalidResourceScope):
+		return authorizationError(AuthorizationErrorInvalidScope, "%v", err)
+	}
+	return err
+}
+
+// validatePKCE проверяет параметры PKCE (RFC 7636, раздел 4.3)
+func (s *AuthorizationService) validatePKCE(client *entity.Client, req *entity.AuthorizationRequest) error {
+	if req.CodeChallenge == "" {
+		if req.CodeChallengeMethod != "" {
+			return authorizationError(AuthorizationErrorInvalidRequest, "code_challenge_method without code_challenge")
+		}
+		if client.IsPublic() || s.config.RequirePKCE {
+			return authorizationError(AuthorizationErrorInvalidRequest, "code_challenge is required")
+		}
+		return nil
+	}
+
+	if req.CodeChallengeMethod == "" {
+		req.CodeChallengeMethod = entity.CodeChallengeMethodPlain
+	}
+	if !containsString(s.CodeChallengeMethods(), req.CodeChallengeMethod) {
+		return authorizationError(AuthorizationErrorInvalidRequest, "code challenge method %s is not supported", req.CodeChallengeMethod)
+	}
+	if !isCodeVerifierFormat(req.CodeChallenge) {
+		return authorizationError(AuthorizationErrorInvalidRequest, "code_challenge must be 43-128 unreserved characters")
+	}
+	return nil
+}
+
+// Push проверяет запрос, переданный аутентифицированным клиентом через PAR,
+// и сохраняет его; request_uri действует PushedRequestLifetime и используется один раз.
+// Если передан объект запроса, параметры берутся только из него (RFC 9126, раздел 3).
+func (s *AuthorizationService) Push(ctx context.Context, client *entity.Client, req entity.AuthorizationRequest, requestObject string) (*entity.PendingAuthorization, error) {
+	if req.ClientID != "" && req.ClientID != client.ClientID {
+		return nil, authorizationError(AuthorizationErrorInvalidRequest, "client_id does not match the authenticated client")
+	}
+	if requestObject != "" {
+		var err error
+		if req, err = s.parseRequestObject(ctx, client, requestObject); err != nil {
+			return nil, err
+		}
+	} else if s.requiresRequestObject(client) {
+		return nil, authorizationError(AuthorizationErrorInvalidRequest, "authorization parameters must be sent in a signed request object")
+	}
+	req.ClientID = client.ClientID
+	if _, err := s.Validate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.store(ctx, client, uuid.Nil, req, true, s.config.PushedRequestLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("authorization request pushed",
+		zap.String("client_id", client.ClientID),
+		zap.Bool("request_object", requestObject != ""),
+		zap.Time("expires_at", pending.ExpiresAt),
+	)
+	return pending, nil
+}
+
+// Resolve возвращает запрос для authorization endpoint. Запрос с request_uri
+// берется из сохраненных через PAR и погашается; остальные параметры, кроме
+// client_id, при этом игнорируются (RFC 9126, раздел 4). Объект запроса
+// передается значением в request или ссылкой в request_uri, зарегистрированном
+// клиентом; параметры также берутся только из него (RFC 9101, раздел 6.3).
+// Если клиенту нужен PAR, прочие запросы отклоняются.
+// Вместе с AuthorizationError возвращается запрос с проверенным redirect_uri.
+func (s *AuthorizationService) Resolve(ctx context.Context, params entity.AuthorizationRequest, requestObject, requestURI string) (*entity.Client, *entity.AuthorizationRequest, error) {
+	if requestObject != "" && requestURI != "" {
+		return nil, nil, fmt.Errorf("%w: request and request_uri must not be used together", ErrInvalidRequestObject)
+	}
+	if strings.HasPrefix(requestURI, entity.RequestURIPrefix) {
+		return s.resolvePushed(ctx, params.ClientID, requestURI)
+	}
+
+	if requestObject != "" || requestURI != "" {
+		client, err := s.activeClient(ctx, params.ClientID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if requestURI != "" {
+			if requestObject, err = s.loadRequestObject(ctx, client, requestURI); err != nil {
+				return nil, nil, err
+			}
+		}
+		if params, err = s.parseRequestObject(ctx, client, requestObject); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client, err := s.Validate(ctx, &params)
+	if err != nil {
+		return client, &params, err
+	}
+	if client.RequirePushedAuthorizationRequests || s.config.RequirePushedRequests {
+		return client, &params, authorizationError(AuthorizationErrorInvalidRequest, "authorization requests must be pushed to the PAR endpoint")
+	}
+	if requestObject == "" && s.requiresRequestObject(client) {
+		return client, &params, authorizationError(AuthorizationErrorInvalidRequest, "authorization parameters must be sent in a signed request object")
+	}
+	return client, &params, nil
+}
+
+// resolvePushed погашает запрос, сохраненный через PAR
+func (s *AuthorizationService) resolvePushed(ctx context.Context, clientID, requestURI string) (*entity.Client, *entity.AuthorizationRequest, error) {
+	id := strings.TrimPrefix(requestURI, entity.RequestURIPrefix)
+	if id == "" {
+		return nil, nil, ErrInvalidRequestURI
+	}
+	pending, err := s.take(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !pending.Pushed || pending.Request.ClientID != clientID {
+		return nil, nil, ErrInvalidRequestURI
+	}
+
+	// Клиент мог измениться после PAR, поэтому запрос проверяется повторно
+	req := pending.Request
+	client, err := s.Validate(ctx, &req)
+	if err != nil {
+		return client, &req, err
+	}
+	return client, &req, nil
+}
+
+// requiresRequestObject сообщает, должен ли клиент передавать параметры в объекте запроса
+func (s *AuthorizationService) requiresRequestObject(client *entity.Client) bool {
+	return client.RequireSignedRequestObject || s.config.RequireSignedRequestObject
+}
+
+// activeClient возвращает активного клиента по client_id
+func (s *AuthorizationService) activeClient(ctx context.Context, clientID string) (*entity.Client, error) {
+	if clientID == "" {
+		return nil, fmt.Errorf("%w: client_id is required", ErrUnknownAuthorizationClient)
+	}
+	client, err := s.clients.GetByClientID(ctx, clientID)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrUnknownAuthorizationClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.Active {
+		return nil, ErrUnknownAuthorizationClient
+	}
+	return client, nil
+}
+
+// Hold сохраняет проверенный запрос, пока пользователь принимает решение на
+// странице согласия. Решение принимается по идентификатору запроса.
+func (s *AuthorizationService) Hold(ctx context.Context, client *entity.Client, userID uuid.UUID, req entity.AuthorizationRequest) (*entity.PendingAuthorization, error) {
+	return s.store(ctx, client, userID, req, false, s.config.ConsentLifetime)
+}
+
+// Take погашает запрос, ожидающий решения пользователя userID
+func (s *AuthorizationService) Take(ctx context.Context, id string, userID uuid.UUID) (*entity.Client, *entity.PendingAuthorization, error) {
+	pending, err := s.take(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending.Pushed || pending.UserID != userID {
+		return nil, nil, ErrInvalidPendingAuthorization
+	}
+
+	client, err := s.clients.GetByID(ctx, pending.ClientID)
+	if err != nil || !client.Active {
+		return nil, nil, ErrInvalidPendingAuthorization
+	}
+	return client, pending, nil
+}
+
+// IssueCode выдает код авторизации по одобренному запросу
+func (s *AuthorizationService) IssueCode(ctx context.Context, client *entity.Client, userID uuid.UUID, req entity.AuthorizationRequest) (*entity.AuthCode, error) {
+	code, err := s.generator.GenerateAuthCode(userID, client.ID, req.RedirectURI, req.Scopes, req.CodeChallenge, req.CodeChallengeMethod)
+	if err != nil {
+		return nil, err
+	}
+	code.Resources = append([]string(nil), req.Resources...)
+	code.AuthorizationDetails = req.AuthorizationDetails.Clone()
+	code.RedirectURIRequired = !req.RedirectURIDefaulted
+	if err := s.codes.Create(ctx, code); err != nil {
+		return nil, fmt.Errorf("failed to store authorization code: %w", err)
+	}
+
+	log.Info("authorization code issued",
+		zap.String("client_id", client.ClientID),
+		zap.String("user_id", userID.String()),
+		zap.Strings("scopes", req.Scopes),
+		zap.Strings("resources", req.Resources),
+		zap.Strings("authorization_details_types", req.AuthorizationDetails.Types()),
+	)
+	return code, nil
+}
+
+// RedeemCode погашает код авторизации на token endpoint. Код удаляется до
+// проверок, поэтому при одновременных обращениях и после любой неудачной
+// попытки он больше не принимается. redirect_uri обязателен, если был передан
+// в запросе авторизации, и должен совпадать с ним (RFC 6749, раздел 4.1.3);
+// code_verifier обязателен для кодов, выданных с PKCE, и запрещен для
+// остальных (RFC 7636, раздел 4.6)
+func (s *AuthorizationService) RedeemCode(ctx context.Context, client *entity.Client, value, redirectURI, codeVerifier string) (*entity.AuthCode, error) {
+	code, err := s.codes.GetByCode(ctx, value)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidAuthorizationCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.codes.Delete(ctx, value)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidAuthorizationCode
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	if redirectURI == "" {
+		if code.RedirectURIRequired {
+			return nil, fmt.Errorf("%w: redirect_uri is required", ErrInvalidAuthorizationCode)
+		}
+		redirectURI = code.RedirectURI
+	}
+	if err := s.validator.ValidateAuthCode(code, client.ID.String(), redirectURI); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAuthorizationCode, err)
+	}
+	if err := verifyCodeChallenge(code, codeVerifier); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAuthorizationCode, err)
+	}
+	code.MarkAsUsed()
+	return code, nil
+}
+
+// store сохраняет запрос под новым случайным идентификатором
+func (s *AuthorizationService) store(ctx context.Context, client *entity.Client, userID uuid.UUID, req entity.AuthorizationRequest, pushed bool, lifetime time.Duration) (*entity.PendingAuthorization, error) {
+	id, err := randomToken(s.config.RequestIDBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pending := &entity.PendingAuthorization{
+		ID:        id,
+		ClientID:  client.ID,
+		Request:   req.Clone(),
+		Pushed:    pushed,
+		UserID:    userID,
+		ExpiresAt: now.Add(lifetime),
+		CreatedAt: now,
+	}
+	if err := s.pending.Create(ctx, pending); err != nil {
+		return nil, fmt.Errorf("failed to store authorization request: %w", err)
+	}
+	return pending, nil
+}
+
+// take находит и удаляет сохраненный запрос. Удаление гарантирует, что при
+// одновременных обращениях запрос получит только один вызов.
+func (s *AuthorizationService) take(ctx context.Context, id string) (*entity.PendingAuthorization, error) {
+	pending, err := s.pending.GetByID(ctx, id)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidRequestURI
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pending.Delete(ctx, id)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidRequestURI
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pending.IsExpired(time.Now()) {
+		return nil, ErrInvalidRequestURI
+	}
+	return pending, nil
+}
+
+// verifyCodeChallenge сверяет code_verifier с code_challenge кода авторизации
+func verifyCodeChallenge(code *entity.AuthCode, verifier string) error {
+	if code.CodeChallenge == "" {
+		if verifier != "" {
+			return fmt.Errorf("code_verifier was not expected")
+		}
+		return nil
+	}
+	if !isCodeVerifierFormat(verifier) {
+		return fmt.Errorf("code_verifier is missing or malformed")
+	}
+
+	expected := verifier
+	if code.CodeMethod == entity.CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(code.CodeChallenge)) != 1 {
+		return fmt.Errorf("code_verifier does not match code_challenge")
+	}
+	return nil
+}
+
+// isCodeVerifierFormat проверяет формат code_verifier и code_challenge:
+// 43-128 символов из набора unreserved (RFC 7636, раздел 4.1)
+func isCodeVerifierFormat(value string) bool {
+	if len(value) < 43 || len(value) > 128 {
+		return false
+	}
+	for _, c := range value {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '-', c == '.', c == '_', c == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
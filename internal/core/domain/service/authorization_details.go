@@ -0,0 +1,111 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jsonschema"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidAuthorizationDetails authorization_details неизвестного типа, не
+// соответствуют схеме типа или выходят за пределы гранта (RFC 9396, раздел 5)
+var ErrInvalidAuthorizationDetails = errors.New("invalid authorization details")
+
+// registeredDetailType тип authorization_details со скомпилированной схемой
+type registeredDetailType struct {
+	entity.AuthorizationDetailType
+	schema *jsonschema.Schema
+}
+
+// AuthorizationDetailTypes реестр типов authorization_details, которые принимает
+// сервер (RFC 9396). Объекты каждого типа проверяются по его JSON Schema.
+// Методы nil реестра отклоняют любые authorization_details.
+type AuthorizationDetailTypes struct {
+	types map[string]registeredDetailType
+}
+
+// NewAuthorizationDetailTypes создает реестр типов
+func NewAuthorizationDetailTypes(types ...entity.AuthorizationDetailType) (*AuthorizationDetailTypes, error) {
+	registry := &AuthorizationDetailTypes{types: make(map[string]registeredDetailType, len(types))}
+	for _, detailType := range types {
+		if detailType.Type == "" {
+			return nil, fmt.Errorf("authorization detail type is required")
+		}
+		if _, exists := registry.types[detailType.Type]; exists {
+			return nil, fmt.Errorf("authorization detail type %s is registered twice", detailType.Type)
+		}
+		registered := registeredDetailType{AuthorizationDetailType: detailType}
+		if len(detailType.Schema) > 0 {
+			schema, err := jsonschema.Compile(detailType.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("authorization detail type %s: %w", detailType.Type, err)
+			}
+			registered.schema = schema
+		}
+		registry.types[detailType.Type] = registered
+	}
+	return registry, nil
+}
+
+// Types возвращает поддерживаемые типы для метаданных сервера
+// (authorization_details_types_supported)
+func (r *AuthorizationDetailTypes) Types() []string {
+	if r == nil {
+		return nil
+	}
+	types := make([]string, 0, len(r.types))
+	for name := range r.types {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Type возвращает описание типа
+func (r *AuthorizationDetailTypes) Type(name string) (entity.AuthorizationDetailType, bool) {
+	if r == nil {
+		return entity.AuthorizationDetailType{}, false
+	}
+	registered, ok := r.types[name]
+	return registered.AuthorizationDetailType, ok
+}
+
+// Validate проверяет, что каждый объект имеет зарегистрированный тип, разрешенный
+// клиенту, и соответствует схеме этого типа
+func (r *AuthorizationDetailTypes) Validate(client *entity.Client, details entity.AuthorizationDetails) error {
+	for i, detail := range details {
+		if r == nil {
+			return fmt.Errorf("%w: authorization_details are not supported", ErrInvalidAuthorizationDetails)
+		}
+		registered, ok := r.types[detail.Type()]
+		if !ok {
+			return fmt.Errorf("%w: unknown type %q", ErrInvalidAuthorizationDetails, detail.Type())
+		}
+		if !client.IsAuthorizationDetailTypeAllowed(detail.Type()) {
+			return fmt.Errorf("%w: type %s is not allowed for the client", ErrInvalidAuthorizationDetails, detail.Type())
+		}
+		if registered.schema == nil {
+			continue
+		}
+		if err := registered.schema.Validate(map[string]interface{}(detail)); err != nil {
+			return fmt.Errorf("%w: authorization_details[%d]: %v", ErrInvalidAuthorizationDetails, i, err)
+		}
+	}
+	return nil
+}
+
+// NarrowAuthorizationDetails возвращает права access token: запрошенные на
+// token endpoint объекты должны в точности совпадать с объектами гранта
+// (RFC 9396, раздел 6.1); без запроса токен получает весь грант
+func NarrowAuthorizationDetails(granted, requested entity.AuthorizationDetails) (entity.AuthorizationDetails, error) {
+	if len(requested) == 0 {
+		return granted.Clone(), nil
+	}
+	for i, detail := range requested {
+		if !granted.Contains(detail) {
+			return nil, fmt.Errorf("%w: authorization_details[%d] was not granted", ErrInvalidAuthorizationDetails, i)
+		}
+	}
+	return requested.Clone(), nil
+}
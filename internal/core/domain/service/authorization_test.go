@@ -0,0 +1,87 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newTestAuthorization(t *testing.T) (*AuthorizationService, *entity.Client) {
+	t.Helper()
+	store := memory.NewStore()
+	client := newTestClient()
+	if err := store.Clients.Create(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	return NewAuthorizationService(store.Clients, store.AuthCodes, store.PendingAuthorizations, nil, nil, nil), client
+}
+
+// issueTestCode проверяет запрос авторизации с redirect_uri и выдает по нему код
+func issueTestCode(t *testing.T, s *AuthorizationService, client *entity.Client, redirectURI string) *entity.AuthCode {
+	t.Helper()
+	ctx := context.Background()
+	req := entity.AuthorizationRequest{
+		ClientID:     client.ClientID,
+		ResponseType: entity.ResponseTypeCode,
+		RedirectURI:  redirectURI,
+		Scopes:       []string{"openid"},
+	}
+	if _, err := s.Validate(ctx, &req); err != nil {
+		t.Fatal(err)
+	}
+	code, err := s.IssueCode(ctx, client, uuid.New(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestRedeemCodeSucceedsOnce(t *testing.T) {
+	s, client := newTestAuthorization(t)
+	code := issueTestCode(t, s, client, client.RedirectURIs[0])
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		redeemed int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.RedeemCode(context.Background(), client, code.Code, client.RedirectURIs[0], ""); err == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if redeemed != 1 {
+		t.Fatalf("code redeemed %d times, want 1", redeemed)
+	}
+}
+
+func TestRedeemCodeRequiresRedirectURIWhenRequested(t *testing.T) {
+	s, client := newTestAuthorization(t)
+	ctx := context.Background()
+
+	code := issueTestCode(t, s, client, client.RedirectURIs[0])
+	if _, err := s.RedeemCode(ctx, client, code.Code, "", ""); !errors.Is(err, ErrInvalidAuthorizationCode) {
+		t.Errorf("missing redirect_uri: error = %v, want ErrInvalidAuthorizationCode", err)
+	}
+	if _, err := s.RedeemCode(ctx, client, code.Code, client.RedirectURIs[0], ""); !errors.Is(err, ErrInvalidAuthorizationCode) {
+		t.Errorf("code was accepted after a failed attempt: error = %v", err)
+	}
+
+	// Адрес не передавался в запросе авторизации, поэтому может быть опущен
+	code = issueTestCode(t, s, client, "")
+	if _, err := s.RedeemCode(ctx, client, code.Code, "", ""); err != nil {
+		t.Errorf("defaulted redirect_uri: %v", err)
+	}
+}
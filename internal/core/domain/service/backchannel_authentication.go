@@ -0,0 +1,349 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ошибки запроса аутентификации по обратному каналу (CIBA Core, раздел 13)
+var (
+	ErrUnknownUserID         = errors.New("unknown user")
+	ErrInvalidBindingMessage = errors.New("invalid binding message")
+)
+
+// Ошибки опроса token endpoint по auth_req_id (CIBA Core, раздел 11);
+// кроме них Poll возвращает ErrAuthorizationPending и ErrSlowDown
+var (
+	ErrAuthReqIDExpired        = errors.New("auth_req_id expired")
+	ErrBackchannelAccessDenied = errors.New("access denied by user")
+	ErrInvalidAuthReqID        = errors.New("invalid auth_req_id")
+)
+
+// ErrInvalidBackchannelRequest запрос аутентификации не найден, истек, уже
+// решен или адресован другому пользователю
+var ErrInvalidBackchannelRequest = errors.New("invalid or expired authentication request")
+
+// LoginHintResolver находит пользователя по login_hint; ports.ErrNotFound —
+// пользователь неизвестен
+type LoginHintResolver func(ctx context.Context, loginHint string) (*entity.User, error)
+
+// ClientNotificationSender отправляет уведомление на адрес клиента с bearer токеном
+// client_notification_token (CIBA Core, разделы 10.2 и 10.3)
+type ClientNotificationSender func(ctx context.Context, endpoint, token string, payload interface{}) error
+
+// BackchannelAuthenticationConfig конфигурация аутентификации по обратному каналу
+type BackchannelAuthenticationConfig struct {
+	// RequestLifetime срок действия auth_req_id; requested_expiry клиента может
+	// только сократить его
+	RequestLifetime time.Duration
+	// PollInterval минимальный интервал опроса token endpoint
+	PollInterval time.Duration
+	// SlowDownIncrement на сколько увеличивается интервал после ответа slow_down
+	SlowDownIncrement time.Duration
+	// AuthReqIDBytes число случайных байт в auth_req_id
+	AuthReqIDBytes int
+	// MaxBindingMessageLength максимальная длина binding_message в символах
+	MaxBindingMessageLength int
+	// ResolveLoginHint находит пользователя по login_hint; nil — по идентификатору
+	// пользователя или адресу электронной почты
+	ResolveLoginHint LoginHintResolver
+	// NotifyClient отправляет уведомления клиентам; nil — POST по https
+	NotifyClient ClientNotificationSender
+}
+
+// DefaultBackchannelAuthenticationConfig возвращает конфигурацию по умолчанию
+func DefaultBackchannelAuthenticationConfig() *BackchannelAuthenticationConfig {
+	return &BackchannelAuthenticationConfig{
+		RequestLifetime:         5 * time.Minute,
+		PollInterval:            5 * time.Second,
+		SlowDownIncrement:       5 * time.Second,
+		AuthReqIDBytes:          32,
+		MaxBindingMessageLength: 64,
+	}
+}
+
+// BackchannelAuthenticationRequest параметры запроса аутентификации клиента
+type BackchannelAuthenticationRequest struct {
+	LoginHint               string
+	Scopes                  []string
+	BindingMessage          string
+	ClientNotificationToken string
+	// RequestedExpiry желаемый срок действия запроса; 0 — значение сервера
+	RequestedExpiry time.Duration
+}
+
+// BackchannelAuthenticationService реализует Client-Initiated Backchannel
+// Authentication: клиент запрашивает аутентификацию пользователя, сервер
+// доставляет запрос на устройство пользователя, а результат клиент получает
+// опросом token endpoint (poll), после уведомления (ping) или сразу вместе
+// с токенами (push)
+type BackchannelAuthenticationService struct {
+	requests ports.BackchannelAuthenticationRepository
+	users    ports.UserRepository
+	notifier ports.AuthenticationDeviceNotifier
+	config   *BackchannelAuthenticationConfig
+
+	resolveLoginHint LoginHintResolver
+	notifyClient     ClientNotificationSender
+}
+
+// NewBackchannelAuthenticationService создает новый экземпляр BackchannelAuthenticationService
+func NewBackchannelAuthenticationService(requests ports.BackchannelAuthenticationRepository, users ports.UserRepository, notifier ports.AuthenticationDeviceNotifier, config *BackchannelAuthenticationConfig) *BackchannelAuthenticationService {
+	if config == nil {
+		config = DefaultBackchannelAuthenticationConfig()
+	}
+	s := &BackchannelAuthenticationService{
+		requests:         requests,
+		users:            users,
+		notifier:         notifier,
+		config:           config,
+		resolveLoginHint: config.ResolveLoginHint,
+		notifyClient:     config.NotifyClient,
+	}
+	if s.resolveLoginHint == nil {
+		s.resolveLoginHint = s.findUser
+	}
+	if s.notifyClient == nil {
+		s.notifyClient = sendClientNotification
+	}
+	return s
+}
+
+// Start находит пользователя по login_hint, создает запрос аутентификации и
+// доставляет его на устройство пользователя
+func (s *BackchannelAuthenticationService) Start(ctx context.Context, client *entity.Client, req BackchannelAuthenticationRequest) (*entity.BackchannelAuthentication, error) {
+	if utf8.RuneCountInString(req.BindingMessage) > s.config.MaxBindingMessageLength {
+		return nil, fmt.Errorf("%w: binding_message is longer than %d characters", ErrInvalidBindingMessage, s.config.MaxBindingMessageLength)
+	}
+
+	user, err := s.resolveLoginHint(ctx, req.LoginHint)
+	if errors.Is(err, ports.ErrNotFound) || (err == nil && !user.Active) {
+		return nil, ErrUnknownUserID
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve login hint: %w", err)
+	}
+
+	authReqID, err := randomToken(s.config.AuthReqIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	lifetime := s.config.RequestLifetime
+	if req.RequestedExpiry > 0 && req.RequestedExpiry < lifetime {
+		lifetime = req.RequestedExpiry
+	}
+
+	now := time.Now()
+	request := &entity.BackchannelAuthentication{
+		ID:                      uuid.New(),
+		AuthReqID:               authReqID,
+		ClientID:                client.ID,
+		UserID:                  user.ID,
+		Scopes:                  req.Scopes,
+		BindingMessage:          req.BindingMessage,
+		DeliveryMode:            client.BackchannelDeliveryMode(),
+		ClientNotificationToken: req.ClientNotificationToken,
+		Status:                  entity.BackchannelAuthenticationPending,
+		Interval:                s.config.PollInterval,
+		ExpiresAt:               now.Add(lifetime),
+		CreatedAt:               now,
+	}
+	if err := s.requests.Create(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to store authentication request: %w", err)
+	}
+
+	if err := s.notifier.Notify(ctx, user, client, request); err != nil {
+		s.remove(ctx, request)
+		return nil, fmt.Errorf("failed to notify authentication device: %w", err)
+	}
+
+	log.Info("backchannel authentication started",
+		zap.String("request_id", request.ID.String()),
+		zap.String("client_id", client.ClientID),
+		zap.String("user_id", user.ID.String()),
+		zap.String("delivery_mode", string(request.DeliveryMode)),
+		zap.Strings("scopes", req.Scopes),
+	)
+	return request, nil
+}
+
+// Pending возвращает ожидающий решения запрос, адресованный пользователю
+func (s *BackchannelAuthenticationService) Pending(ctx context.Context, id uuid.UUID, user *entity.User) (*entity.BackchannelAuthentication, error) {
+	request, err := s.requests.GetByID(ctx, id)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidBackchannelRequest
+	}
+	if err != nil {
+		return nil, err
+	}
+	if request.UserID != user.ID || !request.IsPending() || request.IsExpired(time.Now()) {
+		return nil, ErrInvalidBackchannelRequest
+	}
+	return request, nil
+}
+
+// Approve фиксирует подтверждение пользователя и возвращает решенный запрос
+func (s *BackchannelAuthenticationService) Approve(ctx context.Context, id uuid.UUID, user *entity.User) (*entity.BackchannelAuthentication, error) {
+	return s.decide(ctx, id, user, (*entity.BackchannelAuthentication).Approve)
+}
+
+// Deny фиксирует отказ пользователя и возвращает решенный запрос
+func (s *BackchannelAuthenticationService) Deny(ctx context.Context, id uuid.UUID, user *entity.User) (*entity.BackchannelAuthentication, error) {
+	return s.decide(ctx, id, user, (*entity.BackchannelAuthentication).Deny)
+}
+
+func (s *BackchannelAuthenticationService) decide(ctx context.Context, id uuid.UUID, user *entity.User, decision func(*entity.BackchannelAuthentication)) (*entity.BackchannelAuthentication, error) {
+	request, err := s.Pending(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+	decision(request)
+	if err := s.requests.Update(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to update authentication request: %w", err)
+	}
+
+	log.Info("backchannel authentication decided",
+		zap.String("request_id", request.ID.String()),
+		zap.String("user_id", user.ID.String()),
+		zap.String("status", string(request.Status)),
+	)
+	return request, nil
+}
+
+// Poll обрабатывает опрос token endpoint клиентом. Подтвержденный запрос
+// возвращается один раз и удаляется; в остальных случаях возвращается одна
+// из ошибок ErrAuthorizationPending, ErrSlowDown, ErrAuthReqIDExpired,
+// ErrBackchannelAccessDenied или ErrInvalidAuthReqID. Клиенты в режиме push
+// токены не опрашивают (CIBA Core, раздел 10.3).
+func (s *BackchannelAuthenticationService) Poll(ctx context.Context, client *entity.Client, authReqID string) (*entity.BackchannelAuthentication, error) {
+	request, err := s.requests.GetByAuthReqID(ctx, authReqID)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidAuthReqID
+	}
+	if err != nil {
+		return nil, err
+	}
+	if request.ClientID != client.ID {
+		log.Warn("auth_req_id presented by another client",
+			zap.String("request_id", request.ID.String()),
+			zap.String("client_id", client.ClientID),
+		)
+		return nil, ErrInvalidAuthReqID
+	}
+	if request.DeliveryMode == entity.BackchannelDeliveryPush {
+		return nil, ErrInvalidAuthReqID
+	}
+
+	now := time.Now()
+	if request.IsExpired(now) {
+		s.remove(ctx, request)
+		return nil, ErrAuthReqIDExpired
+	}
+
+	if !request.Poll(now) {
+		request.Interval += s.config.SlowDownIncrement
+		if err := s.requests.Update(ctx, request); err != nil {
+			return nil, fmt.Errorf("failed to update authentication request: %w", err)
+		}
+		return nil, ErrSlowDown
+	}
+
+	switch request.Status {
+	case entity.BackchannelAuthenticationApproved:
+		// auth_req_id одноразовый: удаление до выдачи токенов исключает повторное использование
+		if err := s.requests.Delete(ctx, request.ID); err != nil {
+			return nil, fmt.Errorf("failed to consume authentication request: %w", err)
+		}
+		return request, nil
+	case entity.BackchannelAuthenticationDenied:
+		s.remove(ctx, request)
+		return nil, ErrBackchannelAccessDenied
+	default:
+		if err := s.requests.Update(ctx, request); err != nil {
+			return nil, fmt.Errorf("failed to update authentication request: %w", err)
+		}
+		return nil, ErrAuthorizationPending
+	}
+}
+
+// Consume удаляет решенный запрос, результат которого доставлен клиенту в режиме push
+func (s *BackchannelAuthenticationService) Consume(ctx context.Context, request *entity.BackchannelAuthentication) error {
+	if err := s.requests.Delete(ctx, request.ID); err != nil {
+		return fmt.Errorf("failed to consume authentication request: %w", err)
+	}
+	return nil
+}
+
+// NotifyClient отправляет payload на адрес уведомлений клиента с токеном запроса
+func (s *BackchannelAuthenticationService) NotifyClient(ctx context.Context, client *entity.Client, request *entity.BackchannelAuthentication, payload interface{}) error {
+	if err := s.notifyClient(ctx, client.BackchannelClientNotificationEndpoint, request.ClientNotificationToken, payload); err != nil {
+		log.Warn("failed to notify client",
+			zap.String("request_id", request.ID.String()),
+			zap.String("client_id", client.ClientID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to notify client: %w", err)
+	}
+	return nil
+}
+
+// Interval возвращает минимальный интервал опроса для новых запросов
+func (s *BackchannelAuthenticationService) Interval() time.Duration {
+	return s.config.PollInterval
+}
+
+// findUser находит пользователя по идентификатору или адресу электронной почты
+func (s *BackchannelAuthenticationService) findUser(ctx context.Context, loginHint string) (*entity.User, error) {
+	if loginHint == "" {
+		return nil, ports.ErrNotFound
+	}
+	if id, err := uuid.Parse(loginHint); err == nil {
+		return s.users.GetByID(ctx, id)
+	}
+	return s.users.GetByEmail(ctx, loginHint)
+}
+
+func (s *BackchannelAuthenticationService) remove(ctx context.Context, request *entity.BackchannelAuthentication) {
+	if err := s.requests.Delete(ctx, request.ID); err != nil && !errors.Is(err, ports.ErrNotFound) {
+		log.Error("failed to delete authentication request",
+			zap.String("request_id", request.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// sendClientNotification отправляет уведомление POST запросом с JSON телом;
+// перенаправления не выполняются, как и при загрузке объектов запроса
+func sendClientNotification(ctx context.Context, endpoint, token string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := requestObjectHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
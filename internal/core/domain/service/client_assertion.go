@@ -0,0 +1,180 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ClientAssertionType значение client_assertion_type для утверждений JWT (RFC 7523, раздел 2.2)
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ErrInvalidClientAssertion утверждение клиента не проходит проверку (RFC 7523, раздел 3)
+var ErrInvalidClientAssertion = errors.New("invalid client assertion")
+
+// clientAssertionLeeway допустимое расхождение часов клиента и сервера
+const clientAssertionLeeway = time.Minute
+
+// ClientAssertionConfig конфигурация проверки утверждений клиентов
+type ClientAssertionConfig struct {
+	// Algorithms допустимые алгоритмы подписи private_key_jwt
+	Algorithms []string
+	// MaxLifetime насколько далеко в будущем может быть exp утверждения; jti
+	// запоминается до exp, поэтому срок ограничивает и размер кеша повторов
+	MaxLifetime time.Duration
+}
+
+// DefaultClientAssertionConfig возвращает конфигурацию по умолчанию
+func DefaultClientAssertionConfig() *ClientAssertionConfig {
+	return &ClientAssertionConfig{
+		Algorithms:  append([]string(nil), jose.AsymmetricAlgorithms...),
+		MaxLifetime: 5 * time.Minute,
+	}
+}
+
+// ClientAssertionVerifier проверяет утверждения JWT, которыми клиенты
+// аутентифицируются способами private_key_jwt и client_secret_jwt (RFC 7523)
+type ClientAssertionVerifier struct {
+	keys    *ClientKeys
+	secrets *ClientSecretManager
+	replays ports.ReplayCache
+	config  *ClientAssertionConfig
+}
+
+// NewClientAssertionVerifier создает новый экземпляр ClientAssertionVerifier;
+// keys и secrets nil — конфигурация по умолчанию
+func NewClientAssertionVerifier(keys *ClientKeys, secrets *ClientSecretManager, replays ports.ReplayCache, config *ClientAssertionConfig) *ClientAssertionVerifier {
+	if keys == nil {
+		keys = NewClientKeys(nil)
+	}
+	if secrets == nil {
+		secrets = DefaultClientSecretManager()
+	}
+	if config == nil {
+		config = DefaultClientAssertionConfig()
+	}
+	return &ClientAssertionVerifier{
+		keys:    keys,
+		secrets: secrets,
+		replays: replays,
+		config:  config,
+	}
+}
+
+// AuthMethods возвращает поддерживаемые способы аутентификации по утверждениям.
+// client_secret_jwt доступен, только если менеджер секретов хранит их значения.
+func (v *ClientAssertionVerifier) AuthMethods() []entity.TokenEndpointAuthMethod {
+	methods := []entity.TokenEndpointAuthMethod{entity.AuthMethodPrivateKeyJWT}
+	if v.secrets.SupportsSharedKeys() {
+		methods = append(methods, entity.AuthMethodClientSecretJWT)
+	}
+	return methods
+}
+
+// Algorithms возвращает алгоритмы подписи утверждений для метаданных сервера
+func (v *ClientAssertionVerifier) Algorithms() []string {
+	algorithms := append([]string(nil), v.config.Algorithms...)
+	if v.secrets.SupportsSharedKeys() {
+		algorithms = append(algorithms, jose.HS256)
+	}
+	return algorithms
+}
+
+// AssertionSubject возвращает client_id из утверждения без проверки подписи;
+// используется, чтобы найти клиента, когда client_id не передан отдельно
+func AssertionSubject(assertion string) (string, error) {
+	token, err := jose.Parse(assertion)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidClientAssertion, err)
+	}
+	claims, err := token.Claims()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidClientAssertion, err)
+	}
+	if claims.Subject() == "" {
+		return "", fmt.Errorf("%w: sub is required", ErrInvalidClientAssertion)
+	}
+	return claims.Subject(), nil
+}
+
+// Verify проверяет утверждение клиента: подпись ключом или секретом клиента,
+// iss и sub равны client_id, aud содержит одно из audiences — идентификатор
+// сервера или адрес endpoint, — а jti еще не использовался
+func (v *ClientAssertionVerifier) Verify(ctx context.Context, client *entity.Client, assertion string, audiences []string) error {
+	claims, err := v.verifySignature(ctx, client, assertion)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidClientAssertion, err)
+	}
+
+	if claims.Issuer() != client.ClientID || claims.Subject() != client.ClientID {
+		return fmt.Errorf("%w: iss and sub must be the client_id", ErrInvalidClientAssertion)
+	}
+	audienceMatched := false
+	for _, audience := range audiences {
+		audienceMatched = audienceMatched || (audience != "" && claims.HasAudience(audience))
+	}
+	if !audienceMatched {
+		return fmt.Errorf("%w: aud must identify the authorization server", ErrInvalidClientAssertion)
+	}
+
+	now := time.Now()
+	if err := claims.ValidateTimes(now, clientAssertionLeeway, true); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidClientAssertion, err)
+	}
+	exp, _ := claims.Time("exp")
+	if exp.After(now.Add(v.config.MaxLifetime + clientAssertionLeeway)) {
+		return fmt.Errorf("%w: exp is too far in the future", ErrInvalidClientAssertion)
+	}
+	jti := claims.String("jti")
+	if jti == "" {
+		return fmt.Errorf("%w: jti is required", ErrInvalidClientAssertion)
+	}
+
+	// Утверждение можно использовать один раз (RFC 7523, раздел 3)
+	err = v.replays.Use(ctx, "client_assertion:"+client.ClientID+":"+jti, exp.Add(clientAssertionLeeway))
+	if errors.Is(err, ports.ErrAlreadyExists) {
+		log.Warn("client assertion replayed", zap.String("client_id", client.ClientID))
+		return fmt.Errorf("%w: jti has already been used", ErrInvalidClientAssertion)
+	}
+	return err
+}
+
+// verifySignature проверяет подпись утверждения способом аутентификации клиента
+func (v *ClientAssertionVerifier) verifySignature(ctx context.Context, client *entity.Client, assertion string) (jose.Claims, error) {
+	token, err := jose.Parse(assertion)
+	if err != nil {
+		return nil, err
+	}
+
+	switch client.TokenEndpointAuthMethod {
+	case entity.AuthMethodPrivateKeyJWT:
+		jwk, err := v.keys.Key(ctx, client, token.Header.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := token.Verify(publicKey, v.config.Algorithms...); err != nil {
+			return nil, err
+		}
+	case entity.AuthMethodClientSecretJWT:
+		verified := false
+		for _, secret := range v.secrets.SharedKeys(client) {
+			verified = verified || token.Verify(secret, jose.HS256) == nil
+		}
+		if !verified {
+			return nil, jose.ErrInvalidSignature
+		}
+	default:
+		return nil, fmt.Errorf("client does not use %s", ClientAssertionType)
+	}
+	return token.Claims()
+}
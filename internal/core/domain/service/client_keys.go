@@ -0,0 +1,195 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxJWKSSize максимальный размер набора ключей, загружаемого по jwks_uri
+const maxJWKSSize = 256 << 10
+
+// JWKSFetcher загружает набор открытых ключей по jwks_uri
+type JWKSFetcher func(ctx context.Context, uri string) (*jose.JWKSet, error)
+
+// ClientKeysConfig конфигурация источника ключей клиентов
+type ClientKeysConfig struct {
+	// FetchJWKS загружает ключи по jwks_uri; nil — загрузка по https
+	FetchJWKS JWKSFetcher
+	// CacheLifetime срок, в течение которого загруженный набор используется без обновления
+	CacheLifetime time.Duration
+	// RefreshInterval минимальный интервал между внеочередными загрузками, когда
+	// клиент подписал JWT неизвестным ключом (например, после ротации)
+	RefreshInterval time.Duration
+	// MaxCachedSets максимальное число наборов ключей в кеше; при переполнении
+	// вытесняются устаревшие, затем самые давно загруженные наборы
+	MaxCachedSets int
+}
+
+// DefaultClientKeysConfig возвращает конфигурацию по умолчанию
+func DefaultClientKeysConfig() *ClientKeysConfig {
+	return &ClientKeysConfig{
+		CacheLifetime:   time.Hour,
+		RefreshInterval: time.Minute,
+		MaxCachedSets:   1000,
+	}
+}
+
+// cachedJWKS набор ключей, загруженный по jwks_uri
+type cachedJWKS struct {
+	keys      *jose.JWKSet
+	fetchedAt time.Time
+}
+
+// ClientKeys находит открытые ключи клиента: зарегистрированные в JWKS или
+// опубликованные по jwks_uri
+type ClientKeys struct {
+	config *ClientKeysConfig
+	fetch  JWKSFetcher
+
+	mu    sync.Mutex
+	cache map[string]cachedJWKS
+}
+
+// NewClientKeys создает новый экземпляр ClientKeys
+func NewClientKeys(config *ClientKeysConfig) *ClientKeys {
+	if config == nil {
+		config = DefaultClientKeysConfig()
+	}
+	fetch := config.FetchJWKS
+	if fetch == nil {
+		fetch = fetchJWKS
+	}
+	return &ClientKeys{
+		config: config,
+		fetch:  fetch,
+		cache:  make(map[string]cachedJWKS),
+	}
+}
+
+// Key возвращает открытый ключ клиента с идентификатором kid
+func (k *ClientKeys) Key(ctx context.Context, client *entity.Client, kid string) (*jose.JWK, error) {
+	return k.Find(ctx, client.JWKS, client.JWKSURI, kid)
+}
+
+// Find возвращает ключ kid из набора jwks или, если задан jwksURI, из набора,
+// опубликованного по этому адресу. Используется и для ключей доверенных издателей.
+func (k *ClientKeys) Find(ctx context.Context, jwks *jose.JWKSet, jwksURI, kid string) (*jose.JWK, error) {
+	if jwksURI == "" {
+		if jwk, ok := jwks.Key(kid); ok {
+			return jwk, nil
+		}
+		return nil, fmt.Errorf("signing key %q is not registered", kid)
+	}
+
+	keys, fetchedAt, err := k.load(ctx, jwksURI, false)
+	if err != nil {
+		return nil, err
+	}
+	if jwk, ok := keys.Key(kid); ok {
+		return jwk, nil
+	}
+	// Неизвестный kid: владелец мог сменить ключи, набор загружается заново
+	if time.Since(fetchedAt) >= k.config.RefreshInterval {
+		if keys, _, err = k.load(ctx, jwksURI, true); err != nil {
+			return nil, err
+		}
+		if jwk, ok := keys.Key(kid); ok {
+			return jwk, nil
+		}
+	}
+	return nil, fmt.Errorf("signing key %q is not published at %s", kid, jwksURI)
+}
+
+// load возвращает набор ключей из кеша или загружает его
+func (k *ClientKeys) load(ctx context.Context, uri string, refresh bool) (*jose.JWKSet, time.Time, error) {
+	k.mu.Lock()
+	cached, ok := k.cache[uri]
+	k.mu.Unlock()
+	if ok && !refresh && time.Since(cached.fetchedAt) < k.config.CacheLifetime {
+		return cached.keys, cached.fetchedAt, nil
+	}
+
+	keys, err := k.fetch(ctx, uri)
+	if err != nil {
+		log.Warn("failed to fetch jwks", zap.String("jwks_uri", uri), zap.Error(err))
+		return nil, time.Time{}, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	for _, key := range keys.Keys {
+		if key.IsPrivate() {
+			return nil, time.Time{}, fmt.Errorf("jwks must contain only public keys")
+		}
+	}
+
+	fetchedAt := time.Now()
+	k.mu.Lock()
+	if _, exists := k.cache[uri]; !exists {
+		k.evict(fetchedAt)
+	}
+	k.cache[uri] = cachedJWKS{keys: keys, fetchedAt: fetchedAt}
+	k.mu.Unlock()
+	return keys, fetchedAt, nil
+}
+
+// evict освобождает место для нового набора ключей: удаляет устаревшие наборы,
+// а если кеш все еще заполнен — самый давно загруженный. Вызывается под k.mu.
+func (k *ClientKeys) evict(now time.Time) {
+	limit := k.config.MaxCachedSets
+	if limit <= 0 || len(k.cache) < limit {
+		return
+	}
+	var oldestURI string
+	var oldest time.Time
+	for uri, cached := range k.cache {
+		if now.Sub(cached.fetchedAt) >= k.config.CacheLifetime {
+			delete(k.cache, uri)
+			continue
+		}
+		if oldestURI == "" || cached.fetchedAt.Before(oldest) {
+			oldestURI, oldest = uri, cached.fetchedAt
+		}
+	}
+	if len(k.cache) >= limit {
+		delete(k.cache, oldestURI)
+	}
+}
+
+// fetchJWKS загружает набор ключей по https; перенаправления не выполняются,
+// как и при загрузке объектов запроса
+func fetchJWKS(ctx context.Context, uri string) (*jose.JWKSet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+
+	resp, err := requestObjectHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxJWKSSize {
+		return nil, fmt.Errorf("jwks is larger than %d bytes", maxJWKSSize)
+	}
+	var keys jose.JWKSet
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+	return &keys, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClientKeysCacheIsBounded(t *testing.T) {
+	fetches := 0
+	keys := NewClientKeys(&ClientKeysConfig{
+		FetchJWKS: func(ctx context.Context, uri string) (*jose.JWKSet, error) {
+			fetches++
+			return &jose.JWKSet{}, nil
+		},
+		CacheLifetime:   time.Hour,
+		RefreshInterval: time.Hour,
+		MaxCachedSets:   3,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		keys.Find(ctx, nil, fmt.Sprintf("https://client%d.example.com/jwks", i), "kid")
+	}
+	if len(keys.cache) != 3 {
+		t.Errorf("cache holds %d sets, want 3", len(keys.cache))
+	}
+
+	// Последний загруженный набор остается в кеше и не загружается повторно
+	fetches = 0
+	keys.Find(ctx, nil, "https://client9.example.com/jwks", "kid")
+	if fetches != 0 {
+		t.Errorf("recent set was fetched again %d time(s)", fetches)
+	}
+	keys.Find(ctx, nil, "https://client0.example.com/jwks", "kid")
+	if fetches != 1 {
+		t.Errorf("evicted set was fetched %d time(s), want 1", fetches)
+	}
+}
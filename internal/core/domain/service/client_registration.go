@@ -0,0 +1,590 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Коды ошибок регистрации клиентов (RFC 7591, раздел 3.2.2)
+const (
+	RegistrationErrorInvalidRedirectURI          = "invalid_redirect_uri"
+	RegistrationErrorInvalidClientMetadata       = "invalid_client_metadata"
+	RegistrationErrorInvalidSoftwareStatement    = "invalid_software_statement"
+	RegistrationErrorUnapprovedSoftwareStatement = "unapproved_software_statement"
+)
+
+// ErrInvalidInitialAccessToken начальный токен доступа отсутствует или неверен
+var ErrInvalidInitialAccessToken = errors.New("invalid initial access token")
+
+// ErrRegistrationClosed открытая регистрация не настроена: политика не требует
+// начального токена и не ограничивает области действия
+var ErrRegistrationClosed = errors.New("open client registration is not configured")
+
+// ErrInvalidRegistrationToken токен доступа к конфигурации клиента неверен
+// или клиент не существует; причины не различаются, чтобы не раскрывать client_id
+var ErrInvalidRegistrationToken = errors.New("invalid registration access token")
+
+// RegistrationError ошибка в метаданных клиента
+type RegistrationError struct {
+	Code        string
+	Description string
+}
+
+func (e *RegistrationError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func registrationError(code, format string, args ...interface{}) error {
+	return &RegistrationError{Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+// ClientMetadata метаданные клиента в формате RFC 7591
+type ClientMetadata struct {
+	RedirectURIs            []string                       `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod entity.TokenEndpointAuthMethod `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes              []entity.GrantType             `json:"grant_types,omitempty"`
+	ResponseTypes           []entity.ResponseType          `json:"response_types,omitempty"`
+	ClientName              string                         `json:"client_name,omitempty"`
+	ClientURI               string                         `json:"client_uri,omitempty"`
+	LogoURI                 string                         `json:"logo_uri,omitempty"`
+	Scope                   string                         `json:"scope,omitempty"`
+	TosURI                  string                         `json:"tos_uri,omitempty"`
+	PolicyURI               string                         `json:"policy_uri,omitempty"`
+	SoftwareID              string                         `json:"software_id,omitempty"`
+	SoftwareVersion         string                         `json:"software_version,omitempty"`
+	SoftwareStatement       string                         `json:"software_statement,omitempty"`
+	// RequirePushedAuthorizationRequests клиент начинает авторизацию только через PAR (RFC 9126)
+	RequirePushedAuthorizationRequests bool `json:"require_pushed_authorization_requests,omitempty"`
+	// Метаданные объектов запроса (RFC 9101, раздел 9.2)
+	JWKS                       *jose.JWKSet `json:"jwks,omitempty"`
+	JWKSURI                    string       `json:"jwks_uri,omitempty"`
+	RequestURIs                []string     `json:"request_uris,omitempty"`
+	RequestObjectSigningAlg    string       `json:"request_object_signing_alg,omitempty"`
+	RequireSignedRequestObject bool         `json:"require_signed_request_object,omitempty"`
+	// DPoPBoundAccessTokens клиент всегда использует DPoP (RFC 9449, раздел 5.2)
+	DPoPBoundAccessTokens bool `json:"dpop_bound_access_tokens,omitempty"`
+	// Метаданные аутентификации по сертификату (RFC 8705, разделы 2.1.2 и 3.4)
+	entity.TLSClientAuth
+	TLSClientCertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens,omitempty"`
+	// AuthorizationDetailsTypes типы authorization_details клиента (RFC 9396, раздел 10)
+	AuthorizationDetailsTypes []string `json:"authorization_details_types,omitempty"`
+	// Метаданные доставки результата CIBA (CIBA Core, раздел 4)
+	BackchannelTokenDeliveryMode          entity.BackchannelTokenDeliveryMode `json:"backchannel_token_delivery_mode,omitempty"`
+	BackchannelClientNotificationEndpoint string                              `json:"backchannel_client_notification_endpoint,omitempty"`
+}
+
+// MetadataFromClient возвращает метаданные зарегистрированного клиента
+func MetadataFromClient(client *entity.Client) ClientMetadata {
+	return ClientMetadata{
+		RedirectURIs:            client.RedirectURIs,
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod,
+		GrantTypes:              client.GrantTypes,
+		ResponseTypes:           client.ResponseTypes,
+		ClientName:              client.Name,
+		ClientURI:               client.ClientURI,
+		LogoURI:                 client.LogoURI,
+		Scope:                   strings.Join(client.Scopes, " "),
+		TosURI:                  client.TosURI,
+		PolicyURI:               client.PolicyURI,
+		SoftwareID:              client.SoftwareID,
+		SoftwareVersion:         client.SoftwareVersion,
+
+		RequirePushedAuthorizationRequests: client.RequirePushedAuthorizationRequests,
+		JWKS:                               client.JWKS,
+		JWKSURI:                            client.JWKSURI,
+		RequestURIs:                        client.RequestURIs,
+		RequestObjectSigningAlg:            client.RequestObjectSigningAlg,
+		RequireSignedRequestObject:         client.RequireSignedRequestObject,
+		DPoPBoundAccessTokens:              client.DPoPBoundAccessTokens,
+		TLSClientAuth:                      client.TLSClientAuth,
+
+		TLSClientCertificateBoundAccessTokens: client.TLSClientCertificateBoundAccessTokens,
+		AuthorizationDetailsTypes:             client.AuthorizationDetailsTypes,
+		BackchannelTokenDeliveryMode:          client.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: client.BackchannelClientNotificationEndpoint,
+	}
+}
+
+// ClientRegistrationConfig политика динамической регистрации клиентов
+type ClientRegistrationConfig struct {
+	// AllowedGrantTypes типы авторизации, доступные зарегистрированным клиентам
+	AllowedGrantTypes []entity.GrantType
+	// AllowedAuthMethods способы аутентификации на token endpoint
+	AllowedAuthMethods []entity.TokenEndpointAuthMethod
+	// AllowedScopes области действия, которые может запросить клиент; пусто — без
+	// ограничений, но только если регистрация требует начальный токен
+	AllowedScopes []string
+	// AllowInsecureRedirectURIs разрешает http URI перенаправления не только для loopback адресов
+	AllowInsecureRedirectURIs bool
+	// InitialAccessTokens если задан, регистрация требует один из этих токенов (RFC 7591, раздел 3).
+	// Без них регистрация анонимна: клиент не получает client_credentials, а сервер
+	// не обращается к его адресам (jwks_uri, request_uris, адрес уведомлений CIBA).
+	InitialAccessTokens []string
+	// SoftwareStatementKeys ключи издателей software statement; nil — software statement не принимаются
+	SoftwareStatementKeys *jose.JWKSet
+	// RequireSoftwareStatement требует подписанный software statement при регистрации
+	RequireSoftwareStatement bool
+	// RegistrationTokenBytes число случайных байт в registration access token
+	RegistrationTokenBytes int
+}
+
+// DefaultClientRegistrationConfig возвращает политику по умолчанию: без password grant,
+// только https и loopback URI перенаправления. Регистрация закрыта, пока не заданы
+// InitialAccessTokens или AllowedScopes.
+func DefaultClientRegistrationConfig() *ClientRegistrationConfig {
+	return &ClientRegistrationConfig{
+		AllowedGrantTypes: []entity.GrantType{
+			entity.GrantTypeAuthCode,
+			entity.GrantTypeRefreshToken,
+			entity.GrantTypeClientCreds,
+		},
+		AllowedAuthMethods: []entity.TokenEndpointAuthMethod{
+			entity.AuthMethodClientSecretBasic,
+			entity.AuthMethodClientSecretPost,
+			entity.AuthMethodNone,
+		},
+		RegistrationTokenBytes: 32,
+	}
+}
+
+// RegisteredClient результат регистрации. Секрет и registration access token
+// известны только в момент выдачи: хранятся лишь их хеши.
+type RegisteredClient struct {
+	Client                  *entity.Client
+	ClientSecret            string
+	RegistrationAccessToken string
+}
+
+// ClientRegistrationService реализует динамическую регистрацию клиентов (RFC 7591)
+// и управление их конфигурацией (RFC 7592)
+type ClientRegistrationService struct {
+	clients ports.ClientRepository
+	secrets *ClientSecretManager
+	config  *ClientRegistrationConfig
+	// supported типы авторизации, которые обслуживает сервер; nil — без ограничений
+	supported []entity.GrantType
+}
+
+// NewClientRegistrationService создает новый экземпляр ClientRegistrationService
+func NewClientRegistrationService(clients ports.ClientRepository, secrets *ClientSecretManager, config *ClientRegistrationConfig) *ClientRegistrationService {
+	if config == nil {
+		config = DefaultClientRegistrationConfig()
+	}
+	if secrets == nil {
+		secrets = DefaultClientSecretManager()
+	}
+	return &ClientRegistrationService{
+		clients: clients,
+		secrets: secrets,
+		config:  config,
+	}
+}
+
+// Config возвращает политику регистрации
+func (s *ClientRegistrationService) Config() *ClientRegistrationConfig {
+	return s.config
+}
+
+// SetSupportedGrantTypes ограничивает AllowedGrantTypes типами авторизации,
+// для которых на token endpoint подключен обработчик
+func (s *ClientRegistrationService) SetSupportedGrantTypes(grantTypes []entity.GrantType) {
+	s.supported = append([]entity.GrantType{}, grantTypes...)
+}
+
+// Register регистрирует клиента по метаданным. initialAccessToken проверяется,
+// если политика требует начальный токен.
+func (s *ClientRegistrationService) Register(ctx context.Context, metadata ClientMetadata, initialAccessToken string) (*RegisteredClient, error) {
+	if s.anonymous() && len(s.config.AllowedScopes) == 0 {
+		log.Warn("client registration rejected: open registration requires allowed scopes")
+		return nil, ErrRegistrationClosed
+	}
+	if !s.checkInitialAccessToken(initialAccessToken) {
+		log.Warn("client registration rejected: invalid initial access token")
+		return nil, ErrInvalidInitialAccessToken
+	}
+
+	metadata, err := s.applySoftwareStatement(metadata)
+	if err != nil {
+		return nil, err
+	}
+	metadata = applyMetadataDefaults(metadata)
+
+	client := entity.NewClient(metadata.ClientName, "", nil, nil, nil)
+	if metadata.TokenEndpointAuthMethod == entity.AuthMethodNone {
+		client = entity.NewPublicClient(metadata.ClientName, "", nil, nil, nil)
+	}
+	if client.Name == "" {
+		client.Name = client.ClientID
+	}
+	if err := s.applyMetadata(client, metadata); err != nil {
+		return nil, err
+	}
+
+	registered := &RegisteredClient{Client: client}
+	if client.TokenEndpointAuthMethod.UsesClientSecret() {
+		if registered.ClientSecret, err = s.secrets.Issue(client); err != nil {
+			return nil, err
+		}
+	}
+	if registered.RegistrationAccessToken, err = s.issueRegistrationToken(client); err != nil {
+		return nil, err
+	}
+
+	if err := s.clients.Create(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to store client: %w", err)
+	}
+
+	log.Info("client registered dynamically",
+		zap.String("client_id", client.ClientID),
+		zap.String("client_type", string(client.Type)),
+		zap.String("software_id", client.SoftwareID),
+	)
+	return registered, nil
+}
+
+// Read возвращает клиента по client_id после проверки registration access token
+func (s *ClientRegistrationService) Read(ctx context.Context, clientID, registrationToken string) (*entity.Client, error) {
+	return s.authorize(ctx, clientID, registrationToken)
+}
+
+// Update заменяет метаданные клиента (RFC 7592, раздел 2.2). Секреты клиента
+// сохраняются; смена публичного клиента на конфиденциальный и обратно не допускается.
+func (s *ClientRegistrationService) Update(ctx context.Context, clientID, registrationToken string, metadata ClientMetadata) (*entity.Client, error) {
+	client, err := s.authorize(ctx, clientID, registrationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err = s.applySoftwareStatement(metadata)
+	if err != nil {
+		return nil, err
+	}
+	metadata = applyMetadataDefaults(metadata)
+
+	if (metadata.TokenEndpointAuthMethod == entity.AuthMethodNone) != client.IsPublic() {
+		return nil, registrationError(RegistrationErrorInvalidClientMetadata,
+			"token_endpoint_auth_method cannot change the client type")
+	}
+	if metadata.ClientName != "" {
+		client.Name = metadata.ClientName
+	}
+	if err := s.applyMetadata(client, metadata); err != nil {
+		return nil, err
+	}
+	client.UpdatedAt = time.Now()
+
+	if err := s.clients.Update(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to store client: %w", err)
+	}
+
+	log.Info("dynamically registered client updated",
+		zap.String("client_id", client.ClientID),
+	)
+	return client, nil
+}
+
+// Delete удаляет клиента (RFC 7592, раздел 2.3)
+func (s *ClientRegistrationService) Delete(ctx context.Context, clientID, registrationToken string) error {
+	client, err := s.authorize(ctx, clientID, registrationToken)
+	if err != nil {
+		return err
+	}
+	if err := s.clients.Delete(ctx, client.ID); err != nil {
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+
+	log.Info("dynamically registered client deleted",
+		zap.String("client_id", client.ClientID),
+	)
+	return nil
+}
+
+// authorize находит клиента и проверяет registration access token
+func (s *ClientRegistrationService) authorize(ctx context.Context, clientID, registrationToken string) (*entity.Client, error) {
+	if clientID == "" || registrationToken == "" {
+		return nil, ErrInvalidRegistrationToken
+	}
+
+	client, err := s.clients.GetByClientID(ctx, clientID)
+	if errors.Is(err, ports.ErrNotFound) {
+		log.Warn("registration access for unknown client", zap.String("client_id", clientID))
+		return nil, ErrInvalidRegistrationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := hex.DecodeString(client.RegistrationAccessTokenHash)
+	sum := sha256.Sum256([]byte(registrationToken))
+	if err != nil || len(expected) == 0 || subtle.ConstantTimeCompare(expected, sum[:]) != 1 {
+		log.Warn("invalid registration access token", zap.String("client_id", clientID))
+		return nil, ErrInvalidRegistrationToken
+	}
+	return client, nil
+}
+
+// anonymous сообщает, что регистрация не требует начального токена
+func (s *ClientRegistrationService) anonymous() bool {
+	return len(s.config.InitialAccessTokens) == 0
+}
+
+// checkInitialAccessToken проверяет начальный токен, если политика его требует
+func (s *ClientRegistrationService) checkInitialAccessToken(token string) bool {
+	if len(s.config.InitialAccessTokens) == 0 {
+		return true
+	}
+	match := 0
+	for _, expected := range s.config.InitialAccessTokens {
+		match |= subtle.ConstantTimeCompare([]byte(expected), []byte(token))
+	}
+	return token != "" && match == 1
+}
+
+// applySoftwareStatement проверяет software statement и переносит его утверждения
+// в метаданные; значения из statement имеют приоритет (RFC 7591, раздел 2.3)
+func (s *ClientRegistrationService) applySoftwareStatement(metadata ClientMetadata) (ClientMetadata, error) {
+	if metadata.SoftwareStatement == "" {
+		if s.config.RequireSoftwareStatement {
+			return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "software_statement is required")
+		}
+		return metadata, nil
+	}
+	if s.config.SoftwareStatementKeys == nil {
+		return metadata, registrationError(RegistrationErrorUnapprovedSoftwareStatement, "software statements are not accepted")
+	}
+
+	statement, err := jose.Parse(metadata.SoftwareStatement)
+	if err != nil {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "%v", err)
+	}
+	key, ok := s.config.SoftwareStatementKeys.Key(statement.Header.KeyID)
+	if !ok {
+		return metadata, registrationError(RegistrationErrorUnapprovedSoftwareStatement, "unknown signing key")
+	}
+	publicKey, err := key.PublicKey()
+	if err != nil {
+		return metadata, registrationError(RegistrationErrorUnapprovedSoftwareStatement, "%v", err)
+	}
+	if err := statement.Verify(publicKey, jose.AsymmetricAlgorithms...); err != nil {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "%v", err)
+	}
+
+	claims, err := statement.Claims()
+	if err != nil {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "%v", err)
+	}
+	if claims.Issuer() == "" {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "iss claim is required")
+	}
+	if err := claims.ValidateTimes(time.Now(), time.Minute, false); err != nil {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "%v", err)
+	}
+
+	var fromStatement ClientMetadata
+	if err := json.Unmarshal(statement.Payload, &fromStatement); err != nil {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "invalid claims: %v", err)
+	}
+	if fromStatement.SoftwareStatement != "" {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "nested software statements are not allowed")
+	}
+
+	// Повторное декодирование поверх запроса заменяет только поля, присутствующие в statement
+	if err := json.Unmarshal(statement.Payload, &metadata); err != nil {
+		return metadata, registrationError(RegistrationErrorInvalidSoftwareStatement, "invalid claims: %v", err)
+	}
+
+	log.Info("software statement accepted",
+		zap.String("issuer", claims.Issuer()),
+		zap.String("software_id", metadata.SoftwareID),
+	)
+	return metadata, nil
+}
+
+// applyMetadataDefaults заполняет значения по умолчанию из RFC 7591, раздел 2
+func applyMetadataDefaults(metadata ClientMetadata) ClientMetadata {
+	if metadata.TokenEndpointAuthMethod == "" {
+		metadata.TokenEndpointAuthMethod = entity.AuthMethodClientSecretBasic
+	}
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = []entity.GrantType{entity.GrantTypeAuthCode}
+	}
+	if len(metadata.ResponseTypes) == 0 {
+		metadata.ResponseTypes = make([]entity.ResponseType, 0, 1)
+		for _, grantType := range metadata.GrantTypes {
+			if grantType == entity.GrantTypeAuthCode {
+				metadata.ResponseTypes = append(metadata.ResponseTypes, entity.ResponseTypeCode)
+			}
+		}
+	}
+	return metadata
+}
+
+// applyMetadata проверяет метаданные по политике сервера и переносит их в клиента
+func (s *ClientRegistrationService) applyMetadata(client *entity.Client, metadata ClientMetadata) error {
+	if !containsAuthMethod(s.config.AllowedAuthMethods, metadata.TokenEndpointAuthMethod) {
+		return registrationError(RegistrationErrorInvalidClientMetadata,
+			"token_endpoint_auth_method %s is not allowed", metadata.TokenEndpointAuthMethod)
+	}
+	for _, grantType := range metadata.GrantTypes {
+		if !containsGrantType(s.config.AllowedGrantTypes, grantType) {
+			return registrationError(RegistrationErrorInvalidClientMetadata, "grant type %s is not allowed", grantType)
+		}
+		if s.supported != nil && !containsGrantType(s.supported, grantType) {
+			return registrationError(RegistrationErrorInvalidClientMetadata, "grant type %s is not supported", grantType)
+		}
+	}
+
+	if s.anonymous() {
+		if err := checkAnonymousMetadata(metadata); err != nil {
+			return err
+		}
+	}
+
+	scopes := strings.Fields(metadata.Scope)
+	if len(s.config.AllowedScopes) > 0 {
+		for _, scope := range scopes {
+			if !containsString(s.config.AllowedScopes, scope) {
+				return registrationError(RegistrationErrorInvalidClientMetadata, "scope %s is not allowed", scope)
+			}
+		}
+	}
+
+	for _, raw := range metadata.RedirectURIs {
+		if err := s.checkRedirectURI(raw); err != nil {
+			return err
+		}
+	}
+
+	client.TokenEndpointAuthMethod = metadata.TokenEndpointAuthMethod
+	client.RedirectURIs = metadata.RedirectURIs
+	client.GrantTypes = metadata.GrantTypes
+	client.ResponseTypes = metadata.ResponseTypes
+	client.Scopes = scopes
+	client.ClientURI = metadata.ClientURI
+	client.LogoURI = metadata.LogoURI
+	client.PolicyURI = metadata.PolicyURI
+	client.TosURI = metadata.TosURI
+	client.SoftwareID = metadata.SoftwareID
+	client.SoftwareVersion = metadata.SoftwareVersion
+	client.RequirePushedAuthorizationRequests = metadata.RequirePushedAuthorizationRequests
+	client.JWKS = metadata.JWKS
+	client.JWKSURI = metadata.JWKSURI
+	client.RequestURIs = metadata.RequestURIs
+	client.RequestObjectSigningAlg = metadata.RequestObjectSigningAlg
+	client.RequireSignedRequestObject = metadata.RequireSignedRequestObject
+	client.DPoPBoundAccessTokens = metadata.DPoPBoundAccessTokens
+	client.TLSClientAuth = metadata.TLSClientAuth
+	client.TLSClientCertificateBoundAccessTokens = metadata.TLSClientCertificateBoundAccessTokens
+	client.AuthorizationDetailsTypes = metadata.AuthorizationDetailsTypes
+	client.BackchannelTokenDeliveryMode = metadata.BackchannelTokenDeliveryMode
+	client.BackchannelClientNotificationEndpoint = metadata.BackchannelClientNotificationEndpoint
+
+	if err := client.Validate(); err != nil {
+		code := RegistrationErrorInvalidClientMetadata
+		if strings.Contains(err.Error(), "redirect uri") {
+			code = RegistrationErrorInvalidRedirectURI
+		}
+		return registrationError(code, "%v", err)
+	}
+	return nil
+}
+
+// checkAnonymousMetadata запрещает анонимным клиентам client_credentials, не
+// требующий участия пользователя, и адреса, по которым сервер выполнял бы запросы
+// от их имени (защита от SSRF)
+func checkAnonymousMetadata(metadata ClientMetadata) error {
+	if containsGrantType(metadata.GrantTypes, entity.GrantTypeClientCreds) {
+		return registrationError(RegistrationErrorInvalidClientMetadata,
+			"grant type %s requires an initial access token", entity.GrantTypeClientCreds)
+	}
+	switch {
+	case metadata.JWKSURI != "":
+		return registrationError(RegistrationErrorInvalidClientMetadata, "jwks_uri requires an initial access token")
+	case len(metadata.RequestURIs) > 0:
+		return registrationError(RegistrationErrorInvalidClientMetadata, "request_uris requires an initial access token")
+	case metadata.BackchannelClientNotificationEndpoint != "":
+		return registrationError(RegistrationErrorInvalidClientMetadata,
+			"backchannel_client_notification_endpoint requires an initial access token")
+	}
+	return nil
+}
+
+// checkRedirectURI требует https; http допускается только для loopback адресов
+// (RFC 8252, раздел 7.3), если политика не разрешает иное
+func (s *ClientRegistrationService) checkRedirectURI(raw string) error {
+	uri, err := url.Parse(raw)
+	if err != nil || uri.Host == "" {
+		return registrationError(RegistrationErrorInvalidRedirectURI, "invalid redirect uri: %s", raw)
+	}
+	switch uri.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if s.config.AllowInsecureRedirectURIs || isLoopbackHost(uri.Hostname()) {
+			return nil
+		}
+		return registrationError(RegistrationErrorInvalidRedirectURI, "redirect uri must use https: %s", raw)
+	default:
+		return registrationError(RegistrationErrorInvalidRedirectURI, "unsupported redirect uri scheme: %s", raw)
+	}
+}
+
+// issueRegistrationToken выдает registration access token и сохраняет его хеш в клиенте
+func (s *ClientRegistrationService) issueRegistrationToken(client *entity.Client) (string, error) {
+	token, err := randomToken(s.config.RegistrationTokenBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate registration access token: %w", err)
+	}
+	sum := sha256.Sum256([]byte(token))
+	client.RegistrationAccessTokenHash = hex.EncodeToString(sum[:])
+	return token, nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func containsGrantType(values []entity.GrantType, value entity.GrantType) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAuthMethod(values []entity.TokenEndpointAuthMethod, value entity.TokenEndpointAuthMethod) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClientRegistrationClosedByDefault(t *testing.T) {
+	s := NewClientRegistrationService(memory.NewClientRepository(), nil, nil)
+	_, err := s.Register(context.Background(), ClientMetadata{RedirectURIs: []string{"https://app.example.com/cb"}}, "")
+	if !errors.Is(err, ErrRegistrationClosed) {
+		t.Fatalf("Register() error = %v, want ErrRegistrationClosed", err)
+	}
+}
+
+func TestAnonymousRegistrationRestrictions(t *testing.T) {
+	config := DefaultClientRegistrationConfig()
+	config.AllowedScopes = []string{"openid", "profile"}
+	s := NewClientRegistrationService(memory.NewClientRepository(), nil, config)
+	ctx := context.Background()
+
+	base := ClientMetadata{RedirectURIs: []string{"https://app.example.com/cb"}, Scope: "openid"}
+	if _, err := s.Register(ctx, base, ""); err != nil {
+		t.Fatalf("anonymous registration with allowed scopes failed: %v", err)
+	}
+
+	rejected := map[string]func(m *ClientMetadata){
+		"client_credentials": func(m *ClientMetadata) {
+			m.GrantTypes = []entity.GrantType{entity.GrantTypeClientCreds}
+		},
+		"jwks_uri": func(m *ClientMetadata) {
+			m.TokenEndpointAuthMethod = entity.AuthMethodPrivateKeyJWT
+			m.JWKSURI = "https://169.254.169.254/latest/meta-data"
+		},
+		"request_uris": func(m *ClientMetadata) {
+			m.RequestURIs = []string{"https://internal.example.com/request"}
+		},
+		"unlisted scope": func(m *ClientMetadata) {
+			m.Scope = "openid admin"
+		},
+	}
+	for name, mutate := range rejected {
+		metadata := base
+		mutate(&metadata)
+		_, err := s.Register(ctx, metadata, "")
+		var registrationErr *RegistrationError
+		if !errors.As(err, &registrationErr) {
+			t.Errorf("%s: Register() error = %v, want RegistrationError", name, err)
+		}
+	}
+}
+
+func TestRegistrationWithInitialAccessToken(t *testing.T) {
+	config := DefaultClientRegistrationConfig()
+	config.InitialAccessTokens = []string{"initial-token"}
+	s := NewClientRegistrationService(memory.NewClientRepository(), nil, config)
+	ctx := context.Background()
+
+	metadata := ClientMetadata{GrantTypes: []entity.GrantType{entity.GrantTypeClientCreds}, Scope: "reports"}
+	if _, err := s.Register(ctx, metadata, "wrong"); !errors.Is(err, ErrInvalidInitialAccessToken) {
+		t.Fatalf("Register() with a wrong token error = %v", err)
+	}
+	registered, err := s.Register(ctx, metadata, "initial-token")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if !registered.Client.IsGrantTypeAllowed(entity.GrantTypeClientCreds) || registered.ClientSecret == "" {
+		t.Errorf("client_credentials client was not registered: %+v", registered.Client)
+	}
+}
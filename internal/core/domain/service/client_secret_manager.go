@@ -0,0 +1,340 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ClientSecretManagerConfig конфигурация выдачи и проверки секретов клиентов
+type ClientSecretManagerConfig struct {
+	// SecretBytes число случайных байт в секрете
+	SecretBytes int
+	// MaxActiveSecrets максимальное число одновременно действующих секретов
+	MaxActiveSecrets int
+	// RotationOverlap срок, в течение которого предыдущий секрет действует после ротации
+	RotationOverlap time.Duration
+	// Hasher параметры argon2 для секретов, перенесенных через Adopt: их стойкость
+	// неизвестна, в отличие от выданных менеджером; nil — параметры по умолчанию
+	Hasher *PasswordHasherConfig
+	// GeneratedHasher параметры argon2 для секретов, выданных менеджером; nil —
+	// DefaultGeneratedSecretHasherConfig
+	GeneratedHasher *PasswordHasherConfig
+	// SealingKey ключ AES-256, которым шифруются секреты клиентов client_secret_jwt;
+	// пустой — секреты таким клиентам не выдаются и client_secret_jwt не поддерживается
+	SealingKey []byte
+}
+
+// DefaultClientSecretManagerConfig возвращает конфигурацию по умолчанию
+func DefaultClientSecretManagerConfig() *ClientSecretManagerConfig {
+	return &ClientSecretManagerConfig{
+		SecretBytes:      32,
+		MaxActiveSecrets: 2,
+		RotationOverlap:  24 * time.Hour,
+	}
+}
+
+// DefaultGeneratedSecretHasherConfig возвращает параметры argon2 для выданных
+// секретов. Секрет содержит не меньше 128 случайных бит, и подбор по хешу
+// невозможен при любых параметрах, поэтому они облегчены: секрет проверяется
+// на каждом запросе к token endpoint со всеми действующими секретами клиента.
+func DefaultGeneratedSecretHasherConfig() *PasswordHasherConfig {
+	return &PasswordHasherConfig{
+		Memory:      8 * 1024, // 8MB
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// ClientSecretManager выдает, хеширует и проверяет секреты клиентов. Все секреты
+// хранятся в виде argon2 хешей PasswordHasher: выданные менеджером — с
+// облегченными параметрами, перенесенные — с параметрами паролей. Секреты клиентов
+// client_secret_jwt дополнительно хранятся зашифрованными, чтобы проверять подпись
+// их утверждений. Клиент, перешедший на client_secret_jwt, должен получить новый секрет.
+type ClientSecretManager struct {
+	config    *ClientSecretManagerConfig
+	hasher    *PasswordHasher
+	generated *PasswordHasher
+	sealer    cipher.AEAD
+}
+
+// legacySecretHashPrefix префикс SHA-256 хеша, которым прежние версии хранили
+// выданные секреты; такие хеши только проверяются до ротации секрета
+const legacySecretHashPrefix = "sha256$"
+
+// NewClientSecretManager создает новый экземпляр ClientSecretManager;
+// возвращает ошибку, если ключ шифрования секретов некорректен
+func NewClientSecretManager(config *ClientSecretManagerConfig) (*ClientSecretManager, error) {
+	if config == nil {
+		config = DefaultClientSecretManagerConfig()
+	}
+	m := &ClientSecretManager{
+		config:    config,
+		hasher:    NewPasswordHasher(config.Hasher),
+		generated: newGeneratedSecretHasher(config.GeneratedHasher),
+	}
+	if len(config.SealingKey) > 0 {
+		if len(config.SealingKey) != 32 {
+			return nil, fmt.Errorf("client secret sealing key must be 32 bytes, got %d", len(config.SealingKey))
+		}
+		block, err := aes.NewCipher(config.SealingKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client secret sealer: %w", err)
+		}
+		if m.sealer, err = cipher.NewGCM(block); err != nil {
+			return nil, fmt.Errorf("failed to create client secret sealer: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// DefaultClientSecretManager создает менеджер с конфигурацией по умолчанию,
+// которая не содержит ключа шифрования и не может быть ошибочной
+func DefaultClientSecretManager() *ClientSecretManager {
+	return &ClientSecretManager{
+		config:    DefaultClientSecretManagerConfig(),
+		hasher:    NewPasswordHasher(nil),
+		generated: newGeneratedSecretHasher(nil),
+	}
+}
+
+// newGeneratedSecretHasher создает хешер выданных секретов
+func newGeneratedSecretHasher(config *PasswordHasherConfig) *PasswordHasher {
+	if config == nil {
+		config = DefaultGeneratedSecretHasherConfig()
+	}
+	return NewPasswordHasher(config)
+}
+
+// SupportsSharedKeys сообщает, может ли менеджер хранить секреты для client_secret_jwt
+func (m *ClientSecretManager) SupportsSharedKeys() bool {
+	return m.sealer != nil
+}
+
+// Issue заменяет все секреты клиента одним новым и возвращает его значение
+func (m *ClientSecretManager) Issue(client *entity.Client) (string, error) {
+	if client.IsPublic() {
+		return "", fmt.Errorf("public clients do not have secrets")
+	}
+
+	value, secret, err := m.newSecret(client)
+	if err != nil {
+		return "", err
+	}
+
+	client.Secrets = nil
+	client.AddSecret(secret)
+
+	log.Info("client secret issued",
+		zap.String("client_id", client.ClientID),
+		zap.String("secret_id", secret.ID),
+	)
+	return value, nil
+}
+
+// Adopt заменяет секреты клиента хешем уже существующего секрета.
+// Используется при миграции клиентов, секреты которых хранились открытым текстом.
+func (m *ClientSecretManager) Adopt(client *entity.Client, value string) error {
+	if value == "" {
+		return fmt.Errorf("client secret is empty")
+	}
+
+	hash, err := m.hasher.HashPassword(value)
+	if err != nil {
+		return fmt.Errorf("failed to hash client secret: %w", err)
+	}
+
+	secret := entity.ClientSecret{
+		ID:        uuid.New().String(),
+		Hash:      hash,
+		CreatedAt: time.Now(),
+	}
+	if err := m.seal(client, &secret, value); err != nil {
+		return err
+	}
+
+	client.Secrets = nil
+	client.AddSecret(secret)
+
+	log.Info("existing client secret adopted",
+		zap.String("client_id", client.ClientID),
+	)
+	return nil
+}
+
+// Rotate добавляет клиенту новый секрет. Действующие секреты продолжают
+// работать в течение overlap (0 — значение из конфигурации), после чего истекают.
+// Если действующих секретов становится больше MaxActiveSecrets, самые старые удаляются.
+func (m *ClientSecretManager) Rotate(client *entity.Client, overlap time.Duration) (string, error) {
+	if client.IsPublic() {
+		return "", fmt.Errorf("public clients do not have secrets")
+	}
+	if overlap <= 0 {
+		overlap = m.config.RotationOverlap
+	}
+
+	value, secret, err := m.newSecret(client)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	client.RemoveExpiredSecrets(now)
+
+	expiresAt := now.Add(overlap)
+	for i := range client.Secrets {
+		if client.Secrets[i].ExpiresAt == nil || client.Secrets[i].ExpiresAt.After(expiresAt) {
+			deadline := expiresAt
+			client.Secrets[i].ExpiresAt = &deadline
+		}
+	}
+
+	// Оставляем место для нового секрета, удаляя самые старые
+	sort.SliceStable(client.Secrets, func(i, j int) bool {
+		return client.Secrets[i].CreatedAt.Before(client.Secrets[j].CreatedAt)
+	})
+	if limit := m.config.MaxActiveSecrets - 1; limit >= 0 && len(client.Secrets) > limit {
+		client.Secrets = client.Secrets[len(client.Secrets)-limit:]
+	}
+
+	client.AddSecret(secret)
+
+	log.Info("client secret rotated",
+		zap.String("client_id", client.ClientID),
+		zap.String("secret_id", secret.ID),
+		zap.Time("previous_expires_at", expiresAt),
+	)
+	return value, nil
+}
+
+// Verify проверяет секрет клиента. Сравнение выполняется в постоянном времени
+// и со всеми действующими секретами, чтобы время ответа не выдавало, какой из них совпал.
+func (m *ClientSecretManager) Verify(client *entity.Client, value string) bool {
+	if client == nil || value == "" {
+		return false
+	}
+
+	match := false
+	for _, secret := range client.ActiveSecrets(time.Now()) {
+		ok, err := m.verifyHash(value, secret.Hash)
+		if err != nil {
+			log.Error("failed to verify client secret",
+				zap.String("client_id", client.ClientID),
+				zap.String("secret_id", secret.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		match = match || ok
+	}
+
+	if !match {
+		log.Warn("client secret mismatch",
+			zap.String("client_id", client.ClientID),
+		)
+	}
+	return match
+}
+
+// SharedKeys возвращает значения действующих секретов клиента client_secret_jwt
+// для проверки подписи HMAC
+func (m *ClientSecretManager) SharedKeys(client *entity.Client) [][]byte {
+	if m.sealer == nil {
+		return nil
+	}
+	var keys [][]byte
+	for _, secret := range client.ActiveSecrets(time.Now()) {
+		if secret.Sealed == "" {
+			continue
+		}
+		sealed, err := base64.RawURLEncoding.DecodeString(secret.Sealed)
+		size := m.sealer.NonceSize()
+		if err == nil && len(sealed) < size {
+			err = fmt.Errorf("sealed secret is too short")
+		}
+		var value []byte
+		if err == nil {
+			value, err = m.sealer.Open(nil, sealed[:size], sealed[size:], []byte(secret.ID))
+		}
+		if err != nil {
+			log.Error("failed to unseal client secret",
+				zap.String("client_id", client.ClientID),
+				zap.String("secret_id", secret.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		keys = append(keys, value)
+	}
+	return keys
+}
+
+// seal шифрует значение секрета клиента client_secret_jwt. Идентификатор
+// секрета входит в дополнительные данные, поэтому значения нельзя переставить.
+func (m *ClientSecretManager) seal(client *entity.Client, secret *entity.ClientSecret, value string) error {
+	if client.TokenEndpointAuthMethod != entity.AuthMethodClientSecretJWT {
+		return nil
+	}
+	if m.sealer == nil {
+		return fmt.Errorf("client_secret_jwt requires a secret sealing key")
+	}
+	nonce := make([]byte, m.sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to seal client secret: %w", err)
+	}
+	sealed := m.sealer.Seal(nonce, nonce, []byte(value), []byte(secret.ID))
+	secret.Sealed = base64.RawURLEncoding.EncodeToString(sealed)
+	return nil
+}
+
+// newSecret генерирует случайный секрет и его хеш
+func (m *ClientSecretManager) newSecret(client *entity.Client) (string, entity.ClientSecret, error) {
+	size := m.config.SecretBytes
+	if size < 16 {
+		size = 16
+	}
+
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", entity.ClientSecret{}, fmt.Errorf("failed to generate client secret: %w", err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(raw)
+
+	hash, err := m.generated.HashPassword(value)
+	if err != nil {
+		return "", entity.ClientSecret{}, fmt.Errorf("failed to hash client secret: %w", err)
+	}
+	secret := entity.ClientSecret{
+		ID:        uuid.New().String(),
+		Hash:      hash,
+		CreatedAt: time.Now(),
+	}
+	if err := m.seal(client, &secret, value); err != nil {
+		return "", entity.ClientSecret{}, err
+	}
+	return value, secret, nil
+}
+
+// verifyHash сравнивает секрет с argon2 хешем; параметры берутся из хеша.
+// SHA-256 хеши прежних версий проверяются, пока клиент не получит новый секрет.
+func (m *ClientSecretManager) verifyHash(value, hash string) (bool, error) {
+	if !strings.HasPrefix(hash, legacySecretHashPrefix) {
+		return m.hasher.VerifyPassword(value, hash)
+	}
+	sum := sha256.Sum256([]byte(value))
+	expected := legacySecretHashPrefix + base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1, nil
+}
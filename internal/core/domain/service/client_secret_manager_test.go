@@ -0,0 +1,101 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient() *entity.Client {
+	return entity.NewClient("test", "", []string{"https://client.example.com/cb"}, []entity.GrantType{entity.GrantTypeAuthCode}, []string{"openid"})
+}
+
+func TestClientSecretManagerIssueUsesLightArgon2(t *testing.T) {
+	m := DefaultClientSecretManager()
+	client := newTestClient()
+
+	value, err := m.Issue(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.Secrets) != 1 || !strings.HasPrefix(client.Secrets[0].Hash, "$argon2id$v=19$m=8192,t=1,p=1$") {
+		t.Fatalf("issued secret is not stored as a light argon2 hash: %+v", client.Secrets)
+	}
+	if !m.Verify(client, value) {
+		t.Error("issued secret was rejected")
+	}
+	if m.Verify(client, value+"x") || m.Verify(client, "") {
+		t.Error("wrong secret was accepted")
+	}
+}
+
+func TestClientSecretManagerRotateKeepsPreviousSecret(t *testing.T) {
+	m := DefaultClientSecretManager()
+	client := newTestClient()
+
+	previous, err := m.Issue(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := m.Rotate(client, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Verify(client, previous) || !m.Verify(client, current) {
+		t.Error("secrets are not both valid during the rotation overlap")
+	}
+}
+
+func TestClientSecretManagerAdoptUsesArgon2(t *testing.T) {
+	m, err := NewClientSecretManager(&ClientSecretManagerConfig{
+		Hasher: &PasswordHasherConfig{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient()
+
+	if err := m.Adopt(client, "legacy-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(client.Secrets[0].Hash, "$argon2id$") {
+		t.Fatalf("adopted secret hash = %q, want argon2", client.Secrets[0].Hash)
+	}
+	if !m.Verify(client, "legacy-secret") {
+		t.Error("adopted secret was rejected")
+	}
+	if m.Verify(client, "other-secret") {
+		t.Error("wrong secret was accepted")
+	}
+}
+
+func TestClientSecretManagerVerifiesLegacyHashes(t *testing.T) {
+	m := DefaultClientSecretManager()
+	client := newTestClient()
+	client.AddSecret(entity.ClientSecret{
+		ID:        "legacy",
+		Hash:      "sha256$" + "LCa0a2j_xo_5m0U8HTBBNBNCLXBkg7-g-YpeiGJm564",
+		CreatedAt: time.Now(),
+	})
+
+	if !m.Verify(client, "foo") {
+		t.Error("secret with a legacy SHA-256 hash was rejected")
+	}
+	if m.Verify(client, "bar") {
+		t.Error("wrong secret was accepted")
+	}
+}
+
+func TestNewClientSecretManagerRejectsBadSealingKey(t *testing.T) {
+	if _, err := NewClientSecretManager(&ClientSecretManagerConfig{SealingKey: []byte("short")}); err == nil {
+		t.Error("short sealing key was accepted")
+	}
+	m, err := NewClientSecretManager(&ClientSecretManagerConfig{SealingKey: make([]byte, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.SupportsSharedKeys() {
+		t.Error("manager with a sealing key does not support shared keys")
+	}
+}
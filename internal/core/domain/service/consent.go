@@ -0,0 +1,176 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrConsentNotFound пользователь не давал согласия этому клиенту
+var ErrConsentNotFound = errors.New("consent not found")
+
+// ConsentService хранит согласия пользователей на доступ клиентов: позволяет
+// не показывать страницу согласия повторно и отзывать доступ приложений
+type ConsentService struct {
+	consents  ports.ConsentRepository
+	tokens    ports.TokenRepository
+	codes     ports.AuthCodeRepository
+	auditLogs ports.AuditLogRepository
+	scopes    *ScopeRegistry
+	resources *ResourceIndicators
+}
+
+// NewConsentService создает новый экземпляр ConsentService; auditLogs
+// необязателен, без него выдача и отзыв согласий только журналируются
+func NewConsentService(consents ports.ConsentRepository, tokens ports.TokenRepository, codes ports.AuthCodeRepository, auditLogs ports.AuditLogRepository) *ConsentService {
+	return &ConsentService{
+		consents:  consents,
+		tokens:    tokens,
+		codes:     codes,
+		auditLogs: auditLogs,
+	}
+}
+
+// SetScopes задает реестр областей: согласие на orders покрывает orders:read,
+// а на tenant:42 — области, включенные в шаблон tenant:{id}
+func (s *ConsentService) SetScopes(registry *ScopeRegistry) {
+	s.scopes = registry
+}
+
+// SetResources задает реестр ресурсов: запрос без resource сравнивается с
+// согласием по ресурсам, которые грант получит на самом деле, — ресурсу по
+// умолчанию или токенам без audience
+func (s *ConsentService) SetResources(resources *ResourceIndicators) {
+	s.resources = resources
+}
+
+// Covered проверяет, можно ли выполнить запрос без страницы согласия: клиент —
+// собственное приложение сервиса или прежнее согласие покрывает запрошенные
+// области и ресурсы. authorization_details описывают конкретную операцию,
+// поэтому сторонние клиенты всегда подтверждают их у пользователя.
+func (s *ConsentService) Covered(ctx context.Context, client *entity.Client, userID uuid.UUID, req entity.AuthorizationRequest) (bool, error) {
+	if client.FirstParty {
+		return true, nil
+	}
+	if len(req.AuthorizationDetails) > 0 {
+		return false, nil
+	}
+	consent, err := s.consents.Get(ctx, userID, client.ID)
+	if errors.Is(err, ports.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load consent: %w", err)
+	}
+	if !consent.Covers(nil, s.resources.Granted(req.Resources)) {
+		return false, nil
+	}
+	for _, scope := range req.Scopes {
+		if !s.scopes.Includes(consent.Scopes, scope) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Grant добавляет области и ресурсы к согласию пользователя на доступ клиента
+func (s *ConsentService) Grant(ctx context.Context, client *entity.Client, userID uuid.UUID, scopes, resources []string) error {
+	resources = s.resources.Granted(resources)
+	consent, err := s.consents.Get(ctx, userID, client.ID)
+	switch {
+	case errors.Is(err, ports.ErrNotFound):
+		consent = entity.NewConsent(userID, client.ID, scopes, resources)
+	case err != nil:
+		return fmt.Errorf("failed to load consent: %w", err)
+	case !consent.Grant(scopes, resources):
+		return nil
+	}
+	if err := s.consents.Save(ctx, consent); err != nil {
+		return fmt.Errorf("failed to save consent: %w", err)
+	}
+
+	log.Info("consent granted",
+		zap.String("client_id", client.ClientID),
+		zap.String("user_id", userID.String()),
+		zap.Strings("scopes", scopes),
+		zap.Bool("first_party", client.FirstParty),
+	)
+	auditLog := s.newAuditLog(userID, client, entity.AuditEventConsentGranted, fmt.Sprintf("consent granted to %s", client.Name))
+	auditLog.AddMetadata("scopes", scopes)
+	auditLog.AddMetadata("resources", resources)
+	auditLog.AddMetadata("first_party", client.FirstParty)
+	s.audit(ctx, auditLog)
+	return nil
+}
+
+// List возвращает согласия пользователя
+func (s *ConsentService) List(ctx context.Context, userID uuid.UUID) ([]*entity.Consent, error) {
+	consents, err := s.consents.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consents: %w", err)
+	}
+	return consents, nil
+}
+
+// Revoke удаляет согласие пользователя, непогашенные коды авторизации клиента и
+// отзывает все токены, выданные клиенту от его имени, включая access токены
+// в формате JWT: они тоже хранятся в репозитории и после отзыва отклоняются
+// интроспекцией. Возвращает число отозванных токенов.
+func (s *ConsentService) Revoke(ctx context.Context, userID uuid.UUID, client *entity.Client) (int, error) {
+	err := s.consents.Delete(ctx, userID, client.ID)
+	if errors.Is(err, ports.ErrNotFound) {
+		return 0, ErrConsentNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete consent: %w", err)
+	}
+
+	// Коды удаляются первыми, чтобы по ним не были выданы новые токены
+	codes, err := s.codes.DeleteByUserAndClient(ctx, userID, client.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete authorization codes: %w", err)
+	}
+	revoked, err := s.tokens.RevokeByUserAndClient(ctx, userID, client.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	log.Info("consent revoked",
+		zap.String("client_id", client.ClientID),
+		zap.String("user_id", userID.String()),
+		zap.Int("revoked_tokens", revoked),
+		zap.Int("deleted_codes", codes),
+	)
+	auditLog := s.newAuditLog(userID, client, entity.AuditEventConsentRevoked, fmt.Sprintf("consent revoked for %s", client.Name))
+	auditLog.AddMetadata("revoked_tokens", revoked)
+	auditLog.AddMetadata("deleted_codes", codes)
+	s.audit(ctx, auditLog)
+	return revoked, nil
+}
+
+func (s *ConsentService) newAuditLog(userID uuid.UUID, client *entity.Client, eventType entity.AuditEventType, description string) *entity.AuditLog {
+	auditLog := entity.NewAuditLog(userID.String(), eventType, description, "", "", true)
+	clientID := client.ID.String()
+	auditLog.ClientID = &clientID
+	auditLog.AddMetadata("client_id", client.ClientID)
+	return auditLog
+}
+
+// audit сохраняет запись аудита; ошибка хранилища не отменяет выполненную операцию
+func (s *ConsentService) audit(ctx context.Context, auditLog *entity.AuditLog) {
+	if s.auditLogs == nil {
+		return
+	}
+	if err := s.auditLogs.Save(ctx, auditLog); err != nil {
+		log.Error("failed to save consent audit log",
+			zap.String("user_id", auditLog.UserID),
+			zap.String("event_type", string(auditLog.EventType)),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestConsentCoveredHonorsScopeHierarchy(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	s := NewConsentService(store.Consents, store.Tokens, store.AuthCodes, nil)
+	s.SetScopes(newTestScopeRegistry(t))
+
+	client := newTestClient()
+	userID := uuid.New()
+	if err := s.Grant(ctx, client, userID, []string{"orders", "tenant:42"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		scopes []string
+		want   bool
+	}{
+		{[]string{"orders:read"}, true},
+		{[]string{"tenant:42:read"}, true},
+		{[]string{"tenant:43:read"}, false},
+		{[]string{"orders:read", "profile"}, false},
+	}
+	for _, tt := range tests {
+		covered, err := s.Covered(ctx, client, userID, entity.AuthorizationRequest{Scopes: tt.scopes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if covered != tt.want {
+			t.Errorf("Covered(%v) = %v, want %v", tt.scopes, covered, tt.want)
+		}
+	}
+}
+
+func TestConsentRevokeInvalidatesCodesAndTokens(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	client := newTestClient()
+	if err := store.Clients.Create(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	authorization := NewAuthorizationService(store.Clients, store.AuthCodes, store.PendingAuthorizations, nil, nil, nil)
+	s := NewConsentService(store.Consents, store.Tokens, store.AuthCodes, nil)
+
+	code := issueTestCode(t, authorization, client, client.RedirectURIs[0])
+	if err := s.Grant(ctx, client, code.UserID, []string{"openid"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Access токен в формате JWT хранится вместе с остальными токенами
+	access := entity.NewToken(code.UserID, client.ID, entity.AccessToken, []string{"openid"}, time.Hour)
+	access.Value = "eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJ1c2VyIn0.signature"
+	if err := store.Tokens.Create(ctx, access); err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := s.Revoke(ctx, code.UserID, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != 1 {
+		t.Errorf("revoked %d tokens, want 1", revoked)
+	}
+	stored, err := store.Tokens.GetByValue(ctx, access.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.IsValid() {
+		t.Error("access token is still valid after the consent was revoked")
+	}
+	if _, err := authorization.RedeemCode(ctx, client, code.Code, client.RedirectURIs[0], ""); !errors.Is(err, ErrInvalidAuthorizationCode) {
+		t.Errorf("code redeemed after the consent was revoked: error = %v", err)
+	}
+}
+
+func TestConsentCoveredMatchesGrantedResources(t *testing.T) {
+	ctx := context.Background()
+	api := "https://api.example.com"
+	billing := "https://billing.example.com"
+	withDefault, err := NewResourceIndicators(
+		entity.ResourceServer{URI: api, Scopes: []string{"openid"}, Default: true},
+		entity.ResourceServer{URI: billing, Scopes: []string{"openid"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		resources *ResourceIndicators
+		granted   []string
+		requested []string
+		want      bool
+	}{
+		{"resource consent does not cover a token without audience", nil, []string{billing}, nil, false},
+		{"consent without audience does not cover a resource", nil, nil, []string{billing}, false},
+		{"consent without audience", nil, nil, nil, true},
+		{"empty consent is the default resource", withDefault, nil, []string{api}, true},
+		{"empty request is the default resource", withDefault, []string{api}, nil, true},
+		{"empty request is not another resource", withDefault, []string{billing}, nil, false},
+	}
+	for _, tt := range tests {
+		store := memory.NewStore()
+		s := NewConsentService(store.Consents, store.Tokens, store.AuthCodes, nil)
+		s.SetResources(tt.resources)
+		client := newTestClient()
+		userID := uuid.New()
+		if err := s.Grant(ctx, client, userID, []string{"openid"}, tt.granted); err != nil {
+			t.Fatal(err)
+		}
+		covered, err := s.Covered(ctx, client, userID, entity.AuthorizationRequest{Scopes: []string{"openid"}, Resources: tt.requested})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if covered != tt.want {
+			t.Errorf("%s: Covered = %v, want %v", tt.name, covered, tt.want)
+		}
+	}
+}
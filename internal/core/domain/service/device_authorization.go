@@ -0,0 +1,350 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// userCodeAlphabet согласные без гласных и похожих символов: коды удобно вводить
+// с клавиатуры телевизора и из них не складываются слова (RFC 8628, раздел 6.1)
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// Ошибки опроса token endpoint устройством (RFC 8628, раздел 3.5)
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("polling too frequently")
+	ErrDeviceCodeExpired    = errors.New("device code expired")
+	ErrDeviceAccessDenied   = errors.New("access denied by user")
+	ErrInvalidDeviceCode    = errors.New("invalid device code")
+)
+
+// ErrInvalidUserCode код пользователя не найден, истек или уже использован
+var ErrInvalidUserCode = errors.New("invalid or expired user code")
+
+// ErrTooManyUserCodeAttempts пользователь исчерпал попытки ввода кода (RFC 8628, раздел 5.1)
+var ErrTooManyUserCodeAttempts = errors.New("too many user code attempts")
+
+// DeviceAuthorizationConfig конфигурация авторизации устройств
+type DeviceAuthorizationConfig struct {
+	// CodeLifetime срок действия device_code и user_code
+	CodeLifetime time.Duration
+	// PollInterval минимальный интервал опроса token endpoint
+	PollInterval time.Duration
+	// SlowDownIncrement на сколько увеличивается интервал после ответа slow_down
+	SlowDownIncrement time.Duration
+	// UserCodeLength число символов в коде пользователя
+	UserCodeLength int
+	// DeviceCodeBytes число случайных байт в коде устройства
+	DeviceCodeBytes int
+	// MaxUserCodeAttempts число неверных кодов, которые пользователь может ввести
+	// за UserCodeAttemptWindow; защищает короткие коды от перебора
+	MaxUserCodeAttempts int
+	// UserCodeAttemptWindow период, за который считаются неверные коды
+	UserCodeAttemptWindow time.Duration
+}
+
+// DefaultDeviceAuthorizationConfig возвращает конфигурацию по умолчанию
+func DefaultDeviceAuthorizationConfig() *DeviceAuthorizationConfig {
+	return &DeviceAuthorizationConfig{
+		CodeLifetime:      10 * time.Minute,
+		PollInterval:      5 * time.Second,
+		SlowDownIncrement: 5 * time.Second,
+		UserCodeLength:    8,
+		DeviceCodeBytes:   32,
+
+		MaxUserCodeAttempts:   5,
+		UserCodeAttemptWindow: 15 * time.Minute,
+	}
+}
+
+// DeviceAuthorizationService реализует Device Authorization Grant (RFC 8628)
+type DeviceAuthorizationService struct {
+	authorizations ports.DeviceAuthorizationRepository
+	config         *DeviceAuthorizationConfig
+
+	mu       sync.Mutex
+	failures map[uuid.UUID]userCodeFailures
+}
+
+// userCodeFailures неверные коды, введенные пользователем с начала периода
+type userCodeFailures struct {
+	count int
+	since time.Time
+}
+
+// NewDeviceAuthorizationService создает новый экземпляр DeviceAuthorizationService
+func NewDeviceAuthorizationService(authorizations ports.DeviceAuthorizationRepository, config *DeviceAuthorizationConfig) *DeviceAuthorizationService {
+	if config == nil {
+		config = DefaultDeviceAuthorizationConfig()
+	}
+	return &DeviceAuthorizationService{
+		authorizations: authorizations,
+		config:         config,
+		failures:       make(map[uuid.UUID]userCodeFailures),
+	}
+}
+
+// Start создает запрос авторизации устройства для клиента
+func (s *DeviceAuthorizationService) Start(ctx context.Context, client *entity.Client, scopes []string) (*entity.DeviceAuthorization, error) {
+	deviceCode, err := randomToken(s.config.DeviceCodeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	authorization := &entity.DeviceAuthorization{
+		ID:         uuid.New(),
+		DeviceCode: deviceCode,
+		ClientID:   client.ID,
+		Scopes:     scopes,
+		Status:     entity.DeviceAuthorizationPending,
+		Interval:   s.config.PollInterval,
+		ExpiresAt:  now.Add(s.config.CodeLifetime),
+		CreatedAt:  now,
+	}
+
+	// Коды пользователя короткие, поэтому при совпадении с действующим генерируем заново
+	for attempt := 0; ; attempt++ {
+		if authorization.UserCode, err = s.newUserCode(); err != nil {
+			return nil, err
+		}
+		err = s.authorizations.Create(ctx, authorization)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ports.ErrAlreadyExists) || attempt == 4 {
+			return nil, fmt.Errorf("failed to store device authorization: %w", err)
+		}
+	}
+
+	log.Info("device authorization started",
+		zap.String("authorization_id", authorization.ID.String()),
+		zap.String("client_id", client.ClientID),
+		zap.Strings("scopes", scopes),
+	)
+	return authorization, nil
+}
+
+// Pending возвращает ожидающий решения запрос по коду, введенному пользователем.
+// Неверные коды учитываются: после MaxUserCodeAttempts попыток возвращается
+// ErrTooManyUserCodeAttempts до конца периода.
+func (s *DeviceAuthorizationService) Pending(ctx context.Context, userCode string, user *entity.User) (*entity.DeviceAuthorization, error) {
+	now := time.Now()
+	if s.attemptsExhausted(user.ID, now) {
+		log.Warn("user code attempts exhausted", zap.String("user_id", user.ID.String()))
+		return nil, ErrTooManyUserCodeAttempts
+	}
+
+	authorization, err := s.authorizations.GetByUserCode(ctx, NormalizeUserCode(userCode))
+	if err != nil && !errors.Is(err, ports.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || !authorization.IsPending() || authorization.IsExpired(now) {
+		s.recordFailure(user.ID, now)
+		return nil, ErrInvalidUserCode
+	}
+	return authorization, nil
+}
+
+// Approve фиксирует согласие пользователя на доступ устройства
+func (s *DeviceAuthorizationService) Approve(ctx context.Context, userCode string, user *entity.User) error {
+	return s.decide(ctx, userCode, user, func(authorization *entity.DeviceAuthorization) {
+		authorization.Approve(user.ID)
+	})
+}
+
+// Deny фиксирует отказ пользователя
+func (s *DeviceAuthorizationService) Deny(ctx context.Context, userCode string, user *entity.User) error {
+	return s.decide(ctx, userCode, user, (*entity.DeviceAuthorization).Deny)
+}
+
+// decide сохраняет решение, только если запрос все еще ожидает его: из
+// одновременных Approve и Deny выигрывает одно, второе получает ErrInvalidUserCode
+func (s *DeviceAuthorizationService) decide(ctx context.Context, userCode string, user *entity.User, decision func(*entity.DeviceAuthorization)) error {
+	authorization, err := s.Pending(ctx, userCode, user)
+	if err != nil {
+		return err
+	}
+	decision(authorization)
+	err = s.authorizations.Decide(ctx, authorization)
+	if errors.Is(err, ports.ErrNotFound) {
+		return ErrInvalidUserCode
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update device authorization: %w", err)
+	}
+
+	log.Info("device authorization decided",
+		zap.String("authorization_id", authorization.ID.String()),
+		zap.String("user_id", user.ID.String()),
+		zap.String("status", string(authorization.Status)),
+	)
+	return nil
+}
+
+// attemptsExhausted проверяет, исчерпал ли пользователь попытки ввода кода
+func (s *DeviceAuthorizationService) attemptsExhausted(userID uuid.UUID, now time.Time) bool {
+	if s.config.MaxUserCodeAttempts <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures, ok := s.failures[userID]
+	return ok && now.Sub(failures.since) < s.config.UserCodeAttemptWindow &&
+		failures.count >= s.config.MaxUserCodeAttempts
+}
+
+// recordFailure учитывает неверный код; записи с истекшим периодом удаляются
+func (s *DeviceAuthorizationService) recordFailure(userID uuid.UUID, now time.Time) {
+	if s.config.MaxUserCodeAttempts <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, failures := range s.failures {
+		if now.Sub(failures.since) >= s.config.UserCodeAttemptWindow {
+			delete(s.failures, id)
+		}
+	}
+	failures, ok := s.failures[userID]
+	if !ok {
+		failures.since = now
+	}
+	failures.count++
+	s.failures[userID] = failures
+}
+
+// Poll обрабатывает опрос token endpoint устройством. Подтвержденный запрос
+// возвращается один раз и удаляется; в остальных случаях возвращается одна
+// из ошибок ErrAuthorizationPending, ErrSlowDown, ErrDeviceCodeExpired,
+// ErrDeviceAccessDenied или ErrInvalidDeviceCode.
+func (s *DeviceAuthorizationService) Poll(ctx context.Context, client *entity.Client, deviceCode string) (*entity.DeviceAuthorization, error) {
+	authorization, err := s.authorizations.GetByDeviceCode(ctx, deviceCode)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, ErrInvalidDeviceCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if authorization.ClientID != client.ID {
+		log.Warn("device code presented by another client",
+			zap.String("authorization_id", authorization.ID.String()),
+			zap.String("client_id", client.ClientID),
+		)
+		return nil, ErrInvalidDeviceCode
+	}
+
+	now := time.Now()
+	if authorization.IsExpired(now) {
+		s.remove(ctx, authorization)
+		return nil, ErrDeviceCodeExpired
+	}
+
+	if !authorization.Poll(now) {
+		authorization.Interval += s.config.SlowDownIncrement
+		if err := s.authorizations.Update(ctx, authorization); err != nil {
+			return nil, fmt.Errorf("failed to update device authorization: %w", err)
+		}
+		return nil, ErrSlowDown
+	}
+
+	switch authorization.Status {
+	case entity.DeviceAuthorizationApproved:
+		// Код одноразовый: удаление до выдачи токенов исключает повторное использование
+		if err := s.authorizations.Delete(ctx, authorization.ID); err != nil {
+			return nil, fmt.Errorf("failed to consume device authorization: %w", err)
+		}
+		return authorization, nil
+	case entity.DeviceAuthorizationDenied:
+		s.remove(ctx, authorization)
+		return nil, ErrDeviceAccessDenied
+	default:
+		if err := s.authorizations.Update(ctx, authorization); err != nil {
+			return nil, fmt.Errorf("failed to update device authorization: %w", err)
+		}
+		return nil, ErrAuthorizationPending
+	}
+}
+
+// Interval возвращает минимальный интервал опроса для новых запросов
+func (s *DeviceAuthorizationService) Interval() time.Duration {
+	return s.config.PollInterval
+}
+
+func (s *DeviceAuthorizationService) remove(ctx context.Context, authorization *entity.DeviceAuthorization) {
+	if err := s.authorizations.Delete(ctx, authorization.ID); err != nil && !errors.Is(err, ports.ErrNotFound) {
+		log.Error("failed to delete device authorization",
+			zap.String("authorization_id", authorization.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// newUserCode генерирует код пользователя без смещения распределения символов
+func (s *DeviceAuthorizationService) newUserCode() (string, error) {
+	length := s.config.UserCodeLength
+	if length < 6 {
+		length = 6
+	}
+
+	// Байты не меньше limit отбрасываются, чтобы остаток от деления был равномерным
+	limit := byte(256 - 256%len(userCodeAlphabet))
+	code := make([]byte, 0, length)
+	buf := make([]byte, length*2)
+	for len(code) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate user code: %w", err)
+		}
+		for _, b := range buf {
+			if b < limit && len(code) < length {
+				code = append(code, userCodeAlphabet[int(b)%len(userCodeAlphabet)])
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// NormalizeUserCode приводит введенный пользователем код к хранимому виду:
+// верхний регистр, без дефисов и пробелов
+func NormalizeUserCode(input string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(input) {
+		if r == '-' || r == ' ' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FormatUserCode разбивает код пользователя дефисом пополам для отображения
+func FormatUserCode(code string) string {
+	if len(code) < 6 {
+		return code
+	}
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// randomToken возвращает случайную строку base64url из size байт (не менее 16)
+func randomToken(size int) (string, error) {
+	if size < 16 {
+		size = 16
+	}
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
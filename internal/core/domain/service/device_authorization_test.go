@@ -0,0 +1,109 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func newTestDeviceService(t *testing.T) (*DeviceAuthorizationService, *memory.DeviceAuthorizationRepository, *entity.DeviceAuthorization) {
+	t.Helper()
+	repository := memory.NewDeviceAuthorizationRepository()
+	s := NewDeviceAuthorizationService(repository, nil)
+	authorization, err := s.Start(context.Background(), newTestClient(), []string{"openid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, repository, authorization
+}
+
+func TestDeviceUserCodeAttemptsAreLimited(t *testing.T) {
+	s, _, authorization := newTestDeviceService(t)
+	ctx := context.Background()
+	user := newTestUser()
+
+	for i := 0; i < s.config.MaxUserCodeAttempts; i++ {
+		if _, err := s.Pending(ctx, "BCDFGHJK", user); !errors.Is(err, ErrInvalidUserCode) {
+			t.Fatalf("attempt %d: error = %v, want ErrInvalidUserCode", i+1, err)
+		}
+	}
+	if _, err := s.Pending(ctx, authorization.UserCode, user); !errors.Is(err, ErrTooManyUserCodeAttempts) {
+		t.Fatalf("correct code after exhausted attempts: error = %v, want ErrTooManyUserCodeAttempts", err)
+	}
+	if err := s.Approve(ctx, authorization.UserCode, user); !errors.Is(err, ErrTooManyUserCodeAttempts) {
+		t.Fatalf("Approve after exhausted attempts: error = %v", err)
+	}
+
+	// Попытки считаются для каждого пользователя отдельно
+	if _, err := s.Pending(ctx, FormatUserCode(authorization.UserCode), newTestUser()); err != nil {
+		t.Errorf("another user was locked out: %v", err)
+	}
+}
+
+func TestDeviceDecisionIsMadeOnce(t *testing.T) {
+	s, repository, authorization := newTestDeviceService(t)
+	ctx := context.Background()
+	user := newTestUser()
+
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for _, decide := range []func(context.Context, string, *entity.User) error{s.Approve, s.Deny} {
+		wg.Add(1)
+		go func(decide func(context.Context, string, *entity.User) error) {
+			defer wg.Done()
+			results <- decide(ctx, authorization.UserCode, user)
+		}(decide)
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrInvalidUserCode):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d decisions succeeded, want exactly 1", succeeded)
+	}
+
+	stored, err := repository.GetByDeviceCode(ctx, authorization.DeviceCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.IsPending() {
+		t.Error("decision was not stored")
+	}
+}
+
+func TestDevicePollDoesNotOverwriteDecision(t *testing.T) {
+	s, repository, authorization := newTestDeviceService(t)
+	ctx := context.Background()
+	user := newTestUser()
+
+	// Опрос прочитал запрос до решения пользователя и сохраняет его после
+	stale, err := repository.GetByDeviceCode(ctx, authorization.DeviceCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Approve(ctx, authorization.UserCode, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Update(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := repository.GetByDeviceCode(ctx, authorization.DeviceCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != entity.DeviceAuthorizationApproved || stored.UserID != user.ID {
+		t.Errorf("status = %s, user = %s after a stale poll update", stored.Status, stored.UserID)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DPoPProofType значение typ в заголовке DPoP proof (RFC 9449, раздел 4.2)
+const DPoPProofType = "dpop+jwt"
+
+// Ошибки проверки DPoP proof (RFC 9449, разделы 4.3 и 8)
+var (
+	ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+	ErrUseDPoPNonce     = errors.New("DPoP proof must contain a server nonce")
+)
+
+// dpopNonceMACSize длина усеченного HMAC в nonce
+const dpopNonceMACSize = 16
+
+// DPoPConfig конфигурация проверки DPoP proof
+type DPoPConfig struct {
+	// Algorithms допустимые алгоритмы подписи proof
+	Algorithms []string
+	// ProofLifetime допустимое отклонение iat proof от времени сервера
+	ProofLifetime time.Duration
+	// RequireNonce proof должен содержать nonce, выданный сервером (RFC 9449, раздел 8)
+	RequireNonce bool
+	// NonceLifetime срок действия nonce
+	NonceLifetime time.Duration
+	// NonceKey ключ HMAC для nonce; пустой — случайный ключ процесса, и после
+	// перезапуска клиенты получат новый nonce
+	NonceKey []byte
+}
+
+// DefaultDPoPConfig возвращает конфигурацию по умолчанию
+func DefaultDPoPConfig() *DPoPConfig {
+	return &DPoPConfig{
+		Algorithms:    append([]string(nil), jose.AsymmetricAlgorithms...),
+		ProofLifetime: time.Minute,
+		NonceLifetime: 5 * time.Minute,
+	}
+}
+
+// DPoPProof проверенный DPoP proof
+type DPoPProof struct {
+	// JKT отпечаток открытого ключа из заголовка proof (RFC 7638)
+	JKT      string
+	JTI      string
+	IssuedAt time.Time
+}
+
+// DPoPService проверяет DPoP proof и привязку токенов к ключу клиента (RFC 9449)
+type DPoPService struct {
+	replays  ports.ReplayCache
+	config   *DPoPConfig
+	nonceKey []byte
+}
+
+// NewDPoPService создает новый экземпляр DPoPService
+func NewDPoPService(replays ports.ReplayCache, config *DPoPConfig) *DPoPService {
+	if config == nil {
+		config = DefaultDPoPConfig()
+	}
+	nonceKey := config.NonceKey
+	if len(nonceKey) == 0 {
+		nonceKey = make([]byte, 32)
+		if _, err := rand.Read(nonceKey); err != nil {
+			panic(fmt.Sprintf("failed to generate DPoP nonce key: %v", err))
+		}
+	}
+	return &DPoPService{
+		replays:  replays,
+		config:   config,
+		nonceKey: nonceKey,
+	}
+}
+
+// Algorithms возвращает алгоритмы подписи proof для метаданных сервера
+func (s *DPoPService) Algorithms() []string {
+	return append([]string(nil), s.config.Algorithms...)
+}
+
+// RequiresNonce сообщает, требует ли сервер nonce в proof
+func (s *DPoPService) RequiresNonce() bool {
+	return s.config.RequireNonce
+}
+
+// Nonce выдает новый nonce для заголовка DPoP-Nonce. Nonce не хранится:
+// он содержит время выдачи и HMAC сервера.
+func (s *DPoPService) Nonce() string {
+	issuedAt := binary.BigEndian.AppendUint64(nil, uint64(time.Now().Unix()))
+	return base64.RawURLEncoding.EncodeToString(append(issuedAt, s.nonceMAC(issuedAt)...))
+}
+
+// Verify проверяет DPoP proof запроса method к uri. Для запросов к защищенному
+// ресурсу accessToken — предъявленный токен, и proof должен содержать его хеш (ath).
+func (s *DPoPService) Verify(ctx context.Context, proof, method, uri, accessToken string) (*DPoPProof, error) {
+	verified, err := s.verify(proof, method, uri, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Повтор proof отклоняется, пока его iat остается в допустимом окне
+	key := verified.JKT + ":" + verified.JTI
+	err = s.replays.Use(ctx, key, verified.IssuedAt.Add(s.config.ProofLifetime))
+	if errors.Is(err, ports.ErrAlreadyExists) {
+		log.Warn("DPoP proof replayed", zap.String("jkt", verified.JKT))
+		return nil, fmt.Errorf("%w: jti has already been used", ErrInvalidDPoPProof)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return verified, nil
+}
+
+// CheckDPoPBinding проверяет, что токен привязан к ключу proof. Токен без привязки
+// принимается только без proof: привязанный токен нельзя предъявить как bearer.
+func CheckDPoPBinding(token *entity.Token, proof *DPoPProof) error {
+	jkt := token.DPoPThumbprint()
+	switch {
+	case jkt == "" && proof == nil:
+		return nil
+	case jkt == "":
+		return fmt.Errorf("%w: token is not bound to a DPoP key", ErrInvalidDPoPProof)
+	case proof == nil:
+		return fmt.Errorf("%w: token is bound to a DPoP key", ErrInvalidDPoPProof)
+	case !hmac.Equal([]byte(jkt), []byte(proof.JKT)):
+		return fmt.Errorf("%w: proof key does not match the token binding", ErrInvalidDPoPProof)
+	}
+	return nil
+}
+
+func (s *DPoPService) verify(proof, method, uri, accessToken string) (*DPoPProof, error) {
+	token, err := jose.Parse(proof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	if token.Header.Type != DPoPProofType {
+		return nil, fmt.Errorf("%w: typ must be %s", ErrInvalidDPoPProof, DPoPProofType)
+	}
+	if token.Header.JWK == nil || token.Header.JWK.IsPrivate() {
+		return nil, fmt.Errorf("%w: jwk header must contain a public key", ErrInvalidDPoPProof)
+	}
+	publicKey, err := token.Header.JWK.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	if err := token.Verify(publicKey, s.config.Algorithms...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	jkt, err := token.Header.JWK.Thumbprint()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+
+	claims, err := token.Claims()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	jti := claims.String("jti")
+	if jti == "" {
+		return nil, fmt.Errorf("%w: jti is required", ErrInvalidDPoPProof)
+	}
+	if claims.String("htm") != method {
+		return nil, fmt.Errorf("%w: htm does not match the request method", ErrInvalidDPoPProof)
+	}
+	if !sameHTU(claims.String("htu"), uri) {
+		return nil, fmt.Errorf("%w: htu does not match the request URI", ErrInvalidDPoPProof)
+	}
+	issuedAt, ok := claims.Time("iat")
+	if !ok {
+		return nil, fmt.Errorf("%w: iat is required", ErrInvalidDPoPProof)
+	}
+	if age := time.Since(issuedAt); age > s.config.ProofLifetime || age < -s.config.ProofLifetime {
+		return nil, fmt.Errorf("%w: iat is outside the acceptable window", ErrInvalidDPoPProof)
+	}
+
+	if accessToken != "" {
+		hash := sha256.Sum256([]byte(accessToken))
+		if claims.String("ath") != base64.RawURLEncoding.EncodeToString(hash[:]) {
+			return nil, fmt.Errorf("%w: ath does not match the access token", ErrInvalidDPoPProof)
+		}
+	}
+
+	if s.config.RequireNonce && !s.validNonce(claims.String("nonce")) {
+		return nil, ErrUseDPoPNonce
+	}
+
+	return &DPoPProof{JKT: jkt, JTI: jti, IssuedAt: issuedAt}, nil
+}
+
+// validNonce проверяет HMAC и срок действия nonce
+func (s *DPoPService) validNonce(nonce string) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(raw) != 8+dpopNonceMACSize {
+		return false
+	}
+	issuedAt, mac := raw[:8], raw[8:]
+	if !hmac.Equal(mac, s.nonceMAC(issuedAt)) {
+		return false
+	}
+	age := time.Since(time.Unix(int64(binary.BigEndian.Uint64(issuedAt)), 0))
+	return age >= -s.config.ProofLifetime && age <= s.config.NonceLifetime
+}
+
+func (s *DPoPService) nonceMAC(data []byte) []byte {
+	mac := hmac.New(sha256.New, s.nonceKey)
+	mac.Write(data)
+	return mac.Sum(nil)[:dpopNonceMACSize]
+}
+
+// sameHTU сравнивает htu с адресом запроса без учета query и fragment и
+// регистра схемы и хоста (RFC 9449, раздел 4.3)
+func sameHTU(htu, uri string) bool {
+	got, err := url.Parse(htu)
+	if err != nil || !got.IsAbs() {
+		return false
+	}
+	want, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(got.Scheme, want.Scheme) &&
+		strings.EqualFold(got.Host, want.Host) &&
+		got.EscapedPath() == want.EscapedPath()
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// DomainEventHandler обработчик доменного события
+type DomainEventHandler func(event entity.DomainEvent)
+
+// EventDispatcher синхронно доставляет доменные события подписчикам внутри процесса
+type EventDispatcher struct {
+	mu       sync.RWMutex
+	handlers map[entity.DomainEventType][]DomainEventHandler
+}
+
+// NewEventDispatcher создает новый экземпляр EventDispatcher
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{
+		handlers: make(map[entity.DomainEventType][]DomainEventHandler),
+	}
+}
+
+// Subscribe подписывает обработчик на события указанных типов
+func (d *EventDispatcher) Subscribe(handler DomainEventHandler, eventTypes ...entity.DomainEventType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		d.handlers[eventType] = append(d.handlers[eventType], handler)
+	}
+}
+
+// Publish доставляет события всем подписанным обработчикам
+func (d *EventDispatcher) Publish(events ...entity.DomainEvent) {
+	for _, event := range events {
+		d.mu.RLock()
+		handlers := d.handlers[event.Type]
+		d.mu.RUnlock()
+
+		log.Debug("publishing domain event",
+			zap.String("event_type", string(event.Type)),
+			zap.String("aggregate_id", event.AggregateID.String()),
+			zap.Int("handlers_count", len(handlers)),
+		)
+
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// JWTAccessTokenType значение typ заголовка access token в формате JWT (RFC 9068, раздел 2.1)
+const JWTAccessTokenType = "at+jwt"
+
+// JWTAccessTokenEncoder выпускает access токены в формате JWT (RFC 9068),
+// подписанные активным ключом сервера. Токен по-прежнему хранится в
+// репозитории: отзыв и интроспекция работают так же, как для непрозрачных токенов.
+type JWTAccessTokenEncoder struct {
+	keys   *SigningKeyManager
+	issuer string
+}
+
+// NewJWTAccessTokenEncoder создает новый экземпляр JWTAccessTokenEncoder;
+// issuer — идентификатор сервера авторизации для claim iss
+func NewJWTAccessTokenEncoder(keys *SigningKeyManager, issuer string) *JWTAccessTokenEncoder {
+	return &JWTAccessTokenEncoder{keys: keys, issuer: strings.TrimSuffix(issuer, "/")}
+}
+
+// Encode возвращает подписанный JWT с правами токена. aud — ресурсы токена,
+// а если они не заданы, идентификатор сервера (RFC 9068, раздел 3).
+func (e *JWTAccessTokenEncoder) Encode(ctx context.Context, client *entity.Client, token *entity.Token) (string, error) {
+	key, err := e.keys.ActiveKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	subject := client.ClientID
+	if token.UserID != uuid.Nil {
+		subject = token.UserID.String()
+	}
+	audience := token.Audience
+	if len(audience) == 0 {
+		audience = []string{e.issuer}
+	}
+	claims := jose.Claims{
+		"iss":       e.issuer,
+		"sub":       subject,
+		"aud":       audience,
+		"client_id": client.ClientID,
+		"jti":       token.ID.String(),
+		"iat":       token.CreatedAt.Unix(),
+		"exp":       token.ExpiresAt.Unix(),
+	}
+	if len(token.Scopes) > 0 {
+		claims["scope"] = strings.Join(token.Scopes, " ")
+	}
+	if token.Confirmation != nil {
+		claims["cnf"] = token.Confirmation
+	}
+	if token.Actor != nil {
+		claims["act"] = token.Actor
+	}
+	if len(token.AuthorizationDetails) > 0 {
+		claims["authorization_details"] = token.AuthorizationDetails
+	}
+
+	header := jose.Header{Algorithm: key.Algorithm, KeyID: key.ID, Type: JWTAccessTokenType}
+	return jose.Sign(header, claims, privateKey)
+}
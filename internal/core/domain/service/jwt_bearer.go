@@ -0,0 +1,366 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Ошибки гранта jwt-bearer (RFC 7523, раздел 3.1)
+var (
+	ErrInvalidBearerAssertion = errors.New("invalid assertion")
+	ErrInvalidBearerScope     = errors.New("invalid scope")
+)
+
+// SubjectMapping способ сопоставления sub утверждения локальному пользователю
+type SubjectMapping string
+
+const (
+	// SubjectByEmail пользователь ищется по claim email (или по sub, если он
+	// содержит адрес); адрес должен быть подтвержден издателем (email_verified)
+	SubjectByEmail SubjectMapping = "email"
+	// SubjectByID sub содержит идентификатор локального пользователя
+	SubjectByID SubjectMapping = "id"
+)
+
+// TrustedIssuer издатель утверждений, которым сервер доверяет выдачу токенов
+// от имени своих пользователей
+type TrustedIssuer struct {
+	// Issuer значение iss утверждений
+	Issuer string
+	// JWKS или JWKSURI открытые ключи издателя
+	JWKS    *jose.JWKSet
+	JWKSURI string
+	// Algorithms допустимые алгоритмы подписи; пусто — любые асимметричные
+	Algorithms []string
+	// Scopes области действия, которые можно получить по утверждениям издателя
+	Scopes []string
+	// ClientIDs клиенты, которым разрешено предъявлять утверждения издателя;
+	// пусто — любой клиент с типом авторизации jwt-bearer
+	ClientIDs []string
+	// SubjectMapping способ поиска пользователя; пустой — SubjectByEmail
+	SubjectMapping SubjectMapping
+	// ProvisionUsers создавать пользователя при первом утверждении о нем (только SubjectByEmail)
+	ProvisionUsers bool
+	// EmailDomains домены адресов, владельцами которых издатель признан при
+	// SubjectByEmail: утверждения о существующих пользователях с такими адресами
+	// принимаются, а новые пользователи создаются только с ними. Пусто — издатель
+	// представляет только пользователей, созданных по его утверждениям.
+	EmailDomains []string
+}
+
+// allowsEmail проверяет, входит ли домен адреса в EmailDomains
+func (i *TrustedIssuer) allowsEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, allowed := range i.EmailDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// represents проверяет, может ли издатель делать утверждения о пользователе:
+// пользователь создан по утверждению издателя о том же sub или его адрес
+// относится к доменам издателя
+func (i *TrustedIssuer) represents(user *entity.User, subject string) bool {
+	provisionedBy, _ := user.Attribute("provisioned_by")
+	externalSub, _ := user.Attribute("external_sub")
+	if provisionedBy == i.Issuer && externalSub == subject {
+		return true
+	}
+	return i.allowsEmail(user.Email)
+}
+
+// JWTBearerConfig конфигурация гранта jwt-bearer
+type JWTBearerConfig struct {
+	Issuers []TrustedIssuer
+	// MaxLifetime насколько далеко в будущем может быть exp утверждения; 0 — не ограничено
+	MaxLifetime time.Duration
+}
+
+// DefaultJWTBearerConfig возвращает конфигурацию по умолчанию без доверенных издателей
+func DefaultJWTBearerConfig() *JWTBearerConfig {
+	return &JWTBearerConfig{
+		MaxLifetime: time.Hour,
+	}
+}
+
+// JWTBearerRequest параметры запроса гранта jwt-bearer (RFC 7523, раздел 2.1)
+type JWTBearerRequest struct {
+	Assertion string
+	Scopes    []string
+	// Audiences значения aud, по которым утверждение признается адресованным
+	// серверу: идентификатор сервера и адрес token endpoint
+	Audiences []string
+}
+
+// JWTBearerResult пользователь и области действия, на которые нужно выдать токен
+type JWTBearerResult struct {
+	User   *entity.User
+	Scopes []string
+	// Provisioned пользователь создан по этому утверждению
+	Provisioned bool
+}
+
+// JWTBearerService обменивает утверждения доверенных издателей на токены
+// сервера (RFC 7523, раздел 2.1)
+type JWTBearerService struct {
+	users   ports.UserRepository
+	keys    *ClientKeys
+	replays ports.ReplayCache
+	hasher  *PasswordHasher
+	config  *JWTBearerConfig
+	issuers map[string]*TrustedIssuer
+}
+
+// NewJWTBearerService создает новый экземпляр JWTBearerService; keys nil —
+// конфигурация по умолчанию
+func NewJWTBearerService(users ports.UserRepository, keys *ClientKeys, replays ports.ReplayCache, config *JWTBearerConfig) *JWTBearerService {
+	if keys == nil {
+		keys = NewClientKeys(nil)
+	}
+	if config == nil {
+		config = DefaultJWTBearerConfig()
+	}
+	issuers := make(map[string]*TrustedIssuer, len(config.Issuers))
+	for i := range config.Issuers {
+		issuers[config.Issuers[i].Issuer] = &config.Issuers[i]
+	}
+	return &JWTBearerService{
+		users:   users,
+		keys:    keys,
+		replays: replays,
+		hasher:  NewPasswordHasher(nil),
+		config:  config,
+		issuers: issuers,
+	}
+}
+
+// Exchange проверяет утверждение и находит (или создает) пользователя, о котором
+// оно сделано. Области действия ограничены издателем и клиентом; без запрошенных
+// выдаются все области, общие для них.
+func (s *JWTBearerService) Exchange(ctx context.Context, client *entity.Client, req JWTBearerRequest) (*JWTBearerResult, error) {
+	token, err := jose.Parse(req.Assertion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBearerAssertion, err)
+	}
+	unverified, err := token.Claims()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBearerAssertion, err)
+	}
+	issuer, ok := s.issuers[unverified.Issuer()]
+	if !ok {
+		return nil, fmt.Errorf("%w: issuer %q is not trusted", ErrInvalidBearerAssertion, unverified.Issuer())
+	}
+	if len(issuer.ClientIDs) > 0 && !containsString(issuer.ClientIDs, client.ClientID) {
+		return nil, fmt.Errorf("%w: client may not present assertions of %s", ErrInvalidBearerAssertion, issuer.Issuer)
+	}
+
+	claims, err := s.verify(ctx, issuer, token, req.Audiences)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBearerAssertion, err)
+	}
+
+	granted, err := bearerScopes(client, issuer, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	user, provisioned, err := s.user(ctx, issuer, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("jwt bearer assertion accepted",
+		zap.String("client_id", client.ClientID),
+		zap.String("issuer", issuer.Issuer),
+		zap.String("user_id", user.ID.String()),
+		zap.Bool("provisioned", provisioned),
+	)
+	return &JWTBearerResult{User: user, Scopes: granted, Provisioned: provisioned}, nil
+}
+
+// verify проверяет подпись и утверждения (RFC 7523, раздел 3)
+func (s *JWTBearerService) verify(ctx context.Context, issuer *TrustedIssuer, token *jose.JWS, audiences []string) (jose.Claims, error) {
+	jwk, err := s.keys.Find(ctx, issuer.JWKS, issuer.JWKSURI, token.Header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	algorithms := issuer.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = jose.AsymmetricAlgorithms
+	}
+	if err := token.Verify(publicKey, algorithms...); err != nil {
+		return nil, err
+	}
+
+	claims, err := token.Claims()
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject() == "" {
+		return nil, fmt.Errorf("sub is required")
+	}
+	audienceMatched := false
+	for _, audience := range audiences {
+		audienceMatched = audienceMatched || (audience != "" && claims.HasAudience(audience))
+	}
+	if !audienceMatched {
+		return nil, fmt.Errorf("aud must identify the authorization server")
+	}
+	now := time.Now()
+	if err := claims.ValidateTimes(now, clientAssertionLeeway, true); err != nil {
+		return nil, err
+	}
+	exp, _ := claims.Time("exp")
+	if s.config.MaxLifetime > 0 && exp.After(now.Add(s.config.MaxLifetime+clientAssertionLeeway)) {
+		return nil, fmt.Errorf("exp is too far in the future")
+	}
+
+	// jti необязателен (RFC 7523, раздел 3), но если он есть, утверждение
+	// принимается один раз
+	if jti := claims.String("jti"); jti != "" {
+		err := s.replays.Use(ctx, "jwt_bearer:"+issuer.Issuer+":"+jti, exp.Add(clientAssertionLeeway))
+		if errors.Is(err, ports.ErrAlreadyExists) {
+			log.Warn("jwt bearer assertion replayed", zap.String("issuer", issuer.Issuer))
+			return nil, fmt.Errorf("jti has already been used")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// user находит пользователя, о котором сделано утверждение
+func (s *JWTBearerService) user(ctx context.Context, issuer *TrustedIssuer, claims jose.Claims) (*entity.User, bool, error) {
+	if issuer.SubjectMapping == SubjectByID {
+		id, err := uuid.Parse(claims.Subject())
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: sub must be a user id", ErrInvalidBearerAssertion)
+		}
+		user, err := s.users.GetByID(ctx, id)
+		if errors.Is(err, ports.ErrNotFound) {
+			return nil, false, fmt.Errorf("%w: unknown subject", ErrInvalidBearerAssertion)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return activeUser(user)
+	}
+
+	email := claims.String("email")
+	if email == "" && strings.Contains(claims.Subject(), "@") {
+		email = claims.Subject()
+	}
+	if email == "" {
+		return nil, false, fmt.Errorf("%w: email claim is required", ErrInvalidBearerAssertion)
+	}
+	// Неподтвержденный адрес мог указать сам пользователь издателя
+	if !claims.Bool("email_verified") {
+		return nil, false, fmt.Errorf("%w: email is not verified", ErrInvalidBearerAssertion)
+	}
+	user, err := s.users.GetByEmail(ctx, email)
+	if err == nil {
+		if !issuer.represents(user, claims.Subject()) {
+			log.Warn("jwt bearer assertion for a user of another issuer",
+				zap.String("issuer", issuer.Issuer),
+				zap.String("user_id", user.ID.String()),
+			)
+			return nil, false, fmt.Errorf("%w: unknown subject", ErrInvalidBearerAssertion)
+		}
+		return activeUser(user)
+	}
+	if !errors.Is(err, ports.ErrNotFound) {
+		return nil, false, err
+	}
+	if !issuer.ProvisionUsers || (len(issuer.EmailDomains) > 0 && !issuer.allowsEmail(email)) {
+		return nil, false, fmt.Errorf("%w: unknown subject", ErrInvalidBearerAssertion)
+	}
+
+	user, err = s.provision(ctx, issuer, claims, email)
+	if err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// provision создает пользователя по утверждению. Пароль случайный и никому не
+// известен: пользователь входит через издателя или после сброса пароля.
+func (s *JWTBearerService) provision(ctx context.Context, issuer *TrustedIssuer, claims jose.Claims, email string) (*entity.User, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	password, err := s.hasher.HashPassword(base64.RawURLEncoding.EncodeToString(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	firstName, lastName := claims.String("given_name"), claims.String("family_name")
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(email, "@")
+	}
+	if lastName == "" {
+		lastName = "-"
+	}
+	user := entity.NewUser(email, firstName, lastName, password)
+	user.Attributes = map[string]interface{}{
+		"provisioned_by": issuer.Issuer,
+		"external_sub":   claims.Subject(),
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to provision user: %w", err)
+	}
+
+	log.Info("user provisioned from jwt bearer assertion",
+		zap.String("issuer", issuer.Issuer),
+		zap.String("user_id", user.ID.String()),
+	)
+	return user, nil
+}
+
+func activeUser(user *entity.User) (*entity.User, bool, error) {
+	if !user.Active {
+		return nil, false, fmt.Errorf("%w: user is inactive", ErrInvalidBearerAssertion)
+	}
+	return user, false, nil
+}
+
+// bearerScopes проверяет запрошенные области по политике издателя и клиента
+func bearerScopes(client *entity.Client, issuer *TrustedIssuer, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		granted := make([]string, 0)
+		for _, scope := range issuer.Scopes {
+			if client.IsScopeAllowed(scope) {
+				granted = append(granted, scope)
+			}
+		}
+		return granted, nil
+	}
+	for _, scope := range requested {
+		if !containsString(issuer.Scopes, scope) || !client.IsScopeAllowed(scope) {
+			return nil, fmt.Errorf("%w: scope %s is not allowed", ErrInvalidBearerScope, scope)
+		}
+	}
+	return requested, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testBearerAudience = "https://auth.example.com/token"
+
+// bearerTest грант jwt-bearer с одним доверенным издателем
+type bearerTest struct {
+	t       *testing.T
+	store   *memory.Store
+	service *JWTBearerService
+	client  *entity.Client
+	key     *ecdsa.PrivateKey
+}
+
+func newBearerTest(t *testing.T, issuer TrustedIssuer) *bearerTest {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk, err := jose.NewJWK(&key.PublicKey, "issuer-key", jose.ES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer.Issuer = "https://idp.example.com"
+	issuer.JWKS = &jose.JWKSet{Keys: []jose.JWK{*jwk}}
+	issuer.Scopes = []string{"openid"}
+
+	store := memory.NewStore()
+	config := DefaultJWTBearerConfig()
+	config.Issuers = []TrustedIssuer{issuer}
+	return &bearerTest{
+		t:       t,
+		store:   store,
+		service: NewJWTBearerService(store.Users, nil, store.Replays, config),
+		client:  newTestClient(),
+		key:     key,
+	}
+}
+
+// exchange предъявляет утверждение издателя о sub с дополнительными утверждениями
+func (b *bearerTest) exchange(subject string, extra jose.Claims) (*JWTBearerResult, error) {
+	b.t.Helper()
+	now := time.Now()
+	claims := jose.Claims{
+		"iss": "https://idp.example.com",
+		"sub": subject,
+		"aud": testBearerAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+	assertion, err := jose.Sign(jose.Header{Algorithm: jose.ES256, KeyID: "issuer-key"}, claims, b.key)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	return b.service.Exchange(context.Background(), b.client, JWTBearerRequest{Assertion: assertion, Audiences: []string{testBearerAudience}})
+}
+
+func TestJWTBearerRejectsUsersOfOtherIssuers(t *testing.T) {
+	b := newBearerTest(t, TrustedIssuer{})
+	local := entity.NewUser("admin@example.com", "Local", "Admin", "hash")
+	if err := b.store.Users.Create(context.Background(), local); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := b.exchange("attacker", jose.Claims{"email": local.Email, "email_verified": true})
+	if !errors.Is(err, ErrInvalidBearerAssertion) {
+		t.Errorf("assertion about a local user: error = %v, want ErrInvalidBearerAssertion", err)
+	}
+}
+
+func TestJWTBearerAcceptsUsersOfIssuerDomains(t *testing.T) {
+	b := newBearerTest(t, TrustedIssuer{EmailDomains: []string{"example.com"}})
+	local := entity.NewUser("staff@example.com", "Local", "Staff", "hash")
+	if err := b.store.Users.Create(context.Background(), local); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := b.exchange("staff", jose.Claims{"email": local.Email, "email_verified": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.User.ID != local.ID {
+		t.Errorf("user = %s, want %s", result.User.ID, local.ID)
+	}
+	if _, err := b.exchange("staff", jose.Claims{"email": local.Email}); !errors.Is(err, ErrInvalidBearerAssertion) {
+		t.Errorf("unverified email: error = %v, want ErrInvalidBearerAssertion", err)
+	}
+	if _, err := b.exchange("other", jose.Claims{"email": "someone@other.example", "email_verified": true}); !errors.Is(err, ErrInvalidBearerAssertion) {
+		t.Errorf("unknown user outside the issuer domains: error = %v, want ErrInvalidBearerAssertion", err)
+	}
+}
+
+func TestJWTBearerLinksProvisionedUsersToSubject(t *testing.T) {
+	b := newBearerTest(t, TrustedIssuer{ProvisionUsers: true})
+	verified := jose.Claims{"email": "new@partner.example", "email_verified": true}
+
+	first, err := b.exchange("subject-1", verified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Provisioned {
+		t.Fatal("user was not provisioned")
+	}
+	second, err := b.exchange("subject-1", verified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.User.ID != first.User.ID || second.Provisioned {
+		t.Error("second assertion did not map to the provisioned user")
+	}
+	if _, err := b.exchange("subject-2", verified); !errors.Is(err, ErrInvalidBearerAssertion) {
+		t.Errorf("another subject with the same email: error = %v, want ErrInvalidBearerAssertion", err)
+	}
+}
@@ -27,4 +27,9 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-} 
\ No newline at end of file
+} 
+// SetLogger заменяет логгер пакета, например, чтобы заглушить отладочный
+// вывод в утилитах командной строки
+func SetLogger(logger *zap.Logger) {
+	log = logger
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrInvalidClientCertificate сертификат клиента отсутствует или не соответствует
+// его регистрации (RFC 8705, раздел 2)
+var ErrInvalidClientCertificate = errors.New("invalid client certificate")
+
+// ErrCertificateBindingMismatch токен привязан к другому сертификату (RFC 8705, раздел 3)
+var ErrCertificateBindingMismatch = errors.New("token is bound to a different client certificate")
+
+// VerifyClientCertificate проверяет сертификат, предъявленный клиентом при
+// tls_client_auth или self_signed_tls_client_auth. Цепочку сертификата для
+// tls_client_auth проверяет TLS-сервер или прокси, chainVerified сообщает
+// результат; здесь сравнивается только субъект. Самоподписанный сертификат
+// должен содержать ключ из JWKS клиента.
+func VerifyClientCertificate(client *entity.Client, cert *x509.Certificate, chainVerified bool) error {
+	if cert == nil {
+		return fmt.Errorf("%w: certificate is required", ErrInvalidClientCertificate)
+	}
+
+	switch client.TokenEndpointAuthMethod {
+	case entity.AuthMethodTLSClientAuth:
+		if !chainVerified {
+			return fmt.Errorf("%w: certificate chain is not verified", ErrInvalidClientCertificate)
+		}
+		if !certificateMatchesSubject(cert, client.TLSClientAuth) {
+			return fmt.Errorf("%w: subject does not match the registration", ErrInvalidClientCertificate)
+		}
+		return nil
+	case entity.AuthMethodSelfSignedTLSClientAuth:
+		now := time.Now()
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("%w: certificate is not valid at this time", ErrInvalidClientCertificate)
+		}
+		if !certificateMatchesKeys(cert, client.JWKS) {
+			return fmt.Errorf("%w: certificate key is not registered", ErrInvalidClientCertificate)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: client does not use certificate authentication", ErrInvalidClientCertificate)
+	}
+}
+
+// CheckCertificateBinding проверяет, что токен, привязанный к сертификату,
+// предъявлен по соединению с этим сертификатом; thumbprint — x5t#S256
+// сертификата соединения или пусто, если сертификата нет
+func CheckCertificateBinding(token *entity.Token, thumbprint string) error {
+	bound := token.CertificateThumbprint()
+	if bound != "" && bound != thumbprint {
+		return ErrCertificateBindingMismatch
+	}
+	return nil
+}
+
+// CheckTokenConfirmation проверяет, что привязанный токен предъявлен на token
+// endpoint с тем же ключом DPoP и тем же сертификатом (RFC 9449, раздел 5;
+// RFC 8705, раздел 4); presented — ключи, подтвержденные в запросе, или nil
+func CheckTokenConfirmation(token *entity.Token, presented *entity.TokenConfirmation) error {
+	if token.Confirmation == nil {
+		return nil
+	}
+	if presented == nil {
+		presented = &entity.TokenConfirmation{}
+	}
+	if token.Confirmation.JKT != "" && token.Confirmation.JKT != presented.JKT {
+		return fmt.Errorf("token is bound to another DPoP key")
+	}
+	if token.Confirmation.X5tS256 != "" && token.Confirmation.X5tS256 != presented.X5tS256 {
+		return fmt.Errorf("token is bound to another certificate")
+	}
+	return nil
+}
+
+func certificateMatchesSubject(cert *x509.Certificate, expected entity.TLSClientAuth) bool {
+	switch {
+	case expected.SubjectDN != "":
+		return normalizeDN(cert.Subject.String()) == normalizeDN(expected.SubjectDN)
+	case expected.SANDNS != "":
+		for _, name := range cert.DNSNames {
+			if strings.EqualFold(name, expected.SANDNS) {
+				return true
+			}
+		}
+	case expected.SANURI != "":
+		for _, uri := range cert.URIs {
+			if uri.String() == expected.SANURI {
+				return true
+			}
+		}
+	case expected.SANIP != "":
+		ip := net.ParseIP(expected.SANIP)
+		for _, address := range cert.IPAddresses {
+			if address.Equal(ip) {
+				return true
+			}
+		}
+	case expected.SANEmail != "":
+		for _, email := range cert.EmailAddresses {
+			if strings.EqualFold(email, expected.SANEmail) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeDN приводит отличительное имя RFC 4514 к виду для сравнения:
+// без пробелов вокруг разделителей и без учета регистра
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		attr, value, _ := strings.Cut(part, "=")
+		parts[i] = strings.ToUpper(strings.TrimSpace(attr)) + "=" + strings.ToLower(strings.TrimSpace(value))
+	}
+	return strings.Join(parts, ",")
+}
+
+func certificateMatchesKeys(cert *x509.Certificate, keys *jose.JWKSet) bool {
+	if keys == nil {
+		return false
+	}
+	certThumbprint := jose.CertificateThumbprint(cert.Raw)
+	certKey, err := jose.NewJWK(cert.PublicKey, "", "")
+	if err != nil {
+		return false
+	}
+	keyThumbprint, err := certKey.Thumbprint()
+	if err != nil {
+		return false
+	}
+	for i := range keys.Keys {
+		if keys.Keys[i].X5tS256 == certThumbprint {
+			return true
+		}
+		if registered, err := keys.Keys[i].Thumbprint(); err == nil && registered == keyThumbprint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestVerifyClientCertificateRequiresVerifiedChain(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client.example.com"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient()
+	client.TokenEndpointAuthMethod = entity.AuthMethodTLSClientAuth
+	client.TLSClientAuth = entity.TLSClientAuth{SubjectDN: "CN=client.example.com"}
+
+	// Самоподписанный сертификат с тем же субъектом может выпустить кто угодно
+	if err := VerifyClientCertificate(client, cert, false); !errors.Is(err, ErrInvalidClientCertificate) {
+		t.Errorf("unverified chain: error = %v, want ErrInvalidClientCertificate", err)
+	}
+	if err := VerifyClientCertificate(client, cert, true); err != nil {
+		t.Errorf("verified chain: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/argon2"
@@ -42,14 +43,9 @@ func NewPasswordHasher(config *PasswordHasherConfig) *PasswordHasher {
 		config = DefaultConfig()
 	}
 
-	logger, err := zap.NewDevelopment()
-	if err != nil {
-		panic(err)
-	}
-
 	return &PasswordHasher{
 		config: config,
-		logger: logger,
+		logger: log,
 	}
 }
 
@@ -130,20 +126,24 @@ func (h *PasswordHasher) decodeHash(encodedHash string) (*PasswordHasherConfig,
 		h.logger.Error("invalid hash format", zap.Error(err))
 		return nil, nil, nil, fmt.Errorf("invalid hash format: %w", err)
 	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
 
-	parts := make([]string, 5)
-	for i := range parts {
-		parts[i] = encodedHash
-		encodedHash = encodedHash[len(parts[i]):]
+	// $argon2id$v=19$m=...,t=...,p=...$<salt>$<hash>
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[4] == "" || parts[5] == "" {
+		h.logger.Error("invalid hash format", zap.Int("parts", len(parts)))
+		return nil, nil, nil, fmt.Errorf("invalid hash format")
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
 	if err != nil {
 		h.logger.Error("failed to decode salt", zap.Error(err))
 		return nil, nil, nil, fmt.Errorf("decode salt: %w", err)
 	}
 
-	hash, err := base64.RawStdEncoding.DecodeString(parts[4])
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
 	if err != nil {
 		h.logger.Error("failed to decode hash", zap.Error(err))
 		return nil, nil, nil, fmt.Errorf("decode hash: %w", err)
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// PermissionCacheConfig конфигурация кеша эффективных разрешений
+type PermissionCacheConfig struct {
+	MaxEntries int
+}
+
+// DefaultPermissionCacheConfig возвращает конфигурацию по умолчанию
+func DefaultPermissionCacheConfig() *PermissionCacheConfig {
+	return &PermissionCacheConfig{
+		MaxEntries: 10000,
+	}
+}
+
+// PermissionCacheStats метрики кеша разрешений
+type PermissionCacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+}
+
+// permissionCacheEntry запись кеша: разрешения пользователя для конкретного набора ролей
+type permissionCacheEntry struct {
+	key         string
+	userID      uuid.UUID
+	roleIDs     []uuid.UUID
+	permissions []entity.Permission
+}
+
+// PermissionCache LRU кеш эффективных разрешений пользователей.
+// Ключ включает версию набора ролей пользователя, поэтому изменение ролей
+// в объекте пользователя не приводит к выдаче устаревших данных; изменения,
+// сделанные в других местах, сбрасывают кеш через доменные события.
+type PermissionCache struct {
+	config  *PermissionCacheConfig
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	byUser  map[uuid.UUID]map[string]struct{}
+	byRole  map[uuid.UUID]map[string]struct{}
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	evictions     atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewPermissionCache создает новый экземпляр PermissionCache
+func NewPermissionCache(config *PermissionCacheConfig) *PermissionCache {
+	if config == nil {
+		config = DefaultPermissionCacheConfig()
+	}
+	return &PermissionCache{
+		config:  config,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		byUser:  make(map[uuid.UUID]map[string]struct{}),
+		byRole:  make(map[uuid.UUID]map[string]struct{}),
+	}
+}
+
+// Subscribe подписывает кеш на события, влияющие на эффективные разрешения
+func (c *PermissionCache) Subscribe(dispatcher *EventDispatcher) {
+	dispatcher.Subscribe(c.HandleEvent,
+		entity.EventUserRolesChanged,
+		entity.EventUserDeleted,
+		entity.EventRolePermissionsChanged,
+		entity.EventRoleDeleted,
+		entity.EventPermissionChanged,
+		entity.EventPermissionDeleted,
+	)
+}
+
+// HandleEvent инвалидирует записи, затронутые доменным событием
+func (c *PermissionCache) HandleEvent(event entity.DomainEvent) {
+	switch event.Type {
+	case entity.EventUserRolesChanged, entity.EventUserDeleted:
+		c.InvalidateUser(event.AggregateID)
+	case entity.EventRolePermissionsChanged, entity.EventRoleDeleted:
+		c.InvalidateRole(event.AggregateID)
+	case entity.EventPermissionChanged, entity.EventPermissionDeleted:
+		// Разрешение может входить в любую роль, поэтому сбрасываем весь кеш
+		c.InvalidateAll()
+	}
+}
+
+// Get возвращает копию закешированных разрешений пользователя
+func (c *PermissionCache) Get(user *entity.User) ([]entity.Permission, bool) {
+	key := permissionCacheKey(user)
+
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	permissions := append([]entity.Permission(nil), element.Value.(*permissionCacheEntry).permissions...)
+	c.mu.Unlock()
+
+	c.hits.Add(1)
+	return permissions, true
+}
+
+// Set сохраняет разрешения пользователя для его текущего набора ролей
+func (c *PermissionCache) Set(user *entity.User, permissions []entity.Permission) {
+	if c.config.MaxEntries <= 0 {
+		return
+	}
+
+	entry := &permissionCacheEntry{
+		key:         permissionCacheKey(user),
+		userID:      user.ID,
+		roleIDs:     make([]uuid.UUID, 0, len(user.Roles)),
+		permissions: append([]entity.Permission(nil), permissions...),
+	}
+	for _, role := range user.Roles {
+		entry.roleIDs = append(entry.roleIDs, role.ID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[entry.key]; ok {
+		c.removeElement(element)
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	addToIndex(c.byUser, entry.userID, entry.key)
+	for _, roleID := range entry.roleIDs {
+		addToIndex(c.byRole, roleID, entry.key)
+	}
+
+	for c.order.Len() > c.config.MaxEntries {
+		c.removeElement(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// InvalidateUser удаляет все записи пользователя
+func (c *PermissionCache) InvalidateUser(userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeKeys(c.byUser[userID])
+	log.Debug("permission cache invalidated for user", zap.String("user_id", userID.String()))
+}
+
+// InvalidateRole удаляет все записи пользователей, имеющих роль
+func (c *PermissionCache) InvalidateRole(roleID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeKeys(c.byRole[roleID])
+	log.Debug("permission cache invalidated for role", zap.String("role_id", roleID.String()))
+}
+
+// InvalidateAll очищает кеш
+func (c *PermissionCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidations.Add(uint64(c.order.Len()))
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.byUser = make(map[uuid.UUID]map[string]struct{})
+	c.byRole = make(map[uuid.UUID]map[string]struct{})
+	log.Debug("permission cache cleared")
+}
+
+// Stats возвращает метрики кеша
+func (c *PermissionCache) Stats() PermissionCacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return PermissionCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Evictions:     c.evictions.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+	}
+}
+
+// removeKeys удаляет записи по ключам; вызывается под блокировкой
+func (c *PermissionCache) removeKeys(keys map[string]struct{}) {
+	// Копируем ключи, так как removeElement изменяет индексы
+	pending := make([]string, 0, len(keys))
+	for key := range keys {
+		pending = append(pending, key)
+	}
+	for _, key := range pending {
+		if element, ok := c.entries[key]; ok {
+			c.removeElement(element)
+			c.invalidations.Add(1)
+		}
+	}
+}
+
+// removeElement удаляет запись из списка и индексов; вызывается под блокировкой
+func (c *PermissionCache) removeElement(element *list.Element) {
+	entry := element.Value.(*permissionCacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	removeFromIndex(c.byUser, entry.userID, entry.key)
+	for _, roleID := range entry.roleIDs {
+		removeFromIndex(c.byRole, roleID, entry.key)
+	}
+}
+
+func addToIndex(index map[uuid.UUID]map[string]struct{}, id uuid.UUID, key string) {
+	keys, ok := index[id]
+	if !ok {
+		keys = make(map[string]struct{})
+		index[id] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+func removeFromIndex(index map[uuid.UUID]map[string]struct{}, id uuid.UUID, key string) {
+	keys, ok := index[id]
+	if !ok {
+		return
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(index, id)
+	}
+}
+
+// permissionCacheKey вычисляет ключ из пользователя и версии его набора ролей.
+// Версия учитывает идентификаторы ролей и время их последнего изменения.
+func permissionCacheKey(user *entity.User) string {
+	type roleVersion struct {
+		id        uuid.UUID
+		updatedAt int64
+	}
+
+	versions := make([]roleVersion, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		versions = append(versions, roleVersion{id: role.ID, updatedAt: role.UpdatedAt.UnixNano()})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return bytes.Compare(versions[i].id[:], versions[j].id[:]) < 0
+	})
+
+	hash := sha256.New()
+	var buf [8]byte
+	for _, version := range versions {
+		hash.Write(version.id[:])
+		binary.BigEndian.PutUint64(buf[:], uint64(version.updatedAt))
+		hash.Write(buf[:])
+	}
+	return user.ID.String() + ":" + hex.EncodeToString(hash.Sum(nil)[:12])
+}
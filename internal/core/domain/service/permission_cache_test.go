@@ -0,0 +1,83 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"testing"
+)
+
+func TestPermissionCacheServesAllChecks(t *testing.T) {
+	cache := NewPermissionCache(nil)
+	checker := NewPermissionChecker(&PermissionCheckerConfig{Cache: cache})
+	readers := newTestRole("readers", entity.NewPermission("read users", entity.ResourceUser, entity.ActionRead, ""))
+	user := newTestUser(readers)
+
+	if !checker.HasPermission(user, entity.ResourceUser, entity.ActionRead) {
+		t.Fatal("HasPermission denied a granted permission")
+	}
+	if err := checker.ValidateUserAccess(user, entity.ResourceUser, entity.ActionRead); err != nil {
+		t.Fatalf("ValidateUserAccess: %v", err)
+	}
+	if err := checker.ValidateUserAccess(user, entity.ResourceUser, entity.ActionDelete); err == nil {
+		t.Fatal("ValidateUserAccess granted a missing permission")
+	}
+
+	stats := cache.Stats()
+	if stats.Misses != 1 || stats.Hits != 3 || stats.Entries != 1 {
+		t.Fatalf("cache stats = %+v, want 1 miss and 3 hits", stats)
+	}
+}
+
+func TestPermissionCacheInvalidatedByEvents(t *testing.T) {
+	cache := NewPermissionCache(nil)
+	dispatcher := NewEventDispatcher()
+	cache.Subscribe(dispatcher)
+	checker := NewPermissionChecker(&PermissionCheckerConfig{Cache: cache})
+
+	readers := newTestRole("readers", entity.NewPermission("read users", entity.ResourceUser, entity.ActionRead, ""))
+	user := newTestUser(readers)
+	checker.HasPermission(user, entity.ResourceUser, entity.ActionRead)
+
+	tests := []entity.DomainEvent{
+		entity.NewDomainEvent(entity.EventUserRolesChanged, user.ID),
+		entity.NewDomainEvent(entity.EventRolePermissionsChanged, readers.ID),
+		entity.NewDomainEvent(entity.EventPermissionChanged, readers.Permissions[0].ID),
+	}
+	for _, event := range tests {
+		checker.HasPermission(user, entity.ResourceUser, entity.ActionRead)
+		if cache.Stats().Entries != 1 {
+			t.Fatalf("%s: cache is empty before the event", event.Type)
+		}
+		dispatcher.Publish(event)
+		if entries := cache.Stats().Entries; entries != 0 {
+			t.Errorf("%s: %d entries left after invalidation", event.Type, entries)
+		}
+	}
+}
+
+func TestPermissionCacheKeyFollowsRoleChanges(t *testing.T) {
+	cache := NewPermissionCache(nil)
+	checker := NewPermissionChecker(&PermissionCheckerConfig{Cache: cache})
+	user := newTestUser()
+
+	if checker.HasPermission(user, entity.ResourceRole, entity.ActionCreate) {
+		t.Fatal("user without roles has a permission")
+	}
+	user.AddRole(*newTestRole("editors", entity.NewPermission("create roles", entity.ResourceRole, entity.ActionCreate, "")))
+	if !checker.HasPermission(user, entity.ResourceRole, entity.ActionCreate) {
+		t.Fatal("cached result ignored a new role")
+	}
+}
+
+func TestPermissionCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewPermissionCache(&PermissionCacheConfig{MaxEntries: 2})
+	users := []*entity.User{newTestUser(), newTestUser(), newTestUser()}
+	for _, user := range users {
+		cache.Set(user, nil)
+	}
+	if _, ok := cache.Get(users[0]); ok {
+		t.Error("oldest entry was not evicted")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("cache stats = %+v, want 1 eviction and 2 entries", stats)
+	}
+}
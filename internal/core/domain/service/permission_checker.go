@@ -2,131 +2,192 @@ package service
 
 import (
 	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
 	"fmt"
 	"go.uber.org/zap"
 )
 
+// CombiningAlgorithm определяет способ разрешения конфликта между разрешающими
+// и запрещающими разрешениями разных ролей пользователя
+type CombiningAlgorithm string
+
+const (
+	// DenyOverrides - явный запрет имеет приоритет над любым разрешением
+	DenyOverrides CombiningAlgorithm = "deny_overrides"
+	// AllowOverrides - любое разрешение имеет приоритет над явным запретом
+	AllowOverrides CombiningAlgorithm = "allow_overrides"
+)
+
+// PermissionCheckerConfig конфигурация для проверки прав доступа
+type PermissionCheckerConfig struct {
+	CombiningAlgorithm CombiningAlgorithm
+	// AuditLogRepository при наличии используется для журналирования решений ValidateUserAccess
+	AuditLogRepository ports.AuditLogRepository
+	DecisionLogMode    DecisionLogMode
+	// Cache при наличии хранит эффективные разрешения пользователей между проверками
+	Cache *PermissionCache
+}
+
+// DefaultPermissionCheckerConfig возвращает конфигурацию по умолчанию
+func DefaultPermissionCheckerConfig() *PermissionCheckerConfig {
+	return &PermissionCheckerConfig{
+		CombiningAlgorithm: DenyOverrides,
+		DecisionLogMode:    DecisionLogNone,
+	}
+}
+
 // PermissionChecker сервис для проверки прав доступа
-type PermissionChecker struct{}
+type PermissionChecker struct {
+	config *PermissionCheckerConfig
+}
 
 // NewPermissionChecker создает новый экземпляр PermissionChecker
-func NewPermissionChecker() *PermissionChecker {
-	return &PermissionChecker{}
+func NewPermissionChecker(config *PermissionCheckerConfig) *PermissionChecker {
+	if config == nil {
+		config = DefaultPermissionCheckerConfig()
+	}
+	return &PermissionChecker{config: config}
 }
 
-// HasPermission проверяет наличие разрешения у пользователя
+// HasPermission проверяет наличие разрешения у пользователя с учетом явных запретов.
+// Проверка выполняется на каждый запрос, поэтому решение не журналируется:
+// отказы записывает ValidateUserAccess.
 func (pc *PermissionChecker) HasPermission(user *entity.User, resource entity.ResourceType, action entity.Action) bool {
-	log.Debug("checking user permission",
-		zap.String("user_id", user.ID.String()),
-		zap.String("resource", string(resource)),
-		zap.String("action", string(action)),
-	)
-
 	if user == nil {
 		log.Warn("user is nil during permission check")
 		return false
 	}
-
-	// Проверяем разрешения в ролях пользователя
-	for _, role := range user.Roles {
-		for _, permission := range role.Permissions {
-			if permission.Resource == resource && permission.Action == action {
-				log.Debug("permission found",
-					zap.String("user_id", user.ID.String()),
-					zap.String("role_id", role.ID.String()),
-					zap.String("permission_id", permission.ID.String()),
-				)
-				return true
-			}
-		}
-	}
-
-	log.Debug("permission not found",
-		zap.String("user_id", user.ID.String()),
-		zap.String("resource", string(resource)),
-		zap.String("action", string(action)),
-	)
-	return false
+	return pc.combine(pc.matchPermissions(user, resource, action))
 }
 
-// HasAnyPermission проверяет наличие любого из разрешений у пользователя
+// HasAnyPermission проверяет наличие любого из разрешений у пользователя.
+// Каждое разрешение проверяется через HasPermission, поэтому явный запрет
+// на одно из действий не дает доступа через него, но не блокирует остальные.
 func (pc *PermissionChecker) HasAnyPermission(user *entity.User, permissions []entity.Permission) bool {
-	log.Debug("checking any permission",
-		zap.String("user_id", user.ID.String()),
-		zap.Int("permissions_count", len(permissions)),
-	)
-
 	if user == nil {
 		log.Warn("user is nil during permission check")
 		return false
 	}
-
 	for _, requiredPerm := range permissions {
 		if pc.HasPermission(user, requiredPerm.Resource, requiredPerm.Action) {
-			log.Debug("found matching permission",
-				zap.String("user_id", user.ID.String()),
-				zap.String("resource", string(requiredPerm.Resource)),
-				zap.String("action", string(requiredPerm.Action)),
-			)
 			return true
 		}
 	}
-
-	log.Debug("no matching permissions found",
-		zap.String("user_id", user.ID.String()),
-	)
 	return false
 }
 
-// ValidateUserAccess проверяет доступ пользователя к ресурсу
+// ValidateUserAccess проверяет доступ пользователя к ресурсу.
+// При отказе возвращает *AccessDeniedError с объяснением решения.
+// Объяснение строится только при отказе или при журналировании всех решений.
 func (pc *PermissionChecker) ValidateUserAccess(user *entity.User, resource entity.ResourceType, action entity.Action) error {
-	log.Debug("validating user access",
-		zap.String("user_id", user.ID.String()),
-		zap.String("resource", string(resource)),
-		zap.String("action", string(action)),
-	)
+	if user == nil {
+		log.Warn("user is nil during access validation")
+		return fmt.Errorf("user is nil")
+	}
 
-	if !pc.HasPermission(user, resource, action) {
-		log.Warn("access denied",
-			zap.String("user_id", user.ID.String()),
-			zap.String("resource", string(resource)),
-			zap.String("action", string(action)),
-		)
-		return fmt.Errorf("user does not have permission: %s:%s", resource, action)
+	if pc.HasPermission(user, resource, action) {
+		if pc.logsAllDecisions() {
+			pc.logDecision(pc.Explain(user, resource, action))
+		}
+		return nil
 	}
 
-	log.Debug("access granted",
+	explanation := pc.Explain(user, resource, action)
+	pc.logDecision(explanation)
+	log.Warn("access denied",
 		zap.String("user_id", user.ID.String()),
 		zap.String("resource", string(resource)),
 		zap.String("action", string(action)),
+		zap.String("reason", explanation.Reason),
 	)
-	return nil
+	return &AccessDeniedError{Explanation: explanation}
 }
 
-// GetUserPermissions возвращает все разрешения пользователя
-func (pc *PermissionChecker) GetUserPermissions(user *entity.User) []entity.Permission {
-	log.Debug("getting user permissions",
-		zap.String("user_id", user.ID.String()),
-	)
+// matchPermissions ищет в ролях пользователя разрешающие и запрещающие
+// разрешения для указанного ресурса и действия
+func (pc *PermissionChecker) matchPermissions(user *entity.User, resource entity.ResourceType, action entity.Action) (allowed bool, denied bool) {
+	if pc.config.Cache != nil {
+		for _, permission := range pc.GetUserPermissions(user) {
+			allowed, denied = matchPermission(permission, resource, action, allowed, denied)
+		}
+		return allowed, denied
+	}
+
+	for _, role := range user.Roles {
+		for _, permission := range role.Permissions {
+			allowed, denied = matchPermission(permission, resource, action, allowed, denied)
+		}
+	}
+	return allowed, denied
+}
+
+// matchPermission учитывает одно разрешение в результатах поиска
+func matchPermission(permission entity.Permission, resource entity.ResourceType, action entity.Action, allowed, denied bool) (bool, bool) {
+	if !permission.Matches(resource, action) {
+		return allowed, denied
+	}
+	if permission.IsDeny() {
+		return allowed, true
+	}
+	return true, denied
+}
 
+// algorithm возвращает используемый алгоритм разрешения конфликтов
+func (pc *PermissionChecker) algorithm() CombiningAlgorithm {
+	if pc.config.CombiningAlgorithm == AllowOverrides {
+		return AllowOverrides
+	}
+	return DenyOverrides
+}
+
+// combine применяет алгоритм разрешения конфликтов к найденным разрешениям
+func (pc *PermissionChecker) combine(allowed, denied bool) bool {
+	switch pc.algorithm() {
+	case AllowOverrides:
+		return allowed
+	default:
+		return allowed && !denied
+	}
+}
+
+// GetUserPermissions возвращает все разрешения пользователя.
+// При настроенном кеше результат берется из него и вычисляется только при промахе.
+func (pc *PermissionChecker) GetUserPermissions(user *entity.User) []entity.Permission {
 	if user == nil {
 		log.Warn("user is nil while getting permissions")
 		return nil
 	}
 
+	if pc.config.Cache != nil {
+		if permissions, ok := pc.config.Cache.Get(user); ok {
+			return permissions
+		}
+	}
+
+	permissions := collectPermissions(user)
+	if pc.config.Cache != nil {
+		pc.config.Cache.Set(user, permissions)
+	}
+
+	log.Debug("user permissions collected",
+		zap.String("user_id", user.ID.String()),
+		zap.Int("roles_count", len(user.Roles)),
+		zap.Int("unique_permissions_count", len(permissions)),
+	)
+	return permissions
+}
+
+// collectPermissions собирает уникальные разрешения из всех ролей пользователя
+func collectPermissions(user *entity.User) []entity.Permission {
 	// Используем map для исключения дубликатов
 	permMap := make(map[string]entity.Permission)
 
 	for _, role := range user.Roles {
-		log.Debug("processing role permissions",
-			zap.String("user_id", user.ID.String()),
-			zap.String("role_id", role.ID.String()),
-			zap.String("role_name", role.Name),
-			zap.Int("permissions_count", len(role.Permissions)),
-		)
-
 		for _, perm := range role.Permissions {
 			key := perm.String()
+			if perm.IsDeny() {
+				key += ":" + string(entity.EffectDeny)
+			}
 			permMap[key] = perm
 		}
 	}
@@ -136,41 +197,19 @@ func (pc *PermissionChecker) GetUserPermissions(user *entity.User) []entity.Perm
 	for _, perm := range permMap {
 		permissions = append(permissions, perm)
 	}
-
-	log.Debug("user permissions collected",
-		zap.String("user_id", user.ID.String()),
-		zap.Int("unique_permissions_count", len(permissions)),
-	)
-
 	return permissions
 }
 
 // HasRole проверяет наличие роли у пользователя
 func (pc *PermissionChecker) HasRole(user *entity.User, roleName string) bool {
-	log.Debug("checking user role",
-		zap.String("user_id", user.ID.String()),
-		zap.String("role_name", roleName),
-	)
-
 	if user == nil {
 		log.Warn("user is nil during role check")
 		return false
 	}
-
 	for _, role := range user.Roles {
 		if role.Name == roleName {
-			log.Debug("role found",
-				zap.String("user_id", user.ID.String()),
-				zap.String("role_id", role.ID.String()),
-				zap.String("role_name", roleName),
-			)
 			return true
 		}
 	}
-
-	log.Debug("role not found",
-		zap.String("user_id", user.ID.String()),
-		zap.String("role_name", roleName),
-	)
 	return false
 }
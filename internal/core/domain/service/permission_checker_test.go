@@ -0,0 +1,148 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestUser(roles ...*entity.Role) *entity.User {
+	user := entity.NewUser("user@example.com", "Test", "User", "hash")
+	for _, role := range roles {
+		user.AddRole(*role)
+	}
+	return user
+}
+
+func newTestRole(name string, permissions ...*entity.Permission) *entity.Role {
+	role := entity.NewRole(name, "")
+	for _, permission := range permissions {
+		role.AddPermission(*permission)
+	}
+	return role
+}
+
+func TestPermissionCheckerCombiningAlgorithms(t *testing.T) {
+	readUsers := entity.NewPermission("read users", entity.ResourceUser, entity.ActionRead, "")
+	deleteUsers := entity.NewPermission("delete users", entity.ResourceUser, entity.ActionDelete, "")
+	denyReadUsers := entity.NewDenyPermission("no user reads", entity.ResourceUser, entity.ActionRead, "")
+
+	tests := []struct {
+		name           string
+		roles          []*entity.Role
+		denyOverrides  bool
+		allowOverrides bool
+	}{
+		{"allow", []*entity.Role{newTestRole("readers", readUsers)}, true, true},
+		{"deny without allow", []*entity.Role{newTestRole("blocked", denyReadUsers)}, false, false},
+		{"deny and allow in different roles", []*entity.Role{newTestRole("readers", readUsers), newTestRole("blocked", denyReadUsers)}, false, true},
+		{"deny and allow in one role", []*entity.Role{newTestRole("mixed", readUsers, denyReadUsers)}, false, true},
+		{"deny of another action", []*entity.Role{newTestRole("readers", readUsers), newTestRole("no-delete", entity.NewDenyPermission("no user deletes", entity.ResourceUser, entity.ActionDelete, ""))}, true, true},
+		{"no permissions", nil, false, false},
+	}
+	for _, tt := range tests {
+		for _, cached := range []bool{false, true} {
+			for _, algorithm := range []CombiningAlgorithm{DenyOverrides, AllowOverrides} {
+				config := &PermissionCheckerConfig{CombiningAlgorithm: algorithm}
+				if cached {
+					config.Cache = NewPermissionCache(nil)
+				}
+				checker := NewPermissionChecker(config)
+				user := newTestUser(tt.roles...)
+				want := tt.denyOverrides
+				if algorithm == AllowOverrides {
+					want = tt.allowOverrides
+				}
+
+				// Второй вызов на кешированном пути берет разрешения из кеша
+				for i := 0; i < 2; i++ {
+					if got := checker.HasPermission(user, entity.ResourceUser, entity.ActionRead); got != want {
+						t.Errorf("%s (%s, cached=%v): HasPermission = %v, want %v", tt.name, algorithm, cached, got, want)
+					}
+				}
+				if got := checker.HasAnyPermission(user, []entity.Permission{*readUsers}); got != want {
+					t.Errorf("%s (%s, cached=%v): HasAnyPermission = %v, want %v", tt.name, algorithm, cached, got, want)
+				}
+				if err := checker.ValidateUserAccess(user, entity.ResourceUser, entity.ActionRead); (err == nil) != want {
+					t.Errorf("%s (%s, cached=%v): ValidateUserAccess error = %v, want allowed %v", tt.name, algorithm, cached, err, want)
+				}
+			}
+		}
+	}
+
+	// Запрет одного действия не блокирует доступ через другое
+	user := newTestUser(newTestRole("staff", readUsers, deleteUsers), newTestRole("blocked", denyReadUsers))
+	if !NewPermissionChecker(nil).HasAnyPermission(user, []entity.Permission{*readUsers, *deleteUsers}) {
+		t.Error("deny of user:read blocked user:delete")
+	}
+}
+
+func TestWildcardDenyIsRejected(t *testing.T) {
+	// Permission.Matches сравнивает ресурс и действие точно, поэтому запрет
+	// "user:*" ничего бы не запретил; реестр ресурсов не принимает такие разрешения
+	registry := DefaultResourceRegistry()
+	for _, permission := range []*entity.Permission{
+		entity.NewDenyPermission("no user actions", entity.ResourceUser, "*", ""),
+		entity.NewDenyPermission("no reads", "*", entity.ActionRead, ""),
+	} {
+		if err := registry.ValidatePermission(permission); err == nil {
+			t.Errorf("wildcard deny %s was accepted", permission)
+		}
+	}
+}
+
+func TestValidateUserAccessDoesNotLeakRoles(t *testing.T) {
+	blocked := newTestRole("secret-role", entity.NewDenyPermission("secret-permission", entity.ResourceRole, entity.ActionDelete, ""))
+	user := newTestUser(blocked)
+
+	err := NewPermissionChecker(nil).ValidateUserAccess(user, entity.ResourceRole, entity.ActionDelete)
+	var denied *AccessDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("ValidateUserAccess error = %v, want *AccessDeniedError", err)
+	}
+	for _, secret := range []string{"secret-role", "secret-permission", blocked.Permissions[0].ID.String()} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("error message %q contains %q", err, secret)
+		}
+	}
+	if len(denied.Explanation.MatchedDenies) != 1 || !strings.Contains(denied.Explanation.Reason, "secret-role") {
+		t.Errorf("explanation does not name the denying role: %+v", denied.Explanation)
+	}
+}
+
+func TestValidateUserAccessLogsDecisions(t *testing.T) {
+	readers := newTestRole("readers", entity.NewPermission("read users", entity.ResourceUser, entity.ActionRead, ""))
+	user := newTestUser(readers)
+
+	tests := []struct {
+		mode DecisionLogMode
+		want int
+	}{
+		{DecisionLogNone, 0},
+		{DecisionLogDenied, 1},
+		{DecisionLogAll, 2},
+	}
+	for _, tt := range tests {
+		auditLogs := memory.NewAuditLogRepository()
+		checker := NewPermissionChecker(&PermissionCheckerConfig{AuditLogRepository: auditLogs, DecisionLogMode: tt.mode})
+
+		if err := checker.ValidateUserAccess(user, entity.ResourceUser, entity.ActionRead); err != nil {
+			t.Fatalf("%s: granted access failed: %v", tt.mode, err)
+		}
+		if err := checker.ValidateUserAccess(user, entity.ResourceUser, entity.ActionDelete); err == nil {
+			t.Fatalf("%s: missing permission was granted", tt.mode)
+		}
+
+		logs, err := auditLogs.List(context.Background(), ports.AuditLogFilter{EventType: entity.AuditEventAccessDecision})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != tt.want {
+			t.Errorf("%s: %d audit logs, want %d", tt.mode, len(logs), tt.want)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Environment описывает окружение запроса на доступ
+type Environment struct {
+	Time       time.Time
+	IP         string
+	Attributes map[string]interface{}
+}
+
+// AccessRequest представляет запрос на доступ для оценки политиками ABAC
+type AccessRequest struct {
+	Subject            *entity.User
+	SubjectAttributes  map[string]interface{}
+	Resource           entity.ResourceType
+	ResourceAttributes map[string]interface{}
+	Action             entity.Action
+	Environment        Environment
+}
+
+// PolicyDecision результат оценки запроса политиками
+type PolicyDecision struct {
+	Allowed       bool
+	AllowedBy     []string
+	DeniedBy      []string
+	NotApplicable bool
+}
+
+// PolicyEngineConfig конфигурация движка политик
+type PolicyEngineConfig struct {
+	CombiningAlgorithm CombiningAlgorithm
+}
+
+// DefaultPolicyEngineConfig возвращает конфигурацию по умолчанию
+func DefaultPolicyEngineConfig() *PolicyEngineConfig {
+	return &PolicyEngineConfig{
+		CombiningAlgorithm: DenyOverrides,
+	}
+}
+
+// PolicyEngine сервис для проверки доступа на основе атрибутов (ABAC).
+// Работает рядом с PermissionChecker: роли задают базовые права,
+// политики - контекстные правила над атрибутами субъекта, ресурса и окружения.
+type PolicyEngine struct {
+	config   *PolicyEngineConfig
+	mu       sync.RWMutex
+	policies []entity.Policy
+}
+
+// NewPolicyEngine создает новый экземпляр PolicyEngine
+func NewPolicyEngine(config *PolicyEngineConfig) *PolicyEngine {
+	if config == nil {
+		config = DefaultPolicyEngineConfig()
+	}
+	return &PolicyEngine{config: config}
+}
+
+// SetPolicies атомарно заменяет набор политик после их валидации
+func (e *PolicyEngine) SetPolicies(policies []entity.Policy) error {
+	seen := make(map[string]bool, len(policies))
+	for i := range policies {
+		if err := policies[i].Validate(); err != nil {
+			log.Error("invalid policy", zap.Error(err))
+			return err
+		}
+		if seen[policies[i].ID] {
+			log.Error("duplicate policy id", zap.String("policy_id", policies[i].ID))
+			return fmt.Errorf("duplicate policy id: %s", policies[i].ID)
+		}
+		seen[policies[i].ID] = true
+	}
+
+	e.mu.Lock()
+	e.policies = append([]entity.Policy(nil), policies...)
+	e.mu.Unlock()
+
+	log.Info("policies updated", zap.Int("policies_count", len(policies)))
+	return nil
+}
+
+// Policies возвращает копию текущего набора политик
+func (e *PolicyEngine) Policies() []entity.Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]entity.Policy(nil), e.policies...)
+}
+
+// Evaluate оценивает запрос всеми применимыми политиками.
+// Если ни одна политика не применима, доступ запрещен.
+func (e *PolicyEngine) Evaluate(req *AccessRequest) *PolicyDecision {
+	decision := &PolicyDecision{}
+	if req == nil {
+		log.Warn("access request is nil during policy evaluation")
+		decision.NotApplicable = true
+		return decision
+	}
+
+	attrs := newAttributeSet(req)
+
+	e.mu.RLock()
+	policies := e.policies
+	e.mu.RUnlock()
+
+	for i := range policies {
+		policy := &policies[i]
+		if !policy.AppliesTo(req.Resource, req.Action) || !attrs.matchAll(policy.Conditions, policy.Effect) {
+			continue
+		}
+		if policy.Effect == entity.EffectDeny {
+			decision.DeniedBy = append(decision.DeniedBy, policy.ID)
+		} else {
+			decision.AllowedBy = append(decision.AllowedBy, policy.ID)
+		}
+	}
+
+	allowed, denied := len(decision.AllowedBy) > 0, len(decision.DeniedBy) > 0
+	decision.NotApplicable = !allowed && !denied
+	switch e.config.CombiningAlgorithm {
+	case AllowOverrides:
+		decision.Allowed = allowed
+	default:
+		decision.Allowed = allowed && !denied
+	}
+
+	log.Debug("policy evaluation completed",
+		zap.String("resource", string(req.Resource)),
+		zap.String("action", string(req.Action)),
+		zap.Strings("allowed_by", decision.AllowedBy),
+		zap.Strings("denied_by", decision.DeniedBy),
+		zap.Bool("allowed", decision.Allowed),
+	)
+	return decision
+}
+
+// IsAllowed проверяет, разрешен ли запрос политиками
+func (e *PolicyEngine) IsAllowed(req *AccessRequest) bool {
+	return e.Evaluate(req).Allowed
+}
+
+// ValidateAccess проверяет доступ и возвращает ошибку при отказе
+func (e *PolicyEngine) ValidateAccess(req *AccessRequest) error {
+	decision := e.Evaluate(req)
+	if decision.Allowed {
+		return nil
+	}
+	if req == nil {
+		return fmt.Errorf("access request is nil")
+	}
+	if len(decision.DeniedBy) > 0 {
+		return fmt.Errorf("access denied by policy %s: %s:%s", strings.Join(decision.DeniedBy, ", "), req.Resource, req.Action)
+	}
+	return fmt.Errorf("no policy allows access: %s:%s", req.Resource, req.Action)
+}
+
+// attributeSet разрешает пути атрибутов вида "subject.email" для запроса
+type attributeSet struct {
+	req *AccessRequest
+	now time.Time
+}
+
+func newAttributeSet(req *AccessRequest) *attributeSet {
+	now := req.Environment.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &attributeSet{req: req, now: now}
+}
+
+// resolve возвращает значение атрибута по пути
+func (a *attributeSet) resolve(path string) (interface{}, bool) {
+	if path == entity.AttributeAction {
+		return string(a.req.Action), true
+	}
+
+	prefix, name, _ := strings.Cut(path, ".")
+	switch prefix {
+	case entity.AttributeSubject:
+		return a.subject(name)
+	case entity.AttributeResource:
+		if name == "type" {
+			return string(a.req.Resource), true
+		}
+		value, ok := a.req.ResourceAttributes[name]
+		return value, ok
+	case entity.AttributeEnvironment:
+		switch name {
+		case "time":
+			return a.now, true
+		case "ip":
+			return a.req.Environment.IP, a.req.Environment.IP != ""
+		}
+		value, ok := a.req.Environment.Attributes[name]
+		return value, ok
+	}
+	return nil, false
+}
+
+func (a *attributeSet) subject(name string) (interface{}, bool) {
+	if value, ok := a.req.SubjectAttributes[name]; ok {
+		return value, true
+	}
+
+	user := a.req.Subject
+	if user == nil {
+		return nil, false
+	}
+
+	switch name {
+	case "id":
+		return user.ID.String(), true
+	case "email":
+		return user.Email, true
+	case "first_name":
+		return user.FirstName, true
+	case "last_name":
+		return user.LastName, true
+	case "active":
+		return user.Active, true
+	case "roles":
+		roles := make([]interface{}, 0, len(user.Roles))
+		for _, role := range user.Roles {
+			roles = append(roles, role.Name)
+		}
+		return roles, true
+	}
+	return user.Attribute(name)
+}
+
+func (a *attributeSet) matchAll(conditions []entity.Condition, effect entity.PermissionEffect) bool {
+	for i := range conditions {
+		if !a.match(&conditions[i], effect) {
+			return false
+		}
+	}
+	return true
+}
+
+// match проверяет одно условие. Без атрибута сравнение невозможно: условие
+// разрешающей политики не выполняется ни с каким оператором, в том числе ne
+// и not_in, а условие запрещающей — выполняется, и запрет остается в силе.
+func (a *attributeSet) match(c *entity.Condition, effect entity.PermissionEffect) bool {
+	actual, ok := a.resolve(c.Attribute)
+	if c.Operator == entity.OperatorExists {
+		return ok
+	}
+
+	expected := c.Value
+	if ok && c.ValueFrom != "" {
+		expected, ok = a.resolve(c.ValueFrom)
+	}
+	if !ok {
+		return effect == entity.EffectDeny
+	}
+
+	switch c.Operator {
+	case entity.OperatorEquals:
+		return valuesEqual(actual, expected)
+	case entity.OperatorNotEquals:
+		return !valuesEqual(actual, expected)
+	case entity.OperatorIn:
+		return containsValue(expected, actual)
+	case entity.OperatorNotIn:
+		return !containsValue(expected, actual)
+	case entity.OperatorContains:
+		if s, isString := actual.(string); isString {
+			return strings.Contains(s, fmt.Sprint(expected))
+		}
+		return containsValue(actual, expected)
+	case entity.OperatorGreaterThan:
+		cmp, ok := compareValues(actual, expected)
+		return ok && cmp > 0
+	case entity.OperatorGreaterOrEqual:
+		cmp, ok := compareValues(actual, expected)
+		return ok && cmp >= 0
+	case entity.OperatorLessThan:
+		cmp, ok := compareValues(actual, expected)
+		return ok && cmp < 0
+	case entity.OperatorLessOrEqual:
+		cmp, ok := compareValues(actual, expected)
+		return ok && cmp <= 0
+	case entity.OperatorTimeBetween:
+		return timeBetween(actual, expected)
+	case entity.OperatorWeekdayIn:
+		t, isTime := actual.(time.Time)
+		return isTime && containsFold(expected, t.Weekday().String())
+	case entity.OperatorIPInCIDR:
+		return ipInCIDR(actual, expected)
+	}
+	return false
+}
+
+// toSlice приводит значение к списку; скаляр считается списком из одного элемента
+func toSlice(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		result := make([]interface{}, len(v))
+		for i, s := range v {
+			result[i] = s
+		}
+		return result
+	case nil:
+		return nil
+	default:
+		return []interface{}{v}
+	}
+}
+
+func containsValue(list interface{}, value interface{}) bool {
+	for _, item := range toSlice(list) {
+		if valuesEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list interface{}, value string) bool {
+	for _, item := range toSlice(list) {
+		if strings.EqualFold(fmt.Sprint(item), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// toNumber приводит числовые значения к float64. Строки не преобразуются:
+// "007" и 7 — разные значения.
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// valuesEqual сравнивает значения строго по типу: числа сравниваются как
+// числа независимо от разрядности, строки и булевы значения — только
+// с значениями того же типа
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	case nil:
+		return b == nil
+	}
+	return false
+}
+
+// compareValues сравнивает числа, время или строки
+func compareValues(a, b interface{}) (int, bool) {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	if t, ok := a.(time.Time); ok {
+		other, ok := b.(time.Time)
+		if !ok {
+			s, isString := b.(string)
+			if !isString {
+				return 0, false
+			}
+			var err error
+			if other, err = time.Parse(time.RFC3339, s); err != nil {
+				return 0, false
+			}
+		}
+		return t.Compare(other), true
+	}
+
+	x, okA := a.(string)
+	y, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+// timeBetween проверяет, что время суток попадает в интервал "HH:MM-HH:MM".
+// Интервал, у которого начало больше конца, переходит через полночь.
+func timeBetween(actual, expected interface{}) bool {
+	t, ok := actual.(time.Time)
+	if !ok {
+		return false
+	}
+	window, ok := expected.(string)
+	if !ok {
+		return false
+	}
+	from, to, err := entity.ParseTimeWindow(window)
+	if err != nil {
+		return false
+	}
+
+	minutes := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return minutes >= from && minutes < to
+	}
+	return minutes >= from || minutes < to
+}
+
+func ipInCIDR(actual, expected interface{}) bool {
+	ip := net.ParseIP(fmt.Sprint(actual))
+	if ip == nil {
+		return false
+	}
+	for _, item := range toSlice(expected) {
+		_, network, err := net.ParseCIDR(fmt.Sprint(item))
+		if err != nil {
+			log.Warn("invalid CIDR in policy condition", zap.Any("cidr", item), zap.Error(err))
+			continue
+		}
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
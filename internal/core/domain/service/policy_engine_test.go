@@ -0,0 +1,208 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"testing"
+	"time"
+)
+
+func newTestPolicyEngine(t *testing.T, policies ...entity.Policy) *PolicyEngine {
+	t.Helper()
+	engine := NewPolicyEngine(nil)
+	if err := engine.SetPolicies(policies); err != nil {
+		t.Fatalf("SetPolicies: %v", err)
+	}
+	return engine
+}
+
+func TestPolicyEngineComparesTypesStrictly(t *testing.T) {
+	engine := newTestPolicyEngine(t, entity.Policy{
+		ID:     "employee-7",
+		Effect: entity.EffectAllow,
+		Conditions: []entity.Condition{
+			{Attribute: "subject.employee_id", Operator: entity.OperatorEquals, Value: "7"},
+		},
+	})
+
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{"7", true},
+		{"007", false},
+		{7, false},
+		{float64(7), false},
+	}
+	for _, tt := range tests {
+		req := &AccessRequest{SubjectAttributes: map[string]interface{}{"employee_id": tt.value}}
+		if got := engine.IsAllowed(req); got != tt.want {
+			t.Errorf("employee_id %#v: allowed = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyEngineComparesNumbersAcrossWidths(t *testing.T) {
+	engine := newTestPolicyEngine(t, entity.Policy{
+		ID:     "level",
+		Effect: entity.EffectAllow,
+		Conditions: []entity.Condition{
+			{Attribute: "subject.level", Operator: entity.OperatorEquals, Value: float64(3)},
+			{Attribute: "subject.level", Operator: entity.OperatorGreaterOrEqual, Value: int64(2)},
+		},
+	})
+	for _, level := range []interface{}{3, int64(3), float32(3)} {
+		req := &AccessRequest{SubjectAttributes: map[string]interface{}{"level": level}}
+		if !engine.IsAllowed(req) {
+			t.Errorf("level %#v: access denied", level)
+		}
+	}
+	req := &AccessRequest{SubjectAttributes: map[string]interface{}{"level": "3"}}
+	if engine.IsAllowed(req) {
+		t.Error("string level matched a numeric condition")
+	}
+}
+
+func TestPolicyEngineDenyRulesMatchMissingAttributes(t *testing.T) {
+	allowAll := entity.Policy{ID: "allow-all", Effect: entity.EffectAllow}
+	tests := []struct {
+		name      string
+		condition entity.Condition
+	}{
+		{"ne", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorNotEquals, Value: "finance"}},
+		{"not_in", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorNotIn, Value: []interface{}{"finance", "audit"}}},
+		{"ne value_from", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorNotEquals, ValueFrom: "resource.department"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newTestPolicyEngine(t, allowAll, entity.Policy{
+				ID:         "outside-department",
+				Effect:     entity.EffectDeny,
+				Conditions: []entity.Condition{tt.condition},
+			})
+
+			decision := engine.Evaluate(&AccessRequest{})
+			if decision.Allowed || len(decision.DeniedBy) != 1 {
+				t.Fatalf("missing attribute: decision %+v, want deny", decision)
+			}
+
+			matching := &AccessRequest{
+				SubjectAttributes:  map[string]interface{}{"department": "finance"},
+				ResourceAttributes: map[string]interface{}{"department": "finance"},
+			}
+			if !engine.IsAllowed(matching) {
+				t.Fatal("matching department was denied")
+			}
+		})
+	}
+}
+
+func TestPolicyEngineAllowRulesFailOnMissingAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition entity.Condition
+	}{
+		{"eq", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorEquals, Value: "finance"}},
+		{"in", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorIn, Value: []interface{}{"finance"}}},
+		{"ne", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorNotEquals, Value: "finance"}},
+		{"not_in", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorNotIn, Value: []interface{}{"finance"}}},
+		{"ne value_from", entity.Condition{Attribute: "subject.department", Operator: entity.OperatorNotEquals, ValueFrom: "resource.department"}},
+		{"lt", entity.Condition{Attribute: "subject.level", Operator: entity.OperatorLessThan, Value: 3}},
+	}
+	for _, tt := range tests {
+		engine := newTestPolicyEngine(t, entity.Policy{
+			ID:         "department",
+			Effect:     entity.EffectAllow,
+			Conditions: []entity.Condition{tt.condition},
+		})
+		if engine.IsAllowed(&AccessRequest{}) {
+			t.Errorf("%s matched a missing attribute in an allow policy", tt.name)
+		}
+	}
+}
+
+func TestPolicyEngineDenyRulesWithPositiveOperatorsMatchMissingAttributes(t *testing.T) {
+	engine := newTestPolicyEngine(t,
+		entity.Policy{ID: "allow-all", Effect: entity.EffectAllow},
+		entity.Policy{
+			ID:     "low-clearance",
+			Effect: entity.EffectDeny,
+			Conditions: []entity.Condition{
+				{Attribute: "subject.clearance", Operator: entity.OperatorLessThan, Value: 3},
+			},
+		},
+	)
+	if engine.IsAllowed(&AccessRequest{}) {
+		t.Error("missing clearance lifted the deny")
+	}
+	if !engine.IsAllowed(&AccessRequest{SubjectAttributes: map[string]interface{}{"clearance": 5}}) {
+		t.Error("sufficient clearance was denied")
+	}
+}
+
+func TestPolicyEngineRejectsInvalidTimeWindow(t *testing.T) {
+	for _, window := range []string{"9-18", "09:00", "09:00-25:00", "morning"} {
+		err := NewPolicyEngine(nil).SetPolicies([]entity.Policy{{
+			ID:     "office-hours",
+			Effect: entity.EffectAllow,
+			Conditions: []entity.Condition{
+				{Attribute: "environment.time", Operator: entity.OperatorTimeBetween, Value: window},
+			},
+		}})
+		if err == nil {
+			t.Errorf("time window %q was accepted", window)
+		}
+	}
+}
+
+func TestPolicyEngineEnvironmentConditions(t *testing.T) {
+	engine := newTestPolicyEngine(t, entity.Policy{
+		ID:     "office-hours",
+		Effect: entity.EffectAllow,
+		Conditions: []entity.Condition{
+			{Attribute: "environment.time", Operator: entity.OperatorTimeBetween, Value: "09:00-18:00"},
+			{Attribute: "environment.time", Operator: entity.OperatorWeekdayIn, Value: []interface{}{"monday", "tuesday"}},
+			{Attribute: "environment.ip", Operator: entity.OperatorIPInCIDR, Value: "10.0.0.0/8"},
+		},
+	})
+
+	monday := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		env  Environment
+		want bool
+	}{
+		{"inside", Environment{Time: monday, IP: "10.1.2.3"}, true},
+		{"late", Environment{Time: monday.Add(9 * time.Hour), IP: "10.1.2.3"}, false},
+		{"weekend", Environment{Time: monday.AddDate(0, 0, 5), IP: "10.1.2.3"}, false},
+		{"outside network", Environment{Time: monday, IP: "192.168.0.1"}, false},
+	}
+	for _, tt := range tests {
+		if got := engine.IsAllowed(&AccessRequest{Environment: tt.env}); got != tt.want {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyEngineCombiningAlgorithms(t *testing.T) {
+	policies := []entity.Policy{
+		{ID: "allow", Effect: entity.EffectAllow},
+		{ID: "deny", Effect: entity.EffectDeny},
+	}
+
+	denyOverrides := newTestPolicyEngine(t, policies...)
+	if denyOverrides.IsAllowed(&AccessRequest{}) {
+		t.Error("deny_overrides allowed a denied request")
+	}
+
+	allowOverrides := NewPolicyEngine(&PolicyEngineConfig{CombiningAlgorithm: AllowOverrides})
+	if err := allowOverrides.SetPolicies(policies); err != nil {
+		t.Fatalf("SetPolicies: %v", err)
+	}
+	if !allowOverrides.IsAllowed(&AccessRequest{}) {
+		t.Error("allow_overrides denied an allowed request")
+	}
+
+	if NewPolicyEngine(nil).IsAllowed(&AccessRequest{}) {
+		t.Error("request without applicable policies was allowed")
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/yaml"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PolicyDocument формат файла политик
+type PolicyDocument struct {
+	Policies []entity.Policy `json:"policies"`
+}
+
+// PolicyDecoder декодирует содержимое файла политик
+type PolicyDecoder func(data []byte) (*PolicyDocument, error)
+
+// PolicyLoader загружает политики из JSON/YAML файлов в PolicyEngine
+// и перезагружает их при изменении файлов
+type PolicyLoader struct {
+	engine   *PolicyEngine
+	paths    []string
+	decoders map[string]PolicyDecoder
+	mu       sync.Mutex
+	modTimes map[string]time.Time
+}
+
+// NewPolicyLoader создает загрузчик политик для файлов или каталогов
+func NewPolicyLoader(engine *PolicyEngine, paths ...string) *PolicyLoader {
+	return &PolicyLoader{
+		engine: engine,
+		paths:  paths,
+		decoders: map[string]PolicyDecoder{
+			".json": decodeJSONPolicies,
+			".yaml": decodeYAMLPolicies,
+			".yml":  decodeYAMLPolicies,
+		},
+		modTimes: make(map[string]time.Time),
+	}
+}
+
+// Load читает все файлы политик и заменяет политики в движке.
+// При ошибке в любом файле текущий набор политик не изменяется.
+func (l *PolicyLoader) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files, err := l.files()
+	if err != nil {
+		return err
+	}
+
+	var policies []entity.Policy
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			log.Error("failed to stat policy file", zap.String("file", file), zap.Error(err))
+			return fmt.Errorf("stat policy file %s: %w", file, err)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Error("failed to read policy file", zap.String("file", file), zap.Error(err))
+			return fmt.Errorf("read policy file %s: %w", file, err)
+		}
+
+		doc, err := l.decoders[strings.ToLower(filepath.Ext(file))](data)
+		if err != nil {
+			log.Error("failed to decode policy file", zap.String("file", file), zap.Error(err))
+			return fmt.Errorf("decode policy file %s: %w", file, err)
+		}
+
+		policies = append(policies, doc.Policies...)
+		modTimes[file] = info.ModTime()
+	}
+
+	if err := l.engine.SetPolicies(policies); err != nil {
+		return fmt.Errorf("apply policies: %w", err)
+	}
+	l.modTimes = modTimes
+
+	log.Info("policies loaded",
+		zap.Int("files_count", len(files)),
+		zap.Int("policies_count", len(policies)),
+	)
+	return nil
+}
+
+// Watch периодически проверяет файлы политик и перезагружает их при изменении.
+// Блокируется до отмены контекста.
+func (l *PolicyLoader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := l.changed()
+			if err != nil {
+				log.Error("failed to check policy files", zap.Error(err))
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if err := l.Load(); err != nil {
+				log.Error("failed to reload policies, keeping previous set", zap.Error(err))
+			}
+		}
+	}
+}
+
+// changed проверяет, изменился ли набор файлов или время их модификации
+func (l *PolicyLoader) changed() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files, err := l.files()
+	if err != nil {
+		return false, err
+	}
+	if len(files) != len(l.modTimes) {
+		return true, nil
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, fmt.Errorf("stat policy file %s: %w", file, err)
+		}
+		modTime, ok := l.modTimes[file]
+		if !ok || !info.ModTime().Equal(modTime) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// files возвращает отсортированный список файлов политик; каталоги
+// раскрываются в файлы с поддерживаемыми расширениями
+func (l *PolicyLoader) files() ([]string, error) {
+	var files []string
+	for _, path := range l.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat policy path %s: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			if _, ok := l.decoders[strings.ToLower(filepath.Ext(path))]; !ok {
+				return nil, fmt.Errorf("unsupported policy file format: %s", path)
+			}
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("read policy directory %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if _, ok := l.decoders[strings.ToLower(filepath.Ext(entry.Name()))]; ok {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func decodeJSONPolicies(data []byte) (*PolicyDocument, error) {
+	var doc PolicyDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func decodeYAMLPolicies(data []byte) (*PolicyDocument, error) {
+	var doc PolicyDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,496 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// UserNamespace пространство имен, в котором пользователи выступают субъектами отношений
+const UserNamespace = "user"
+
+// ConsistencyToken непрозрачный токен согласованности (zookie). Проверка,
+// выполненная с токеном, видит как минимум все изменения до его выдачи.
+type ConsistencyToken string
+
+// encodeConsistencyToken кодирует ревизию хранилища в токен
+func encodeConsistencyToken(revision uint64) ConsistencyToken {
+	return ConsistencyToken(base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(revision, 10))))
+}
+
+// decodeConsistencyToken декодирует ревизию из токена
+func decodeConsistencyToken(token ConsistencyToken) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(string(token))
+	if err != nil {
+		return 0, fmt.Errorf("invalid consistency token: %w", err)
+	}
+	revision, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid consistency token: %w", err)
+	}
+	return revision, nil
+}
+
+// UsersetTree дерево множества пользователей, возвращаемое Expand
+type UsersetTree struct {
+	Object   entity.ObjectRef    `json:"object"`
+	Relation string              `json:"relation"`
+	Subjects []entity.SubjectRef `json:"subjects,omitempty"`
+	Children []*UsersetTree      `json:"children,omitempty"`
+}
+
+// RelationshipCheckerConfig конфигурация проверки отношений
+type RelationshipCheckerConfig struct {
+	MaxDepth int
+}
+
+// DefaultRelationshipCheckerConfig возвращает конфигурацию по умолчанию
+func DefaultRelationshipCheckerConfig() *RelationshipCheckerConfig {
+	return &RelationshipCheckerConfig{
+		MaxDepth: 25,
+	}
+}
+
+// RelationshipChecker сервис авторизации на основе отношений (в стиле Zanzibar).
+// Альтернатива PermissionChecker для доступа к отдельным объектам,
+// например к документам, которыми поделились с пользователем или группой.
+type RelationshipChecker struct {
+	config *RelationshipCheckerConfig
+	schema *entity.NamespaceSchema
+	repo   ports.RelationTupleRepository
+}
+
+// NewRelationshipChecker создает новый экземпляр RelationshipChecker
+func NewRelationshipChecker(schema *entity.NamespaceSchema, repo ports.RelationTupleRepository, config *RelationshipCheckerConfig) *RelationshipChecker {
+	if config == nil {
+		config = DefaultRelationshipCheckerConfig()
+	}
+	return &RelationshipChecker{
+		config: config,
+		schema: schema,
+		repo:   repo,
+	}
+}
+
+// UserSubject возвращает субъект отношения для пользователя
+func UserSubject(user *entity.User) entity.SubjectRef {
+	return entity.SubjectRef{Object: entity.ObjectRef{Namespace: UserNamespace, ID: user.ID.String()}}
+}
+
+// WriteTuples добавляет и удаляет отношения после проверки по схеме
+func (rc *RelationshipChecker) WriteTuples(ctx context.Context, inserts []entity.RelationTuple, deletes []entity.RelationTuple) (ConsistencyToken, error) {
+	for _, tuple := range inserts {
+		if err := rc.schema.ValidateTuple(tuple); err != nil {
+			log.Warn("invalid relation tuple", zap.String("tuple", tuple.String()), zap.Error(err))
+			return "", err
+		}
+	}
+
+	revision, err := rc.repo.Write(ctx, inserts, deletes)
+	if err != nil {
+		log.Error("failed to write relation tuples", zap.Error(err))
+		return "", fmt.Errorf("write relation tuples: %w", err)
+	}
+
+	log.Debug("relation tuples written",
+		zap.Int("inserts_count", len(inserts)),
+		zap.Int("deletes_count", len(deletes)),
+		zap.Uint64("revision", revision),
+	)
+	return encodeConsistencyToken(revision), nil
+}
+
+// Check проверяет, входит ли субъект в отношение relation объекта.
+// Пустой токен означает проверку на последней ревизии.
+func (rc *RelationshipChecker) Check(ctx context.Context, object entity.ObjectRef, relation string, subject entity.SubjectRef, token ConsistencyToken) (bool, ConsistencyToken, error) {
+	revision, err := rc.snapshot(ctx, token)
+	if err != nil {
+		return false, "", err
+	}
+
+	allowed, err := rc.check(ctx, newRelationCheck(subject, revision), object, relation, 0)
+	if err != nil {
+		log.Error("relationship check failed",
+			zap.String("object", object.String()),
+			zap.String("relation", relation),
+			zap.String("subject", subject.String()),
+			zap.Error(err),
+		)
+		return false, "", err
+	}
+
+	log.Debug("relationship check completed",
+		zap.String("object", object.String()),
+		zap.String("relation", relation),
+		zap.String("subject", subject.String()),
+		zap.Uint64("revision", revision),
+		zap.Bool("allowed", allowed),
+	)
+	return allowed, encodeConsistencyToken(revision), nil
+}
+
+// Expand возвращает дерево субъектов, входящих в отношение объекта
+func (rc *RelationshipChecker) Expand(ctx context.Context, object entity.ObjectRef, relation string, token ConsistencyToken) (*UsersetTree, ConsistencyToken, error) {
+	revision, err := rc.snapshot(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tree, err := rc.expand(ctx, newRelationExpansion(revision), object, relation, 0)
+	if err != nil {
+		log.Error("relationship expand failed",
+			zap.String("object", object.String()),
+			zap.String("relation", relation),
+			zap.Error(err),
+		)
+		return nil, "", err
+	}
+	return tree, encodeConsistencyToken(revision), nil
+}
+
+// ListObjects возвращает объекты пространства имен, для которых субъект входит в отношение
+func (rc *RelationshipChecker) ListObjects(ctx context.Context, namespace, relation string, subject entity.SubjectRef, token ConsistencyToken) ([]entity.ObjectRef, ConsistencyToken, error) {
+	if _, ok := rc.schema.Relation(namespace, relation); !ok {
+		return nil, "", fmt.Errorf("unknown relation %s#%s", namespace, relation)
+	}
+
+	revision, err := rc.snapshot(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Любой объект, имеющий отношение, упоминается хотя бы в одном собственном кортеже,
+	// поэтому достаточно проверить объекты, встречающиеся в пространстве имен
+	tuples, err := rc.repo.Read(ctx, ports.RelationTupleFilter{Namespace: namespace}, revision)
+	if err != nil {
+		return nil, "", fmt.Errorf("read relation tuples: %w", err)
+	}
+
+	candidates := make(map[string]entity.ObjectRef)
+	for _, tuple := range tuples {
+		candidates[tuple.Object.ID] = tuple.Object
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// Общее состояние позволяет не проверять повторно группы, общие для объектов
+	state := newRelationCheck(subject, revision)
+	objects := make([]entity.ObjectRef, 0)
+	for _, id := range ids {
+		allowed, err := rc.check(ctx, state, candidates[id], relation, 0)
+		if err != nil {
+			return nil, "", err
+		}
+		if allowed {
+			objects = append(objects, candidates[id])
+		}
+	}
+
+	log.Debug("relationship objects listed",
+		zap.String("namespace", namespace),
+		zap.String("relation", relation),
+		zap.String("subject", subject.String()),
+		zap.Int("objects_count", len(objects)),
+	)
+	return objects, encodeConsistencyToken(revision), nil
+}
+
+// ValidateUserAccess проверяет отношение пользователя к объекту на последней ревизии
+func (rc *RelationshipChecker) ValidateUserAccess(ctx context.Context, user *entity.User, object entity.ObjectRef, relation string) error {
+	if user == nil {
+		log.Warn("user is nil during relationship check")
+		return fmt.Errorf("user is nil")
+	}
+
+	allowed, _, err := rc.Check(ctx, object, relation, UserSubject(user), "")
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Warn("access denied",
+			zap.String("user_id", user.ID.String()),
+			zap.String("object", object.String()),
+			zap.String("relation", relation),
+		)
+		return fmt.Errorf("user does not have relation: %s#%s", object, relation)
+	}
+	return nil
+}
+
+// snapshot выбирает ревизию для чтения: последнюю, но не старше ревизии токена
+func (rc *RelationshipChecker) snapshot(ctx context.Context, token ConsistencyToken) (uint64, error) {
+	current, err := rc.repo.CurrentRevision(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get current revision: %w", err)
+	}
+	if token == "" {
+		return current, nil
+	}
+
+	required, err := decodeConsistencyToken(token)
+	if err != nil {
+		return 0, err
+	}
+	if required > current {
+		return 0, fmt.Errorf("storage has not reached revision of consistency token")
+	}
+	return current, nil
+}
+
+// relationKey отношение объекта, посещаемое при обходе графа отношений
+type relationKey struct {
+	object   entity.ObjectRef
+	relation string
+}
+
+// relationCheck состояние одной проверки: отношения на текущем пути обхода
+// и уже вычисленные результаты. Отношение, которое снова встречается на пути
+// (группы, входящие друг в друга), не дает нового членства и считается
+// невыполненным, поэтому цикл не приводит к ошибке глубины.
+type relationCheck struct {
+	subject  entity.SubjectRef
+	revision uint64
+	visiting map[relationKey]bool
+	results  map[relationKey]bool
+	// cycles число обрывов цикла: отрицательный результат, полученный после
+	// обрыва, зависит от еще не вычисленных отношений и не запоминается
+	cycles int
+}
+
+func newRelationCheck(subject entity.SubjectRef, revision uint64) *relationCheck {
+	return &relationCheck{
+		subject:  subject,
+		revision: revision,
+		visiting: make(map[relationKey]bool),
+		results:  make(map[relationKey]bool),
+	}
+}
+
+func (rc *RelationshipChecker) check(ctx context.Context, state *relationCheck, object entity.ObjectRef, relation string, depth int) (bool, error) {
+	key := relationKey{object: object, relation: relation}
+	if allowed, ok := state.results[key]; ok {
+		return allowed, nil
+	}
+	if state.visiting[key] {
+		state.cycles++
+		return false, nil
+	}
+	if depth > rc.config.MaxDepth {
+		return false, fmt.Errorf("maximum relationship depth exceeded at %s#%s", object, relation)
+	}
+
+	state.visiting[key] = true
+	cycles := state.cycles
+	allowed, err := rc.evaluate(ctx, state, object, relation, depth)
+	delete(state.visiting, key)
+	if err != nil {
+		return false, err
+	}
+	if allowed || state.cycles == cycles {
+		state.results[key] = allowed
+	}
+	return allowed, nil
+}
+
+// evaluate проверяет отношение по правилам переписывания из схемы
+func (rc *RelationshipChecker) evaluate(ctx context.Context, state *relationCheck, object entity.ObjectRef, relation string, depth int) (bool, error) {
+	config, ok := rc.schema.Relation(object.Namespace, relation)
+	if !ok {
+		return false, fmt.Errorf("unknown relation %s#%s", object.Namespace, relation)
+	}
+
+	// Субъект-множество совпадает с самим отношением, например group:eng#member
+	subject := state.subject
+	if subject.IsUserset() && subject.Object == object && subject.Relation == relation {
+		return true, nil
+	}
+
+	for _, rewrite := range config.Rewrites() {
+		var (
+			allowed bool
+			err     error
+		)
+		switch {
+		case rewrite.This:
+			allowed, err = rc.checkDirect(ctx, state, object, relation, depth)
+		case rewrite.ComputedUserset != "":
+			allowed, err = rc.check(ctx, state, object, rewrite.ComputedUserset, depth+1)
+		case rewrite.TupleToUserset != nil:
+			allowed, err = rc.checkTupleToUserset(ctx, state, object, rewrite.TupleToUserset, depth)
+		}
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (rc *RelationshipChecker) checkDirect(ctx context.Context, state *relationCheck, object entity.ObjectRef, relation string, depth int) (bool, error) {
+	tuples, err := rc.repo.Read(ctx, ports.RelationTupleFilter{
+		Namespace: object.Namespace,
+		ObjectID:  object.ID,
+		Relation:  relation,
+	}, state.revision)
+	if err != nil {
+		return false, fmt.Errorf("read relation tuples: %w", err)
+	}
+
+	for _, tuple := range tuples {
+		if tuple.Subject == state.subject {
+			return true, nil
+		}
+	}
+
+	for _, tuple := range tuples {
+		if !tuple.Subject.IsUserset() {
+			continue
+		}
+		allowed, err := rc.check(ctx, state, tuple.Subject.Object, tuple.Subject.Relation, depth+1)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (rc *RelationshipChecker) checkTupleToUserset(ctx context.Context, state *relationCheck, object entity.ObjectRef, rewrite *entity.TupleToUserset, depth int) (bool, error) {
+	tuples, err := rc.repo.Read(ctx, ports.RelationTupleFilter{
+		Namespace: object.Namespace,
+		ObjectID:  object.ID,
+		Relation:  rewrite.Tupleset,
+	}, state.revision)
+	if err != nil {
+		return false, fmt.Errorf("read relation tuples: %w", err)
+	}
+
+	for _, tuple := range tuples {
+		target := tuple.Subject.Object
+		if _, ok := rc.schema.Relation(target.Namespace, rewrite.ComputedUserset); !ok {
+			continue
+		}
+		allowed, err := rc.check(ctx, state, target, rewrite.ComputedUserset, depth+1)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// relationExpansion состояние одного Expand: уже построенные поддеревья
+// и отношения на текущем пути обхода
+type relationExpansion struct {
+	revision uint64
+	visiting map[relationKey]bool
+	trees    map[relationKey]*UsersetTree
+}
+
+func newRelationExpansion(revision uint64) *relationExpansion {
+	return &relationExpansion{
+		revision: revision,
+		visiting: make(map[relationKey]bool),
+		trees:    make(map[relationKey]*UsersetTree),
+	}
+}
+
+// expand строит дерево отношения. Уже построенное поддерево используется
+// повторно, а отношение, замыкающее цикл, остается листом без потомков.
+func (rc *RelationshipChecker) expand(ctx context.Context, state *relationExpansion, object entity.ObjectRef, relation string, depth int) (*UsersetTree, error) {
+	key := relationKey{object: object, relation: relation}
+	if tree, ok := state.trees[key]; ok {
+		return tree, nil
+	}
+	if state.visiting[key] {
+		return &UsersetTree{Object: object, Relation: relation}, nil
+	}
+	if depth > rc.config.MaxDepth {
+		return nil, fmt.Errorf("maximum relationship depth exceeded at %s#%s", object, relation)
+	}
+
+	state.visiting[key] = true
+	tree, err := rc.expandRewrites(ctx, state, object, relation, depth)
+	delete(state.visiting, key)
+	if err != nil {
+		return nil, err
+	}
+	state.trees[key] = tree
+	return tree, nil
+}
+
+func (rc *RelationshipChecker) expandRewrites(ctx context.Context, state *relationExpansion, object entity.ObjectRef, relation string, depth int) (*UsersetTree, error) {
+	config, ok := rc.schema.Relation(object.Namespace, relation)
+	if !ok {
+		return nil, fmt.Errorf("unknown relation %s#%s", object.Namespace, relation)
+	}
+
+	tree := &UsersetTree{Object: object, Relation: relation}
+	for _, rewrite := range config.Rewrites() {
+		switch {
+		case rewrite.This:
+			tuples, err := rc.repo.Read(ctx, ports.RelationTupleFilter{
+				Namespace: object.Namespace,
+				ObjectID:  object.ID,
+				Relation:  relation,
+			}, state.revision)
+			if err != nil {
+				return nil, fmt.Errorf("read relation tuples: %w", err)
+			}
+			for _, tuple := range tuples {
+				tree.Subjects = append(tree.Subjects, tuple.Subject)
+				if !tuple.Subject.IsUserset() {
+					continue
+				}
+				child, err := rc.expand(ctx, state, tuple.Subject.Object, tuple.Subject.Relation, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				tree.Children = append(tree.Children, child)
+			}
+		case rewrite.ComputedUserset != "":
+			child, err := rc.expand(ctx, state, object, rewrite.ComputedUserset, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			tree.Children = append(tree.Children, child)
+		case rewrite.TupleToUserset != nil:
+			tuples, err := rc.repo.Read(ctx, ports.RelationTupleFilter{
+				Namespace: object.Namespace,
+				ObjectID:  object.ID,
+				Relation:  rewrite.TupleToUserset.Tupleset,
+			}, state.revision)
+			if err != nil {
+				return nil, fmt.Errorf("read relation tuples: %w", err)
+			}
+			for _, tuple := range tuples {
+				target := tuple.Subject.Object
+				if _, ok := rc.schema.Relation(target.Namespace, rewrite.TupleToUserset.ComputedUserset); !ok {
+					continue
+				}
+				child, err := rc.expand(ctx, state, target, rewrite.TupleToUserset.ComputedUserset, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				tree.Children = append(tree.Children, child)
+			}
+		}
+	}
+	return tree, nil
+}
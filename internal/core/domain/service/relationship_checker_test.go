@@ -0,0 +1,154 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"fmt"
+	"testing"
+)
+
+func newTestRelationshipChecker(t *testing.T, tuples ...string) *RelationshipChecker {
+	t.Helper()
+	schema, err := entity.NewNamespaceSchema(
+		entity.NamespaceConfig{Name: UserNamespace},
+		entity.NamespaceConfig{Name: "group", Relations: []entity.RelationConfig{{Name: "member"}}},
+		entity.NamespaceConfig{Name: "folder", Relations: []entity.RelationConfig{{Name: "viewer"}}},
+		entity.NamespaceConfig{Name: "doc", Relations: []entity.RelationConfig{
+			{Name: "parent"},
+			{Name: "owner"},
+			{Name: "viewer", Union: []entity.UsersetRewrite{
+				{This: true},
+				{ComputedUserset: "owner"},
+				{TupleToUserset: &entity.TupleToUserset{Tupleset: "parent", ComputedUserset: "viewer"}},
+			}},
+		}},
+	)
+	if err != nil {
+		t.Fatalf("NewNamespaceSchema: %v", err)
+	}
+
+	checker := NewRelationshipChecker(schema, memory.NewRelationTupleRepository(), nil)
+	inserts := make([]entity.RelationTuple, 0, len(tuples))
+	for _, raw := range tuples {
+		tuple, err := entity.ParseRelationTuple(raw)
+		if err != nil {
+			t.Fatalf("ParseRelationTuple(%q): %v", raw, err)
+		}
+		inserts = append(inserts, tuple)
+	}
+	if _, err := checker.WriteTuples(context.Background(), inserts, nil); err != nil {
+		t.Fatalf("WriteTuples: %v", err)
+	}
+	return checker
+}
+
+func checkRelation(t *testing.T, checker *RelationshipChecker, object, relation, subject string) bool {
+	t.Helper()
+	objectRef, err := entity.ParseObjectRef(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subjectRef, err := entity.ParseSubjectRef(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed, token, err := checker.Check(context.Background(), objectRef, relation, subjectRef, "")
+	if err != nil {
+		t.Fatalf("Check(%s#%s@%s): %v", object, relation, subject, err)
+	}
+	if token == "" {
+		t.Fatal("Check returned an empty consistency token")
+	}
+	return allowed
+}
+
+func TestRelationshipCheckerRewrites(t *testing.T) {
+	checker := newTestRelationshipChecker(t,
+		"doc:readme#owner@user:alice",
+		"doc:readme#parent@folder:docs",
+		"folder:docs#viewer@group:eng#member",
+		"group:eng#member@user:bob",
+	)
+
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{"user:alice", true},
+		{"user:bob", true},
+		{"user:carol", false},
+		{"group:eng#member", true},
+	}
+	for _, tt := range tests {
+		if got := checkRelation(t, checker, "doc:readme", "viewer", tt.subject); got != tt.want {
+			t.Errorf("doc:readme#viewer@%s = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestRelationshipCheckerGroupCycle(t *testing.T) {
+	checker := newTestRelationshipChecker(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+		"group:b#member@user:bob",
+	)
+
+	if checkRelation(t, checker, "group:a", "member", "user:carol") {
+		t.Error("user outside of the cycle is a member")
+	}
+	if !checkRelation(t, checker, "group:a", "member", "user:bob") {
+		t.Error("member of group b is not a member of group a")
+	}
+	if !checkRelation(t, checker, "group:b", "member", "user:bob") {
+		t.Error("direct member of group b is not a member")
+	}
+
+	object, _ := entity.ParseObjectRef("group:a")
+	tree, _, err := checker.Expand(context.Background(), object, "member", "")
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	if len(tree.Children) != 1 || len(tree.Children[0].Children) != 1 || tree.Children[0].Children[0].Children != nil {
+		t.Fatalf("Expand did not stop at the cycle: %+v", tree)
+	}
+}
+
+func TestRelationshipCheckerCycleResultsAreNotCachedAsFalse(t *testing.T) {
+	// Проверка a обходит b, пока a еще на пути, и только потом находит alice
+	// через c; результат b не должен запомниться отрицательным
+	checker := newTestRelationshipChecker(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+		"group:a#member@group:c#member",
+		"group:c#member@user:alice",
+	)
+	objects, _, err := checker.ListObjects(context.Background(), "group", "member", entity.SubjectRef{
+		Object: entity.ObjectRef{Namespace: UserNamespace, ID: "alice"},
+	}, "")
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("ListObjects = %v, want all three groups", objects)
+	}
+}
+
+func TestRelationshipCheckerWideNesting(t *testing.T) {
+	// Каждый уровень содержит две ссылки на следующий: без запоминания
+	// число проверок растет как 2^depth
+	const depth = 20
+	tuples := []string{fmt.Sprintf("group:g%d#member@user:bob", depth)}
+	for i := 0; i < depth; i++ {
+		tuples = append(tuples,
+			fmt.Sprintf("group:g%d#member@group:g%d#member", i, i+1),
+			fmt.Sprintf("group:g%d#member@group:h%d#member", i, i+1),
+			fmt.Sprintf("group:h%d#member@group:g%d#member", i+1, i+1),
+		)
+	}
+	checker := newTestRelationshipChecker(t, tuples...)
+
+	if checkRelation(t, checker, "group:g0", "member", "user:carol") {
+		t.Error("unrelated user is a member")
+	}
+}
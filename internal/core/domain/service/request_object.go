@@ -0,0 +1,248 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/pkg/jose"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRequestObject объект запроса не расшифровывается, не проходит проверку
+// подписи или содержит недопустимые утверждения (RFC 9101, раздел 7)
+var ErrInvalidRequestObject = errors.New("invalid request object")
+
+// maxRequestObjectSize максимальный размер объекта запроса, загружаемого по request_uri
+const maxRequestObjectSize = 64 << 10
+
+// requestObjectLeeway допустимое расхождение часов клиента и сервера
+const requestObjectLeeway = time.Minute
+
+// Типы объекта запроса в заголовке typ (RFC 9101, раздел 10.8)
+var requestObjectTypes = []string{"", "JWT", "oauth-authz-req+jwt"}
+
+// RequestObjectFetcher загружает объект запроса по зарегистрированному request_uri
+type RequestObjectFetcher func(ctx context.Context, uri string) (string, error)
+
+// RequestObjectDecryptionKey закрытый ключ сервера для расшифровки объектов запроса
+type RequestObjectDecryptionKey struct {
+	KeyID string
+	// Algorithm алгоритм управления ключом: jose.ECDHES или jose.RSAOAEP256
+	Algorithm string
+	// PrivateKey *ecdsa.PrivateKey или *rsa.PrivateKey
+	PrivateKey crypto.Signer
+}
+
+// requestObjectHTTPClient клиент для загрузки объектов запроса; перенаправления
+// не выполняются, чтобы адрес нельзя было подменить после регистрации
+var requestObjectHTTPClient = &http.Client{
+	Timeout: 5 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// fetchRequestObject загружает объект запроса по https (RFC 9101, раздел 5.2.3)
+func fetchRequestObject(ctx context.Context, uri string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/oauth-authz-req+jwt")
+
+	resp, err := requestObjectHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRequestObjectSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > maxRequestObjectSize {
+		return "", fmt.Errorf("request object is larger than %d bytes", maxRequestObjectSize)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// RequestObjectSigningAlgorithms возвращает алгоритмы подписи объектов запроса
+func (s *AuthorizationService) RequestObjectSigningAlgorithms() []string {
+	return append([]string(nil), jose.AsymmetricAlgorithms...)
+}
+
+// RequestObjectEncryptionKeys возвращает открытые ключи, которыми клиенты могут
+// шифровать объекты запроса; пусто, если шифрование не настроено
+func (s *AuthorizationService) RequestObjectEncryptionKeys() []jose.JWK {
+	keys := make([]jose.JWK, 0, len(s.config.DecryptionKeys))
+	for _, key := range s.config.DecryptionKeys {
+		jwk, err := jose.NewJWK(key.PrivateKey.Public(), key.KeyID, key.Algorithm)
+		if err != nil {
+			continue
+		}
+		jwk.Use = "enc"
+		keys = append(keys, *jwk)
+	}
+	return keys
+}
+
+// RequestObjectEncryptionAlgorithms возвращает алгоритмы управления ключом,
+// для которых у сервера есть ключи расшифровки
+func (s *AuthorizationService) RequestObjectEncryptionAlgorithms() []string {
+	algorithms := make([]string, 0, len(s.config.DecryptionKeys))
+	for _, key := range s.config.DecryptionKeys {
+		if !containsString(algorithms, key.Algorithm) {
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+	return algorithms
+}
+
+// loadRequestObject загружает объект запроса по request_uri, если клиент его зарегистрировал
+func (s *AuthorizationService) loadRequestObject(ctx context.Context, client *entity.Client, uri string) (string, error) {
+	if !client.IsRequestURIRegistered(uri) {
+		return "", fmt.Errorf("%w: request_uri is not registered for the client", ErrInvalidRequestURI)
+	}
+	fetch := s.config.FetchRequestObject
+	if fetch == nil {
+		fetch = fetchRequestObject
+	}
+	requestObject, err := fetch(ctx, uri)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequestURI, err)
+	}
+	return requestObject, nil
+}
+
+// parseRequestObject расшифровывает при необходимости и проверяет объект запроса
+// клиента. Параметры авторизации берутся только из объекта: параметры адресной
+// строки, кроме client_id, игнорируются (RFC 9101, раздел 6.3).
+func (s *AuthorizationService) parseRequestObject(ctx context.Context, client *entity.Client, raw string) (entity.AuthorizationRequest, error) {
+	req, err := s.verifyRequestObject(ctx, client, raw)
+	if err != nil {
+		return entity.AuthorizationRequest{}, fmt.Errorf("%w: %v", ErrInvalidRequestObject, err)
+	}
+	return req, nil
+}
+
+func (s *AuthorizationService) verifyRequestObject(ctx context.Context, client *entity.Client, raw string) (entity.AuthorizationRequest, error) {
+	if jose.IsJWE(raw) {
+		decrypted, err := s.decryptRequestObject(raw)
+		if err != nil {
+			return entity.AuthorizationRequest{}, err
+		}
+		raw = decrypted
+	}
+
+	token, err := jose.Parse(raw)
+	if err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+	if !containsString(requestObjectTypes, token.Header.Type) {
+		return entity.AuthorizationRequest{}, fmt.Errorf("unexpected typ %q", token.Header.Type)
+	}
+
+	jwk, err := s.keys.Key(ctx, client, token.Header.KeyID)
+	if err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+	publicKey, err := jwk.PublicKey()
+	if err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+	allowed := jose.AsymmetricAlgorithms
+	if client.RequestObjectSigningAlg != "" {
+		allowed = []string{client.RequestObjectSigningAlg}
+	}
+	if err := token.Verify(publicKey, allowed...); err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+
+	claims, err := token.Claims()
+	if err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+	if claims.Issuer() != client.ClientID {
+		return entity.AuthorizationRequest{}, fmt.Errorf("iss must be the client_id")
+	}
+	if s.config.Issuer == "" || !claims.HasAudience(s.config.Issuer) {
+		return entity.AuthorizationRequest{}, fmt.Errorf("aud must contain the authorization server issuer")
+	}
+	now := time.Now()
+	if err := claims.ValidateTimes(now, requestObjectLeeway, true); err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+	if exp, _ := claims.Time("exp"); s.config.RequestObjectLifetime > 0 && exp.After(now.Add(s.config.RequestObjectLifetime+requestObjectLeeway)) {
+		return entity.AuthorizationRequest{}, fmt.Errorf("exp is too far in the future")
+	}
+	if clientID := claims.String("client_id"); clientID != "" && clientID != client.ClientID {
+		return entity.AuthorizationRequest{}, fmt.Errorf("client_id claim does not match the client")
+	}
+	if _, nested := claims["request"]; nested {
+		return entity.AuthorizationRequest{}, fmt.Errorf("request object must not contain request")
+	}
+	if _, nested := claims["request_uri"]; nested {
+		return entity.AuthorizationRequest{}, fmt.Errorf("request object must not contain request_uri")
+	}
+
+	details, err := entity.AuthorizationDetailsFromClaim(claims["authorization_details"])
+	if err != nil {
+		return entity.AuthorizationRequest{}, err
+	}
+
+	return entity.AuthorizationRequest{
+		ClientID:            client.ClientID,
+		ResponseType:        entity.ResponseType(claims.String("response_type")),
+		RedirectURI:         claims.String("redirect_uri"),
+		Scopes:              strings.Fields(claims.String("scope")),
+		State:               claims.String("state"),
+		CodeChallenge:       claims.String("code_challenge"),
+		CodeChallengeMethod: claims.String("code_challenge_method"),
+		Resources:           claims.Strings("resource"),
+
+		AuthorizationDetails: details,
+	}, nil
+}
+
+// decryptRequestObject расшифровывает объект запроса ключом сервера и
+// возвращает вложенный JWS
+func (s *AuthorizationService) decryptRequestObject(raw string) (string, error) {
+	encrypted, err := jose.ParseJWE(raw)
+	if err != nil {
+		return "", err
+	}
+	for _, key := range s.config.DecryptionKeys {
+		if key.Algorithm != encrypted.Header.Algorithm {
+			continue
+		}
+		if encrypted.Header.KeyID != "" && key.KeyID != encrypted.Header.KeyID {
+			continue
+		}
+		plaintext, err := encrypted.Decrypt(decryptionKey(key.PrivateKey))
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	}
+	return "", fmt.Errorf("no decryption key for alg %s and kid %q", encrypted.Header.Algorithm, encrypted.Header.KeyID)
+}
+
+// decryptionKey приводит закрытый ключ к типу, который ожидает jose.JWE.Decrypt
+func decryptionKey(key crypto.Signer) interface{} {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		return k
+	default:
+		return nil
+	}
+}
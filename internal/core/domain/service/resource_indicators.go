@@ -0,0 +1,153 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidResourceScope запрошенные области действия не подходят ресурсу или
+// выходят за пределы гранта
+var ErrInvalidResourceScope = errors.New("invalid scope for resource")
+
+// ResourceIndicators реестр защищенных ресурсов, для которых клиенты могут
+// запрашивать токены параметром resource (RFC 8707). У каждого ресурса свой
+// набор областей действия; access token получает aud выбранных ресурсов и
+// только их области. Методы nil реестра отклоняют любые ресурсы.
+type ResourceIndicators struct {
+	resources map[string]entity.ResourceServer
+	// fallback ресурс по умолчанию; пустой — токены без ресурсов не получают audience
+	fallback string
+	scopes   *ScopeRegistry
+}
+
+// NewResourceIndicators создает реестр ресурсов
+func NewResourceIndicators(resources ...entity.ResourceServer) (*ResourceIndicators, error) {
+	registry := &ResourceIndicators{resources: make(map[string]entity.ResourceServer, len(resources))}
+	for _, resource := range resources {
+		if err := resource.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exists := registry.resources[resource.URI]; exists {
+			return nil, fmt.Errorf("resource %s is registered twice", resource.URI)
+		}
+		if resource.Default {
+			if registry.fallback != "" {
+				return nil, fmt.Errorf("resources %s and %s are both marked as default", registry.fallback, resource.URI)
+			}
+			registry.fallback = resource.URI
+		}
+		resource.Scopes = append([]string(nil), resource.Scopes...)
+		registry.resources[resource.URI] = resource
+	}
+	return registry, nil
+}
+
+// SetScopes задает реестр областей: запрошенная область входит в грант, если ее
+// включает одна из выданных (orders включает orders:read)
+func (r *ResourceIndicators) SetScopes(registry *ScopeRegistry) {
+	r.scopes = registry
+}
+
+// Default возвращает идентификатор ресурса по умолчанию
+func (r *ResourceIndicators) Default() (string, bool) {
+	if r == nil || r.fallback == "" {
+		return "", false
+	}
+	return r.fallback, true
+}
+
+// Granted возвращает ресурсы гранта: пустой набор означает только ресурс по
+// умолчанию, а без него — токены без audience
+func (r *ResourceIndicators) Granted(resources []string) []string {
+	if len(resources) > 0 {
+		return resources
+	}
+	if fallback, ok := r.Default(); ok {
+		return []string{fallback}
+	}
+	return nil
+}
+
+// Resource возвращает ресурс по идентификатору
+func (r *ResourceIndicators) Resource(uri string) (entity.ResourceServer, bool) {
+	if r == nil {
+		return entity.ResourceServer{}, false
+	}
+	resource, ok := r.resources[uri]
+	return resource, ok
+}
+
+// Narrow определяет области действия и audience access token. grantScopes и
+// grantResources — весь грант: то, что одобрил пользователь и что сохраняется в
+// refresh token; пустой grantResources допускает только ресурс по умолчанию (Granted).
+// requestedResources и requestedScopes — параметры resource и scope запроса
+// (RFC 8707, раздел 2.2); без них токен получает все ресурсы и области гранта.
+// Области, которые не принимает ни один из ресурсов, в токен не попадают.
+func (r *ResourceIndicators) Narrow(grantScopes, grantResources, requestedResources, requestedScopes []string) ([]string, []string, error) {
+	var registry *ScopeRegistry
+	if r != nil {
+		registry = r.scopes
+	}
+	scopes := grantScopes
+	if len(requestedScopes) > 0 {
+		for _, scope := range requestedScopes {
+			if !registry.Includes(grantScopes, scope) {
+				return nil, nil, fmt.Errorf("%w: scope %s exceeds the grant", ErrInvalidResourceScope, scope)
+			}
+		}
+		scopes = requestedScopes
+	}
+
+	granted := r.Granted(grantResources)
+	// fallback audience — ресурс по умолчанию, который никто не называл
+	fallback := len(requestedResources) == 0 && len(grantResources) == 0
+	targets := requestedResources
+	if len(targets) == 0 {
+		targets = granted
+	}
+	audience := make([]string, 0, len(targets))
+	resources := make([]entity.ResourceServer, 0, len(targets))
+	for _, uri := range targets {
+		if !entity.IsResourceIndicator(uri) {
+			return nil, nil, fmt.Errorf("%w: resource must be an absolute URI without a fragment: %s", ErrInvalidTarget, uri)
+		}
+		resource, ok := r.Resource(uri)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown resource %s", ErrInvalidTarget, uri)
+		}
+		if !containsString(granted, uri) {
+			return nil, nil, fmt.Errorf("%w: resource %s was not granted", ErrInvalidTarget, uri)
+		}
+		if !containsString(audience, uri) {
+			audience = append(audience, uri)
+			resources = append(resources, resource)
+		}
+	}
+	if len(audience) == 0 {
+		return append([]string(nil), scopes...), nil, nil
+	}
+
+	accepted := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		ok := false
+		for i := range resources {
+			ok = ok || resources[i].AcceptsScope(scope)
+		}
+		switch {
+		case ok:
+			accepted = append(accepted, scope)
+		case len(requestedScopes) > 0 && !fallback:
+			return nil, nil, fmt.Errorf("%w: scope %s is not accepted by the requested resources", ErrInvalidResourceScope, scope)
+		}
+	}
+	if len(accepted) == 0 && len(scopes) > 0 && fallback {
+		// Ресурс по умолчанию не принимает ни одной области гранта (например,
+		// только openid): токен выдается без audience
+		return append([]string(nil), scopes...), nil, nil
+	}
+	if len(accepted) == 0 && len(scopes) > 0 {
+		return nil, nil, fmt.Errorf("%w: none of the granted scopes is accepted by the requested resources", ErrInvalidResourceScope)
+	}
+	return accepted, audience, nil
+}
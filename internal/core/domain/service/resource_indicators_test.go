@@ -0,0 +1,98 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResourceIndicatorsNarrow(t *testing.T) {
+	resources, err := NewResourceIndicators(
+		entity.ResourceServer{URI: "https://api.example.com", Scopes: []string{"orders", "orders:read"}, Default: true},
+		entity.ResourceServer{URI: "https://billing.example.com", Scopes: []string{"invoices"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources.SetScopes(newTestScopeRegistry(t))
+
+	tests := []struct {
+		name               string
+		grantScopes        []string
+		grantResources     []string
+		requestedResources []string
+		requestedScopes    []string
+		wantScopes         []string
+		wantAudience       []string
+		wantErr            error
+	}{
+		{
+			name:         "empty grant gets the default resource",
+			grantScopes:  []string{"orders"},
+			wantScopes:   []string{"orders"},
+			wantAudience: []string{"https://api.example.com"},
+		},
+		{
+			name:               "empty grant does not allow other resources",
+			grantScopes:        []string{"invoices"},
+			requestedResources: []string{"https://billing.example.com"},
+			wantErr:            ErrInvalidTarget,
+		},
+		{
+			name:         "default resource that accepts no granted scope",
+			grantScopes:  []string{"openid"},
+			wantScopes:   []string{"openid"},
+			wantAudience: nil,
+		},
+		{
+			name:               "granted resource",
+			grantScopes:        []string{"invoices"},
+			grantResources:     []string{"https://billing.example.com"},
+			requestedResources: []string{"https://billing.example.com"},
+			wantScopes:         []string{"invoices"},
+			wantAudience:       []string{"https://billing.example.com"},
+		},
+		{
+			name:            "child of a granted scope",
+			grantScopes:     []string{"orders"},
+			grantResources:  []string{"https://api.example.com"},
+			requestedScopes: []string{"orders:read"},
+			wantScopes:      []string{"orders:read"},
+			wantAudience:    []string{"https://api.example.com"},
+		},
+		{
+			name:            "parent of a granted scope",
+			grantScopes:     []string{"orders:read"},
+			grantResources:  []string{"https://api.example.com"},
+			requestedScopes: []string{"orders"},
+			wantErr:         ErrInvalidResourceScope,
+		},
+	}
+	for _, tt := range tests {
+		scopes, audience, err := resources.Narrow(tt.grantScopes, tt.grantResources, tt.requestedResources, tt.requestedScopes)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(scopes, tt.wantScopes) || !reflect.DeepEqual(audience, tt.wantAudience) {
+			t.Errorf("%s: Narrow = %v, %v, want %v, %v", tt.name, scopes, audience, tt.wantScopes, tt.wantAudience)
+		}
+	}
+}
+
+func TestNewResourceIndicatorsRejectsSecondDefault(t *testing.T) {
+	_, err := NewResourceIndicators(
+		entity.ResourceServer{URI: "https://api.example.com", Default: true},
+		entity.ResourceServer{URI: "https://billing.example.com", Default: true},
+	)
+	if err == nil {
+		t.Error("two default resources were accepted")
+	}
+}
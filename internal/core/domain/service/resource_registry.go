@@ -0,0 +1,180 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ResourceRegistry реестр типов ресурсов и действий. Приложения, построенные
+// на сервисе, регистрируют собственные ресурсы и действия во время работы,
+// а проверка разрешений использует реестр вместо фиксированных списков.
+type ResourceRegistry struct {
+	mu        sync.RWMutex
+	resources map[entity.ResourceType]entity.ResourceDefinition
+	actions   map[entity.Action]entity.ActionDefinition
+}
+
+// NewResourceRegistry создает пустой реестр ресурсов
+func NewResourceRegistry() *ResourceRegistry {
+	return &ResourceRegistry{
+		resources: make(map[entity.ResourceType]entity.ResourceDefinition),
+		actions:   make(map[entity.Action]entity.ActionDefinition),
+	}
+}
+
+// DefaultResourceRegistry создает реестр со встроенными ресурсами и действиями сервиса
+func DefaultResourceRegistry() *ResourceRegistry {
+	r := NewResourceRegistry()
+
+	builtinActions := []entity.ActionDefinition{
+		{Action: entity.ActionCreate, Description: "Create a resource"},
+		{Action: entity.ActionRead, Description: "Read a resource"},
+		{Action: entity.ActionUpdate, Description: "Update a resource"},
+		{Action: entity.ActionDelete, Description: "Delete a resource"},
+		{Action: entity.ActionList, Description: "List resources"},
+	}
+	for _, def := range builtinActions {
+		r.actions[def.Action] = def
+	}
+
+	builtinResources := []entity.ResourceDefinition{
+		{Type: entity.ResourceUser, Description: "User accounts"},
+		{Type: entity.ResourceRole, Description: "Roles and their permissions"},
+		{Type: entity.ResourcePermission, Description: "Permissions"},
+		{Type: entity.ResourceClient, Description: "OAuth clients"},
+	}
+	for _, def := range builtinResources {
+		r.resources[def.Type] = def
+	}
+
+	return r
+}
+
+// RegisterResource регистрирует тип ресурса. Все действия ресурса
+// должны быть зарегистрированы заранее.
+func (r *ResourceRegistry) RegisterResource(def entity.ResourceDefinition) error {
+	if def.Type == "" {
+		return fmt.Errorf("resource type is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.resources[def.Type]; exists {
+		log.Warn("resource type already registered", zap.String("resource", string(def.Type)))
+		return fmt.Errorf("resource type already registered: %s", def.Type)
+	}
+	for _, action := range def.Actions {
+		if _, ok := r.actions[action]; !ok {
+			return fmt.Errorf("resource %s references unknown action: %s", def.Type, action)
+		}
+	}
+
+	def.Actions = append([]entity.Action(nil), def.Actions...)
+	r.resources[def.Type] = def
+
+	log.Info("resource type registered",
+		zap.String("resource", string(def.Type)),
+		zap.Int("actions_count", len(def.Actions)),
+	)
+	return nil
+}
+
+// RegisterAction регистрирует действие
+func (r *ResourceRegistry) RegisterAction(def entity.ActionDefinition) error {
+	if def.Action == "" {
+		return fmt.Errorf("action is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.actions[def.Action]; exists {
+		log.Warn("action already registered", zap.String("action", string(def.Action)))
+		return fmt.Errorf("action already registered: %s", def.Action)
+	}
+	r.actions[def.Action] = def
+
+	log.Info("action registered", zap.String("action", string(def.Action)))
+	return nil
+}
+
+// Resource возвращает описание типа ресурса
+func (r *ResourceRegistry) Resource(resource entity.ResourceType) (entity.ResourceDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.resources[resource]
+	return def, ok
+}
+
+// Action возвращает описание действия
+func (r *ResourceRegistry) Action(action entity.Action) (entity.ActionDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.actions[action]
+	return def, ok
+}
+
+// Resources возвращает все зарегистрированные типы ресурсов, упорядоченные по имени
+func (r *ResourceRegistry) Resources() []entity.ResourceDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]entity.ResourceDefinition, 0, len(r.resources))
+	for _, def := range r.resources {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Type < defs[j].Type })
+	return defs
+}
+
+// Actions возвращает все зарегистрированные действия, упорядоченные по имени
+func (r *ResourceRegistry) Actions() []entity.ActionDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]entity.ActionDefinition, 0, len(r.actions))
+	for _, def := range r.actions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Action < defs[j].Action })
+	return defs
+}
+
+// ValidateResourceAction проверяет, что ресурс и действие зарегистрированы
+// и действие применимо к ресурсу
+func (r *ResourceRegistry) ValidateResourceAction(resource entity.ResourceType, action entity.Action) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.resources[resource]
+	if !ok {
+		return fmt.Errorf("unknown resource type: %s", resource)
+	}
+	if _, ok := r.actions[action]; !ok {
+		return fmt.Errorf("unknown action: %s", action)
+	}
+	if !def.SupportsAction(action) {
+		return fmt.Errorf("action %s is not supported by resource %s", action, resource)
+	}
+	return nil
+}
+
+// ValidatePermission проверяет ресурс и действие разрешения по реестру
+func (r *ResourceRegistry) ValidatePermission(permission *entity.Permission) error {
+	if permission == nil {
+		return fmt.Errorf("permission is nil")
+	}
+	if err := r.ValidateResourceAction(permission.Resource, permission.Action); err != nil {
+		log.Warn("invalid permission",
+			zap.String("permission", permission.String()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("invalid permission %s: %w", permission.Name, err)
+	}
+	return nil
+}
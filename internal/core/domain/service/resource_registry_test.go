@@ -0,0 +1,47 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"testing"
+)
+
+func TestResourceRegistryValidatesPermissions(t *testing.T) {
+	registry := DefaultResourceRegistry()
+	if err := registry.RegisterAction(entity.ActionDefinition{Action: "approve"}); err != nil {
+		t.Fatalf("RegisterAction: %v", err)
+	}
+	if err := registry.RegisterResource(entity.ResourceDefinition{Type: "invoice", Actions: []entity.Action{"approve", entity.ActionRead}}); err != nil {
+		t.Fatalf("RegisterResource: %v", err)
+	}
+
+	tests := []struct {
+		resource entity.ResourceType
+		action   entity.Action
+		valid    bool
+	}{
+		{entity.ResourceUser, entity.ActionRead, true},
+		{"invoice", "approve", true},
+		{"invoice", entity.ActionDelete, false},
+		{"report", entity.ActionRead, false},
+		{entity.ResourceUser, "archive", false},
+	}
+	for _, tt := range tests {
+		err := registry.ValidatePermission(entity.NewPermission("test", tt.resource, tt.action, ""))
+		if (err == nil) != tt.valid {
+			t.Errorf("%s:%s: error = %v, want valid = %v", tt.resource, tt.action, err, tt.valid)
+		}
+	}
+}
+
+func TestResourceRegistryRejectsInvalidDefinitions(t *testing.T) {
+	registry := DefaultResourceRegistry()
+	if err := registry.RegisterResource(entity.ResourceDefinition{Type: entity.ResourceUser}); err == nil {
+		t.Error("duplicate resource was registered")
+	}
+	if err := registry.RegisterResource(entity.ResourceDefinition{Type: "invoice", Actions: []entity.Action{"approve"}}); err == nil {
+		t.Error("resource with an unknown action was registered")
+	}
+	if err := registry.RegisterAction(entity.ActionDefinition{Action: entity.ActionRead}); err == nil {
+		t.Error("duplicate action was registered")
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"fmt"
+	"strings"
+)
+
+// ScopeRegistry реестр областей действия: описания для страницы согласия,
+// области по умолчанию, иерархия (orders включает orders:read) и шаблоны
+// с параметрами (tenant:{id}). Области, которых нет в реестре, остаются
+// произвольными строками и проверяются точным совпадением, как без реестра.
+// Методы nil реестра сохраняют поведение без реестра.
+type ScopeRegistry struct {
+	byName    map[string]entity.Scope
+	names     []string
+	templates []entity.Scope
+}
+
+// NewScopeRegistry создает реестр и проверяет, что дочерние области
+// зарегистрированы, используют только параметры родителя и не образуют цикл
+func NewScopeRegistry(scopes ...entity.Scope) (*ScopeRegistry, error) {
+	registry := &ScopeRegistry{byName: make(map[string]entity.Scope, len(scopes))}
+	for _, scope := range scopes {
+		if err := validateScopeName(scope.Name); err != nil {
+			return nil, err
+		}
+		if _, exists := registry.byName[scope.Name]; exists {
+			return nil, fmt.Errorf("scope %s is registered twice", scope.Name)
+		}
+		registry.byName[scope.Name] = scope
+		registry.names = append(registry.names, scope.Name)
+		if scope.IsParameterized() {
+			registry.templates = append(registry.templates, scope)
+		}
+	}
+
+	for _, scope := range scopes {
+		params := make(map[string]bool)
+		for _, name := range scope.Parameters() {
+			params[name] = true
+		}
+		for _, implied := range scope.Implies {
+			child, ok := registry.byName[implied]
+			if !ok {
+				return nil, fmt.Errorf("scope %s implies unregistered scope %s", scope.Name, implied)
+			}
+			for _, name := range child.Parameters() {
+				if !params[name] {
+					return nil, fmt.Errorf("scope %s implies %s with unknown parameter {%s}", scope.Name, implied, name)
+				}
+			}
+		}
+	}
+	if err := registry.checkCycles(); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// validateScopeName проверяет имя области: без пробелов и пустых сегментов,
+// параметры шаблона не повторяются
+func validateScopeName(name string) error {
+	if name == "" {
+		return fmt.Errorf("scope name is required")
+	}
+	if strings.ContainsAny(name, " \t\r\n\"\\") {
+		return fmt.Errorf("invalid scope name %q", name)
+	}
+	seen := make(map[string]bool)
+	for _, segment := range strings.Split(name, ":") {
+		if segment == "" {
+			return fmt.Errorf("scope %s has an empty segment", name)
+		}
+		for _, param := range (entity.Scope{Name: segment}).Parameters() {
+			if seen[param] {
+				return fmt.Errorf("scope %s repeats parameter {%s}", name, param)
+			}
+			seen[param] = true
+		}
+	}
+	return nil
+}
+
+// checkCycles запрещает циклы в иерархии: иначе область включала бы саму себя
+func (r *ScopeRegistry) checkCycles() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(r.byName))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("scope hierarchy contains a cycle through %s", name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, implied := range r.byName[name].Implies {
+			if err := visit(implied); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for _, name := range r.names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scope возвращает описание области и значения параметров шаблона. Имя шаблона
+// (tenant:{id}) находит сам шаблон без подстановки параметров.
+func (r *ScopeRegistry) Scope(value string) (entity.Scope, map[string]string, bool) {
+	if r == nil {
+		return entity.Scope{}, nil, false
+	}
+	if scope, ok := r.byName[value]; ok {
+		return scope, nil, true
+	}
+	for _, template := range r.templates {
+		if params, ok := template.Match(value); ok {
+			return template, params, true
+		}
+	}
+	return entity.Scope{}, nil, false
+}
+
+// Supported возвращает области без параметров для метаданных сервера (scopes_supported)
+func (r *ScopeRegistry) Supported() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.names))
+	for _, name := range r.names {
+		if !r.byName[name].IsParameterized() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Defaults возвращает области по умолчанию, разрешенные клиенту. Если в реестре
+// нет областей по умолчанию, клиент получает все свои области.
+func (r *ScopeRegistry) Defaults(client *entity.Client) []string {
+	if r == nil {
+		return append([]string(nil), client.Scopes...)
+	}
+	defaults := make([]string, 0)
+	for _, name := range r.names {
+		if scope := r.byName[name]; scope.Default && !scope.IsParameterized() {
+			defaults = append(defaults, name)
+		}
+	}
+	if len(defaults) == 0 {
+		return append([]string(nil), client.Scopes...)
+	}
+	allowed := make([]string, 0, len(defaults))
+	for _, scope := range defaults {
+		if r.IsAllowed(client, scope) {
+			allowed = append(allowed, scope)
+		}
+	}
+	return allowed
+}
+
+// Expand возвращает области вместе со всеми включенными в них дочерними,
+// подставляя параметры шаблонов: tenant:42 включает tenant:42:read
+func (r *ScopeRegistry) Expand(scopes []string) []string {
+	expanded := append([]string(nil), scopes...)
+	if r == nil {
+		return expanded
+	}
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		seen[scope] = true
+	}
+	for i := 0; i < len(expanded); i++ {
+		scope, params, ok := r.Scope(expanded[i])
+		if !ok {
+			continue
+		}
+		for _, implied := range scope.Implies {
+			implied = entity.ExpandScopeParameters(implied, params)
+			if !seen[implied] {
+				seen[implied] = true
+				expanded = append(expanded, implied)
+			}
+		}
+	}
+	return expanded
+}
+
+// Includes проверяет, покрывают ли выданные области требуемую с учетом иерархии
+func (r *ScopeRegistry) Includes(granted []string, scope string) bool {
+	for _, value := range r.Expand(granted) {
+		if value == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllowed проверяет, может ли клиент запросить область: она указана у клиента,
+// включена в одну из его областей или соответствует шаблону, указанному у клиента
+// (клиенту с tenant:{id} доступны tenant:42 и включенные в нее области).
+// Шаблоном считается только зарегистрированная область с параметрами: строка
+// {any} в списке клиента, которой нет в реестре, не открывает доступ к admin.
+func (r *ScopeRegistry) IsAllowed(client *entity.Client, scope string) bool {
+	if client.IsScopeAllowed(scope) {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	for _, value := range r.Expand(client.Scopes) {
+		if value == scope {
+			return true
+		}
+		if template, ok := r.byName[value]; ok && template.IsParameterized() {
+			if _, ok := template.Match(scope); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Describe возвращает описание области на первом подходящем языке из languages;
+// для незарегистрированных областей — само значение
+func (r *ScopeRegistry) Describe(value string, languages []string) string {
+	scope, params, ok := r.Scope(value)
+	if !ok {
+		return value
+	}
+	return scope.Describe(value, params, languages)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"testing"
+)
+
+func newTestScopeRegistry(t *testing.T) *ScopeRegistry {
+	t.Helper()
+	registry, err := NewScopeRegistry(
+		entity.Scope{Name: "orders", Implies: []string{"orders:read"}},
+		entity.Scope{Name: "orders:read"},
+		entity.Scope{Name: "tenant:{id}", Implies: []string{"tenant:{id}:read"}},
+		entity.Scope{Name: "tenant:{id}:read"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestScopeRegistryIsAllowed(t *testing.T) {
+	registry := newTestScopeRegistry(t)
+
+	tests := []struct {
+		name   string
+		client []string
+		scope  string
+		want   bool
+	}{
+		{"listed scope", []string{"orders"}, "orders", true},
+		{"implied scope", []string{"orders"}, "orders:read", true},
+		{"template value", []string{"tenant:{id}"}, "tenant:42", true},
+		{"implied by template", []string{"tenant:{id}"}, "tenant:42:read", true},
+		{"unregistered pattern", []string{"{any}"}, "admin", false},
+		{"unregistered pattern with prefix", []string{"orders:{op}"}, "orders:write", false},
+		{"other scope", []string{"orders"}, "admin", false},
+	}
+	for _, tt := range tests {
+		client := entity.NewClient("test", "", nil, nil, tt.client)
+		if got := registry.IsAllowed(client, tt.scope); got != tt.want {
+			t.Errorf("%s: IsAllowed(%v, %s) = %v, want %v", tt.name, tt.client, tt.scope, got, tt.want)
+		}
+	}
+}
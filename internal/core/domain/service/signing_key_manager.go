@@ -0,0 +1,161 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SigningAlgorithmES256 алгоритм подписи ECDSA P-256 с SHA-256
+const SigningAlgorithmES256 = "ES256"
+
+// SigningKeyManagerConfig конфигурация управления ключами подписи
+type SigningKeyManagerConfig struct {
+	// RetiredKeyTTL время, в течение которого выведенный ключ публикуется
+	// для проверки ранее выданных токенов; должно превышать срок жизни токенов
+	RetiredKeyTTL time.Duration
+}
+
+// DefaultSigningKeyManagerConfig возвращает конфигурацию по умолчанию
+func DefaultSigningKeyManagerConfig() *SigningKeyManagerConfig {
+	return &SigningKeyManagerConfig{
+		RetiredKeyTTL: 7 * 24 * time.Hour,
+	}
+}
+
+// SigningKeyManager управляет ротацией ключей подписи токенов
+type SigningKeyManager struct {
+	keys   ports.SigningKeyRepository
+	config *SigningKeyManagerConfig
+}
+
+// NewSigningKeyManager создает новый экземпляр SigningKeyManager
+func NewSigningKeyManager(keys ports.SigningKeyRepository, config *SigningKeyManagerConfig) *SigningKeyManager {
+	if config == nil {
+		config = DefaultSigningKeyManagerConfig()
+	}
+	return &SigningKeyManager{
+		keys:   keys,
+		config: config,
+	}
+}
+
+// Rotate создает новый активный ключ, выводит предыдущие активные ключи
+// из использования и удаляет выведенные ключи с истекшим сроком публикации
+func (m *SigningKeyManager) Rotate(ctx context.Context) (*entity.SigningKey, error) {
+	log.Info("rotating signing keys")
+
+	key, err := generateSigningKey()
+	if err != nil {
+		log.Error("failed to generate signing key", zap.Error(err))
+		return nil, err
+	}
+
+	existing, err := m.keys.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	if err := m.keys.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	now := time.Now()
+	for _, old := range existing {
+		if old.IsActive() {
+			old.Retire()
+			if err := m.keys.Update(ctx, old); err != nil {
+				return nil, fmt.Errorf("failed to retire signing key %s: %w", old.ID, err)
+			}
+			log.Info("signing key retired", zap.String("kid", old.ID))
+			continue
+		}
+		if old.RetiredAt != nil && now.Sub(*old.RetiredAt) > m.config.RetiredKeyTTL {
+			if err := m.keys.Delete(ctx, old.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete signing key %s: %w", old.ID, err)
+			}
+			log.Info("expired signing key deleted", zap.String("kid", old.ID))
+		}
+	}
+
+	log.Info("signing key activated",
+		zap.String("kid", key.ID),
+		zap.String("alg", key.Algorithm),
+	)
+	return key, nil
+}
+
+// ActiveKey возвращает ключ, которым подписываются новые токены
+func (m *SigningKeyManager) ActiveKey(ctx context.Context) (*entity.SigningKey, error) {
+	keys, err := m.keys.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	var active *entity.SigningKey
+	for _, key := range keys {
+		if key.IsActive() {
+			active = key
+		}
+	}
+	if active == nil {
+		return nil, fmt.Errorf("no active signing key")
+	}
+	return active, nil
+}
+
+// VerificationKeys возвращает ключи, которыми можно проверять подписи:
+// активный и выведенные, срок публикации которых не истек
+func (m *SigningKeyManager) VerificationKeys(ctx context.Context) ([]*entity.SigningKey, error) {
+	keys, err := m.keys.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	now := time.Now()
+	result := make([]*entity.SigningKey, 0, len(keys))
+	for _, key := range keys {
+		if key.RetiredAt != nil && now.Sub(*key.RetiredAt) > m.config.RetiredKeyTTL {
+			continue
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// generateSigningKey создает ключ ES256; kid вычисляется из открытого ключа
+func generateSigningKey() (*entity.SigningKey, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	sum := sha256.Sum256(publicDER)
+	return &entity.SigningKey{
+		ID:         base64.RawURLEncoding.EncodeToString(sum[:16]),
+		Algorithm:  SigningAlgorithmES256,
+		PrivateKey: privateDER,
+		PublicKey:  publicDER,
+		Status:     entity.SigningKeyActive,
+		CreatedAt:  time.Now(),
+	}, nil
+}
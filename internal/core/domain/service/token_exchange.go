@@ -0,0 +1,297 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"AuthAndOauth/internal/core/ports"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Идентификаторы типов токенов (RFC 8693, раздел 3)
+const (
+	TokenTypeIDAccessToken  = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeIDRefreshToken = "urn:ietf:params:oauth:token-type:refresh_token"
+)
+
+// Ошибки обмена токенов (RFC 8693, раздел 2.2.2). Ошибки оборачиваются
+// с пояснением, которое можно вернуть клиенту в error_description.
+var (
+	ErrInvalidSubjectToken     = errors.New("invalid subject token")
+	ErrInvalidActorToken       = errors.New("invalid actor token")
+	ErrUnsupportedTokenType    = errors.New("unsupported token type")
+	ErrInvalidTarget           = errors.New("invalid target")
+	ErrInvalidExchangeScope    = errors.New("invalid scope")
+	ErrTokenExchangeNotAllowed = errors.New("token exchange not allowed")
+)
+
+// TokenExchangeConfig конфигурация обмена токенов
+type TokenExchangeConfig struct {
+	// MaxDelegationDepth максимальная длина цепочки act в выпущенном токене
+	MaxDelegationDepth int
+}
+
+// DefaultTokenExchangeConfig возвращает конфигурацию по умолчанию
+func DefaultTokenExchangeConfig() *TokenExchangeConfig {
+	return &TokenExchangeConfig{
+		MaxDelegationDepth: 4,
+	}
+}
+
+// TokenExchangeRequest параметры запроса обмена токенов (RFC 8693, раздел 2.1)
+type TokenExchangeRequest struct {
+	SubjectToken       string
+	SubjectTokenType   string
+	ActorToken         string
+	ActorTokenType     string
+	RequestedTokenType string
+	Audiences          []string
+	Resources          []string
+	Scopes             []string
+	// Confirmation ключи, подтвержденные клиентом в запросе (DPoP proof,
+	// сертификат mTLS); привязанные токены обмениваются только с ними
+	Confirmation *entity.TokenConfirmation
+}
+
+// TokenExchangeResult описание токена, который нужно выпустить клиенту
+type TokenExchangeResult struct {
+	// UserID субъект исходного токена; uuid.Nil, если субъект — клиент
+	UserID   uuid.UUID
+	Scopes   []string
+	Audience []string
+	Actor    *entity.TokenActor
+	// NotAfter новый токен не должен пережить исходный
+	NotAfter time.Time
+	// IssuedTokenType идентификатор типа выпускаемого токена
+	IssuedTokenType string
+}
+
+// TokenExchangeService реализует Token Exchange (RFC 8693)
+type TokenExchangeService struct {
+	tokens    ports.TokenRepository
+	clients   ports.ClientRepository
+	validator *TokenValidator
+	config    *TokenExchangeConfig
+	scopes    *ScopeRegistry
+	resources *ResourceIndicators
+}
+
+// NewTokenExchangeService создает новый экземпляр TokenExchangeService
+func NewTokenExchangeService(tokens ports.TokenRepository, clients ports.ClientRepository, validator *TokenValidator, config *TokenExchangeConfig) *TokenExchangeService {
+	if validator == nil {
+		validator = NewTokenValidator()
+	}
+	if config == nil {
+		config = DefaultTokenExchangeConfig()
+	}
+	return &TokenExchangeService{
+		tokens:    tokens,
+		clients:   clients,
+		validator: validator,
+		config:    config,
+	}
+}
+
+// SetScopes задает реестр областей: токен с orders обменивается на orders:read
+func (s *TokenExchangeService) SetScopes(registry *ScopeRegistry) {
+	s.scopes = registry
+}
+
+// SetResources задает реестр ресурсов: исходный токен без audience
+// обменивается только на токен для ресурса по умолчанию
+func (s *TokenExchangeService) SetResources(resources *ResourceIndicators) {
+	s.resources = resources
+}
+
+// Exchange проверяет запрос клиента по его политике и описывает токен, который
+// нужно выпустить. Области действия могут только сужаться.
+func (s *TokenExchangeService) Exchange(ctx context.Context, client *entity.Client, req TokenExchangeRequest) (*TokenExchangeResult, error) {
+	policy := client.TokenExchange
+	if policy == nil {
+		policy = entity.DefaultTokenExchangePolicy()
+	}
+
+	if req.RequestedTokenType != "" && req.RequestedTokenType != TokenTypeIDAccessToken {
+		return nil, fmt.Errorf("%w: requested token type %s", ErrUnsupportedTokenType, req.RequestedTokenType)
+	}
+
+	subject, err := s.loadToken(ctx, client, policy, req.SubjectToken, req.SubjectTokenType, req.Confirmation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubjectToken, err)
+	}
+
+	result := &TokenExchangeResult{
+		UserID:          subject.UserID,
+		Actor:           subject.Actor.Clone(),
+		NotAfter:        subject.ExpiresAt,
+		IssuedTokenType: TokenTypeIDAccessToken,
+	}
+
+	if req.ActorToken != "" {
+		if !policy.AllowDelegation {
+			return nil, fmt.Errorf("%w: delegation is not allowed for this client", ErrTokenExchangeNotAllowed)
+		}
+		actor, err := s.loadToken(ctx, client, policy, req.ActorToken, req.ActorTokenType, req.Confirmation)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidActorToken, err)
+		}
+		identity, err := s.actorIdentity(ctx, actor)
+		if err != nil {
+			return nil, err
+		}
+		// Текущий участник становится внешним act, предыдущие сохраняются вложенными
+		identity.Actor = result.Actor
+		result.Actor = identity
+		if result.Actor.Depth() > s.config.MaxDelegationDepth {
+			return nil, fmt.Errorf("%w: delegation chain is longer than %d", ErrTokenExchangeNotAllowed, s.config.MaxDelegationDepth)
+		}
+		if actor.ExpiresAt.Before(result.NotAfter) {
+			result.NotAfter = actor.ExpiresAt
+		}
+	} else {
+		if req.ActorTokenType != "" {
+			return nil, fmt.Errorf("%w: actor_token_type without actor_token", ErrInvalidActorToken)
+		}
+		if !policy.AllowImpersonation {
+			return nil, fmt.Errorf("%w: impersonation is not allowed for this client, actor_token is required", ErrTokenExchangeNotAllowed)
+		}
+	}
+
+	if result.Audience, err = s.exchangeAudience(policy, subject, req); err != nil {
+		return nil, err
+	}
+	if result.Scopes, err = s.exchangeScopes(client, subject, req.Scopes); err != nil {
+		return nil, err
+	}
+
+	log.Info("token exchange approved",
+		zap.String("client_id", client.ClientID),
+		zap.String("subject_token_id", subject.ID.String()),
+		zap.Strings("audience", result.Audience),
+		zap.Strings("scopes", result.Scopes),
+		zap.Int("delegation_depth", result.Actor.Depth()),
+	)
+	return result, nil
+}
+
+// loadToken находит действующий токен указанного типа и проверяет, что клиенту
+// разрешено обменивать токены его владельца. Токен, привязанный к ключу (cnf),
+// принимается только с подтверждением владения этим ключом.
+func (s *TokenExchangeService) loadToken(ctx context.Context, client *entity.Client, policy *entity.TokenExchangePolicy, value, typeID string, presented *entity.TokenConfirmation) (*entity.Token, error) {
+	if value == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+
+	var tokenType entity.TokenType
+	switch typeID {
+	case TokenTypeIDAccessToken:
+		tokenType = entity.AccessToken
+	case TokenTypeIDRefreshToken:
+		tokenType = entity.RefreshToken
+	case "":
+		return nil, fmt.Errorf("token type is required")
+	default:
+		return nil, fmt.Errorf("unsupported token type %s", typeID)
+	}
+
+	token, err := s.tokens.GetByValue(ctx, value)
+	if errors.Is(err, ports.ErrNotFound) {
+		return nil, fmt.Errorf("token is unknown")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.Type != tokenType {
+		return nil, fmt.Errorf("token is not of type %s", typeID)
+	}
+	if err := s.validator.ValidateToken(token); err != nil {
+		return nil, err
+	}
+	if err := CheckTokenConfirmation(token, presented); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.clients.GetByID(ctx, token.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("token client not found")
+	}
+	if !policy.IsSubjectClientAllowed(client.ClientID, owner.ClientID) {
+		return nil, fmt.Errorf("tokens of client %s cannot be exchanged by this client", owner.ClientID)
+	}
+	return token, nil
+}
+
+// actorIdentity описывает владельца actor_token для claim act: пользователя
+// или клиента, если токен выдан без пользователя
+func (s *TokenExchangeService) actorIdentity(ctx context.Context, token *entity.Token) (*entity.TokenActor, error) {
+	owner, err := s.clients.GetByID(ctx, token.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token client not found", ErrInvalidActorToken)
+	}
+	actor := &entity.TokenActor{Subject: owner.ClientID, ClientID: owner.ClientID}
+	if token.UserID != uuid.Nil {
+		actor.Subject = token.UserID.String()
+	}
+	return actor, nil
+}
+
+// exchangeAudience объединяет параметры audience и resource и проверяет их по политике.
+// Без параметров новый токен получает audience исходного; audience исходного
+// токена не расширяется. Исходный токен без audience открывает только ресурс
+// по умолчанию, а без него — только токен без audience.
+func (s *TokenExchangeService) exchangeAudience(policy *entity.TokenExchangePolicy, subject *entity.Token, req TokenExchangeRequest) ([]string, error) {
+	granted := s.resources.Granted(subject.Audience)
+	if len(req.Audiences) == 0 && len(req.Resources) == 0 {
+		return append([]string(nil), granted...), nil
+	}
+	audience := make([]string, 0, len(req.Audiences)+len(req.Resources))
+	for _, resource := range req.Resources {
+		if !entity.IsResourceIndicator(resource) {
+			return nil, fmt.Errorf("%w: resource must be an absolute URI without a fragment: %s", ErrInvalidTarget, resource)
+		}
+	}
+	for _, target := range append(append([]string(nil), req.Audiences...), req.Resources...) {
+		if !policy.IsAudienceAllowed(target) {
+			return nil, fmt.Errorf("%w: %s is not an allowed audience", ErrInvalidTarget, target)
+		}
+		if !containsString(granted, target) {
+			return nil, fmt.Errorf("%w: %s is outside the subject token audience", ErrInvalidTarget, target)
+		}
+		if !containsString(audience, target) {
+			audience = append(audience, target)
+		}
+	}
+	return audience, nil
+}
+
+// exchangeScopes проверяет, что запрошенные области включены в области исходного
+// токена с учетом иерархии и доступны клиенту. Без параметра scope выдаются
+// общие области исходного токена и клиента.
+func (s *TokenExchangeService) exchangeScopes(client *entity.Client, subject *entity.Token, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		scopes := make([]string, 0, len(subject.Scopes))
+		for _, scope := range subject.Scopes {
+			if s.scopes.IsAllowed(client, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		if len(scopes) == 0 {
+			return nil, fmt.Errorf("%w: subject token has no scopes allowed for this client", ErrInvalidExchangeScope)
+		}
+		return scopes, nil
+	}
+
+	for _, scope := range requested {
+		if !s.scopes.Includes(subject.Scopes, scope) {
+			return nil, fmt.Errorf("%w: scope %s exceeds the subject token", ErrInvalidExchangeScope, scope)
+		}
+		if !s.scopes.IsAllowed(client, scope) {
+			return nil, fmt.Errorf("%w: scope %s is not allowed", ErrInvalidExchangeScope, scope)
+		}
+	}
+	return append([]string(nil), requested...), nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"AuthAndOauth/internal/adapters/repository/memory"
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// newTestExchange создает клиента с политикой обмена и его access token
+// с привязкой confirmation и audience
+func newTestExchange(t *testing.T, confirmation *entity.TokenConfirmation, audience ...string) (*TokenExchangeService, *entity.Client, *entity.Token) {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+
+	client := newTestClient()
+	client.TokenExchange = &entity.TokenExchangePolicy{
+		Audiences:          []string{"https://api.example.com", "https://billing.example.com"},
+		AllowImpersonation: true,
+	}
+	if err := store.Clients.Create(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	subject := entity.NewToken(uuid.New(), client.ID, entity.AccessToken, []string{"openid"}, time.Hour)
+	subject.Audience = audience
+	subject.Confirmation = confirmation
+	if err := store.Tokens.Create(ctx, subject); err != nil {
+		t.Fatal(err)
+	}
+	return NewTokenExchangeService(store.Tokens, store.Clients, nil, nil), client, subject
+}
+
+func exchangeRequest(subject *entity.Token) TokenExchangeRequest {
+	return TokenExchangeRequest{SubjectToken: subject.Value, SubjectTokenType: TokenTypeIDAccessToken}
+}
+
+func TestTokenExchangeKeepsSubjectAudience(t *testing.T) {
+	s, client, subject := newTestExchange(t, nil, "https://api.example.com")
+	ctx := context.Background()
+
+	result, err := s.Exchange(ctx, client, exchangeRequest(subject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Audience, subject.Audience) {
+		t.Errorf("audience = %v, want the subject audience %v", result.Audience, subject.Audience)
+	}
+
+	// Политика разрешает billing, но исходный токен выпущен только для api
+	req := exchangeRequest(subject)
+	req.Audiences = []string{"https://billing.example.com"}
+	if _, err := s.Exchange(ctx, client, req); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("widening the audience: error = %v, want ErrInvalidTarget", err)
+	}
+
+	req.Audiences = []string{"https://api.example.com"}
+	if _, err := s.Exchange(ctx, client, req); err != nil {
+		t.Errorf("keeping the audience: error = %v", err)
+	}
+}
+
+func TestTokenExchangeRequiresBoundKey(t *testing.T) {
+	s, client, subject := newTestExchange(t, &entity.TokenConfirmation{JKT: "bound-key"})
+	ctx := context.Background()
+
+	req := exchangeRequest(subject)
+	if _, err := s.Exchange(ctx, client, req); !errors.Is(err, ErrInvalidSubjectToken) {
+		t.Errorf("bound token without proof: error = %v, want ErrInvalidSubjectToken", err)
+	}
+	req.Confirmation = &entity.TokenConfirmation{JKT: "other-key"}
+	if _, err := s.Exchange(ctx, client, req); !errors.Is(err, ErrInvalidSubjectToken) {
+		t.Errorf("bound token with another key: error = %v, want ErrInvalidSubjectToken", err)
+	}
+	req.Confirmation = &entity.TokenConfirmation{JKT: "bound-key"}
+	if _, err := s.Exchange(ctx, client, req); err != nil {
+		t.Errorf("bound token with its key: error = %v", err)
+	}
+}
+
+func TestTokenExchangeWithoutSubjectAudienceAllowsOnlyDefaultResource(t *testing.T) {
+	ctx := context.Background()
+
+	s, client, subject := newTestExchange(t, nil)
+	req := exchangeRequest(subject)
+	req.Audiences = []string{"https://billing.example.com"}
+	if _, err := s.Exchange(ctx, client, req); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("no default resource: error = %v, want ErrInvalidTarget", err)
+	}
+
+	resources, err := NewResourceIndicators(
+		entity.ResourceServer{URI: "https://api.example.com", Default: true},
+		entity.ResourceServer{URI: "https://billing.example.com"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetResources(resources)
+	if _, err := s.Exchange(ctx, client, req); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("non-default resource: error = %v, want ErrInvalidTarget", err)
+	}
+	result, err := s.Exchange(ctx, client, exchangeRequest(subject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Audience, []string{"https://api.example.com"}) {
+		t.Errorf("audience = %v, want the default resource", result.Audience)
+	}
+}
+
+func TestTokenExchangeNarrowsScopesThroughRegistry(t *testing.T) {
+	ctx := context.Background()
+	s, client, subject := newTestExchange(t, nil)
+	subject.Scopes = []string{"orders"}
+	client.Scopes = []string{"orders"}
+	if err := s.tokens.Update(ctx, subject); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.clients.Update(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	req := exchangeRequest(subject)
+	req.Scopes = []string{"orders:read"}
+	if _, err := s.Exchange(ctx, client, req); !errors.Is(err, ErrInvalidExchangeScope) {
+		t.Errorf("without registry: error = %v, want ErrInvalidExchangeScope", err)
+	}
+
+	s.SetScopes(newTestScopeRegistry(t))
+	result, err := s.Exchange(ctx, client, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Scopes, []string{"orders:read"}) {
+		t.Errorf("scopes = %v, want [orders:read]", result.Scopes)
+	}
+}
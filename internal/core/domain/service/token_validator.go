@@ -9,13 +9,21 @@ import (
 )
 
 // TokenValidator сервис для валидации токенов
-type TokenValidator struct{}
+type TokenValidator struct {
+	scopes *ScopeRegistry
+}
 
 // NewTokenValidator создает новый экземпляр TokenValidator
 func NewTokenValidator() *TokenValidator {
 	return &TokenValidator{}
 }
 
+// SetScopes задает реестр областей: ValidateScopes учитывает иерархию,
+// и токен с областью orders проходит проверку на orders:read
+func (v *TokenValidator) SetScopes(registry *ScopeRegistry) {
+	v.scopes = registry
+}
+
 // ValidateToken проверяет валидность токена
 func (v *TokenValidator) ValidateToken(token *entity.Token) error {
 	log.Debug("validating token",
@@ -63,7 +71,7 @@ func (v *TokenValidator) ValidateScopes(token *entity.Token, requiredScopes []st
 	}
 
 	tokenScopes := make(map[string]bool)
-	for _, scope := range token.Scopes {
+	for _, scope := range v.scopes.Expand(token.Scopes) {
 		tokenScopes[scope] = true
 	}
 
@@ -81,6 +89,33 @@ func (v *TokenValidator) ValidateScopes(token *entity.Token, requiredScopes []st
 	return nil
 }
 
+// ValidateAudience проверяет, что токен выпущен для ресурса (RFC 8707, раздел 2).
+// Ресурс с идентификатором принимает только токены, в audience которых он указан;
+// пустой resource — только токены без audience, не ограниченные ресурсами.
+func (v *TokenValidator) ValidateAudience(token *entity.Token, resource string) error {
+	if len(token.Audience) == 0 {
+		if resource == "" {
+			return nil
+		}
+		log.Warn("token without audience presented to a resource",
+			zap.String("token_id", token.ID.String()),
+			zap.String("resource", resource),
+		)
+		return fmt.Errorf("token is not intended for %s", resource)
+	}
+	for _, audience := range token.Audience {
+		if audience == resource {
+			return nil
+		}
+	}
+	log.Warn("token presented to a foreign resource",
+		zap.String("token_id", token.ID.String()),
+		zap.String("resource", resource),
+		zap.Strings("audience", token.Audience),
+	)
+	return fmt.Errorf("token is not intended for %s", resource)
+}
+
 // ValidateAuthCode проверяет валидность кода авторизации
 func (v *TokenValidator) ValidateAuthCode(code *entity.AuthCode, clientID string, redirectURI string) error {
 	log.Debug("validating authorization code",
@@ -170,7 +205,7 @@ func (v *TokenValidator) ValidateClient(client *entity.Client, grantType entity.
 // ValidateSession проверяет валидность сессии
 func (v *TokenValidator) ValidateSession(session *entity.Session) error {
 	log.Debug("validating session",
-		zap.String("session_id", session.ID.String()),
+		zap.String("session_id", session.ID),
 	)
 
 	if session == nil {
@@ -180,7 +215,7 @@ func (v *TokenValidator) ValidateSession(session *entity.Session) error {
 
 	if !session.IsActive() {
 		log.Warn("session is not active",
-			zap.String("session_id", session.ID.String()),
+			zap.String("session_id", session.ID),
 			zap.String("status", string(session.Status)),
 		)
 		return fmt.Errorf("session is not active")
@@ -188,7 +223,7 @@ func (v *TokenValidator) ValidateSession(session *entity.Session) error {
 
 	if session.IsExpired() {
 		log.Warn("session is expired",
-			zap.String("session_id", session.ID.String()),
+			zap.String("session_id", session.ID),
 			zap.Time("expires_at", session.ExpiresAt),
 		)
 		return fmt.Errorf("session is expired")
@@ -197,14 +232,14 @@ func (v *TokenValidator) ValidateSession(session *entity.Session) error {
 	inactiveTime := time.Since(session.LastUsedAt)
 	if inactiveTime > 30*time.Minute {
 		log.Warn("session inactive timeout",
-			zap.String("session_id", session.ID.String()),
+			zap.String("session_id", session.ID),
 			zap.Duration("inactive_time", inactiveTime),
 		)
 		return fmt.Errorf("session inactive timeout")
 	}
 
 	log.Debug("session is valid",
-		zap.String("session_id", session.ID.String()),
+		zap.String("session_id", session.ID),
 	)
 	return nil
 }
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateAudience(t *testing.T) {
+	v := NewTokenValidator()
+	unrestricted := entity.NewToken(uuid.New(), uuid.New(), entity.AccessToken, []string{"openid"}, time.Hour)
+	restricted := entity.NewToken(uuid.New(), uuid.New(), entity.AccessToken, []string{"openid"}, time.Hour)
+	restricted.Audience = []string{"https://api.example.com"}
+
+	tests := []struct {
+		name     string
+		token    *entity.Token
+		resource string
+		valid    bool
+	}{
+		{"no audience, no resource", unrestricted, "", true},
+		{"no audience, configured resource", unrestricted, "https://api.example.com", false},
+		{"matching audience", restricted, "https://api.example.com", true},
+		{"foreign audience", restricted, "https://billing.example.com", false},
+		{"audience, no resource", restricted, "", false},
+	}
+	for _, tt := range tests {
+		if err := v.ValidateAudience(tt.token, tt.resource); (err == nil) != tt.valid {
+			t.Errorf("%s: error = %v, want valid = %v", tt.name, err, tt.valid)
+		}
+	}
+}
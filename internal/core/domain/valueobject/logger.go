@@ -13,3 +13,8 @@ func init() {
 		panic(err)
 	}
 }
+
+// SetLogger заменяет логгер пакета
+func SetLogger(logger *zap.Logger) {
+	log = logger
+}
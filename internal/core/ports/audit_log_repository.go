@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+	"time"
+)
+
+// AuditLogFilter фильтр записей аудита; пустые поля не ограничивают выборку
+type AuditLogFilter struct {
+	UserID    string
+	EventType entity.AuditEventType
+	From      time.Time
+	To        time.Time
+}
+
+// AuditLogRepository хранилище записей аудита безопасности
+type AuditLogRepository interface {
+	// Save сохраняет запись аудита
+	Save(ctx context.Context, auditLog *entity.AuditLog) error
+	// List возвращает записи аудита, упорядоченные по времени создания
+	List(ctx context.Context, filter AuditLogFilter) ([]*entity.AuditLog, error)
+}
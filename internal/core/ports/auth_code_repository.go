@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// AuthCodeRepository хранилище кодов авторизации
+type AuthCodeRepository interface {
+	Create(ctx context.Context, code *entity.AuthCode) error
+	GetByCode(ctx context.Context, code string) (*entity.AuthCode, error)
+	Update(ctx context.Context, code *entity.AuthCode) error
+	// Delete удаляет код по его значению; ErrNotFound означает, что код уже погашен
+	Delete(ctx context.Context, code string) error
+	// DeleteByUserAndClient удаляет непогашенные коды, выданные клиенту от имени
+	// пользователя, и возвращает их число
+	DeleteByUserAndClient(ctx context.Context, userID, clientID uuid.UUID) (int, error)
+}
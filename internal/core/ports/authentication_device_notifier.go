@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+)
+
+// AuthenticationDeviceNotifier доставляет запрос аутентификации на устройство
+// пользователя (например, push-уведомлением в мобильное приложение). Устройство
+// показывает клиента, области действия и binding message, а решение пользователя
+// возвращается серверу по идентификатору запроса.
+type AuthenticationDeviceNotifier interface {
+	Notify(ctx context.Context, user *entity.User, client *entity.Client, request *entity.BackchannelAuthentication) error
+}
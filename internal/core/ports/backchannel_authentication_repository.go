@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// BackchannelAuthenticationRepository хранилище запросов аутентификации по обратному каналу
+type BackchannelAuthenticationRepository interface {
+	Create(ctx context.Context, request *entity.BackchannelAuthentication) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.BackchannelAuthentication, error)
+	GetByAuthReqID(ctx context.Context, authReqID string) (*entity.BackchannelAuthentication, error)
+	Update(ctx context.Context, request *entity.BackchannelAuthentication) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
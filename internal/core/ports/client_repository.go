@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ClientFilter фильтр клиентов; пустые поля не ограничивают выборку
+type ClientFilter struct {
+	// Name подстрока имени без учета регистра
+	Name      string
+	Active    *bool
+	GrantType entity.GrantType
+}
+
+// ClientRepository хранилище OAuth клиентов
+type ClientRepository interface {
+	Create(ctx context.Context, client *entity.Client) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Client, error)
+	GetByClientID(ctx context.Context, clientID string) (*entity.Client, error)
+	Update(ctx context.Context, client *entity.Client) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List возвращает страницу клиентов и общее число записей по фильтру
+	List(ctx context.Context, filter ClientFilter, page Pagination) ([]*entity.Client, int, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ConsentRepository хранилище согласий пользователей; на пару пользователь —
+// клиент приходится не более одного согласия
+type ConsentRepository interface {
+	// Save создает согласие или заменяет согласие той же пары пользователь — клиент
+	Save(ctx context.Context, consent *entity.Consent) error
+	Get(ctx context.Context, userID, clientID uuid.UUID) (*entity.Consent, error)
+	// ListByUser возвращает согласия пользователя, упорядоченные по времени создания
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Consent, error)
+	Delete(ctx context.Context, userID, clientID uuid.UUID) error
+}
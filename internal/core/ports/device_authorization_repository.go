@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DeviceAuthorizationRepository хранилище запросов авторизации устройств
+type DeviceAuthorizationRepository interface {
+	Create(ctx context.Context, authorization *entity.DeviceAuthorization) error
+	GetByDeviceCode(ctx context.Context, deviceCode string) (*entity.DeviceAuthorization, error)
+	GetByUserCode(ctx context.Context, userCode string) (*entity.DeviceAuthorization, error)
+	// Update сохраняет сведения об опросе; коды и решение пользователя не изменяются
+	Update(ctx context.Context, authorization *entity.DeviceAuthorization) error
+	// Decide сохраняет решение пользователя, только если запрос все еще ожидает
+	// решения; иначе возвращает ErrNotFound. Из двух одновременных решений
+	// сохраняется одно.
+	Decide(ctx context.Context, authorization *entity.DeviceAuthorization) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
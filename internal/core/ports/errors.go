@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotFound возвращается хранилищем, если запись не найдена
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists возвращается хранилищем при нарушении уникальности
+	ErrAlreadyExists = errors.New("already exists")
+)
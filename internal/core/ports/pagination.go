@@ -0,0 +1,41 @@
+package ports
+
+// DefaultPageLimit размер страницы по умолчанию
+const DefaultPageLimit = 50
+
+// MaxPageLimit максимальный размер страницы
+const MaxPageLimit = 500
+
+// Pagination параметры постраничной выборки
+type Pagination struct {
+	Offset int
+	Limit  int
+}
+
+// Normalize приводит параметры к допустимым значениям
+func (p Pagination) Normalize() Pagination {
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	return p
+}
+
+// Bounds возвращает границы страницы в срезе длины total
+func (p Pagination) Bounds(total int) (int, int) {
+	p = p.Normalize()
+	start := p.Offset
+	if start > total {
+		start = total
+	}
+	end := start + p.Limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+)
+
+// PendingAuthorizationRepository хранилище запросов авторизации, ожидающих использования
+type PendingAuthorizationRepository interface {
+	Create(ctx context.Context, pending *entity.PendingAuthorization) error
+	GetByID(ctx context.Context, id string) (*entity.PendingAuthorization, error)
+	// Delete удаляет запрос; ErrNotFound означает, что запрос уже использован
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PermissionFilter фильтр разрешений; пустые поля не ограничивают выборку
+type PermissionFilter struct {
+	Resource entity.ResourceType
+	Action   entity.Action
+	Effect   entity.PermissionEffect
+}
+
+// PermissionRepository хранилище разрешений
+type PermissionRepository interface {
+	Create(ctx context.Context, permission *entity.Permission) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Permission, error)
+	Update(ctx context.Context, permission *entity.Permission) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List возвращает страницу разрешений и общее число записей по фильтру
+	List(ctx context.Context, filter PermissionFilter, page Pagination) ([]*entity.Permission, int, error)
+}
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+)
+
+// RelationTupleFilter фильтр для чтения отношений; пустые поля не ограничивают выборку
+type RelationTupleFilter struct {
+	Namespace string
+	ObjectID  string
+	Relation  string
+	Subject   *entity.SubjectRef
+}
+
+// RelationTupleRepository хранилище отношений для авторизации на основе отношений.
+// Каждая запись создает новую ревизию; чтение выполняется по снимку на ревизию,
+// что позволяет выдавать и соблюдать токены согласованности.
+type RelationTupleRepository interface {
+	// Write атомарно добавляет и удаляет отношения и возвращает новую ревизию
+	Write(ctx context.Context, inserts []entity.RelationTuple, deletes []entity.RelationTuple) (uint64, error)
+	// Read возвращает отношения, существовавшие на указанной ревизии
+	Read(ctx context.Context, filter RelationTupleFilter, revision uint64) ([]entity.RelationTuple, error)
+	// CurrentRevision возвращает последнюю зафиксированную ревизию
+	CurrentRevision(ctx context.Context) (uint64, error)
+}
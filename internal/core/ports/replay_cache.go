@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// ReplayCache запоминает одноразовые идентификаторы (например, jti в DPoP proof)
+// до истечения срока, пока повторное предъявление еще может быть принято
+type ReplayCache interface {
+	// Use отмечает идентификатор использованным; ErrAlreadyExists, если он уже
+	// использован и срок его хранения не истек
+	Use(ctx context.Context, key string, expiresAt time.Time) error
+}
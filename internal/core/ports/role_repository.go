@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RoleFilter фильтр ролей; пустые поля не ограничивают выборку
+type RoleFilter struct {
+	// Name подстрока имени без учета регистра
+	Name         string
+	PermissionID *uuid.UUID
+}
+
+// RoleRepository хранилище ролей
+type RoleRepository interface {
+	Create(ctx context.Context, role *entity.Role) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Role, error)
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List возвращает страницу ролей и общее число записей по фильтру
+	List(ctx context.Context, filter RoleFilter, page Pagination) ([]*entity.Role, int, error)
+}
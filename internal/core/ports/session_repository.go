@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+)
+
+// SessionRepository хранилище пользовательских сессий
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	GetByID(ctx context.Context, id string) (*entity.Session, error)
+	Update(ctx context.Context, session *entity.Session) error
+	// ListByUser возвращает все сессии пользователя, включая отозванные
+	ListByUser(ctx context.Context, userID string) ([]*entity.Session, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+)
+
+// SigningKeyRepository хранилище ключей подписи токенов
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *entity.SigningKey) error
+	GetByID(ctx context.Context, kid string) (*entity.SigningKey, error)
+	Update(ctx context.Context, key *entity.SigningKey) error
+	Delete(ctx context.Context, kid string) error
+	// List возвращает все ключи, упорядоченные по времени создания
+	List(ctx context.Context) ([]*entity.SigningKey, error)
+}
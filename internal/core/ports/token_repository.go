@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TokenRepository хранилище выданных токенов
+type TokenRepository interface {
+	Create(ctx context.Context, token *entity.Token) error
+	GetByValue(ctx context.Context, value string) (*entity.Token, error)
+	Update(ctx context.Context, token *entity.Token) error
+	// Revoke отзывает действующий токен; ErrNotFound означает, что токен уже
+	// отозван. Используется для ротации refresh token.
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeFamily отзывает все токены семейства (entity.Token.Family) и
+	// возвращает число отозванных
+	RevokeFamily(ctx context.Context, family uuid.UUID) (int, error)
+	// RevokeByUserAndClient отзывает все действующие токены, выданные клиенту
+	// от имени пользователя, и возвращает число отозванных
+	RevokeByUserAndClient(ctx context.Context, userID, clientID uuid.UUID) (int, error)
+	// ListByUser возвращает все токены пользователя, включая отозванные
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.Token, error)
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"AuthAndOauth/internal/core/domain/entity"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// UserFilter фильтр пользователей; пустые поля не ограничивают выборку
+type UserFilter struct {
+	// Email подстрока адреса без учета регистра
+	Email  string
+	Active *bool
+	RoleID *uuid.UUID
+}
+
+// UserRepository хранилище пользователей
+type UserRepository interface {
+	Create(ctx context.Context, user *entity.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// List возвращает страницу пользователей и общее число записей по фильтру
+	List(ctx context.Context, filter UserFilter, page Pagination) ([]*entity.User, int, error)
+}
@@ -0,0 +1,101 @@
+package jose
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Claims набор утверждений JWT (RFC 7519)
+type Claims map[string]interface{}
+
+// String возвращает строковое утверждение; отсутствующее или нестроковое — пустая строка
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Bool возвращает логическое утверждение; отсутствующее или нелогическое — false
+func (c Claims) Bool(name string) bool {
+	value, _ := c[name].(bool)
+	return value
+}
+
+// Strings возвращает утверждение, заданное строкой или массивом строк
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case []string:
+		return value
+	default:
+		return nil
+	}
+}
+
+// Time возвращает утверждение NumericDate; ok=false, если утверждения нет
+func (c Claims) Time(name string) (time.Time, bool) {
+	switch value := c[name].(type) {
+	case float64:
+		seconds, fraction := math.Modf(value)
+		return time.Unix(int64(seconds), int64(fraction*1e9)), true
+	case int64:
+		return time.Unix(value, 0), true
+	case int:
+		return time.Unix(int64(value), 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// Issuer возвращает утверждение iss
+func (c Claims) Issuer() string {
+	return c.String("iss")
+}
+
+// Subject возвращает утверждение sub
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// Audience возвращает утверждение aud
+func (c Claims) Audience() []string {
+	return c.Strings("aud")
+}
+
+// HasAudience проверяет, входит ли значение в утверждение aud
+func (c Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Audience() {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTimes проверяет утверждения exp, nbf и iat с допустимым расхождением часов.
+// Если requireExp, отсутствие exp считается ошибкой.
+func (c Claims) ValidateTimes(now time.Time, leeway time.Duration, requireExp bool) error {
+	exp, ok := c.Time("exp")
+	if !ok && requireExp {
+		return fmt.Errorf("jose: exp claim is required")
+	}
+	if ok && !now.Before(exp.Add(leeway)) {
+		return fmt.Errorf("jose: token is expired")
+	}
+	if nbf, ok := c.Time("nbf"); ok && now.Add(leeway).Before(nbf) {
+		return fmt.Errorf("jose: token is not valid yet")
+	}
+	if iat, ok := c.Time("iat"); ok && now.Add(leeway).Before(iat) {
+		return fmt.Errorf("jose: token is issued in the future")
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package jose
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Алгоритмы управления ключом JWE (RFC 7518, раздел 4)
+const (
+	ECDHES     = "ECDH-ES"
+	RSAOAEP256 = "RSA-OAEP-256"
+)
+
+// Алгоритмы шифрования содержимого JWE (RFC 7518, раздел 5)
+const (
+	EncA128GCM = "A128GCM"
+	EncA256GCM = "A256GCM"
+)
+
+const (
+	gcmIVSize   = 12
+	gcmTagSize  = 16
+	maxJWEParts = 5
+)
+
+// KeyManagementAlgorithms поддерживаемые алгоритмы управления ключом JWE
+var KeyManagementAlgorithms = []string{ECDHES, RSAOAEP256}
+
+// ContentEncryptionAlgorithms поддерживаемые алгоритмы шифрования содержимого JWE
+var ContentEncryptionAlgorithms = []string{EncA128GCM, EncA256GCM}
+
+// ErrDecryption содержимое JWE не удалось расшифровать
+var ErrDecryption = errors.New("jose: decryption failed")
+
+// JWEHeader защищенный заголовок JWE
+type JWEHeader struct {
+	Algorithm  string `json:"alg"`
+	Encryption string `json:"enc"`
+	KeyID      string `json:"kid,omitempty"`
+	Type       string `json:"typ,omitempty"`
+	// ContentType тип вложенного содержимого; "JWT" для вложенного JWS
+	ContentType string `json:"cty,omitempty"`
+	// EphemeralKey временный открытый ключ отправителя для ECDH-ES
+	EphemeralKey *JWK   `json:"epk,omitempty"`
+	PartyUInfo   string `json:"apu,omitempty"`
+	PartyVInfo   string `json:"apv,omitempty"`
+}
+
+// JWE разобранный JWE в компактной сериализации
+type JWE struct {
+	Header JWEHeader
+
+	protected    string
+	encryptedKey []byte
+	iv           []byte
+	ciphertext   []byte
+	tag          []byte
+}
+
+// IsJWE проверяет, похожа ли строка на JWE в компактной сериализации (пять частей)
+func IsJWE(compact string) bool {
+	return strings.Count(compact, ".") == maxJWEParts-1
+}
+
+// ParseJWE разбирает JWE в компактной сериализации без расшифровки
+func ParseJWE(compact string) (*JWE, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != maxJWEParts {
+		return nil, fmt.Errorf("jose: encrypted token must have five parts")
+	}
+
+	headerJSON, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid header encoding")
+	}
+	var header JWEHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("jose: invalid header: %w", err)
+	}
+	if !contains(KeyManagementAlgorithms, header.Algorithm) {
+		return nil, fmt.Errorf("jose: unsupported key management algorithm %q", header.Algorithm)
+	}
+	if !contains(ContentEncryptionAlgorithms, header.Encryption) {
+		return nil, fmt.Errorf("jose: unsupported content encryption algorithm %q", header.Encryption)
+	}
+
+	jwe := &JWE{Header: header, protected: parts[0]}
+	for i, dst := range []*[]byte{&jwe.encryptedKey, &jwe.iv, &jwe.ciphertext, &jwe.tag} {
+		if *dst, err = decodeSegment(parts[i+1]); err != nil {
+			return nil, fmt.Errorf("jose: invalid segment encoding")
+		}
+	}
+	if len(jwe.iv) != gcmIVSize || len(jwe.tag) != gcmTagSize {
+		return nil, fmt.Errorf("jose: invalid initialization vector or authentication tag")
+	}
+	return jwe, nil
+}
+
+// Decrypt расшифровывает содержимое закрытым ключом получателя:
+// *ecdsa.PrivateKey для ECDH-ES или *rsa.PrivateKey для RSA-OAEP-256
+func (j *JWE) Decrypt(key interface{}) ([]byte, error) {
+	size := contentKeySize(j.Header.Encryption)
+
+	var cek []byte
+	switch j.Header.Algorithm {
+	case ECDHES:
+		privateKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jose: %s requires an EC private key", j.Header.Algorithm)
+		}
+		if len(j.encryptedKey) != 0 {
+			return nil, fmt.Errorf("jose: %s must not carry an encrypted key", j.Header.Algorithm)
+		}
+		if j.Header.EphemeralKey == nil {
+			return nil, fmt.Errorf("jose: epk header is required")
+		}
+		ephemeral, err := j.Header.EphemeralKey.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		ephemeralKey, ok := ephemeral.(*ecdsa.PublicKey)
+		if !ok || ephemeralKey.Curve != privateKey.Curve {
+			return nil, fmt.Errorf("jose: epk must be an EC key on the recipient curve")
+		}
+		if cek, err = deriveECDHES(privateKey, ephemeralKey, j.Header, size); err != nil {
+			return nil, err
+		}
+	case RSAOAEP256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("jose: %s requires an RSA private key", j.Header.Algorithm)
+		}
+		var err error
+		cek, err = rsa.DecryptOAEP(sha256.New(), nil, privateKey, j.encryptedKey, nil)
+		if err != nil || len(cek) != size {
+			return nil, ErrDecryption
+		}
+	default:
+		return nil, fmt.Errorf("jose: unsupported key management algorithm %q", j.Header.Algorithm)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, j.iv, append(append([]byte(nil), j.ciphertext...), j.tag...), []byte(j.protected))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return plaintext, nil
+}
+
+// Encrypt шифрует содержимое открытым ключом получателя и возвращает JWE в
+// компактной сериализации. Для ECDH-ES временный ключ создается автоматически.
+func Encrypt(header JWEHeader, plaintext []byte, key interface{}) (string, error) {
+	if !contains(ContentEncryptionAlgorithms, header.Encryption) {
+		return "", fmt.Errorf("jose: unsupported content encryption algorithm %q", header.Encryption)
+	}
+	size := contentKeySize(header.Encryption)
+
+	var cek, encryptedKey []byte
+	switch header.Algorithm {
+	case ECDHES:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return "", fmt.Errorf("jose: %s requires an EC public key", header.Algorithm)
+		}
+		ephemeral, err := ecdsa.GenerateKey(publicKey.Curve, rand.Reader)
+		if err != nil {
+			return "", fmt.Errorf("jose: generate ephemeral key: %w", err)
+		}
+		if header.EphemeralKey, err = NewJWK(&ephemeral.PublicKey, "", ""); err != nil {
+			return "", err
+		}
+		header.EphemeralKey.Use = ""
+		if cek, err = deriveECDHES(ephemeral, publicKey, header, size); err != nil {
+			return "", err
+		}
+	case RSAOAEP256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return "", fmt.Errorf("jose: %s requires an RSA public key", header.Algorithm)
+		}
+		cek = make([]byte, size)
+		if _, err := rand.Read(cek); err != nil {
+			return "", err
+		}
+		var err error
+		if encryptedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, cek, nil); err != nil {
+			return "", fmt.Errorf("jose: encrypt key: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("jose: unsupported key management algorithm %q", header.Algorithm)
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("jose: encode header: %w", err)
+	}
+	protected := encodeSegment(headerJSON)
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcmIVSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcmTagSize], sealed[len(sealed)-gcmTagSize:]
+
+	return strings.Join([]string{
+		protected,
+		encodeSegment(encryptedKey),
+		encodeSegment(iv),
+		encodeSegment(ciphertext),
+		encodeSegment(tag),
+	}, "."), nil
+}
+
+// deriveECDHES вычисляет ключ шифрования содержимого по ECDH-ES в режиме
+// прямого согласования и Concat KDF (RFC 7518, раздел 4.6.2)
+func deriveECDHES(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey, header JWEHeader, size int) ([]byte, error) {
+	private, err := privateKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid private key: %w", err)
+	}
+	public, err := publicKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid public key: %w", err)
+	}
+	z, err := private.ECDH(public)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+
+	apu, err := decodeSegment(header.PartyUInfo)
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid apu")
+	}
+	apv, err := decodeSegment(header.PartyVInfo)
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid apv")
+	}
+
+	otherInfo := lengthPrefixed([]byte(header.Encryption))
+	otherInfo = append(otherInfo, lengthPrefixed(apu)...)
+	otherInfo = append(otherInfo, lengthPrefixed(apv)...)
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(size*8))
+
+	key := make([]byte, 0, size+sha256.Size)
+	for counter := uint32(1); len(key) < size; counter++ {
+		h := sha256.New()
+		_ = binary.Write(h, binary.BigEndian, counter)
+		h.Write(z)
+		h.Write(otherInfo)
+		key = h.Sum(key)
+	}
+	return key[:size], nil
+}
+
+func lengthPrefixed(data []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...)
+}
+
+func contentKeySize(enc string) int {
+	if enc == EncA128GCM {
+		return 16
+	}
+	return 32
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid content encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
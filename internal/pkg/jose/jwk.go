@@ -0,0 +1,219 @@
+// Package jose реализует подмножество JOSE, необходимое серверу авторизации:
+// компактную сериализацию JWS (RFC 7515) и JWE (RFC 7516), ключи JWK и наборы
+// JWKS (RFC 7517) и отпечатки JWK (RFC 7638). Используется только стандартная библиотека.
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Типы ключей JWK
+const (
+	KeyTypeEC  = "EC"
+	KeyTypeRSA = "RSA"
+	KeyTypeOct = "oct"
+)
+
+// JWK открытый ключ в формате JSON Web Key. Закрытые параметры не поддерживаются:
+// пакет публикует и принимает только открытые ключи.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	// K симметричный ключ (kty=oct); используется только внутри процесса и не публикуется
+	K string `json:"k,omitempty"`
+	// X5tS256 отпечаток SHA-256 сертификата X.509 (RFC 8705)
+	X5tS256 string `json:"x5t#S256,omitempty"`
+}
+
+// JWKSet набор ключей (JWKS)
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Key возвращает ключ по kid; если kid пуст и ключ в наборе один, возвращается он
+func (s *JWKSet) Key(kid string) (*JWK, bool) {
+	if s == nil {
+		return nil, false
+	}
+	if kid == "" {
+		if len(s.Keys) == 1 {
+			return &s.Keys[0], true
+		}
+		return nil, false
+	}
+	for i := range s.Keys {
+		if s.Keys[i].KeyID == kid {
+			return &s.Keys[i], true
+		}
+	}
+	return nil, false
+}
+
+// NewJWK создает JWK для открытого ключа ECDSA или RSA
+func NewJWK(publicKey crypto.PublicKey, kid, alg string) (*JWK, error) {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		crv, size, err := curveParams(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{
+			KeyType:   KeyTypeEC,
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: alg,
+			Curve:     crv,
+			X:         encodeSegment(key.X.FillBytes(make([]byte, size))),
+			Y:         encodeSegment(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *rsa.PublicKey:
+		return &JWK{
+			KeyType:   KeyTypeRSA,
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: alg,
+			N:         encodeSegment(key.N.Bytes()),
+			E:         encodeSegment(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("jose: unsupported public key type %T", publicKey)
+	}
+}
+
+// PublicKey возвращает ключ для проверки подписи:
+// *ecdsa.PublicKey, *rsa.PublicKey или []byte для kty=oct
+func (k *JWK) PublicKey() (interface{}, error) {
+	switch k.KeyType {
+	case KeyTypeEC:
+		curve, size, err := curveByName(k.Curve)
+		if err != nil {
+			return nil, err
+		}
+		x, err := decodeSegment(k.X)
+		if err != nil || len(x) != size {
+			return nil, fmt.Errorf("jose: invalid EC key x coordinate")
+		}
+		y, err := decodeSegment(k.Y)
+		if err != nil || len(y) != size {
+			return nil, fmt.Errorf("jose: invalid EC key y coordinate")
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("jose: EC key point is not on the curve")
+		}
+		return key, nil
+	case KeyTypeRSA:
+		n, err := decodeSegment(k.N)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("jose: invalid RSA modulus")
+		}
+		e, err := decodeSegment(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jose: invalid RSA exponent")
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("jose: RSA key must be at least 2048 bits")
+		}
+		return key, nil
+	case KeyTypeOct:
+		secret, err := decodeSegment(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("jose: invalid symmetric key")
+		}
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("jose: unsupported key type %q", k.KeyType)
+	}
+}
+
+// IsPrivate проверяет, содержит ли ключ секретный материал, который нельзя принимать от клиентов
+func (k *JWK) IsPrivate() bool {
+	return k.KeyType == KeyTypeOct || k.K != ""
+}
+
+// Thumbprint вычисляет отпечаток ключа по RFC 7638 (SHA-256, base64url)
+func (k *JWK) Thumbprint() (string, error) {
+	// Обязательные члены в лексикографическом порядке
+	var members interface{}
+	switch k.KeyType {
+	case KeyTypeEC:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Curve, k.KeyType, k.X, k.Y}
+	case KeyTypeRSA:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.KeyType, k.N}
+	default:
+		return "", fmt.Errorf("jose: thumbprint is not supported for key type %q", k.KeyType)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return encodeSegment(sum[:]), nil
+}
+
+// CertificateThumbprint вычисляет отпечаток x5t#S256 сертификата X.509 в DER
+// (RFC 7515, раздел 4.1.8)
+func CertificateThumbprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return encodeSegment(sum[:])
+}
+
+func curveParams(curve elliptic.Curve) (string, int, error) {
+	switch curve {
+	case elliptic.P256():
+		return "P-256", 32, nil
+	case elliptic.P384():
+		return "P-384", 48, nil
+	case elliptic.P521():
+		return "P-521", 66, nil
+	default:
+		return "", 0, fmt.Errorf("jose: unsupported curve")
+	}
+}
+
+func curveByName(name string) (elliptic.Curve, int, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), 32, nil
+	case "P-384":
+		return elliptic.P384(), 48, nil
+	case "P-521":
+		return elliptic.P521(), 66, nil
+	default:
+		return nil, 0, fmt.Errorf("jose: unsupported curve %q", name)
+	}
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,260 @@
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+// Алгоритмы подписи JWS
+const (
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+	RS256 = "RS256"
+	PS256 = "PS256"
+	HS256 = "HS256"
+)
+
+// ErrInvalidSignature подпись не соответствует ключу
+var ErrInvalidSignature = errors.New("jose: invalid signature")
+
+// AsymmetricAlgorithms алгоритмы с открытым ключом, принимаемые по умолчанию
+var AsymmetricAlgorithms = []string{ES256, ES384, ES512, RS256, PS256}
+
+// Header защищенный заголовок JWS
+type Header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+	// JWK открытый ключ, встроенный в заголовок (например, в DPoP proof)
+	JWK *JWK `json:"jwk,omitempty"`
+}
+
+// JWS разобранный, но еще не проверенный JWS в компактной сериализации
+type JWS struct {
+	Header  Header
+	Payload []byte
+
+	signingInput string
+	signature    []byte
+}
+
+// Parse разбирает JWS в компактной сериализации без проверки подписи.
+// Алгоритм "none" отклоняется.
+func Parse(compact string) (*JWS, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("jose: token must have three parts")
+	}
+
+	headerJSON, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid header encoding")
+	}
+	var header Header
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("jose: invalid header: %w", err)
+	}
+	if header.Algorithm == "" || strings.EqualFold(header.Algorithm, "none") {
+		return nil, fmt.Errorf("jose: unsigned tokens are not accepted")
+	}
+
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid payload encoding")
+	}
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("jose: invalid signature encoding")
+	}
+
+	return &JWS{
+		Header:       header,
+		Payload:      payload,
+		signingInput: parts[0] + "." + parts[1],
+		signature:    signature,
+	}, nil
+}
+
+// Claims разбирает полезную нагрузку как набор утверждений JWT
+func (j *JWS) Claims() (Claims, error) {
+	var claims Claims
+	if err := json.Unmarshal(j.Payload, &claims); err != nil {
+		return nil, fmt.Errorf("jose: invalid claims: %w", err)
+	}
+	if claims == nil {
+		return nil, fmt.Errorf("jose: claims must be a JSON object")
+	}
+	return claims, nil
+}
+
+// Verify проверяет подпись ключом, полученным из JWK.PublicKey или переданным напрямую.
+// Алгоритм заголовка должен входить в allowed и соответствовать типу ключа.
+func (j *JWS) Verify(key interface{}, allowed ...string) error {
+	if !contains(allowed, j.Header.Algorithm) {
+		return fmt.Errorf("jose: algorithm %s is not allowed", j.Header.Algorithm)
+	}
+
+	switch j.Header.Algorithm {
+	case ES256, ES384, ES512:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("jose: %s requires an EC key", j.Header.Algorithm)
+		}
+		hashFunc, size := ecdsaParams(j.Header.Algorithm)
+		if publicKey.Curve.Params().BitSize != ecdsaCurveBits(j.Header.Algorithm) {
+			return fmt.Errorf("jose: key curve does not match %s", j.Header.Algorithm)
+		}
+		if len(j.signature) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(j.signature[:size])
+		s := new(big.Int).SetBytes(j.signature[size:])
+		if !ecdsa.Verify(publicKey, digest(hashFunc, j.signingInput), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case RS256, PS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("jose: %s requires an RSA key", j.Header.Algorithm)
+		}
+		hashed := digest(crypto.SHA256, j.signingInput)
+		var err error
+		if j.Header.Algorithm == RS256 {
+			err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed, j.signature)
+		} else {
+			err = rsa.VerifyPSS(publicKey, crypto.SHA256, hashed, j.signature, nil)
+		}
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return fmt.Errorf("jose: %s requires a symmetric key", j.Header.Algorithm)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(j.signingInput))
+		if !hmac.Equal(mac.Sum(nil), j.signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("jose: unsupported algorithm %s", j.Header.Algorithm)
+	}
+}
+
+// Sign подписывает полезную нагрузку и возвращает JWS в компактной сериализации.
+// key — *ecdsa.PrivateKey, *rsa.PrivateKey или []byte для HS256.
+func Sign(header Header, payload interface{}, key interface{}) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("jose: encode header: %w", err)
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("jose: encode payload: %w", err)
+	}
+	signingInput := encodeSegment(headerJSON) + "." + encodeSegment(payloadJSON)
+
+	var signature []byte
+	switch header.Algorithm {
+	case ES256, ES384, ES512:
+		privateKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("jose: %s requires an EC private key", header.Algorithm)
+		}
+		hashFunc, size := ecdsaParams(header.Algorithm)
+		r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest(hashFunc, signingInput))
+		if err != nil {
+			return "", fmt.Errorf("jose: sign: %w", err)
+		}
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	case RS256, PS256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("jose: %s requires an RSA private key", header.Algorithm)
+		}
+		hashed := digest(crypto.SHA256, signingInput)
+		if header.Algorithm == RS256 {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hashed)
+		} else {
+			signature, err = rsa.SignPSS(rand.Reader, privateKey, crypto.SHA256, hashed, nil)
+		}
+		if err != nil {
+			return "", fmt.Errorf("jose: sign: %w", err)
+		}
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return "", fmt.Errorf("jose: %s requires a symmetric key", header.Algorithm)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	default:
+		return "", fmt.Errorf("jose: unsupported algorithm %s", header.Algorithm)
+	}
+
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+func ecdsaParams(alg string) (crypto.Hash, int) {
+	switch alg {
+	case ES384:
+		return crypto.SHA384, 48
+	case ES512:
+		return crypto.SHA512, 66
+	default:
+		return crypto.SHA256, 32
+	}
+}
+
+func ecdsaCurveBits(alg string) int {
+	switch alg {
+	case ES384:
+		return 384
+	case ES512:
+		return 521
+	default:
+		return 256
+	}
+}
+
+func digest(hashFunc crypto.Hash, input string) []byte {
+	var h hash.Hash
+	switch hashFunc {
+	case crypto.SHA384:
+		h = sha512.New384()
+	case crypto.SHA512:
+		h = sha512.New()
+	default:
+		h = sha256.New()
+	}
+	h.Write([]byte(input))
+	return h.Sum(nil)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
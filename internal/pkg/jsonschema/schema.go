@@ -0,0 +1,335 @@
+// Package jsonschema реализует подмножество JSON Schema, достаточное для
+// проверки структур вроде authorization_details: type, properties, required,
+// additionalProperties, items, enum, const, minimum, maximum, minLength,
+// maxLength, pattern, minItems и maxItems. Ссылки ($ref), композиция схем
+// (allOf, anyOf, oneOf) и format не поддерживаются; прочие ключевые слова
+// игнорируются, как того требует спецификация.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Schema скомпилированная схема
+type Schema struct {
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	noAdditional         bool
+	items                *Schema
+	enum                 []interface{}
+	constant             interface{}
+	hasConst             bool
+	minimum, maximum     *float64
+	minLength, maxLength *int
+	minItems, maxItems   *int
+	pattern              *regexp.Regexp
+}
+
+// document исходное представление схемы
+type document struct {
+	Type                 json.RawMessage            `json:"type"`
+	Properties           map[string]json.RawMessage `json:"properties"`
+	Required             []string                   `json:"required"`
+	AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	Items                json.RawMessage            `json:"items"`
+	Enum                 []interface{}              `json:"enum"`
+	Const                json.RawMessage            `json:"const"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	MinItems             *int                       `json:"minItems"`
+	MaxItems             *int                       `json:"maxItems"`
+	Pattern              *string                    `json:"pattern"`
+}
+
+// schemaTypes допустимые значения ключевого слова type
+var schemaTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "integer": true, "string": true,
+}
+
+// Compile разбирает схему в формате JSON
+func Compile(raw []byte) (*Schema, error) {
+	schema, err := compile(raw, "#")
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return schema, nil
+}
+
+func compile(raw []byte, path string) (*Schema, error) {
+	var flag bool
+	if err := json.Unmarshal(raw, &flag); err == nil {
+		// true принимает любое значение, false — никакое
+		if flag {
+			return &Schema{}, nil
+		}
+		return &Schema{enum: []interface{}{}}, nil
+	}
+
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean: %v", path, err)
+	}
+	schema := &Schema{
+		required:  doc.Required,
+		enum:      doc.Enum,
+		minimum:   doc.Minimum,
+		maximum:   doc.Maximum,
+		minLength: doc.MinLength,
+		maxLength: doc.MaxLength,
+		minItems:  doc.MinItems,
+		maxItems:  doc.MaxItems,
+	}
+
+	if len(doc.Type) > 0 {
+		var single string
+		if err := json.Unmarshal(doc.Type, &single); err == nil {
+			schema.types = []string{single}
+		} else if err := json.Unmarshal(doc.Type, &schema.types); err != nil {
+			return nil, fmt.Errorf("%s/type: must be a string or an array of strings", path)
+		}
+		for _, name := range schema.types {
+			if !schemaTypes[name] {
+				return nil, fmt.Errorf("%s/type: unknown type %q", path, name)
+			}
+		}
+	}
+
+	if len(doc.Properties) > 0 {
+		schema.properties = make(map[string]*Schema, len(doc.Properties))
+		for name, property := range doc.Properties {
+			compiled, err := compile(property, path+"/properties/"+name)
+			if err != nil {
+				return nil, err
+			}
+			schema.properties[name] = compiled
+		}
+	}
+	if len(doc.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(doc.AdditionalProperties, &allowed); err == nil {
+			schema.noAdditional = !allowed
+		} else {
+			compiled, err := compile(doc.AdditionalProperties, path+"/additionalProperties")
+			if err != nil {
+				return nil, err
+			}
+			schema.additionalProperties = compiled
+		}
+	}
+	if len(doc.Items) > 0 {
+		compiled, err := compile(doc.Items, path+"/items")
+		if err != nil {
+			return nil, err
+		}
+		schema.items = compiled
+	}
+	if len(doc.Const) > 0 {
+		if err := json.Unmarshal(doc.Const, &schema.constant); err != nil {
+			return nil, fmt.Errorf("%s/const: %v", path, err)
+		}
+		schema.hasConst = true
+	}
+	if doc.Pattern != nil {
+		pattern, err := regexp.Compile(*doc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s/pattern: %v", path, err)
+		}
+		schema.pattern = pattern
+	}
+	return schema, nil
+}
+
+// Validate проверяет значение, полученное из encoding/json (map[string]interface{},
+// []interface{}, string, float64 или json.Number, bool, nil). Ошибка указывает
+// путь к первому нарушению в формате JSON Pointer.
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate(value, "")
+}
+
+func (s *Schema) validate(value interface{}, path string) error {
+	if s.enum != nil && !containsValue(s.enum, value) {
+		return violation(path, "value is not one of the allowed values")
+	}
+	if s.hasConst && !equal(s.constant, value) {
+		return violation(path, "value must be %v", s.constant)
+	}
+	if len(s.types) > 0 && !s.matchesType(value) {
+		return violation(path, "value must be of type %v", s.types)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(v, path)
+	case []interface{}:
+		return s.validateArray(v, path)
+	case string:
+		return s.validateString(v, path)
+	case float64, json.Number:
+		number, _ := toFloat(v)
+		if s.minimum != nil && number < *s.minimum {
+			return violation(path, "value must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && number > *s.maximum {
+			return violation(path, "value must be at most %v", *s.maximum)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(object map[string]interface{}, path string) error {
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			return violation(path, "property %s is required", name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, declared := s.properties[name]
+		switch {
+		case declared:
+		case s.noAdditional:
+			return violation(path, "property %s is not allowed", name)
+		case s.additionalProperties != nil:
+			property = s.additionalProperties
+		default:
+			continue
+		}
+		if err := property.validate(object[name], path+"/"+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(array []interface{}, path string) error {
+	if s.minItems != nil && len(array) < *s.minItems {
+		return violation(path, "array must contain at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(array) > *s.maxItems {
+		return violation(path, "array must contain at most %d items", *s.maxItems)
+	}
+	if s.items == nil {
+		return nil
+	}
+	for i, item := range array {
+		if err := s.items.validate(item, path+"/"+strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(value, path string) error {
+	length := utf8.RuneCountInString(value)
+	if s.minLength != nil && length < *s.minLength {
+		return violation(path, "string must be at least %d characters long", *s.minLength)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		return violation(path, "string must be at most %d characters long", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		return violation(path, "string does not match pattern %s", s.pattern)
+	}
+	return nil
+}
+
+// matchesType проверяет значение по ключевому слову type
+func (s *Schema) matchesType(value interface{}) bool {
+	for _, name := range s.types {
+		switch name {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat(value); ok {
+				return true
+			}
+		case "integer":
+			if number, ok := toFloat(value); ok && number == math.Trunc(number) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// equal сравнивает значения JSON; числа сравниваются по величине
+func equal(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, allowed := range values {
+		if equal(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError нарушение схемы
+type ValidationError struct {
+	// Path путь к значению в формате JSON Pointer; пустой — корень документа
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+func violation(path, format string, args ...interface{}) error {
+	return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
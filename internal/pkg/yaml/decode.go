@@ -0,0 +1,454 @@
+// Package yaml реализует подмножество YAML, достаточное для конфигурационных
+// файлов сервиса: блочные отображения и последовательности, однострочные
+// flow-коллекции, строки в кавычках, числа, булевы значения и null.
+// Якоря, теги и многострочные скаляры не поддерживаются. Marshal выводит
+// документы в том же подмножестве.
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// line представляет значимую строку документа
+type line struct {
+	number  int
+	indent  int
+	content string
+}
+
+// Unmarshal разбирает YAML документ и заполняет v так же, как encoding/json.
+// Значение v должно быть указателем; поля сопоставляются по json тегам.
+func Unmarshal(data []byte, v interface{}) error {
+	value, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("yaml: convert document: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("yaml: %w", err)
+	}
+	return nil
+}
+
+// Parse разбирает YAML документ в map[string]interface{}, []interface{} или скаляр
+func Parse(data []byte) (interface{}, error) {
+	lines, err := splitLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &parser{lines: lines}
+	value, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		return nil, fmt.Errorf("yaml: line %d: unexpected indentation", l.number)
+	}
+	return value, nil
+}
+
+// splitLines отбрасывает пустые строки, комментарии и маркеры документа
+func splitLines(data string) ([]line, error) {
+	var lines []line
+	for i, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		content := strings.TrimRight(stripComment(raw), " \t")
+		trimmed := strings.TrimLeft(content, " ")
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, line{
+			number:  i + 1,
+			indent:  len(content) - len(trimmed),
+			content: trimmed,
+		})
+	}
+	return lines, nil
+}
+
+// stripComment удаляет комментарий, не находящийся внутри строки в кавычках
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+// parseBlock разбирает блок, все строки которого имеют указанный отступ
+func (p *parser) parseBlock(indent int) (interface{}, error) {
+	l := p.lines[p.pos]
+	if isSequenceItem(l.content) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *parser) parseSequence(indent int) (interface{}, error) {
+	items := make([]interface{}, 0)
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml: line %d: unexpected indentation", l.number)
+		}
+		if !isSequenceItem(l.content) {
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(l.content, "-"), " ")
+		if rest == "" {
+			p.pos++
+			value, err := p.parseNested(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+
+		if _, _, ok := splitKey(rest); (ok && !isFlow(rest)) || isSequenceItem(rest) {
+			// Элемент последовательности начинается с вложенного блока: переносим
+			// его первую строку на отступ содержимого и разбираем как обычный блок
+			p.lines[p.pos] = line{
+				number:  l.number,
+				indent:  indent + len(l.content) - len(rest),
+				content: rest,
+			}
+			value, err := p.parseBlock(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+
+		value, err := parseScalar(rest, l.number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		p.pos++
+	}
+	return items, nil
+}
+
+func (p *parser) parseMapping(indent int) (interface{}, error) {
+	result := make(map[string]interface{})
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml: line %d: unexpected indentation", l.number)
+		}
+		if isSequenceItem(l.content) {
+			break
+		}
+
+		key, rest, ok := splitKey(l.content)
+		if !ok {
+			return nil, fmt.Errorf("yaml: line %d: expected \"key: value\"", l.number)
+		}
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("yaml: line %d: duplicate key %q", l.number, key)
+		}
+		p.pos++
+
+		if rest != "" {
+			value, err := parseScalar(rest, l.number)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+			continue
+		}
+
+		// Последовательность под ключом допускается на том же отступе
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].content) {
+			value, err := p.parseSequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+			continue
+		}
+
+		value, err := p.parseNested(indent)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseNested разбирает вложенный блок с отступом больше parent или возвращает null
+func (p *parser) parseNested(parent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parent {
+		return nil, nil
+	}
+	return p.parseBlock(p.lines[p.pos].indent)
+}
+
+func isSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func isFlow(s string) bool {
+	return strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{")
+}
+
+// splitKey разделяет строку отображения на ключ и значение
+func splitKey(content string) (string, string, bool) {
+	if content == "" {
+		return "", "", false
+	}
+
+	if content[0] == '"' || content[0] == '\'' {
+		end := closingQuote(content)
+		if end < 0 || end+1 >= len(content) || content[end+1] != ':' {
+			return "", "", false
+		}
+		key, err := unquote(content[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		rest := content[end+2:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest), true
+	}
+
+	for i := 0; i < len(content); i++ {
+		if content[i] != ':' {
+			continue
+		}
+		if i+1 == len(content) || content[i+1] == ' ' {
+			return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// closingQuote возвращает позицию закрывающей кавычки строки, начинающейся с кавычки
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// parseScalar разбирает значение в строке: скаляр или flow-коллекцию
+func parseScalar(s string, number int) (interface{}, error) {
+	if s == "|" || s == ">" || strings.HasPrefix(s, "|") || strings.HasPrefix(s, ">") {
+		return nil, fmt.Errorf("yaml: line %d: block scalars are not supported", number)
+	}
+	if strings.HasPrefix(s, "&") || strings.HasPrefix(s, "*") || strings.HasPrefix(s, "!") {
+		return nil, fmt.Errorf("yaml: line %d: anchors, aliases and tags are not supported", number)
+	}
+
+	fp := &flowParser{input: s, number: number}
+	value, err := fp.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	fp.skipSpaces()
+	if fp.pos != len(fp.input) {
+		return nil, fmt.Errorf("yaml: line %d: unexpected %q", number, fp.input[fp.pos:])
+	}
+	return value, nil
+}
+
+// flowParser разбирает однострочные значения: [a, b], {k: v} и скаляры
+type flowParser struct {
+	input  string
+	pos    int
+	number int
+	depth  int
+}
+
+func (fp *flowParser) skipSpaces() {
+	for fp.pos < len(fp.input) && fp.input[fp.pos] == ' ' {
+		fp.pos++
+	}
+}
+
+func (fp *flowParser) parseValue() (interface{}, error) {
+	fp.skipSpaces()
+	if fp.pos >= len(fp.input) {
+		return nil, nil
+	}
+
+	switch fp.input[fp.pos] {
+	case '[':
+		return fp.parseFlowSequence()
+	case '{':
+		return fp.parseFlowMapping()
+	case '"', '\'':
+		end := closingQuote(fp.input[fp.pos:])
+		if end < 0 {
+			return nil, fmt.Errorf("yaml: line %d: unterminated string", fp.number)
+		}
+		value, err := unquote(fp.input[fp.pos : fp.pos+end+1])
+		if err != nil {
+			return nil, fmt.Errorf("yaml: line %d: invalid string: %w", fp.number, err)
+		}
+		fp.pos += end + 1
+		return value, nil
+	}
+
+	start := fp.pos
+	for fp.pos < len(fp.input) {
+		c := fp.input[fp.pos]
+		if fp.depth > 0 && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if fp.depth > 0 && c == ':' && (fp.pos+1 == len(fp.input) || fp.input[fp.pos+1] == ' ') {
+			break
+		}
+		fp.pos++
+	}
+	return resolvePlain(strings.TrimSpace(fp.input[start:fp.pos])), nil
+}
+
+func (fp *flowParser) parseFlowSequence() (interface{}, error) {
+	fp.pos++
+	fp.depth++
+	defer func() { fp.depth-- }()
+
+	items := make([]interface{}, 0)
+	for {
+		fp.skipSpaces()
+		if fp.pos >= len(fp.input) {
+			return nil, fmt.Errorf("yaml: line %d: unterminated flow sequence", fp.number)
+		}
+		if fp.input[fp.pos] == ']' {
+			fp.pos++
+			return items, nil
+		}
+
+		value, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+
+		fp.skipSpaces()
+		if fp.pos < len(fp.input) && fp.input[fp.pos] == ',' {
+			fp.pos++
+		}
+	}
+}
+
+func (fp *flowParser) parseFlowMapping() (interface{}, error) {
+	fp.pos++
+	fp.depth++
+	defer func() { fp.depth-- }()
+
+	result := make(map[string]interface{})
+	for {
+		fp.skipSpaces()
+		if fp.pos >= len(fp.input) {
+			return nil, fmt.Errorf("yaml: line %d: unterminated flow mapping", fp.number)
+		}
+		if fp.input[fp.pos] == '}' {
+			fp.pos++
+			return result, nil
+		}
+
+		key, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		fp.skipSpaces()
+		if fp.pos >= len(fp.input) || fp.input[fp.pos] != ':' {
+			return nil, fmt.Errorf("yaml: line %d: expected ':' in flow mapping", fp.number)
+		}
+		fp.pos++
+
+		value, err := fp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result[fmt.Sprint(key)] = value
+
+		fp.skipSpaces()
+		if fp.pos < len(fp.input) && fp.input[fp.pos] == ',' {
+			fp.pos++
+		}
+	}
+}
+
+// resolvePlain определяет тип скаляра без кавычек
+func resolvePlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if strings.Trim(s, "0123456789+-.eE") == "" {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
@@ -0,0 +1,201 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// indentStep число пробелов на уровень вложенности при выводе
+const indentStep = 2
+
+// Marshal сериализует v в YAML. Значение сначала кодируется через encoding/json,
+// поэтому учитываются json теги, а порядок полей структуры сохраняется.
+// Результат читается функцией Unmarshal.
+func Marshal(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+
+	var buf bytes.Buffer
+	switch node := value.(type) {
+	case orderedMap:
+		if len(node) == 0 {
+			buf.WriteString("{}\n")
+			break
+		}
+		writeMapping(&buf, node, 0)
+	case []interface{}:
+		if len(node) == 0 {
+			buf.WriteString("[]\n")
+			break
+		}
+		writeSequence(&buf, node, 0)
+	default:
+		buf.WriteString(formatScalar(node))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// orderedMap отображение с сохранением порядка ключей
+type orderedMap []keyValue
+
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// decodeOrdered читает JSON значение, сохраняя порядок ключей объектов
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		node := orderedMap{}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, keyValue{key: keyToken.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return node, err
+	case '[':
+		node := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, value)
+		}
+		_, err = decoder.Token()
+		return node, err
+	}
+	return nil, fmt.Errorf("unexpected delimiter %q", delim)
+}
+
+func writeMapping(w io.Writer, node orderedMap, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, kv := range node {
+		fmt.Fprintf(w, "%s%s:", pad, formatString(kv.key))
+		writeValue(w, kv.value, indent)
+	}
+}
+
+func writeSequence(w io.Writer, node []interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, item := range node {
+		switch value := item.(type) {
+		case orderedMap:
+			if len(value) == 0 {
+				fmt.Fprintf(w, "%s- {}\n", pad)
+				continue
+			}
+			// Первый ключ пишется в строке элемента, остальные выравниваются под ним
+			fmt.Fprintf(w, "%s- %s:", pad, formatString(value[0].key))
+			writeValue(w, value[0].value, indent+indentStep)
+			writeMapping(w, value[1:], indent+indentStep)
+		case []interface{}:
+			if len(value) == 0 {
+				fmt.Fprintf(w, "%s- []\n", pad)
+				continue
+			}
+			fmt.Fprintf(w, "%s-\n", pad)
+			writeSequence(w, value, indent+indentStep)
+		default:
+			fmt.Fprintf(w, "%s- %s\n", pad, formatScalar(value))
+		}
+	}
+}
+
+// writeValue дописывает значение после "ключ:" в текущей строке или блоком ниже
+func writeValue(w io.Writer, value interface{}, indent int) {
+	switch node := value.(type) {
+	case orderedMap:
+		if len(node) == 0 {
+			fmt.Fprint(w, " {}\n")
+			return
+		}
+		fmt.Fprint(w, "\n")
+		writeMapping(w, node, indent+indentStep)
+	case []interface{}:
+		if len(node) == 0 {
+			fmt.Fprint(w, " []\n")
+			return
+		}
+		fmt.Fprint(w, "\n")
+		writeSequence(w, node, indent+indentStep)
+	default:
+		fmt.Fprintf(w, " %s\n", formatScalar(node))
+	}
+}
+
+func formatScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return formatString(v)
+	}
+	return formatString(fmt.Sprint(value))
+}
+
+// formatString выводит строку без кавычек, если декодер прочитает ее обратно
+// как ту же строку, иначе в двойных кавычках
+func formatString(s string) string {
+	if needsQuotes(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	if resolved, ok := resolvePlain(s).(string); !ok || resolved != s {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
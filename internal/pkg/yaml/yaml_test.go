@@ -0,0 +1,149 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	doc := `
+# policies
+version: 1
+name: "quoted: value" # comment
+ratio: 0.5
+enabled: true
+missing: ~
+tags: [a, "b c", 3]
+limits: {read: 10, write: off}
+items:
+- id: first
+  values:
+    - x
+    - y
+- plain
+nested:
+  level:
+    key: 'it''s'
+`
+	got, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]interface{}{
+		"version": int64(1),
+		"name":    "quoted: value",
+		"ratio":   0.5,
+		"enabled": true,
+		"missing": nil,
+		"tags":    []interface{}{"a", "b c", int64(3)},
+		"limits":  map[string]interface{}{"read": int64(10), "write": "off"},
+		"items": []interface{}{
+			map[string]interface{}{"id": "first", "values": []interface{}{"x", "y"}},
+			"plain",
+		},
+		"nested": map[string]interface{}{
+			"level": map[string]interface{}{"key": "it's"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestParseEmptyDocument(t *testing.T) {
+	got, err := Parse([]byte("---\n# nothing here\n"))
+	if err != nil || got != nil {
+		t.Fatalf("Parse = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"tab indentation":   "a:\n\tb: 1\n",
+		"block scalar":      "a: |\n  text\n",
+		"anchor":            "a: &x 1\n",
+		"duplicate key":     "a: 1\na: 2\n",
+		"unterminated":      "a: \"open\n",
+		"unterminated flow": "a: [1, 2\n",
+		"bad indentation":   "a: 1\n  b: 2\n",
+		"not a mapping":     "a: 1\nplain\n",
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(doc)); err == nil {
+				t.Fatalf("Parse(%q) succeeded, want error", doc)
+			} else if !strings.HasPrefix(err.Error(), "yaml: ") {
+				t.Fatalf("error %q has no yaml prefix", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalUsesJSONTags(t *testing.T) {
+	type rule struct {
+		ID      string   `json:"id"`
+		Actions []string `json:"actions"`
+		Limit   int      `json:"limit"`
+	}
+	var doc struct {
+		Rules []rule `json:"rules"`
+	}
+	data := "rules:\n  - id: r1\n    actions: [read, write]\n    limit: 5\n"
+	if err := Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := []rule{{ID: "r1", Actions: []string{"read", "write"}, Limit: 5}}
+	if !reflect.DeepEqual(doc.Rules, want) {
+		t.Fatalf("Unmarshal = %#v, want %#v", doc.Rules, want)
+	}
+
+	if err := Unmarshal([]byte("rules: 1\n"), &doc); err == nil {
+		t.Fatal("Unmarshal with a type mismatch succeeded, want error")
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	type client struct {
+		Name     string            `json:"name"`
+		Scopes   []string          `json:"scopes"`
+		Labels   map[string]string `json:"labels"`
+		Active   bool              `json:"active"`
+		Weight   float64           `json:"weight"`
+		Optional *string           `json:"optional"`
+	}
+	in := []client{{
+		Name:   "needs: quotes",
+		Scopes: []string{"true", "007", "- dash", "", " padded "},
+		Labels: map[string]string{"team": "#ops", "empty": ""},
+		Active: true,
+		Weight: 1.5,
+	}}
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out []client
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip:\n got %#v\nwant %#v\ndocument:\n%s", out, in, data)
+	}
+}
+
+func TestMarshalEmptyCollections(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{map[string]int{}, "{}\n"},
+		{[]int{}, "[]\n"},
+	}
+	for _, tt := range tests {
+		data, err := Marshal(tt.value)
+		if err != nil || string(data) != tt.want {
+			t.Fatalf("Marshal(%#v) = %q, %v; want %q", tt.value, data, err, tt.want)
+		}
+	}
+}